@@ -0,0 +1,116 @@
+// Command reindex rebuilds the search indices from MySQL without downtime.
+//
+// Each collection is bulk loaded into a new versioned index while the live one keeps
+// serving, then both of its aliases are swapped atomically. An interrupted reindex
+// resumes from its last batch when run again.
+//
+//	go run ./cmd/reindex -index posts
+//	go run ./cmd/reindex -index all -delete-old
+//	go run ./cmd/reindex -status
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/kobayashirei/airy/internal/config"
+	"github.com/kobayashirei/airy/internal/database"
+	"github.com/kobayashirei/airy/internal/logger"
+	"github.com/kobayashirei/airy/internal/repository"
+	"github.com/kobayashirei/airy/internal/search"
+	"github.com/kobayashirei/airy/internal/service"
+	"go.uber.org/zap"
+)
+
+func main() {
+	index := flag.String("index", "all", "collection to reindex: posts, users or all")
+	batchSize := flag.Int("batch", 500, "rows per bulk request")
+	restart := flag.Bool("restart", false, "discard an unfinished reindex instead of resuming it")
+	deleteOld := flag.Bool("delete-old", false, "delete the replaced indices after the swap")
+	status := flag.Bool("status", false, "print the state of the last reindexes and exit")
+	flag.Parse()
+
+	aliases := []string{search.PostIndex, search.UserIndex}
+	if *index != "all" {
+		if _, ok := search.IndexSpecs[*index]; !ok {
+			fmt.Printf("Unknown index %q, expected posts, users or all\n", *index)
+			os.Exit(2)
+		}
+		aliases = []string{*index}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("Failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+	if err := logger.Init(&cfg.Log); err != nil {
+		fmt.Printf("Failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer logger.Sync()
+
+	// Stop between batches on interrupt; the reindex resumes from there
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	esClient, err := search.NewClient(cfg, logger.Logger)
+	if err != nil {
+		logger.Fatal("Failed to connect to Elasticsearch", zap.Error(err))
+	}
+	defer esClient.Close()
+
+	if *status {
+		reindexer := service.NewSearchReindexer(esClient, nil, nil, nil, logger.Logger)
+		for _, alias := range aliases {
+			state, err := reindexer.Status(ctx, alias)
+			if err != nil {
+				logger.Fatal("Failed to get reindex status", zap.String("index", alias), zap.Error(err))
+			}
+			printStatus(alias, state)
+		}
+		return
+	}
+
+	if err := database.Init(&cfg.Database); err != nil {
+		logger.Fatal("Failed to initialize database", zap.Error(err))
+	}
+	defer database.Close()
+
+	db := database.GetDB()
+	reindexer := service.NewSearchReindexer(
+		esClient,
+		repository.NewPostRepository(db),
+		repository.NewUserRepository(db),
+		repository.NewBatchRepository(db),
+		logger.Logger,
+	)
+
+	for _, alias := range aliases {
+		state, err := reindexer.Reindex(ctx, alias, service.ReindexOptions{
+			BatchSize: *batchSize,
+			Restart:   *restart,
+			DeleteOld: *deleteOld,
+		})
+		if err != nil {
+			logger.Fatal("Reindex failed, run again to resume", zap.String("index", alias), zap.Error(err))
+		}
+		printStatus(alias, state)
+	}
+}
+
+// printStatus prints the state of a collection's last reindex
+func printStatus(alias string, state *service.ReindexState) {
+	if state == nil {
+		fmt.Printf("%s: never reindexed\n", alias)
+		return
+	}
+	fmt.Printf("%s: %s into %s, %d/%d copied, started %s, updated %s\n",
+		alias, state.Phase, state.Index, state.Copied, state.Total,
+		state.StartedAt.Format(time.RFC3339), state.UpdatedAt.Format(time.RFC3339))
+}
@@ -17,8 +17,14 @@ import (
 	"github.com/kobayashirei/airy/internal/database"
 	"github.com/kobayashirei/airy/internal/logger"
 	"github.com/kobayashirei/airy/internal/middleware"
+	"github.com/kobayashirei/airy/internal/mq"
+	"github.com/kobayashirei/airy/internal/pagination"
+	"github.com/kobayashirei/airy/internal/repository"
 	"github.com/kobayashirei/airy/internal/response"
 	appRouter "github.com/kobayashirei/airy/internal/router"
+	"github.com/kobayashirei/airy/internal/search"
+	"github.com/kobayashirei/airy/internal/service"
+	"github.com/kobayashirei/airy/internal/taskpool"
 	"github.com/kobayashirei/airy/internal/version"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
@@ -46,6 +52,7 @@ func main() {
 		zap.String("github", version.GitHub),
 		zap.String("website", version.Website),
 		zap.String("mode", cfg.Server.Mode),
+		zap.String("role", cfg.Server.Role),
 	)
 
 	// Initialize database (optional)
@@ -138,6 +145,10 @@ func main() {
 			}
 		}
 		if database.GetDB() != nil {
+			// Keep built-in roles and permissions in sync with the route policy table
+			if err := database.SeedRolesAndPermissions(); err != nil {
+				logger.Warn("Failed to seed roles and permissions", zap.Error(err))
+			}
 			defer database.Close()
 		}
 	} else {
@@ -154,11 +165,206 @@ func main() {
 		logger.Warn("Redis initialization skipped (ENABLE_REDIS=false)")
 	}
 
+	// Initialize shared infrastructure for the content routes
+	deps := &appRouter.Dependencies{Producer: version.Name + "/" + version.Version}
+
+	// Task pool for async work (view counts, cache refresh, ...)
+	poolCfg := taskpool.DefaultConfig()
+	if cfg.Pool.Size > 0 {
+		poolCfg.Size = cfg.Pool.Size
+	}
+	poolCfg.Logger = logger.Logger
+	if cfg.Pool.RetryMaxAttempts > 0 {
+		poolCfg.Retry = taskpool.RetryPolicy{
+			MaxAttempts: cfg.Pool.RetryMaxAttempts,
+			BaseDelay:   cfg.Pool.RetryBaseDelay,
+			MaxDelay:    cfg.Pool.RetryMaxDelay,
+		}
+	}
+	if pool, err := taskpool.NewPool(poolCfg); err != nil {
+		logger.Warn("Failed to create task pool, async tasks disabled", zap.Error(err))
+	} else {
+		deps.TaskPool = pool
+		defer func() {
+			if err := pool.ReleaseTimeout(5 * time.Second); err != nil {
+				logger.Warn("Task pool did not drain before shutdown", zap.Error(err))
+			}
+		}()
+	}
+
+	// Message queue (optional, degraded mode publishes no events)
+	retryPolicy := mq.RetryPolicy{
+		MaxAttempts: cfg.MQ.RetryMaxAttempts,
+		BaseDelay:   cfg.MQ.RetryBaseDelay,
+		MaxDelay:    cfg.MQ.RetryMaxDelay,
+	}
+	if cfg.Features.EnableMQ && cfg.MQ.Driver == config.MQDriverMemory {
+		// Single node: events are routed and consumed in process
+		memoryQueue := mq.NewMemoryQueue(mq.MemoryConfig{
+			BufferSize:  cfg.MQ.MemoryBufferSize,
+			Concurrency: cfg.MQ.Concurrency,
+			Retry:       retryPolicy,
+			Logger:      logger.Logger,
+		})
+		deps.MessageQueue = memoryQueue
+		defer func() {
+			if err := memoryQueue.Close(); err != nil {
+				logger.Warn("In-memory message queue did not drain before shutdown", zap.Error(err))
+			}
+		}()
+		logger.Info("Using in-memory message queue (MQ_DRIVER=memory)")
+	} else if cfg.Features.EnableMQ {
+		rabbit, err := mq.NewRabbitMQ(&mq.Config{
+			URL:         cfg.MQ.GetAddr(),
+			QueuePrefix: cfg.MQ.QueuePrefix,
+			Prefetch:    cfg.MQ.Prefetch,
+			Concurrency: cfg.MQ.Concurrency,
+			Retry:       retryPolicy,
+
+			ReconnectBaseDelay: cfg.MQ.ReconnectBaseDelay,
+			ReconnectMaxDelay:  cfg.MQ.ReconnectMaxDelay,
+			ConfirmTimeout:     cfg.MQ.ConfirmTimeout,
+			SpoolDir:           cfg.MQ.SpoolDir,
+			SpoolMaxAttempts:   cfg.MQ.SpoolMaxAttempts,
+			SpoolInterval:      cfg.MQ.SpoolInterval,
+			Logger:             logger.Logger,
+		})
+		if err != nil {
+			logger.Warn("Failed to connect to message queue, continuing without events", zap.Error(err))
+		} else {
+			deps.MessageQueue = rabbit
+			defer rabbit.Close()
+		}
+	} else {
+		logger.Warn("Message queue initialization skipped (ENABLE_MQ=false)")
+	}
+
+	// Transactional outbox: services record events with their writes and the relay publishes them
+	if deps.MessageQueue != nil && database.GetDB() != nil {
+		outboxRepo := repository.NewOutboxRepository(database.GetDB())
+		deps.Outbox = service.NewOutboxQueue(outboxRepo)
+
+		relay := service.NewOutboxRelay(outboxRepo, deps.MessageQueue, service.OutboxRelayConfig{
+			PollInterval: cfg.MQ.OutboxPollInterval,
+			BatchSize:    cfg.MQ.OutboxBatchSize,
+			MaxAttempts:  cfg.MQ.OutboxMaxAttempts,
+			Retention:    cfg.MQ.OutboxRetention,
+			Logger:       logger.Logger,
+		})
+		relay.Start()
+		defer relay.Stop()
+	}
+
+	// Keep dead-lettered events in the database for the admin API to inspect and replay
+	if dlq, ok := deps.MessageQueue.(mq.DeadLetterQueue); ok && database.GetDB() != nil {
+		deadLetterService := service.NewDeadLetterService(
+			repository.NewDeadLetterRepository(database.GetDB()),
+			repository.NewAdminLogRepository(database.GetDB()),
+			dlq,
+		)
+		if err := dlq.SubscribeDeadLetters(deadLetterService.Record); err != nil {
+			logger.Warn("Failed to subscribe to dead letters", zap.Error(err))
+		}
+	}
+
+	// Elasticsearch (optional, degraded mode disables search)
+	if cfg.Features.EnableSearch {
+		esClient, err := search.NewClient(cfg, logger.Logger)
+		if err != nil {
+			logger.Warn("Failed to connect to Elasticsearch, search disabled", zap.Error(err))
+		} else {
+			deps.SearchClient = esClient
+			// Searches and writes go through aliases, which must exist before the first write
+			for _, spec := range []search.IndexSpec{search.PostIndexSpec, search.UserIndexSpec} {
+				if err := esClient.EnsureIndex(context.Background(), spec); err != nil {
+					logger.Warn("Failed to ensure search index", zap.String("index", spec.Alias), zap.Error(err))
+				}
+			}
+		}
+	} else {
+		logger.Warn("Elasticsearch initialization skipped (ENABLE_ES=false)")
+	}
+
+	// Common tasks submitted to the task pool by name
+	if deps.TaskPool != nil && database.GetDB() != nil {
+		if err := appRouter.RegisterTasks(cfg, deps); err != nil {
+			logger.Warn("Failed to register tasks", zap.Error(err))
+		}
+	}
+
+	// Durable job queue for the registered tasks; every replica may enqueue, worker replicas run them
+	if deps.TaskPool != nil && cache.GetClient() != nil {
+		deps.Jobs = taskpool.NewJobQueue(cache.GetClient(), deps.TaskPool.Registry(), taskpool.JobQueueConfig{
+			Concurrency:       cfg.Workers.Concurrency["jobs"],
+			PollInterval:      cfg.Jobs.PollInterval,
+			VisibilityTimeout: cfg.Jobs.VisibilityTimeout,
+			Retry: taskpool.RetryPolicy{
+				MaxAttempts: cfg.Jobs.RetryMaxAttempts,
+				BaseDelay:   cfg.Jobs.RetryBaseDelay,
+				MaxDelay:    cfg.Jobs.RetryMaxDelay,
+			},
+			MaxDead: cfg.Jobs.MaxDead,
+			Logger:  logger.Logger,
+		})
+		if cfg.Server.RunsWorkers() && cfg.Workers.Enabled("jobs") {
+			appRouter.SchedulePeriodicJobs(cfg, deps)
+			deps.Jobs.Start()
+			// Deferred after the task pool's release, so it runs first
+			defer func() {
+				ctx, cancel := context.WithTimeout(context.Background(), cfg.Workers.DrainTimeout)
+				defer cancel()
+				if err := deps.Jobs.Stop(ctx); err != nil {
+					logger.Warn("Jobs did not finish before shutdown, they run again after their visibility timeout", zap.Error(err))
+				}
+			}()
+
+			// Enqueue the publishing jobs of scheduled posts, should Redis have lost them
+			if database.GetDB() != nil {
+				scheduler := service.NewPostScheduler(deps.Jobs, repository.NewPostRepository(database.GetDB()), logger.Logger)
+				if scheduled, err := scheduler.Resync(context.Background()); err != nil {
+					logger.Warn("Failed to resync scheduled posts", zap.Error(err))
+				} else {
+					logger.Info("Scheduled posts resynced", zap.Int("posts", scheduled))
+				}
+			}
+		}
+	}
+
+	// Background workers consume the events; API-only replicas leave them to worker replicas
+	if cfg.Server.RunsWorkers() && deps.MessageQueue != nil && database.GetDB() != nil {
+		deps.Workers = appRouter.SetupWorkers(cfg, deps)
+		if err := deps.Workers.Start(); err != nil {
+			logger.Error("Some workers failed to start", zap.Error(err))
+		}
+		// Deferred after the message queue's Close, so it runs first
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), cfg.Workers.DrainTimeout)
+			defer cancel()
+			if err := deps.Workers.Stop(ctx); err != nil {
+				logger.Warn("Workers did not drain before shutdown", zap.Error(err))
+			}
+		}()
+	} else if cfg.Server.RunsWorkers() {
+		logger.Warn("Workers not started, they need the message queue and the database")
+	}
+
+	// Pagination cursors must verify on every replica, so sign them with a shared secret
+	cursorSecret := cfg.Security.CursorSecret
+	if cursorSecret == "" {
+		cursorSecret = cfg.JWT.Secret
+	}
+	pagination.SetSecret([]byte(cursorSecret))
+
 	// Set Gin mode
 	gin.SetMode(cfg.Server.Mode)
 
 	// Create router
-	router := setupRouter(cfg)
+	router := setupRouter(cfg, deps)
+
+	// Refuse to start when a mutating route is missing from the route policy table
+	if err := appRouter.ValidateRouteGuards(router.Routes()); err != nil {
+		logger.Fatal("Route guard check failed", zap.Error(err))
+	}
 
 	// Create HTTP server
 	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
@@ -208,7 +414,7 @@ func main() {
 }
 
 // setupRouter configures and returns the Gin router
-func setupRouter(cfg *config.Config) *gin.Engine {
+func setupRouter(cfg *config.Config, deps *appRouter.Dependencies) *gin.Engine {
 	router := gin.New()
 
 	// Apply middleware
@@ -276,6 +482,57 @@ func setupRouter(cfg *config.Config) *gin.Engine {
 			payload["cache"] = "disabled"
 		}
 
+		// Message queue and search are optional; losing them only degrades the service
+		if !cfg.Features.EnableMQ {
+			payload["message_queue"] = "disabled"
+		} else if deps.MessageQueue == nil {
+			payload["message_queue"] = "unavailable"
+			payload["status"] = "degraded"
+		} else if reporter, ok := deps.MessageQueue.(mq.HealthReporter); ok {
+			// A lost connection or a dead consumer means events pile up unhandled
+			health := reporter.Health()
+			payload["consumers"] = health.Consumers
+			switch {
+			case !health.Connected:
+				payload["message_queue"] = "reconnecting"
+				payload["status"] = "degraded"
+			case !health.Healthy():
+				payload["message_queue"] = "degraded"
+				payload["status"] = "degraded"
+			default:
+				payload["message_queue"] = "healthy"
+			}
+		} else {
+			payload["message_queue"] = "healthy"
+		}
+
+		// A worker that failed to subscribe leaves its events unhandled
+		if deps.Workers != nil {
+			workers := deps.Workers.Status()
+			payload["workers"] = workers
+			for _, worker := range workers {
+				if worker.State == service.WorkerStateFailed {
+					payload["status"] = "degraded"
+				}
+			}
+		}
+
+		if !cfg.Features.EnableSearch {
+			payload["search"] = "disabled"
+		} else if deps.SearchClient == nil {
+			payload["search"] = "unavailable"
+			payload["status"] = "degraded"
+		} else {
+			payload["search"] = "healthy"
+		}
+		// Searches fail over to MySQL while Elasticsearch is unavailable
+		if search := deps.SearchStatus(); search != nil {
+			payload["search_backend"] = search
+			if search.Breaker == service.SearchBreakerOpen {
+				payload["status"] = "degraded"
+			}
+		}
+
 		if _, ok := payload["status"]; !ok {
 			payload["status"] = "healthy"
 		}
@@ -336,6 +593,14 @@ func setupRouter(cfg *config.Config) *gin.Engine {
 	// Metrics endpoint for Prometheus
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
+	// Worker replicas serve only health, version and metrics
+	if !cfg.Server.ServesAPI() {
+		router.NoRoute(func(c *gin.Context) {
+			response.NotFound(c, "Route not found")
+		})
+		return router
+	}
+
 	// API v1 routes
 	v1 := router.Group("/api/v1")
 	{
@@ -361,8 +626,29 @@ func setupRouter(cfg *config.Config) *gin.Engine {
 		// Setup user profile routes
 		appRouter.SetupUserProfileRoutes(v1, cfg)
 
+		// Setup follow routes
+		appRouter.SetupFollowRoutes(v1, cfg, deps)
+
+		// Setup favorite routes
+		appRouter.SetupFavoriteRoutes(v1, cfg, deps)
+
 		// Setup admin routes
-		appRouter.SetupAdminRoutes(v1, cfg)
+		appRouter.SetupAdminRoutes(v1, cfg, deps)
+
+		// Setup post routes
+		appRouter.SetupPostRoutes(v1, cfg, deps)
+
+		// Setup comment routes
+		appRouter.SetupCommentRoutes(v1, cfg, deps)
+
+		// Setup vote routes
+		appRouter.SetupVoteRoutes(v1, cfg, deps)
+
+		// Setup feed routes
+		appRouter.SetupFeedRoutes(v1, cfg, deps)
+
+		// Setup search routes
+		appRouter.SetupSearchRoutes(v1, cfg, deps)
 	}
 
 	// 404 handler
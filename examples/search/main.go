@@ -12,6 +12,7 @@ import (
 	"github.com/kobayashirei/airy/internal/repository"
 	"github.com/kobayashirei/airy/internal/search"
 	"github.com/kobayashirei/airy/internal/service"
+	"go.uber.org/zap"
 )
 
 // This example demonstrates how to set up and use the search system
@@ -23,50 +24,54 @@ func main() {
 	}
 
 	// Initialize logger
-	log := logger.NewLogger(cfg.Log.Level, cfg.Log.Output)
+	if err := logger.Init(&cfg.Log); err != nil {
+		log.Fatal("Failed to initialize logger:", err)
+	}
+	log := logger.Logger
 
 	// Initialize database
-	db, err := database.NewDatabase(&cfg.Database, log)
-	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+	if err := database.Init(&cfg.Database); err != nil {
+		log.Fatal("Failed to connect to database", zap.Error(err))
 	}
+	defer database.Close()
+	db := database.GetDB()
 
 	// Initialize Elasticsearch client
 	esClient, err := search.NewClient(cfg, log)
 	if err != nil {
-		log.Fatal("Failed to create Elasticsearch client:", err)
+		log.Fatal("Failed to create Elasticsearch client", zap.Error(err))
 	}
 
 	// Initialize repositories
-	userRepo := repository.NewUserRepository(db.DB)
-	postRepo := repository.NewPostRepository(db.DB)
-	circleRepo := repository.NewCircleRepository(db.DB)
-	profileRepo := repository.NewUserProfileRepository(db.DB)
-	statsRepo := repository.NewUserStatsRepository(db.DB)
+	userRepo := repository.NewUserRepository(db)
+	postRepo := repository.NewPostRepository(db)
+	circleRepo := repository.NewCircleRepository(db)
+	profileRepo := repository.NewUserProfileRepository(db)
+	statsRepo := repository.NewUserStatsRepository(db)
 
-	// Initialize search service
+	// Initialize search service on Elasticsearch, without a fallback
 	searchService := service.NewSearchService(
-		esClient,
-		userRepo,
-		postRepo,
-		circleRepo,
+		service.NewESSearchBackend(esClient, userRepo, postRepo, circleRepo, log),
+		nil,
+		service.SearchBreakerOptions{},
 		log,
 	)
 
 	// Initialize indices
 	ctx := context.Background()
 	if err := searchService.InitializeIndices(ctx); err != nil {
-		log.Fatal("Failed to initialize search indices:", err)
+		log.Fatal("Failed to initialize search indices", zap.Error(err))
 	}
 	fmt.Println("✓ Search indices initialized")
 
 	// Initialize message queue
 	mqConfig := &mq.Config{
-		URL: cfg.MQ.GetAddr(),
+		URL:    cfg.MQ.GetAddr(),
+		Logger: log,
 	}
-	messageQueue, err := mq.NewRabbitMQ(mqConfig, log)
+	messageQueue, err := mq.NewRabbitMQ(mqConfig)
 	if err != nil {
-		log.Fatal("Failed to connect to message queue:", err)
+		log.Fatal("Failed to connect to message queue", zap.Error(err))
 	}
 	defer messageQueue.Close()
 
@@ -82,7 +87,7 @@ func main() {
 
 	// Subscribe to events
 	if err := searchConsumer.Subscribe(messageQueue); err != nil {
-		log.Fatal("Failed to subscribe to search events:", err)
+		log.Fatal("Failed to subscribe to search events", zap.Error(err))
 	}
 	fmt.Println("✓ Search consumer subscribed to events")
 
@@ -97,7 +102,7 @@ func main() {
 
 	results, err := searchService.SearchPosts(ctx, searchQuery)
 	if err != nil {
-		log.Fatal("Failed to search posts:", err)
+		log.Fatal("Failed to search posts", zap.Error(err))
 	}
 
 	fmt.Printf("Found %d posts matching 'golang'\n", results.Total)
@@ -120,7 +125,7 @@ func main() {
 
 	circleResults, err := searchService.SearchPosts(ctx, circleSearchQuery)
 	if err != nil {
-		log.Fatal("Failed to search posts in circle:", err)
+		log.Fatal("Failed to search posts in circle", zap.Error(err))
 	}
 
 	fmt.Printf("Found %d posts in circle 1\n", circleResults.Total)
@@ -140,7 +145,7 @@ func main() {
 
 	tagResults, err := searchService.SearchPosts(ctx, tagSearchQuery)
 	if err != nil {
-		log.Fatal("Failed to search posts by tag:", err)
+		log.Fatal("Failed to search posts by tag", zap.Error(err))
 	}
 
 	fmt.Printf("Found %d posts with tag 'tutorial'\n", tagResults.Total)
@@ -160,7 +165,7 @@ func main() {
 
 	userResults, err := searchService.SearchUsers(ctx, userSearchQuery)
 	if err != nil {
-		log.Fatal("Failed to search users:", err)
+		log.Fatal("Failed to search users", zap.Error(err))
 	}
 
 	fmt.Printf("Found %d users matching 'john'\n", userResults.Total)
@@ -20,13 +20,17 @@ var (
 type Claims struct {
 	UserID int64    `json:"user_id"`
 	Roles  []string `json:"roles"`
+	// CircleRoles holds circle-scoped role names keyed by circle ID
+	CircleRoles map[int64][]string `json:"circle_roles,omitempty"`
 	jwt.RegisteredClaims
 }
 
 // JWTService handles JWT token operations
 type JWTService interface {
 	GenerateToken(userID int64, roles []string) (string, error)
+	GenerateTokenWithCircleRoles(userID int64, roles []string, circleRoles map[int64][]string) (string, error)
 	ParseToken(tokenString string) (*Claims, error)
+	ParseRefreshToken(tokenString string) (*Claims, error)
 	RefreshToken(tokenString string) (string, error)
 }
 
@@ -46,10 +50,16 @@ func NewJWTService(secretKey string, expiration time.Duration) JWTService {
 
 // GenerateToken generates a new JWT token
 func (s *jwtService) GenerateToken(userID int64, roles []string) (string, error) {
+	return s.GenerateTokenWithCircleRoles(userID, roles, nil)
+}
+
+// GenerateTokenWithCircleRoles generates a new JWT token carrying circle-scoped roles
+func (s *jwtService) GenerateTokenWithCircleRoles(userID int64, roles []string, circleRoles map[int64][]string) (string, error) {
 	now := time.Now()
 	claims := &Claims{
-		UserID: userID,
-		Roles:  roles,
+		UserID:      userID,
+		Roles:       roles,
+		CircleRoles: circleRoles,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(now.Add(s.expiration)),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -85,9 +95,9 @@ func (s *jwtService) ParseToken(tokenString string) (*Claims, error) {
 	return nil, ErrInvalidToken
 }
 
-// RefreshToken refreshes an existing token
-func (s *jwtService) RefreshToken(tokenString string) (string, error) {
-	// Parse the token without validating expiration
+// ParseRefreshToken verifies the token signature and returns its claims
+// without validating expiration, so an expired token can still be refreshed
+func (s *jwtService) ParseRefreshToken(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, ErrInvalidToken
@@ -96,14 +106,24 @@ func (s *jwtService) RefreshToken(tokenString string) (string, error) {
 	}, jwt.WithoutClaimsValidation())
 
 	if err != nil {
-		return "", ErrInvalidToken
+		return nil, ErrInvalidToken
 	}
 
 	claims, ok := token.Claims.(*Claims)
 	if !ok {
-		return "", ErrInvalidToken
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+// RefreshToken refreshes an existing token
+func (s *jwtService) RefreshToken(tokenString string) (string, error) {
+	claims, err := s.ParseRefreshToken(tokenString)
+	if err != nil {
+		return "", err
 	}
 
 	// Generate a new token with the same user ID and roles
-	return s.GenerateToken(claims.UserID, claims.Roles)
+	return s.GenerateTokenWithCircleRoles(claims.UserID, claims.Roles, claims.CircleRoles)
 }
@@ -17,6 +17,7 @@ const (
 	PrefixToken        = "token"
 	PrefixNotification = "notification"
 	PrefixConversation = "conversation"
+	PrefixEvent        = "processed_event"
 )
 
 // KeyGenerator provides methods to generate cache keys
@@ -123,6 +124,12 @@ func (kg *KeyGenerator) ConversationListKey(userID int64) string {
 	return fmt.Sprintf("%s:%s:%d", PrefixConversation, PrefixUser, userID)
 }
 
+// ProcessedEventKey generates a cache key recording a consumer group's handling of an event
+// Format: processed_event:{group}:{event_id}
+func (kg *KeyGenerator) ProcessedEventKey(group, eventID string) string {
+	return fmt.Sprintf("%s:%s:%s", PrefixEvent, group, eventID)
+}
+
 // Standalone key generation functions for convenience
 
 // UserKey generates a cache key for user data
@@ -150,6 +157,16 @@ func GetUserFeedKey(userID int64) string {
 	return fmt.Sprintf("%s:%s:%d", PrefixFeed, PrefixUser, userID)
 }
 
+// GetUserOutboxKey generates a cache key for an author's outbox (posts pulled by followers at read time)
+func GetUserOutboxKey(authorID int64) string {
+	return fmt.Sprintf("%s:outbox:%d", PrefixFeed, authorID)
+}
+
+// GetPostFeedsKey generates a cache key for the set of feed keys a post was written to
+func GetPostFeedsKey(postID int64) string {
+	return fmt.Sprintf("%s:%s:%d:feeds", PrefixFeed, PrefixPost, postID)
+}
+
 // GetCircleFeedKey generates a cache key for circle feed
 func GetCircleFeedKey(circleID int64) string {
 	return fmt.Sprintf("%s:%s:%d", PrefixFeed, PrefixCircle, circleID)
@@ -66,6 +66,12 @@ func TestKeyGenerator_CircleFeedKey(t *testing.T) {
 	assert.Equal(t, "timeline:circle:300", key)
 }
 
+func TestKeyGenerator_ProcessedEventKey(t *testing.T) {
+	kg := NewKeyGenerator()
+	key := kg.ProcessedEventKey("votes", "9b2f6c1e")
+	assert.Equal(t, "processed_event:votes:9b2f6c1e", key)
+}
+
 func TestKeyGenerator_SessionKey(t *testing.T) {
 	kg := NewKeyGenerator()
 	key := kg.SessionKey("abc123token")
@@ -101,3 +107,13 @@ func TestKeyGenerator_ConversationListKey(t *testing.T) {
 	key := kg.ConversationListKey(123)
 	assert.Equal(t, "conversation:user:123", key)
 }
+
+func TestGetUserOutboxKey(t *testing.T) {
+	key := GetUserOutboxKey(42)
+	assert.Equal(t, "timeline:outbox:42", key)
+}
+
+func TestGetPostFeedsKey(t *testing.T) {
+	key := GetPostFeedsKey(7)
+	assert.Equal(t, "timeline:post:7:feeds", key)
+}
@@ -2,6 +2,8 @@ package config
 
 import (
     "fmt"
+    "strconv"
+    "strings"
     "time"
 
     "github.com/joho/godotenv"
@@ -14,6 +16,7 @@ type Config struct {
 	Database  DatabaseConfig
 	Redis     RedisConfig
 	ES        ESConfig
+	Search    SearchConfig
 	JWT       JWTConfig
 	MQ        MQConfig
 	Log       LogConfig
@@ -24,18 +27,38 @@ type Config struct {
 	RateLimit RateLimitConfig
 	Security  SecurityConfig
 	Features  FeaturesConfig
+	Workers   WorkersConfig
+	Jobs      JobsConfig
 }
 
+// Server roles
+const (
+	ServerRoleAll    = "all"    // Serve the API and run the workers
+	ServerRoleAPI    = "api"    // Serve the API only
+	ServerRoleWorker = "worker" // Run the workers, serving only health, version and metrics
+)
+
 // ServerConfig holds server configuration
 type ServerConfig struct {
 	Host        string
 	Port        int
 	Mode        string
+	Role        string // all (or empty), api or worker
 	TLSEnabled  bool
 	TLSCertFile string
 	TLSKeyFile  string
 }
 
+// ServesAPI reports whether the server serves the /api/v1 routes
+func (c *ServerConfig) ServesAPI() bool {
+	return c.Role != ServerRoleWorker
+}
+
+// RunsWorkers reports whether the server runs the message queue workers
+func (c *ServerConfig) RunsWorkers() bool {
+	return c.Role != ServerRoleAPI
+}
+
 // DatabaseConfig holds database configuration
 type DatabaseConfig struct {
 	Host            string
@@ -64,18 +87,95 @@ type ESConfig struct {
 	Port int
 }
 
+// Search backends
+const (
+	SearchBackendElasticsearch = "elasticsearch"
+	SearchBackendMySQL         = "mysql" // FULLTEXT and LIKE queries over the posts and users tables
+)
+
+// SearchConfig holds search backend configuration
+type SearchConfig struct {
+	Backend         string        // "elasticsearch" or "mysql"
+	Fallback        bool          // Serves searches from MySQL while Elasticsearch is unavailable
+	MySQLFullText   bool          // Matches keywords in MySQL with FULLTEXT indexes rather than only LIKE
+	BreakerFailures int           // Consecutive failures that open the circuit breaker
+	BreakerOpen     time.Duration // How long the breaker stays open before Elasticsearch is health checked again
+	HealthTimeout   time.Duration // How long an Elasticsearch health check may take
+}
+
 // JWTConfig holds JWT configuration
 type JWTConfig struct {
 	Secret     string
 	Expiration time.Duration
 }
 
+// Message queue drivers
+const (
+	MQDriverRabbitMQ = "rabbitmq"
+	MQDriverMemory   = "memory"
+)
+
 // MQConfig holds message queue configuration
 type MQConfig struct {
+	Driver   string // rabbitmq, or memory to run the event pipeline in process
 	Host     string
 	Port     int
 	User     string
 	Password string
+	// Consumer groups
+	QueuePrefix string // Prefix of durable consumer group queue names
+	Prefetch    int    // Unacknowledged messages each consumer may hold
+	Concurrency int    // Consumers per consumer group queue
+	// Consumer retries; after RetryMaxAttempts a message is dead-lettered
+	RetryMaxAttempts int
+	RetryBaseDelay   time.Duration
+	RetryMaxDelay    time.Duration
+	// Reconnects back off from ReconnectBaseDelay, doubling up to ReconnectMaxDelay, and never give up
+	ReconnectBaseDelay time.Duration
+	ReconnectMaxDelay  time.Duration
+	// Publisher confirms; messages that are not confirmed are spooled to SpoolDir
+	ConfirmTimeout   time.Duration
+	SpoolDir         string // Empty disables the spool
+	SpoolMaxAttempts int    // Times a message may be spooled before it is dropped
+	SpoolInterval    time.Duration
+	// Transactional outbox relay
+	OutboxPollInterval time.Duration
+	OutboxBatchSize    int
+	OutboxMaxAttempts  int           // Attempts before an event is marked failed; 0 retries forever
+	OutboxRetention    time.Duration // How long published events stay in the outbox table
+	// Consumer deduplication; a claim blocks redeliveries while an event is handled
+	DedupeClaimTTL time.Duration
+	DedupeTTL      time.Duration // How long a handled event's redeliveries are recognized
+	// In-memory driver
+	MemoryBufferSize int // Messages buffered per subscription before publishers block
+}
+
+// WorkersConfig holds background worker configuration
+type WorkersConfig struct {
+	Disabled     []string       // Workers not to run, by name
+	Concurrency  map[string]int // Consumers per queue, by worker name; absent workers use MQ.Concurrency
+	DrainTimeout time.Duration  // How long shutdown waits for workers to handle in-flight messages
+}
+
+// JobsConfig holds configuration of the Redis job queue. Its runner is the "jobs"
+// worker: WORKERS_DISABLED and WORKERS_CONCURRENCY apply to it.
+type JobsConfig struct {
+	PollInterval      time.Duration // How often due jobs are claimed
+	VisibilityTimeout time.Duration // How long a claimed job may go without a heartbeat before it runs again
+	RetryMaxAttempts  int           // Attempts of a failed job, including the first, before it is dead
+	RetryBaseDelay    time.Duration // Delay before the first retry, doubled on each further retry
+	RetryMaxDelay     time.Duration // Upper bound of the retry delay
+	MaxDead           int           // Dead jobs kept; the oldest are dropped beyond it
+}
+
+// Enabled reports whether the named worker should run
+func (c *WorkersConfig) Enabled(name string) bool {
+	for _, disabled := range c.Disabled {
+		if disabled == name {
+			return false
+		}
+	}
+	return true
 }
 
 // LogConfig holds logging configuration
@@ -87,7 +187,10 @@ type LogConfig struct {
 
 // PoolConfig holds goroutine pool configuration
 type PoolConfig struct {
-	Size int
+	Size             int
+	RetryMaxAttempts int           // Attempts of a failed task, including the first
+	RetryBaseDelay   time.Duration // Delay before the first retry, doubled on each further retry
+	RetryMaxDelay    time.Duration // Upper bound of the retry delay
 }
 
 // CacheConfig holds cache configuration
@@ -110,7 +213,23 @@ type FeedConfig struct {
 
 // HotnessConfig holds hotness calculation configuration
 type HotnessConfig struct {
-	Algorithm string // "reddit" or "hackernews"
+	Algorithm          string               // "reddit", "hackernews", "best" or "controversial"
+	Weights            HotnessWeightsConfig // What the signals of a post count for
+	Gravity            float64              // How quickly posts fall with age under "hackernews"
+	OverridesRefresh   time.Duration        // How long per-circle overrides are cached
+	RecomputeInterval  time.Duration        // How often recent posts are recomputed; 0 disables it
+	RecomputeWindow    time.Duration        // Posts published within it are recomputed
+	RecomputeShards    int                  // Jobs the recompute is split into, by post ID
+	RecomputeBatchSize int                  // Posts read and written at a time
+}
+
+// HotnessWeightsConfig holds how much each signal of a post counts for in its hotness, in upvotes
+type HotnessWeightsConfig struct {
+	Upvote   float64
+	Downvote float64
+	Comment  float64
+	Favorite float64
+	View     float64
 }
 
 // RateLimitConfig holds rate limiting configuration
@@ -127,12 +246,16 @@ type SecurityConfig struct {
 	EncryptionKey string
 	// BcryptCost is the cost factor for bcrypt password hashing (4-31, default 10)
 	BcryptCost int
+	// CursorSecret signs pagination cursors; falls back to the JWT secret when empty
+	CursorSecret string
 }
 
 // FeaturesConfig holds feature toggles for local development
 type FeaturesConfig struct {
     EnableDatabase      bool
     EnableRedis         bool
+    EnableMQ            bool
+    EnableSearch        bool
     AutoCreateDB        bool
     AutoMigrate         bool
     AllowStartWithoutDB bool
@@ -156,6 +279,7 @@ func Load() (*Config, error) {
 			Host:        viper.GetString("SERVER_HOST"),
 			Port:        viper.GetInt("SERVER_PORT"),
 			Mode:        viper.GetString("GIN_MODE"),
+			Role:        viper.GetString("SERVER_ROLE"),
 			TLSEnabled:  viper.GetBool("TLS_ENABLED"),
 			TLSCertFile: viper.GetString("TLS_CERT_FILE"),
 			TLSKeyFile:  viper.GetString("TLS_KEY_FILE"),
@@ -182,15 +306,48 @@ func Load() (*Config, error) {
 			Host: viper.GetString("ES_HOST"),
 			Port: viper.GetInt("ES_PORT"),
 		},
+		Search: SearchConfig{
+			Backend:         viper.GetString("SEARCH_BACKEND"),
+			Fallback:        viper.GetBool("SEARCH_FALLBACK_ENABLED"),
+			MySQLFullText:   viper.GetBool("SEARCH_MYSQL_FULLTEXT"),
+			BreakerFailures: viper.GetInt("SEARCH_BREAKER_FAILURES"),
+			BreakerOpen:     viper.GetDuration("SEARCH_BREAKER_OPEN_DURATION") * time.Millisecond,
+			HealthTimeout:   viper.GetDuration("SEARCH_HEALTH_TIMEOUT") * time.Millisecond,
+		},
 		JWT: JWTConfig{
 			Secret:     viper.GetString("JWT_SECRET"),
 			Expiration: viper.GetDuration("JWT_EXPIRATION") * time.Second,
 		},
 		MQ: MQConfig{
+			Driver:   viper.GetString("MQ_DRIVER"),
 			Host:     viper.GetString("MQ_HOST"),
 			Port:     viper.GetInt("MQ_PORT"),
 			User:     viper.GetString("MQ_USER"),
 			Password: viper.GetString("MQ_PASSWORD"),
+
+			QueuePrefix: viper.GetString("MQ_QUEUE_PREFIX"),
+			Prefetch:    viper.GetInt("MQ_PREFETCH"),
+			Concurrency: viper.GetInt("MQ_CONSUMER_CONCURRENCY"),
+
+			RetryMaxAttempts: viper.GetInt("MQ_RETRY_MAX_ATTEMPTS"),
+			RetryBaseDelay:   viper.GetDuration("MQ_RETRY_BASE_DELAY") * time.Millisecond,
+			RetryMaxDelay:    viper.GetDuration("MQ_RETRY_MAX_DELAY") * time.Millisecond,
+
+			ReconnectBaseDelay: viper.GetDuration("MQ_RECONNECT_BASE_DELAY") * time.Millisecond,
+			ReconnectMaxDelay:  viper.GetDuration("MQ_RECONNECT_MAX_DELAY") * time.Millisecond,
+
+			ConfirmTimeout:   viper.GetDuration("MQ_CONFIRM_TIMEOUT") * time.Millisecond,
+			SpoolDir:         viper.GetString("MQ_SPOOL_DIR"),
+			SpoolMaxAttempts: viper.GetInt("MQ_SPOOL_MAX_ATTEMPTS"),
+			SpoolInterval:    viper.GetDuration("MQ_SPOOL_INTERVAL") * time.Millisecond,
+
+			OutboxPollInterval: viper.GetDuration("MQ_OUTBOX_POLL_INTERVAL") * time.Millisecond,
+			OutboxBatchSize:    viper.GetInt("MQ_OUTBOX_BATCH_SIZE"),
+			OutboxMaxAttempts:  viper.GetInt("MQ_OUTBOX_MAX_ATTEMPTS"),
+			OutboxRetention:    viper.GetDuration("MQ_OUTBOX_RETENTION") * time.Hour,
+			DedupeClaimTTL:     viper.GetDuration("MQ_DEDUPE_CLAIM_TTL") * time.Millisecond,
+			DedupeTTL:          viper.GetDuration("MQ_DEDUPE_TTL") * time.Hour,
+			MemoryBufferSize:   viper.GetInt("MQ_MEMORY_BUFFER_SIZE"),
 		},
 		Log: LogConfig{
 			Level:    viper.GetString("LOG_LEVEL"),
@@ -198,7 +355,10 @@ func Load() (*Config, error) {
 			FilePath: viper.GetString("LOG_FILE_PATH"),
 		},
 		Pool: PoolConfig{
-			Size: viper.GetInt("GOROUTINE_POOL_SIZE"),
+			Size:             viper.GetInt("GOROUTINE_POOL_SIZE"),
+			RetryMaxAttempts: viper.GetInt("GOROUTINE_POOL_RETRY_MAX_ATTEMPTS"),
+			RetryBaseDelay:   viper.GetDuration("GOROUTINE_POOL_RETRY_BASE_DELAY") * time.Millisecond,
+			RetryMaxDelay:    viper.GetDuration("GOROUTINE_POOL_RETRY_MAX_DELAY") * time.Millisecond,
 		},
 		Cache: CacheConfig{
 			DefaultExpiration:     viper.GetDuration("CACHE_DEFAULT_EXPIRATION") * time.Second,
@@ -215,6 +375,19 @@ func Load() (*Config, error) {
 		},
 		Hotness: HotnessConfig{
 			Algorithm: viper.GetString("HOTNESS_ALGORITHM"),
+			Weights: HotnessWeightsConfig{
+				Upvote:   viper.GetFloat64("HOTNESS_WEIGHT_UPVOTE"),
+				Downvote: viper.GetFloat64("HOTNESS_WEIGHT_DOWNVOTE"),
+				Comment:  viper.GetFloat64("HOTNESS_WEIGHT_COMMENT"),
+				Favorite: viper.GetFloat64("HOTNESS_WEIGHT_FAVORITE"),
+				View:     viper.GetFloat64("HOTNESS_WEIGHT_VIEW"),
+			},
+			Gravity:            viper.GetFloat64("HOTNESS_GRAVITY"),
+			OverridesRefresh:   viper.GetDuration("HOTNESS_OVERRIDES_REFRESH") * time.Millisecond,
+			RecomputeInterval:  viper.GetDuration("HOTNESS_RECOMPUTE_INTERVAL") * time.Millisecond,
+			RecomputeWindow:    viper.GetDuration("HOTNESS_RECOMPUTE_WINDOW") * time.Hour,
+			RecomputeShards:    viper.GetInt("HOTNESS_RECOMPUTE_SHARDS"),
+			RecomputeBatchSize: viper.GetInt("HOTNESS_RECOMPUTE_BATCH_SIZE"),
 		},
 		RateLimit: RateLimitConfig{
 			Enabled:           viper.GetBool("RATE_LIMIT_ENABLED"),
@@ -224,16 +397,37 @@ func Load() (*Config, error) {
 		Security: SecurityConfig{
 			EncryptionKey: viper.GetString("ENCRYPTION_KEY"),
 			BcryptCost:    viper.GetInt("BCRYPT_COST"),
+			CursorSecret:  viper.GetString("CURSOR_SECRET"),
 		},
 		Features: FeaturesConfig{
 			EnableDatabase:      viper.GetBool("ENABLE_DATABASE"),
 			EnableRedis:         viper.GetBool("ENABLE_REDIS"),
+			EnableMQ:            viper.GetBool("ENABLE_MQ"),
+			EnableSearch:        viper.GetBool("ENABLE_ES"),
 			AutoCreateDB:        viper.GetBool("ENABLE_DB_AUTO_CREATE"),
 			AutoMigrate:         viper.GetBool("ENABLE_DB_AUTO_MIGRATE"),
 			AllowStartWithoutDB: viper.GetBool("ALLOW_START_WITHOUT_DB"),
 			UseGormAutoMigrate:  viper.GetBool("USE_GORM_AUTOMIGRATE"),
 		},
+		Workers: WorkersConfig{
+			Disabled:     parseList(viper.GetString("WORKERS_DISABLED")),
+			DrainTimeout: viper.GetDuration("WORKERS_DRAIN_TIMEOUT") * time.Millisecond,
+		},
+		Jobs: JobsConfig{
+			PollInterval:      viper.GetDuration("JOBS_POLL_INTERVAL") * time.Millisecond,
+			VisibilityTimeout: viper.GetDuration("JOBS_VISIBILITY_TIMEOUT") * time.Millisecond,
+			RetryMaxAttempts:  viper.GetInt("JOBS_RETRY_MAX_ATTEMPTS"),
+			RetryBaseDelay:    viper.GetDuration("JOBS_RETRY_BASE_DELAY") * time.Millisecond,
+			RetryMaxDelay:     viper.GetDuration("JOBS_RETRY_MAX_DELAY") * time.Millisecond,
+			MaxDead:           viper.GetInt("JOBS_MAX_DEAD"),
+		},
+	}
+
+	concurrency, err := parseConcurrency(viper.GetString("WORKERS_CONCURRENCY"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
+	config.Workers.Concurrency = concurrency
 
 	// Validate configuration
 	if err := config.Validate(); err != nil {
@@ -248,6 +442,7 @@ func setDefaults() {
 	viper.SetDefault("SERVER_HOST", "0.0.0.0")
 	viper.SetDefault("SERVER_PORT", 8080)
 	viper.SetDefault("GIN_MODE", "debug")
+	viper.SetDefault("SERVER_ROLE", ServerRoleAll)
 
 	viper.SetDefault("DB_HOST", "localhost")
 	viper.SetDefault("DB_PORT", 3306)
@@ -268,19 +463,59 @@ func setDefaults() {
 	viper.SetDefault("ES_HOST", "localhost")
 	viper.SetDefault("ES_PORT", 9200)
 
+	viper.SetDefault("SEARCH_BACKEND", SearchBackendElasticsearch)
+	viper.SetDefault("SEARCH_FALLBACK_ENABLED", true)
+	viper.SetDefault("SEARCH_MYSQL_FULLTEXT", true)
+	viper.SetDefault("SEARCH_BREAKER_FAILURES", 3)
+	viper.SetDefault("SEARCH_BREAKER_OPEN_DURATION", 30000)
+	viper.SetDefault("SEARCH_HEALTH_TIMEOUT", 2000)
+
 	viper.SetDefault("JWT_SECRET", "change-me-in-production")
 	viper.SetDefault("JWT_EXPIRATION", 86400)
 
+	viper.SetDefault("MQ_DRIVER", MQDriverRabbitMQ)
 	viper.SetDefault("MQ_HOST", "localhost")
 	viper.SetDefault("MQ_PORT", 5672)
 	viper.SetDefault("MQ_USER", "guest")
 	viper.SetDefault("MQ_PASSWORD", "guest")
+	viper.SetDefault("MQ_QUEUE_PREFIX", "airy")
+	viper.SetDefault("MQ_PREFETCH", 10)
+	viper.SetDefault("MQ_CONSUMER_CONCURRENCY", 1)
+	viper.SetDefault("MQ_RETRY_MAX_ATTEMPTS", 5)
+	viper.SetDefault("MQ_RETRY_BASE_DELAY", 1000)
+	viper.SetDefault("MQ_RETRY_MAX_DELAY", 300000)
+	viper.SetDefault("MQ_RECONNECT_BASE_DELAY", 1000)
+	viper.SetDefault("MQ_RECONNECT_MAX_DELAY", 30000)
+	viper.SetDefault("MQ_CONFIRM_TIMEOUT", 5000)
+	viper.SetDefault("MQ_SPOOL_DIR", "data/mq-spool")
+	viper.SetDefault("MQ_SPOOL_MAX_ATTEMPTS", 5)
+	viper.SetDefault("MQ_SPOOL_INTERVAL", 5000)
+	viper.SetDefault("MQ_OUTBOX_POLL_INTERVAL", 500)
+	viper.SetDefault("MQ_OUTBOX_BATCH_SIZE", 100)
+	viper.SetDefault("MQ_OUTBOX_MAX_ATTEMPTS", 20)
+	viper.SetDefault("MQ_OUTBOX_RETENTION", 24)
+	viper.SetDefault("MQ_DEDUPE_CLAIM_TTL", 300000)
+	viper.SetDefault("MQ_DEDUPE_TTL", 72)
+	viper.SetDefault("MQ_MEMORY_BUFFER_SIZE", 1024)
+
+	viper.SetDefault("WORKERS_DISABLED", "")
+	viper.SetDefault("WORKERS_CONCURRENCY", "")
+	viper.SetDefault("WORKERS_DRAIN_TIMEOUT", 10000)
+	viper.SetDefault("JOBS_POLL_INTERVAL", 1000)
+	viper.SetDefault("JOBS_VISIBILITY_TIMEOUT", 300000)
+	viper.SetDefault("JOBS_RETRY_MAX_ATTEMPTS", 5)
+	viper.SetDefault("JOBS_RETRY_BASE_DELAY", 1000)
+	viper.SetDefault("JOBS_RETRY_MAX_DELAY", 600000)
+	viper.SetDefault("JOBS_MAX_DEAD", 10000)
 
 	viper.SetDefault("LOG_LEVEL", "info")
 	viper.SetDefault("LOG_OUTPUT", "stdout")
 	viper.SetDefault("LOG_FILE_PATH", "logs/app.log")
 
 	viper.SetDefault("GOROUTINE_POOL_SIZE", 10000)
+	viper.SetDefault("GOROUTINE_POOL_RETRY_MAX_ATTEMPTS", 3)
+	viper.SetDefault("GOROUTINE_POOL_RETRY_BASE_DELAY", 100)
+	viper.SetDefault("GOROUTINE_POOL_RETRY_MAX_DELAY", 5000)
 
 	viper.SetDefault("CACHE_DEFAULT_EXPIRATION", 3600)
 	viper.SetDefault("CACHE_CLEANUP_INTERVAL", 600)
@@ -294,6 +529,17 @@ func setDefaults() {
 	viper.SetDefault("FEED_FANOUT_THRESHOLD", 1000)
 
 	viper.SetDefault("HOTNESS_ALGORITHM", "reddit")
+	viper.SetDefault("HOTNESS_WEIGHT_UPVOTE", 1)
+	viper.SetDefault("HOTNESS_WEIGHT_DOWNVOTE", 1)
+	viper.SetDefault("HOTNESS_WEIGHT_COMMENT", 0)
+	viper.SetDefault("HOTNESS_WEIGHT_FAVORITE", 2)
+	viper.SetDefault("HOTNESS_WEIGHT_VIEW", 0)
+	viper.SetDefault("HOTNESS_GRAVITY", 1.8)
+	viper.SetDefault("HOTNESS_OVERRIDES_REFRESH", 60000)
+	viper.SetDefault("HOTNESS_RECOMPUTE_INTERVAL", 300000)
+	viper.SetDefault("HOTNESS_RECOMPUTE_WINDOW", 72)
+	viper.SetDefault("HOTNESS_RECOMPUTE_SHARDS", 8)
+	viper.SetDefault("HOTNESS_RECOMPUTE_BATCH_SIZE", 500)
 
 	viper.SetDefault("RATE_LIMIT_ENABLED", true)
 	viper.SetDefault("RATE_LIMIT_REQUESTS_PER_SECOND", 100)
@@ -307,10 +553,13 @@ func setDefaults() {
 	// Security defaults
 	viper.SetDefault("ENCRYPTION_KEY", "")
 	viper.SetDefault("BCRYPT_COST", 10)
+	viper.SetDefault("CURSOR_SECRET", "")
 
 	// Feature toggles (useful for local development)
 	viper.SetDefault("ENABLE_DATABASE", true)
 	viper.SetDefault("ENABLE_REDIS", true)
+	viper.SetDefault("ENABLE_MQ", true)
+	viper.SetDefault("ENABLE_ES", true)
 	viper.SetDefault("ENABLE_DB_AUTO_CREATE", true)
 	viper.SetDefault("ENABLE_DB_AUTO_MIGRATE", true)
 	viper.SetDefault("ALLOW_START_WITHOUT_DB", true)
@@ -333,6 +582,30 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	if c.Features.EnableMQ && c.MQ.Driver != MQDriverRabbitMQ && c.MQ.Driver != MQDriverMemory {
+		return fmt.Errorf("invalid message queue driver: %q", c.MQ.Driver)
+	}
+
+	switch c.Server.Role {
+	case "", ServerRoleAll, ServerRoleAPI, ServerRoleWorker:
+	default:
+		return fmt.Errorf("invalid server role: %q", c.Server.Role)
+	}
+
+	if c.Search.Backend != "" && c.Search.Backend != SearchBackendElasticsearch && c.Search.Backend != SearchBackendMySQL {
+		return fmt.Errorf("invalid search backend: %q", c.Search.Backend)
+	}
+
+	switch c.Hotness.Algorithm {
+	case "", "reddit", "hackernews", "best", "controversial":
+	default:
+		return fmt.Errorf("invalid hotness algorithm: %q", c.Hotness.Algorithm)
+	}
+	w := c.Hotness.Weights
+	if w.Upvote < 0 || w.Downvote < 0 || w.Comment < 0 || w.Favorite < 0 || w.View < 0 || c.Hotness.Gravity < 0 {
+		return fmt.Errorf("hotness weights and gravity must not be negative")
+	}
+
 	if c.Pool.Size <= 0 {
 		return fmt.Errorf("goroutine pool size must be positive")
 	}
@@ -355,6 +628,34 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// parseList splits a comma-separated list, dropping blank entries
+func parseList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseConcurrency parses per-worker concurrency given as "name=n,name=n"
+func parseConcurrency(value string) (map[string]int, error) {
+	concurrency := make(map[string]int)
+	for _, item := range parseList(value) {
+		name, n, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid worker concurrency %q: expected name=n", item)
+		}
+		count, err := strconv.Atoi(strings.TrimSpace(n))
+		if err != nil || count <= 0 {
+			return nil, fmt.Errorf("invalid worker concurrency %q: expected a positive number", item)
+		}
+		concurrency[strings.TrimSpace(name)] = count
+	}
+	return concurrency, nil
+}
+
 // GetDSN returns the database connection string
 func (c *DatabaseConfig) GetDSN() string {
 	params := "charset=utf8mb4&parseTime=True&loc=Local&timeout=5s&readTimeout=5s&writeTimeout=5s"
@@ -160,6 +160,54 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "unknown message queue driver",
+			config: &Config{
+				Server:   ServerConfig{Port: 8080},
+				JWT:      JWTConfig{Secret: "valid-secret"},
+				Database: DatabaseConfig{Name: "test"},
+				Pool:     PoolConfig{Size: 100},
+				Security: SecurityConfig{BcryptCost: 10},
+				MQ:       MQConfig{Driver: "kafka"},
+				Features: FeaturesConfig{EnableMQ: true},
+			},
+			wantErr: true,
+		},
+		{
+			name: "in-memory message queue driver",
+			config: &Config{
+				Server:   ServerConfig{Port: 8080},
+				JWT:      JWTConfig{Secret: "valid-secret"},
+				Database: DatabaseConfig{Name: "test"},
+				Pool:     PoolConfig{Size: 100},
+				Security: SecurityConfig{BcryptCost: 10},
+				MQ:       MQConfig{Driver: MQDriverMemory},
+				Features: FeaturesConfig{EnableMQ: true},
+			},
+			wantErr: false,
+		},
+		{
+			name: "worker server role",
+			config: &Config{
+				Server:   ServerConfig{Port: 8080, Role: ServerRoleWorker},
+				JWT:      JWTConfig{Secret: "valid-secret"},
+				Database: DatabaseConfig{Name: "test"},
+				Pool:     PoolConfig{Size: 100},
+				Security: SecurityConfig{BcryptCost: 10},
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown server role",
+			config: &Config{
+				Server:   ServerConfig{Port: 8080, Role: "cron"},
+				JWT:      JWTConfig{Secret: "valid-secret"},
+				Database: DatabaseConfig{Name: "test"},
+				Pool:     PoolConfig{Size: 100},
+				Security: SecurityConfig{BcryptCost: 10},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -172,6 +220,59 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+func TestServerConfig_Role(t *testing.T) {
+	tests := []struct {
+		role        string
+		servesAPI   bool
+		runsWorkers bool
+	}{
+		{role: "", servesAPI: true, runsWorkers: true},
+		{role: ServerRoleAll, servesAPI: true, runsWorkers: true},
+		{role: ServerRoleAPI, servesAPI: true, runsWorkers: false},
+		{role: ServerRoleWorker, servesAPI: false, runsWorkers: true},
+	}
+
+	for _, tt := range tests {
+		cfg := ServerConfig{Role: tt.role}
+		if got := cfg.ServesAPI(); got != tt.servesAPI {
+			t.Errorf("ServesAPI() with role %q = %v, want %v", tt.role, got, tt.servesAPI)
+		}
+		if got := cfg.RunsWorkers(); got != tt.runsWorkers {
+			t.Errorf("RunsWorkers() with role %q = %v, want %v", tt.role, got, tt.runsWorkers)
+		}
+	}
+}
+
+func TestWorkersConfig(t *testing.T) {
+	t.Run("parses per-worker concurrency", func(t *testing.T) {
+		concurrency, err := parseConcurrency(" search=4, votes=2 ,")
+		if err != nil {
+			t.Fatalf("parseConcurrency() error = %v", err)
+		}
+		if len(concurrency) != 2 || concurrency["search"] != 4 || concurrency["votes"] != 2 {
+			t.Errorf("parseConcurrency() = %v", concurrency)
+		}
+	})
+
+	t.Run("rejects malformed concurrency", func(t *testing.T) {
+		for _, value := range []string{"search", "search=0", "search=many"} {
+			if _, err := parseConcurrency(value); err == nil {
+				t.Errorf("parseConcurrency(%q) expected an error", value)
+			}
+		}
+	})
+
+	t.Run("disables listed workers", func(t *testing.T) {
+		cfg := WorkersConfig{Disabled: parseList("feed, search")}
+		if cfg.Enabled("feed") || cfg.Enabled("search") {
+			t.Error("Expected feed and search to be disabled")
+		}
+		if !cfg.Enabled("votes") {
+			t.Error("Expected votes to be enabled")
+		}
+	})
+}
+
 func TestGetDSN(t *testing.T) {
 	cfg := DatabaseConfig{
 		Host:     "localhost",
@@ -13,6 +13,8 @@ import (
 	"gorm.io/gorm"
 )
 
+// BootstrapAdmin creates the initial admin account if needed and grants it the
+// global super_admin role
 func BootstrapAdmin(cfg *config.Config) error {
 	db := GetDB()
 	if db == nil {
@@ -32,37 +34,41 @@ func BootstrapAdmin(cfg *config.Config) error {
 		password = uuid.NewString()
 	}
 
-	var existing models.User
-	if err := db.Where("email = ? OR username = ?", email, username).First(&existing).Error; err == nil {
-		return nil
-	}
+	now := time.Now()
 
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), cfg.Security.BcryptCost)
-	if err != nil {
-		return fmt.Errorf("failed to hash password: %w", err)
-	}
+	// Reuse an existing admin account so re-running bootstrap still grants the role
+	var user models.User
+	if err := db.Where("email = ? OR username = ?", email, username).First(&user).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return fmt.Errorf("failed to look up admin user: %w", err)
+		}
 
-	now := time.Now()
-	user := models.User{
-		Username:     username,
-		Email:        email,
-		PasswordHash: string(hash),
-		Status:       "active",
-		CreatedAt:    now,
-		UpdatedAt:    now,
-	}
-	if err := db.Create(&user).Error; err != nil {
-		return fmt.Errorf("failed to create admin user: %w", err)
-	}
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), cfg.Security.BcryptCost)
+		if err != nil {
+			return fmt.Errorf("failed to hash password: %w", err)
+		}
 
-	profile := models.UserProfile{UserID: user.ID, CreatedAt: now, UpdatedAt: now}
-	_ = db.FirstOrCreate(&profile, models.UserProfile{UserID: user.ID}).Error
-	stats := models.UserStats{UserID: user.ID, CreatedAt: now, UpdatedAt: now}
-	_ = db.FirstOrCreate(&stats, models.UserStats{UserID: user.ID}).Error
+		user = models.User{
+			Username:     username,
+			Email:        email,
+			PasswordHash: string(hash),
+			Status:       "active",
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+		if err := db.Create(&user).Error; err != nil {
+			return fmt.Errorf("failed to create admin user: %w", err)
+		}
+
+		profile := models.UserProfile{UserID: user.ID, CreatedAt: now, UpdatedAt: now}
+		_ = db.FirstOrCreate(&profile, models.UserProfile{UserID: user.ID}).Error
+		stats := models.UserStats{UserID: user.ID, CreatedAt: now, UpdatedAt: now}
+		_ = db.FirstOrCreate(&stats, models.UserStats{UserID: user.ID}).Error
+	}
 
 	var role models.Role
-	if err := db.Where("name = ?", "super_admin").First(&role).Error; err != nil {
-		role = models.Role{Name: "super_admin", CreatedAt: now, UpdatedAt: now}
+	if err := db.Where("name = ?", models.RoleSuperAdmin).First(&role).Error; err != nil {
+		role = models.Role{Name: models.RoleSuperAdmin, CreatedAt: now, UpdatedAt: now}
 		if err := db.Create(&role).Error; err != nil {
 			return fmt.Errorf("failed to create role: %w", err)
 		}
@@ -79,3 +85,45 @@ func BootstrapAdmin(cfg *config.Config) error {
 	appLogger.Info("Admin bootstrap completed")
 	return nil
 }
+
+// SeedRolesAndPermissions creates the built-in roles and permissions and grants
+// them according to models.DefaultRolePermissions. It is safe to run on every start.
+func SeedRolesAndPermissions() error {
+	db := GetDB()
+	if db == nil {
+		return gorm.ErrInvalidDB
+	}
+
+	now := time.Now()
+
+	// Ensure every permission exists
+	permIDs := make(map[string]int64)
+	for _, name := range models.AllPermissions() {
+		perm := models.Permission{Name: name, CreatedAt: now, UpdatedAt: now}
+		if err := db.Where("name = ?", name).FirstOrCreate(&perm).Error; err != nil {
+			return fmt.Errorf("failed to seed permission %s: %w", name, err)
+		}
+		permIDs[name] = perm.ID
+	}
+
+	// Ensure every role exists and holds its default permissions
+	grants := map[string][]string{
+		models.RoleSuperAdmin: models.AllPermissions(),
+		models.RoleModerator:  models.DefaultRolePermissions[models.RoleModerator],
+		models.RoleUser:       models.DefaultRolePermissions[models.RoleUser],
+	}
+	for roleName, perms := range grants {
+		role := models.Role{Name: roleName, CreatedAt: now, UpdatedAt: now}
+		if err := db.Where("name = ?", roleName).FirstOrCreate(&role).Error; err != nil {
+			return fmt.Errorf("failed to seed role %s: %w", roleName, err)
+		}
+		for _, permName := range perms {
+			rp := models.RolePermission{RoleID: role.ID, PermissionID: permIDs[permName], CreatedAt: now}
+			if err := db.Where("role_id = ? AND permission_id = ?", rp.RoleID, rp.PermissionID).FirstOrCreate(&rp).Error; err != nil {
+				return fmt.Errorf("failed to grant %s to %s: %w", permName, roleName, err)
+			}
+		}
+	}
+
+	return nil
+}
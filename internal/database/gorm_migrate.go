@@ -16,20 +16,25 @@ func RunGormAutoMigrate() error {
 		&models.User{},
 		&models.UserProfile{},
 		&models.UserStats{},
+		&models.Follow{},
 		&models.Role{},
 		&models.Permission{},
 		&models.RolePermission{},
 		&models.UserRole{},
 		&models.Circle{},
 		&models.CircleMember{},
+		&models.CircleHotnessSettings{},
 		&models.Post{},
 		&models.Comment{},
 		&models.Vote{},
 		&models.Favorite{},
+		&models.FavoriteCollection{},
 		&models.EntityCount{},
 		&models.Notification{},
 		&models.Conversation{},
 		&models.Message{},
 		&models.AdminLog{},
+		&models.OutboxEvent{},
+		&models.DeadLetter{},
 	)
 }
@@ -21,24 +21,29 @@ func CheckSchema() (*SchemaStatus, error) {
 	status := &SchemaStatus{OK: true, MissingColumns: map[string][]string{}}
 
 	tables := map[string][]string{
-		models.User{}.TableName():           {"id", "username", "password_hash"},
-		models.UserProfile{}.TableName():    {"user_id"},
-		models.UserStats{}.TableName():      {"user_id"},
-		models.Role{}.TableName():           {"id", "name"},
-		models.Permission{}.TableName():     {"id", "name"},
-		models.RolePermission{}.TableName(): {"role_id", "permission_id"},
-		models.UserRole{}.TableName():       {"user_id", "role_id"},
-		models.Circle{}.TableName():         {"id", "name", "creator_id"},
-		models.CircleMember{}.TableName():   {"id", "circle_id", "user_id"},
-		models.Post{}.TableName():           {"id", "author_id"},
-		models.Comment{}.TableName():        {"id", "author_id", "post_id"},
-		models.Vote{}.TableName():           {"id", "user_id", "entity_type", "entity_id"},
-		models.Favorite{}.TableName():       {"id", "user_id", "post_id"},
-		models.EntityCount{}.TableName():    {"entity_type", "entity_id"},
-		models.Notification{}.TableName():   {"id", "receiver_id"},
-		models.Conversation{}.TableName():   {"id", "user1_id", "user2_id"},
-		models.Message{}.TableName():        {"id", "conversation_id", "sender_id"},
-		models.AdminLog{}.TableName():       {"id", "operator_id"},
+		models.User{}.TableName():                  {"id", "username", "password_hash"},
+		models.UserProfile{}.TableName():           {"user_id"},
+		models.UserStats{}.TableName():             {"user_id"},
+		models.Follow{}.TableName():                {"id", "follower_id", "following_id"},
+		models.Role{}.TableName():                  {"id", "name"},
+		models.Permission{}.TableName():            {"id", "name"},
+		models.RolePermission{}.TableName():        {"role_id", "permission_id"},
+		models.UserRole{}.TableName():              {"user_id", "role_id"},
+		models.Circle{}.TableName():                {"id", "name", "creator_id"},
+		models.CircleMember{}.TableName():          {"id", "circle_id", "user_id"},
+		models.CircleHotnessSettings{}.TableName(): {"circle_id", "settings"},
+		models.Post{}.TableName():                  {"id", "author_id"},
+		models.Comment{}.TableName():               {"id", "author_id", "post_id", "score", "best_score"},
+		models.Vote{}.TableName():                  {"id", "user_id", "entity_type", "entity_id"},
+		models.Favorite{}.TableName():              {"id", "user_id", "post_id", "collection_id"},
+		models.FavoriteCollection{}.TableName():    {"id", "user_id", "name"},
+		models.EntityCount{}.TableName():           {"entity_type", "entity_id"},
+		models.Notification{}.TableName():          {"id", "receiver_id"},
+		models.Conversation{}.TableName():          {"id", "user1_id", "user2_id"},
+		models.Message{}.TableName():               {"id", "conversation_id", "sender_id"},
+		models.AdminLog{}.TableName():              {"id", "operator_id"},
+		models.OutboxEvent{}.TableName():           {"id", "event_id", "aggregate_type", "aggregate_id", "status", "next_attempt_at"},
+		models.DeadLetter{}.TableName():            {"id", "letter_id", "consumer_group", "topic", "status"},
 	}
 
 	for table, cols := range tables {
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -200,13 +201,16 @@ func (h *AdminHandler) ListLogs(c *gin.Context) {
 		req.EndDate = &endDate
 	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
-	req.Page = page
+	req.Cursor = c.Query("cursor")
 	req.PageSize = pageSize
 
 	result, err := h.adminService.ListLogs(c.Request.Context(), req)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			response.Error(c, http.StatusBadRequest, "BAD_REQUEST", "invalid cursor", nil)
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to list logs", err.Error())
 		return
 	}
@@ -58,6 +58,11 @@ func (m *MockUserService) RefreshToken(ctx context.Context, token string) (*serv
 	return args.Get(0).(*service.RefreshTokenResponse), args.Error(1)
 }
 
+func (m *MockUserService) ResendActivation(ctx context.Context, identifier string) error {
+	args := m.Called(ctx, identifier)
+	return args.Error(0)
+}
+
 func setupTestRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
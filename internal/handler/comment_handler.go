@@ -71,8 +71,10 @@ func (h *CommentHandler) CreateComment(c *gin.Context) {
 	response.Success(c, comment)
 }
 
-// GetCommentTree handles retrieving all comments for a post in tree structure
+// GetCommentTree handles retrieving a page of a post's comment thread in tree structure
 // GET /api/v1/posts/:id/comments
+// Query: sort (best, top, new, old), cursor, page_size, depth, replies, and parent_id to
+// load more replies to a comment
 // Implements Requirement 5.2
 func (h *CommentHandler) GetCommentTree(c *gin.Context) {
 	// Parse post ID from URL parameter
@@ -83,16 +85,45 @@ func (h *CommentHandler) GetCommentTree(c *gin.Context) {
 		return
 	}
 
-	commentTree, err := h.commentService.GetCommentTree(c.Request.Context(), postID)
+	req := service.CommentTreeRequest{
+		PostID: postID,
+		Sort:   service.CommentSort(c.Query("sort")),
+		Cursor: c.Query("cursor"),
+	}
+	req.PageSize, _ = strconv.Atoi(c.Query("page_size"))
+	req.Depth, _ = strconv.Atoi(c.Query("depth"))
+	req.Replies, _ = strconv.Atoi(c.Query("replies"))
+	if parentIDStr := c.Query("parent_id"); parentIDStr != "" {
+		parentID, err := strconv.ParseInt(parentIDStr, 10, 64)
+		if err != nil {
+			response.BadRequest(c, "Invalid parent comment ID", nil)
+			return
+		}
+		req.ParentID = &parentID
+	}
+
+	// Embed the viewer's votes when authenticated
+	if uid, exists := c.Get("userID"); exists {
+		id := uid.(int64)
+		req.ViewerID = &id
+	}
+
+	tree, err := h.commentService.GetCommentTree(c.Request.Context(), req)
 	if err != nil {
-		response.InternalError(c, "Failed to retrieve comments")
+		switch {
+		case errors.Is(err, service.ErrInvalidCommentSort):
+			response.BadRequest(c, "Invalid sort, expected best, top, new or old", nil)
+		case errors.Is(err, service.ErrInvalidCursor):
+			response.BadRequest(c, "Invalid cursor", nil)
+		case errors.Is(err, service.ErrCommentNotFound):
+			response.NotFound(c, "Parent comment not found")
+		default:
+			response.InternalError(c, "Failed to retrieve comments")
+		}
 		return
 	}
 
-	response.Success(c, gin.H{
-		"post_id":  postID,
-		"comments": commentTree,
-	})
+	response.Success(c, tree)
 }
 
 // DeleteComment handles deleting a comment
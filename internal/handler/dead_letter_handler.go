@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kobayashirei/airy/internal/models"
+	"github.com/kobayashirei/airy/internal/response"
+	"github.com/kobayashirei/airy/internal/service"
+)
+
+// DeadLetterHandler handles admin requests on dead-lettered events
+type DeadLetterHandler struct {
+	deadLetterService service.DeadLetterService
+}
+
+// NewDeadLetterHandler creates a new dead letter handler
+func NewDeadLetterHandler(deadLetterService service.DeadLetterService) *DeadLetterHandler {
+	return &DeadLetterHandler{
+		deadLetterService: deadLetterService,
+	}
+}
+
+// ListDeadLetters retrieves dead letters, newest first
+// GET /api/v1/admin/dead-letters
+func (h *DeadLetterHandler) ListDeadLetters(c *gin.Context) {
+	var req service.DeadLetterListRequest
+
+	// Parse query parameters
+	req.ConsumerGroup = c.Query("consumer_group")
+	req.Topic = c.Query("topic")
+	req.Status = c.Query("status")
+	if !validDeadLetterStatus(req.Status) {
+		response.Error(c, http.StatusBadRequest, "BAD_REQUEST", "invalid status", nil)
+		return
+	}
+
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	req.Cursor = c.Query("cursor")
+	req.PageSize = pageSize
+
+	result, err := h.deadLetterService.List(c.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			response.Error(c, http.StatusBadRequest, "BAD_REQUEST", "invalid cursor", nil)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to list dead letters", err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// GetDeadLetter retrieves a dead letter with its payload
+// GET /api/v1/admin/dead-letters/:id
+func (h *DeadLetterHandler) GetDeadLetter(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	letter, err := h.deadLetterService.Get(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err, "failed to get dead letter")
+		return
+	}
+
+	response.Success(c, letter)
+}
+
+// ReplayDeadLetter delivers a dead-lettered event again to the consumers it failed in
+// POST /api/v1/admin/dead-letters/:id/replay
+func (h *DeadLetterHandler) ReplayDeadLetter(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	operatorID, exists := c.Get("userID")
+	if !exists {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	letter, err := h.deadLetterService.Replay(c.Request.Context(), operatorID.(int64), id, c.ClientIP())
+	if err != nil {
+		h.handleError(c, err, "failed to replay dead letter")
+		return
+	}
+
+	response.Success(c, letter)
+}
+
+// DeleteDeadLetter removes a dead letter
+// DELETE /api/v1/admin/dead-letters/:id
+func (h *DeadLetterHandler) DeleteDeadLetter(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	operatorID, exists := c.Get("userID")
+	if !exists {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	if err := h.deadLetterService.Delete(c.Request.Context(), operatorID.(int64), id, c.ClientIP()); err != nil {
+		h.handleError(c, err, "failed to delete dead letter")
+		return
+	}
+
+	response.Success(c, gin.H{"message": "dead letter deleted successfully"})
+}
+
+// PurgeDeadLetters removes every dead letter matching the query filters; without filters it removes all
+// DELETE /api/v1/admin/dead-letters
+func (h *DeadLetterHandler) PurgeDeadLetters(c *gin.Context) {
+	req := service.DeadLetterPurgeRequest{
+		ConsumerGroup: c.Query("consumer_group"),
+		Topic:         c.Query("topic"),
+		Status:        c.Query("status"),
+	}
+	if !validDeadLetterStatus(req.Status) {
+		response.Error(c, http.StatusBadRequest, "BAD_REQUEST", "invalid status", nil)
+		return
+	}
+
+	operatorID, exists := c.Get("userID")
+	if !exists {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	deleted, err := h.deadLetterService.Purge(c.Request.Context(), operatorID.(int64), req, c.ClientIP())
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to purge dead letters", err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{"deleted": deleted})
+}
+
+// parseID parses the dead letter ID path parameter, responding with 400 when it is invalid
+func (h *DeadLetterHandler) parseID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "BAD_REQUEST", "invalid dead letter ID", err.Error())
+		return 0, false
+	}
+	return id, true
+}
+
+// handleError maps dead letter service errors to responses
+func (h *DeadLetterHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrDeadLetterNotFound):
+		response.NotFound(c, "dead letter not found")
+	case errors.Is(err, service.ErrDeadLetterReplayUnavailable):
+		response.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", err.Error(), nil)
+	default:
+		response.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", message, err.Error())
+	}
+}
+
+// validDeadLetterStatus reports whether status is empty or a dead letter status
+func validDeadLetterStatus(status string) bool {
+	return status == "" || status == models.DeadLetterStatusDead || status == models.DeadLetterStatusReplayed
+}
@@ -0,0 +1,287 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kobayashirei/airy/internal/response"
+	"github.com/kobayashirei/airy/internal/service"
+)
+
+// FavoriteHandler handles favorite-related HTTP requests
+type FavoriteHandler struct {
+	favoriteService service.FavoriteService
+}
+
+// NewFavoriteHandler creates a new favorite handler
+func NewFavoriteHandler(favoriteService service.FavoriteService) *FavoriteHandler {
+	return &FavoriteHandler{
+		favoriteService: favoriteService,
+	}
+}
+
+// FavoriteRequest represents the optional body of a favorite request
+type FavoriteRequest struct {
+	CollectionID *int64 `json:"collection_id"`
+}
+
+// Favorite handles adding a post to the user's favorites
+// POST /api/v1/posts/:id/favorite
+func (h *FavoriteHandler) Favorite(c *gin.Context) {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+	postID, ok := h.parseID(c, "Invalid post ID")
+	if !ok {
+		return
+	}
+
+	var req FavoriteRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, "Invalid request body", err.Error())
+			return
+		}
+	}
+
+	if err := h.favoriteService.Favorite(c.Request.Context(), userID, postID, req.CollectionID); err != nil {
+		switch {
+		case errors.Is(err, service.ErrPostNotFound):
+			response.NotFound(c, "Post not found")
+		case errors.Is(err, service.ErrFavoriteCollectionNotFound):
+			response.NotFound(c, "Collection not found")
+		default:
+			response.InternalError(c, "Failed to favorite post")
+		}
+		return
+	}
+
+	response.Success(c, gin.H{
+		"favorited":     true,
+		"collection_id": req.CollectionID,
+	})
+}
+
+// Unfavorite handles removing a post from the user's favorites
+// DELETE /api/v1/posts/:id/favorite
+func (h *FavoriteHandler) Unfavorite(c *gin.Context) {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+	postID, ok := h.parseID(c, "Invalid post ID")
+	if !ok {
+		return
+	}
+
+	if err := h.favoriteService.Unfavorite(c.Request.Context(), userID, postID); err != nil {
+		response.InternalError(c, "Failed to unfavorite post")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"favorited": false,
+	})
+}
+
+// GetFavoriteStatus handles checking whether the user has favorited a post
+// GET /api/v1/posts/:id/favorite
+func (h *FavoriteHandler) GetFavoriteStatus(c *gin.Context) {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+	postID, ok := h.parseID(c, "Invalid post ID")
+	if !ok {
+		return
+	}
+
+	favorited, err := h.favoriteService.IsFavorited(c.Request.Context(), userID, postID)
+	if err != nil {
+		response.InternalError(c, "Failed to check favorite status")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"favorited": favorited,
+	})
+}
+
+// ListFavorites handles listing the user's favorites
+// GET /api/v1/favorites
+func (h *FavoriteHandler) ListFavorites(c *gin.Context) {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var collectionID *int64
+	if collectionStr := c.Query("collection_id"); collectionStr != "" {
+		id, err := strconv.ParseInt(collectionStr, 10, 64)
+		if err != nil {
+			response.BadRequest(c, "Invalid collection ID", nil)
+			return
+		}
+		collectionID = &id
+	}
+
+	limit := 20
+	if limitStr := c.Query("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > 100 {
+			response.BadRequest(c, "Invalid limit parameter (must be between 1 and 100)", nil)
+			return
+		}
+	}
+
+	resp, err := h.favoriteService.ListFavorites(c.Request.Context(), userID, collectionID, c.Query("cursor"), limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCursor):
+			response.BadRequest(c, "Invalid cursor", nil)
+		case errors.Is(err, service.ErrFavoriteCollectionNotFound):
+			response.NotFound(c, "Collection not found")
+		default:
+			response.InternalError(c, "Failed to retrieve favorites")
+		}
+		return
+	}
+
+	response.Success(c, resp)
+}
+
+// ListCollections handles listing the user's favorite collections
+// GET /api/v1/favorites/collections
+func (h *FavoriteHandler) ListCollections(c *gin.Context) {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	collections, err := h.favoriteService.ListCollections(c.Request.Context(), userID)
+	if err != nil {
+		response.InternalError(c, "Failed to retrieve collections")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"collections": collections,
+	})
+}
+
+// CreateCollection handles creating a favorite collection
+// POST /api/v1/favorites/collections
+func (h *FavoriteHandler) CreateCollection(c *gin.Context) {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req service.FavoriteCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	collection, err := h.favoriteService.CreateCollection(c.Request.Context(), userID, req)
+	if err != nil {
+		h.writeCollectionError(c, err, "Failed to create collection")
+		return
+	}
+
+	response.Success(c, collection)
+}
+
+// UpdateCollection handles renaming a favorite collection
+// PUT /api/v1/favorites/collections/:id
+func (h *FavoriteHandler) UpdateCollection(c *gin.Context) {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+	collectionID, ok := h.parseID(c, "Invalid collection ID")
+	if !ok {
+		return
+	}
+
+	var req service.FavoriteCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	collection, err := h.favoriteService.UpdateCollection(c.Request.Context(), userID, collectionID, req)
+	if err != nil {
+		h.writeCollectionError(c, err, "Failed to update collection")
+		return
+	}
+
+	response.Success(c, collection)
+}
+
+// DeleteCollection handles deleting a favorite collection
+// DELETE /api/v1/favorites/collections/:id
+func (h *FavoriteHandler) DeleteCollection(c *gin.Context) {
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+	collectionID, ok := h.parseID(c, "Invalid collection ID")
+	if !ok {
+		return
+	}
+
+	if err := h.favoriteService.DeleteCollection(c.Request.Context(), userID, collectionID); err != nil {
+		h.writeCollectionError(c, err, "Failed to delete collection")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Collection deleted successfully",
+	})
+}
+
+// writeCollectionError maps collection service errors to responses
+func (h *FavoriteHandler) writeCollectionError(c *gin.Context, err error, failureMessage string) {
+	switch {
+	case errors.Is(err, service.ErrFavoriteCollectionNotFound):
+		response.NotFound(c, "Collection not found")
+	case errors.Is(err, service.ErrFavoriteCollectionExists):
+		response.Conflict(c, "A collection with this name already exists")
+	case errors.Is(err, service.ErrInvalidCollectionName):
+		response.BadRequest(c, err.Error(), nil)
+	default:
+		response.InternalError(c, failureMessage)
+	}
+}
+
+// currentUserID reads the authenticated user ID from the request context
+func (h *FavoriteHandler) currentUserID(c *gin.Context) (int64, bool) {
+	userIDValue, exists := c.Get("userID")
+	if !exists {
+		response.Unauthorized(c, "Authentication required")
+		return 0, false
+	}
+
+	userID, ok := userIDValue.(int64)
+	if !ok {
+		response.InternalError(c, "Invalid user ID in context")
+		return 0, false
+	}
+
+	return userID, true
+}
+
+// parseID reads the :id path parameter
+func (h *FavoriteHandler) parseID(c *gin.Context, invalidMessage string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, invalidMessage, nil)
+		return 0, false
+	}
+	return id, true
+}
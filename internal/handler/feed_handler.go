@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -27,7 +28,7 @@ func NewFeedHandler(feedService service.FeedService) *FeedHandler {
 // GetUserFeedRequest represents the request for getting user feed
 type GetUserFeedRequest struct {
 	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
-	Offset int    `form:"offset" binding:"omitempty,min=0"`
+	Cursor string `form:"cursor"`
 	SortBy string `form:"sort_by" binding:"omitempty,oneof=created_at hotness_score"`
 }
 
@@ -38,9 +39,9 @@ type GetUserFeedRequest struct {
 // @Accept json
 // @Produce json
 // @Param limit query int false "Number of posts to return (default: 20, max: 100)"
-// @Param offset query int false "Offset for pagination (default: 0)"
+// @Param cursor query string false "next_cursor from the previous page (omit for the first page)"
 // @Param sort_by query string false "Sort by field: created_at or hotness_score (default: created_at)"
-// @Success 200 {object} response.Response{data=[]models.Post}
+// @Success 200 {object} response.Response{data=service.FeedResponse}
 // @Failure 400 {object} response.Response
 // @Failure 401 {object} response.Response
 // @Failure 500 {object} response.Response
@@ -71,8 +72,12 @@ func (h *FeedHandler) GetUserFeed(c *gin.Context) {
 	}
 
 	// Get user feed
-	posts, err := h.feedService.GetUserFeed(c.Request.Context(), userID.(int64), req.Limit, req.Offset, req.SortBy)
+	feed, err := h.feedService.GetUserFeed(c.Request.Context(), userID.(int64), req.Limit, req.Cursor, req.SortBy)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			response.Error(c, http.StatusBadRequest, "invalid_cursor", "Invalid cursor", nil)
+			return
+		}
 		appLogger.Error("Failed to get user feed",
 			zap.Int64("user_id", userID.(int64)),
 			zap.Error(err),
@@ -81,7 +86,7 @@ func (h *FeedHandler) GetUserFeed(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, posts)
+	response.Success(c, feed)
 }
 
 // GetCircleFeedRequest represents the request for getting circle feed
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kobayashirei/airy/internal/response"
+	"github.com/kobayashirei/airy/internal/service"
+)
+
+// FollowHandler handles follow-related HTTP requests
+type FollowHandler struct {
+	followService service.FollowService
+}
+
+// NewFollowHandler creates a new follow handler
+func NewFollowHandler(followService service.FollowService) *FollowHandler {
+	return &FollowHandler{
+		followService: followService,
+	}
+}
+
+// Follow handles following a user
+// POST /api/v1/users/:id/follow
+func (h *FollowHandler) Follow(c *gin.Context) {
+	followerID, targetID, ok := h.parseFollowPair(c)
+	if !ok {
+		return
+	}
+
+	if err := h.followService.Follow(c.Request.Context(), followerID, targetID); err != nil {
+		switch {
+		case errors.Is(err, service.ErrCannotFollowSelf):
+			response.BadRequest(c, "You cannot follow yourself", nil)
+		case errors.Is(err, service.ErrUserNotFound):
+			response.NotFound(c, "User not found")
+		default:
+			response.InternalError(c, "Failed to follow user")
+		}
+		return
+	}
+
+	response.Success(c, gin.H{
+		"following": true,
+	})
+}
+
+// Unfollow handles unfollowing a user
+// DELETE /api/v1/users/:id/follow
+func (h *FollowHandler) Unfollow(c *gin.Context) {
+	followerID, targetID, ok := h.parseFollowPair(c)
+	if !ok {
+		return
+	}
+
+	if err := h.followService.Unfollow(c.Request.Context(), followerID, targetID); err != nil {
+		switch {
+		case errors.Is(err, service.ErrCannotFollowSelf):
+			response.BadRequest(c, "You cannot unfollow yourself", nil)
+		default:
+			response.InternalError(c, "Failed to unfollow user")
+		}
+		return
+	}
+
+	response.Success(c, gin.H{
+		"following": false,
+	})
+}
+
+// GetFollowStatus handles checking whether the authenticated user follows a user
+// GET /api/v1/users/:id/follow
+func (h *FollowHandler) GetFollowStatus(c *gin.Context) {
+	followerID, targetID, ok := h.parseFollowPair(c)
+	if !ok {
+		return
+	}
+
+	following, err := h.followService.IsFollowing(c.Request.Context(), followerID, targetID)
+	if err != nil {
+		response.InternalError(c, "Failed to check follow status")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"following": following,
+	})
+}
+
+// GetFollowers handles listing the followers of a user
+// GET /api/v1/users/:id/followers
+func (h *FollowHandler) GetFollowers(c *gin.Context) {
+	h.listFollows(c, h.followService.GetFollowers, "Failed to retrieve followers")
+}
+
+// GetFollowing handles listing the users a user follows
+// GET /api/v1/users/:id/following
+func (h *FollowHandler) GetFollowing(c *gin.Context) {
+	h.listFollows(c, h.followService.GetFollowing, "Failed to retrieve followed users")
+}
+
+// listFollows parses the user ID, cursor and limit and writes one page of a follow list
+func (h *FollowHandler) listFollows(
+	c *gin.Context,
+	list func(ctx context.Context, userID int64, cursor string, limit int) (*service.FollowListResponse, error),
+	failureMessage string,
+) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID", nil)
+		return
+	}
+
+	limit := 20
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > 100 {
+			response.BadRequest(c, "Invalid limit parameter (must be between 1 and 100)", nil)
+			return
+		}
+	}
+
+	resp, err := list(c.Request.Context(), userID, c.Query("cursor"), limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCursor):
+			response.BadRequest(c, "Invalid cursor", nil)
+		case errors.Is(err, service.ErrUserNotFound):
+			response.NotFound(c, "User not found")
+		default:
+			response.InternalError(c, failureMessage)
+		}
+		return
+	}
+
+	response.Success(c, resp)
+}
+
+// parseFollowPair reads the authenticated user ID and the target user ID from the request
+func (h *FollowHandler) parseFollowPair(c *gin.Context) (int64, int64, bool) {
+	userIDValue, exists := c.Get("userID")
+	if !exists {
+		response.Unauthorized(c, "Authentication required")
+		return 0, 0, false
+	}
+
+	userID, ok := userIDValue.(int64)
+	if !ok {
+		response.InternalError(c, "Invalid user ID in context")
+		return 0, 0, false
+	}
+
+	targetID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid user ID", nil)
+		return 0, 0, false
+	}
+
+	return userID, targetID, true
+}
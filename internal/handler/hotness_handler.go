@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kobayashirei/airy/internal/response"
+	"github.com/kobayashirei/airy/internal/service"
+)
+
+// HotnessHandler handles admin requests on how post hotness is calculated
+type HotnessHandler struct {
+	hotnessAdminService service.HotnessAdminService
+}
+
+// NewHotnessHandler creates a new hotness handler
+func NewHotnessHandler(hotnessAdminService service.HotnessAdminService) *HotnessHandler {
+	return &HotnessHandler{
+		hotnessAdminService: hotnessAdminService,
+	}
+}
+
+// GetSettings retrieves the default hotness settings and the circle overrides
+// GET /api/v1/admin/hotness
+func (h *HotnessHandler) GetSettings(c *gin.Context) {
+	result, err := h.hotnessAdminService.GetSettings(c.Request.Context())
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to get hotness settings", err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// PreviewSettings ranks recent posts under candidate settings next to their current ranking
+// POST /api/v1/admin/hotness/preview
+func (h *HotnessHandler) PreviewSettings(c *gin.Context) {
+	var req service.HotnessPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "BAD_REQUEST", "invalid request", err.Error())
+		return
+	}
+
+	result, err := h.hotnessAdminService.Preview(c.Request.Context(), req)
+	if err != nil {
+		h.handleError(c, err, "failed to preview hotness settings")
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// SetCircleSettings overrides the hotness settings of a circle
+// PUT /api/v1/admin/circles/:id/hotness
+func (h *HotnessHandler) SetCircleSettings(c *gin.Context) {
+	circleID, ok := h.parseCircleID(c)
+	if !ok {
+		return
+	}
+
+	var settings service.HotnessSettings
+	if err := c.ShouldBindJSON(&settings); err != nil {
+		response.Error(c, http.StatusBadRequest, "BAD_REQUEST", "invalid request", err.Error())
+		return
+	}
+
+	operatorID, exists := c.Get("userID")
+	if !exists {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	result, err := h.hotnessAdminService.SetCircleSettings(c.Request.Context(), operatorID.(int64), circleID, settings, c.ClientIP())
+	if err != nil {
+		h.handleError(c, err, "failed to set circle hotness settings")
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// DeleteCircleSettings removes the hotness override of a circle
+// DELETE /api/v1/admin/circles/:id/hotness
+func (h *HotnessHandler) DeleteCircleSettings(c *gin.Context) {
+	circleID, ok := h.parseCircleID(c)
+	if !ok {
+		return
+	}
+
+	operatorID, exists := c.Get("userID")
+	if !exists {
+		response.Unauthorized(c, "unauthorized")
+		return
+	}
+
+	if err := h.hotnessAdminService.DeleteCircleSettings(c.Request.Context(), operatorID.(int64), circleID, c.ClientIP()); err != nil {
+		h.handleError(c, err, "failed to delete circle hotness settings")
+		return
+	}
+
+	response.Success(c, gin.H{"message": "circle hotness settings deleted successfully"})
+}
+
+// parseCircleID parses the circle ID path parameter, responding with 400 when it is invalid
+func (h *HotnessHandler) parseCircleID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "BAD_REQUEST", "invalid circle ID", err.Error())
+		return 0, false
+	}
+	return id, true
+}
+
+// handleError maps hotness admin service errors to responses
+func (h *HotnessHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidHotnessSettings):
+		response.Error(c, http.StatusBadRequest, "BAD_REQUEST", err.Error(), nil)
+	case errors.Is(err, service.ErrCircleNotFound):
+		response.NotFound(c, "circle not found")
+	case errors.Is(err, service.ErrCircleHotnessNotFound):
+		response.NotFound(c, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", message, err.Error())
+	}
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kobayashirei/airy/internal/response"
+	"github.com/kobayashirei/airy/internal/taskpool"
+)
+
+// JobHandler handles admin requests on the job queue
+type JobHandler struct {
+	jobs *taskpool.JobQueue
+}
+
+// NewJobHandler creates a new job handler. A nil job queue, as without Redis, responds
+// with 503.
+func NewJobHandler(jobs *taskpool.JobQueue) *JobHandler {
+	return &JobHandler{
+		jobs: jobs,
+	}
+}
+
+// GetJobs reports how many jobs are in each state and lists the dead jobs, most recently failed first
+// GET /api/v1/admin/jobs
+func (h *JobHandler) GetJobs(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	stats, err := h.jobs.Stats(c.Request.Context())
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to count jobs", err.Error())
+		return
+	}
+	dead, err := h.jobs.Dead(c.Request.Context(), limit)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", "failed to list dead jobs", err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"stats": stats,
+		"dead":  dead,
+	})
+}
+
+// RetryDeadJob schedules a dead job to run now with fresh attempts
+// POST /api/v1/admin/jobs/dead/:id/retry
+func (h *JobHandler) RetryDeadJob(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	if err := h.jobs.RetryDead(c.Request.Context(), c.Param("id")); err != nil {
+		h.handleError(c, err, "failed to retry job")
+		return
+	}
+
+	response.Success(c, gin.H{"message": "job scheduled"})
+}
+
+// DeleteDeadJob removes a dead job
+// DELETE /api/v1/admin/jobs/dead/:id
+func (h *JobHandler) DeleteDeadJob(c *gin.Context) {
+	if !h.available(c) {
+		return
+	}
+
+	if err := h.jobs.DeleteDead(c.Request.Context(), c.Param("id")); err != nil {
+		h.handleError(c, err, "failed to delete job")
+		return
+	}
+
+	response.Success(c, gin.H{"message": "job deleted successfully"})
+}
+
+// available responds with 503 when there is no job queue
+func (h *JobHandler) available(c *gin.Context) bool {
+	if h.jobs == nil {
+		response.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "job queue is not available", nil)
+		return false
+	}
+	return true
+}
+
+// handleError maps job queue errors to responses
+func (h *JobHandler) handleError(c *gin.Context, err error, message string) {
+	if errors.Is(err, taskpool.ErrJobNotFound) {
+		response.NotFound(c, "dead job not found")
+		return
+	}
+	response.Error(c, http.StatusInternalServerError, "INTERNAL_ERROR", message, err.Error())
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -32,15 +33,8 @@ func (h *MessageHandler) GetConversations(c *gin.Context) {
 	}
 
 	// Parse pagination parameters
-	page := 1
 	pageSize := 20
 
-	if pageStr := c.Query("page"); pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
-	}
-
 	if pageSizeStr := c.Query("page_size"); pageSizeStr != "" {
 		if ps, err := strconv.Atoi(pageSizeStr); err == nil && ps > 0 && ps <= 100 {
 			pageSize = ps
@@ -48,8 +42,12 @@ func (h *MessageHandler) GetConversations(c *gin.Context) {
 	}
 
 	// Get conversations
-	result, err := h.messageService.GetConversations(c.Request.Context(), userID.(int64), page, pageSize)
+	result, err := h.messageService.GetConversations(c.Request.Context(), userID.(int64), c.Query("cursor"), pageSize)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			response.BadRequest(c, "Invalid cursor", nil)
+			return
+		}
 		response.InternalError(c, "Failed to retrieve conversations")
 		return
 	}
@@ -76,15 +74,8 @@ func (h *MessageHandler) GetMessages(c *gin.Context) {
 	}
 
 	// Parse pagination parameters
-	page := 1
 	pageSize := 50
 
-	if pageStr := c.Query("page"); pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
-	}
-
 	if pageSizeStr := c.Query("page_size"); pageSizeStr != "" {
 		if ps, err := strconv.Atoi(pageSizeStr); err == nil && ps > 0 && ps <= 100 {
 			pageSize = ps
@@ -92,9 +83,11 @@ func (h *MessageHandler) GetMessages(c *gin.Context) {
 	}
 
 	// Get messages
-	result, err := h.messageService.GetMessages(c.Request.Context(), userID.(int64), conversationID, page, pageSize)
+	result, err := h.messageService.GetMessages(c.Request.Context(), userID.(int64), conversationID, c.Query("cursor"), pageSize)
 	if err != nil {
-		if err == service.ErrConversationNotFound {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			response.BadRequest(c, "Invalid cursor", nil)
+		} else if err == service.ErrConversationNotFound {
 			response.NotFound(c, "Conversation not found")
 		} else if err == service.ErrUnauthorizedConversation {
 			response.Forbidden(c, "Unauthorized to access this conversation")
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -32,15 +33,8 @@ func (h *NotificationHandler) GetNotifications(c *gin.Context) {
 	}
 
 	// Parse pagination parameters
-	page := 1
 	pageSize := 20
 
-	if pageStr := c.Query("page"); pageStr != "" {
-		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-			page = p
-		}
-	}
-
 	if pageSizeStr := c.Query("page_size"); pageSizeStr != "" {
 		if ps, err := strconv.Atoi(pageSizeStr); err == nil && ps > 0 && ps <= 100 {
 			pageSize = ps
@@ -48,8 +42,12 @@ func (h *NotificationHandler) GetNotifications(c *gin.Context) {
 	}
 
 	// Get notifications
-	result, err := h.notificationService.GetNotifications(c.Request.Context(), userID.(int64), page, pageSize)
+	result, err := h.notificationService.GetNotifications(c.Request.Context(), userID.(int64), c.Query("cursor"), pageSize)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			response.BadRequest(c, "Invalid cursor", nil)
+			return
+		}
 		response.InternalError(c, "Failed to retrieve notifications")
 		return
 	}
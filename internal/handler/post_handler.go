@@ -2,6 +2,7 @@ package handler
 
 import (
 	"errors"
+	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -43,6 +44,8 @@ func (h *PostHandler) CreatePost(c *gin.Context) {
 	if err != nil {
 		if errors.Is(err, service.ErrModerationFailed) {
 			response.InternalError(c, "Content moderation failed")
+		} else if errors.Is(err, service.ErrSchedulingUnavailable) {
+			response.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "Scheduled publishing is not available", nil)
 		} else {
 			response.InternalError(c, "Failed to create post")
 		}
@@ -161,6 +164,84 @@ func (h *PostHandler) DeletePost(c *gin.Context) {
 	})
 }
 
+// SchedulePost handles scheduling or rescheduling a draft or scheduled post
+// PUT /api/v1/posts/:id/schedule
+func (h *PostHandler) SchedulePost(c *gin.Context) {
+	// Parse post ID from URL parameter
+	postIDStr := c.Param("id")
+	postID, err := strconv.ParseInt(postIDStr, 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid post ID", nil)
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req service.SchedulePostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body", err.Error())
+		return
+	}
+
+	post, err := h.postService.SchedulePost(c.Request.Context(), postID, userID.(int64), req.ScheduledAt)
+	if err != nil {
+		h.handleScheduleError(c, err, "You are not authorized to schedule this post", "Failed to schedule post")
+		return
+	}
+
+	response.Success(c, post)
+}
+
+// CancelSchedule handles turning a scheduled post back into a draft
+// DELETE /api/v1/posts/:id/schedule
+func (h *PostHandler) CancelSchedule(c *gin.Context) {
+	// Parse post ID from URL parameter
+	postIDStr := c.Param("id")
+	postID, err := strconv.ParseInt(postIDStr, 10, 64)
+	if err != nil {
+		response.BadRequest(c, "Invalid post ID", nil)
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get("userID")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	post, err := h.postService.CancelSchedule(c.Request.Context(), postID, userID.(int64))
+	if err != nil {
+		h.handleScheduleError(c, err, "You are not authorized to unschedule this post", "Failed to cancel schedule")
+		return
+	}
+
+	response.Success(c, post)
+}
+
+// handleScheduleError maps scheduling errors to responses
+func (h *PostHandler) handleScheduleError(c *gin.Context, err error, forbidden, internal string) {
+	switch {
+	case errors.Is(err, service.ErrPostNotFound):
+		response.NotFound(c, "Post not found")
+	case errors.Is(err, service.ErrUnauthorized):
+		response.Forbidden(c, forbidden)
+	case errors.Is(err, service.ErrInvalidSchedule):
+		response.BadRequest(c, err.Error(), nil)
+	case errors.Is(err, service.ErrPostNotSchedulable):
+		response.Conflict(c, "Only draft and scheduled posts can be scheduled")
+	case errors.Is(err, service.ErrSchedulingUnavailable):
+		response.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "Scheduled publishing is not available", nil)
+	default:
+		response.InternalError(c, internal)
+	}
+}
+
 // ListPosts handles listing posts with pagination and filtering
 // GET /api/v1/posts
 func (h *PostHandler) ListPosts(c *gin.Context) {
@@ -171,15 +252,16 @@ func (h *PostHandler) ListPosts(c *gin.Context) {
 	}
 
 	// Set defaults if not provided
-	if req.Page < 1 {
-		req.Page = 1
-	}
 	if req.PageSize < 1 {
 		req.PageSize = 20
 	}
 
 	result, err := h.postService.ListPosts(c.Request.Context(), req)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			response.BadRequest(c, "Invalid cursor", nil)
+			return
+		}
 		response.InternalError(c, "Failed to list posts")
 		return
 	}
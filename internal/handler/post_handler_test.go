@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -59,6 +60,27 @@ func (m *MockPostService) ListPosts(ctx context.Context, opts service.ListPostsR
 	return args.Get(0).(*service.ListPostsResponse), args.Error(1)
 }
 
+func (m *MockPostService) SchedulePost(ctx context.Context, postID int64, userID int64, scheduledAt time.Time) (*models.Post, error) {
+	args := m.Called(ctx, postID, userID, scheduledAt)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Post), args.Error(1)
+}
+
+func (m *MockPostService) CancelSchedule(ctx context.Context, postID int64, userID int64) (*models.Post, error) {
+	args := m.Called(ctx, postID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Post), args.Error(1)
+}
+
+func (m *MockPostService) PublishScheduledPost(ctx context.Context, postID int64) error {
+	args := m.Called(ctx, postID)
+	return args.Error(0)
+}
+
 func TestCreatePost_Success(t *testing.T) {
 	mockService := new(MockPostService)
 	handler := NewPostHandler(mockService)
@@ -240,14 +262,12 @@ func TestListPosts_Success(t *testing.T) {
 			{ID: 1, Title: "Post 1"},
 			{ID: 2, Title: "Post 2"},
 		},
-		Total:      2,
-		Page:       1,
-		PageSize:   20,
-		TotalPages: 1,
+		Total:    2,
+		PageSize: 20,
 	}, nil)
 
 	// Create request
-	req, _ := http.NewRequest("GET", "/api/v1/posts?page=1&page_size=20", nil)
+	req, _ := http.NewRequest("GET", "/api/v1/posts?page_size=20", nil)
 
 	// Execute request
 	w := httptest.NewRecorder()
@@ -382,3 +402,32 @@ func TestGetPost_InternalError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 	mockService.AssertExpectations(t)
 }
+
+func TestSchedulePost_NotSchedulable(t *testing.T) {
+	mockService := new(MockPostService)
+	handler := NewPostHandler(mockService)
+	router := setupTestRouter()
+
+	// Setup route
+	router.PUT("/api/v1/posts/:id/schedule", func(c *gin.Context) {
+		c.Set("userID", int64(1))
+		handler.SchedulePost(c)
+	})
+
+	// Setup expectations - published posts cannot be scheduled
+	scheduledAt := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+	mockService.On("SchedulePost", mock.Anything, int64(1), int64(1), scheduledAt).Return(nil, service.ErrPostNotSchedulable)
+
+	// Create request
+	body, _ := json.Marshal(map[string]interface{}{"scheduled_at": scheduledAt})
+	req, _ := http.NewRequest("PUT", "/api/v1/posts/1/schedule", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Execute request
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assertions
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockService.AssertExpectations(t)
+}
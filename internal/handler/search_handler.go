@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -107,6 +109,10 @@ func (h *SearchHandler) SearchPosts(c *gin.Context) {
 	// Execute search
 	result, err := h.searchService.SearchPosts(c.Request.Context(), query)
 	if err != nil {
+		if errors.Is(err, service.ErrSearchUnavailable) {
+			response.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "Search is temporarily unavailable", nil)
+			return
+		}
 		response.InternalError(c, "Failed to search posts")
 		return
 	}
@@ -191,6 +197,10 @@ func (h *SearchHandler) SearchUsers(c *gin.Context) {
 	// Execute search
 	result, err := h.searchService.SearchUsers(c.Request.Context(), query)
 	if err != nil {
+		if errors.Is(err, service.ErrSearchUnavailable) {
+			response.Error(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "Search is temporarily unavailable", nil)
+			return
+		}
 		response.InternalError(c, "Failed to search users")
 		return
 	}
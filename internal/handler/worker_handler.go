@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kobayashirei/airy/internal/response"
+	"github.com/kobayashirei/airy/internal/service"
+)
+
+// WorkerHandler handles admin requests on background workers
+type WorkerHandler struct {
+	workers *service.WorkerManager
+}
+
+// NewWorkerHandler creates a new worker handler. A nil manager, as on API-only
+// replicas, reports no workers.
+func NewWorkerHandler(workers *service.WorkerManager) *WorkerHandler {
+	return &WorkerHandler{
+		workers: workers,
+	}
+}
+
+// ListWorkers reports each worker's state, consumer group lag and last error
+// GET /api/v1/admin/workers
+func (h *WorkerHandler) ListWorkers(c *gin.Context) {
+	response.Success(c, gin.H{
+		"workers": h.workers.Status(),
+	})
+}
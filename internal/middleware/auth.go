@@ -44,6 +44,7 @@ func AuthMiddleware(jwtService auth.JWTService) gin.HandlerFunc {
 		// Set user information in context
 		c.Set("userID", claims.UserID)
 		c.Set("roles", claims.Roles)
+		c.Set("circleRoles", claims.CircleRoles)
 
 		c.Next()
 	}
@@ -69,6 +70,7 @@ func OptionalAuthMiddleware(jwtService auth.JWTService) gin.HandlerFunc {
 		if err == nil {
 			c.Set("userID", claims.UserID)
 			c.Set("roles", claims.Roles)
+			c.Set("circleRoles", claims.CircleRoles)
 		}
 
 		c.Next()
@@ -94,3 +96,17 @@ func GetRoles(c *gin.Context) ([]string, bool) {
 	roleList, ok := roles.([]string)
 	return roleList, ok
 }
+
+// GetCircleRoles retrieves the circle-scoped roles for a circle from the context
+func GetCircleRoles(c *gin.Context, circleID int64) ([]string, bool) {
+	circleRoles, exists := c.Get("circleRoles")
+	if !exists {
+		return nil, false
+	}
+	roleMap, ok := circleRoles.(map[int64][]string)
+	if !ok {
+		return nil, false
+	}
+	roles, ok := roleMap[circleID]
+	return roles, ok
+}
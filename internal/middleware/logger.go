@@ -8,6 +8,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/kobayashirei/airy/internal/logger"
+	"github.com/kobayashirei/airy/internal/mq"
 )
 
 // RequestLogger is a middleware that logs HTTP requests
@@ -18,6 +19,13 @@ func RequestLogger() gin.HandlerFunc {
 		requestID := uuid.New().String()
 		c.Set("request_id", requestID)
 
+		// Events published while handling the request are correlated with it
+		ctx := mq.ContextWithCorrelationID(c.Request.Context(), requestID)
+		if traceParent := c.GetHeader("traceparent"); traceParent != "" {
+			ctx = mq.ContextWithTraceParent(ctx, traceParent)
+		}
+		c.Request = c.Request.WithContext(ctx)
+
 		// Start timer
 		start := time.Now()
 		path := c.Request.URL.Path
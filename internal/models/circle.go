@@ -23,6 +23,19 @@ func (Circle) TableName() string {
 	return "circles"
 }
 
+// CircleHotnessSettings overrides the hotness algorithm for the posts of a circle
+type CircleHotnessSettings struct {
+	CircleID  int64     `gorm:"primaryKey;autoIncrement:false" json:"circle_id"`
+	Settings  string    `gorm:"type:json;not null" json:"settings"` // JSON object of the algorithm, weights and gravity
+	UpdatedBy int64     `gorm:"not null" json:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for CircleHotnessSettings model
+func (CircleHotnessSettings) TableName() string {
+	return "circle_hotness_settings"
+}
+
 // CircleMember represents a member of a circle
 type CircleMember struct {
 	ID       int64     `gorm:"primaryKey" json:"id"`
@@ -12,7 +12,7 @@ type Post struct {
 	CoverImage      string     `gorm:"size:255" json:"cover_image"`
 	AuthorID        int64      `gorm:"index;not null" json:"author_id"`
 	CircleID        *int64     `gorm:"index" json:"circle_id"`
-	Status          string     `gorm:"size:20;index;default:'draft'" json:"status"` // draft, pending, published, hidden, deleted
+	Status          string     `gorm:"size:20;index;default:'draft'" json:"status"` // draft, pending, scheduled, published, hidden, deleted
 	Category        string     `gorm:"size:50" json:"category"`
 	Tags            string     `gorm:"type:json" json:"tags"` // JSON array
 	ScheduledAt     *time.Time `json:"scheduled_at"`
@@ -42,6 +42,8 @@ type Comment struct {
 	RootID    int64      `gorm:"index;not null" json:"root_id"` // Root comment ID
 	Level     int        `gorm:"default:0" json:"level"`
 	Path      string     `gorm:"size:255;index" json:"path"` // Path enumeration, e.g., "1.2.5"
+	Score     int        `gorm:"not null;default:0" json:"score"`      // Upvotes minus downvotes
+	BestScore float64    `gorm:"not null;default:0" json:"best_score"` // Wilson lower bound of the share of upvotes
 	Status    string     `gorm:"size:20;default:'published'" json:"status"` // published, hidden, deleted
 	CreatedAt time.Time  `gorm:"index" json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
@@ -70,10 +72,11 @@ func (Vote) TableName() string {
 
 // Favorite represents a user's favorite post
 type Favorite struct {
-	ID        int64     `gorm:"primaryKey" json:"id"`
-	UserID    int64     `gorm:"uniqueIndex:idx_user_post;not null" json:"user_id"`
-	PostID    int64     `gorm:"uniqueIndex:idx_user_post;index;not null" json:"post_id"`
-	CreatedAt time.Time `json:"created_at"`
+	ID           int64     `gorm:"primaryKey" json:"id"`
+	UserID       int64     `gorm:"uniqueIndex:idx_user_post;not null" json:"user_id"`
+	PostID       int64     `gorm:"uniqueIndex:idx_user_post;index;not null" json:"post_id"`
+	CollectionID *int64    `gorm:"index" json:"collection_id"` // Optional named collection; nil for uncategorized
+	CreatedAt    time.Time `json:"created_at"`
 }
 
 // TableName specifies the table name for Favorite model
@@ -81,6 +84,21 @@ func (Favorite) TableName() string {
 	return "favorites"
 }
 
+// FavoriteCollection is a named, user-owned group of favorites
+type FavoriteCollection struct {
+	ID          int64     `gorm:"primaryKey" json:"id"`
+	UserID      int64     `gorm:"uniqueIndex:idx_user_name;not null" json:"user_id"`
+	Name        string    `gorm:"size:100;uniqueIndex:idx_user_name;not null" json:"name"`
+	Description string    `gorm:"size:500" json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for FavoriteCollection model
+func (FavoriteCollection) TableName() string {
+	return "favorite_collections"
+}
+
 // EntityCount represents aggregated counts for posts and comments
 type EntityCount struct {
 	EntityType    string    `gorm:"primaryKey;size:20;not null" json:"entity_type"` // post, comment
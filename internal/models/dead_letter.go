@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Dead letter statuses
+const (
+	DeadLetterStatusDead     = "dead"
+	DeadLetterStatusReplayed = "replayed"
+)
+
+// DeadLetter is a message a consumer kept failing to handle, kept for inspection and replay
+type DeadLetter struct {
+	ID            int64      `gorm:"primaryKey" json:"id"`
+	LetterID      string     `gorm:"size:36;uniqueIndex;not null" json:"letter_id"`
+	Queue         string     `gorm:"size:255;not null" json:"queue"`
+	ConsumerGroup string     `gorm:"size:100;index:idx_dead_letters_group_topic" json:"consumer_group"`
+	Topic         string     `gorm:"size:100;index:idx_dead_letters_group_topic;not null" json:"topic"`
+	Payload       string     `gorm:"type:json;not null" json:"payload"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	LastError     string     `gorm:"type:text" json:"last_error"`
+	Status        string     `gorm:"size:20;index;not null;default:dead" json:"status"` // dead, replayed
+	ReplayCount   int        `gorm:"not null;default:0" json:"replay_count"`
+	FailedAt      time.Time  `gorm:"not null" json:"failed_at"`
+	ReplayedAt    *time.Time `json:"replayed_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// TableName specifies the table name for DeadLetter model
+func (DeadLetter) TableName() string {
+	return "dead_letters"
+}
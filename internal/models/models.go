@@ -9,6 +9,7 @@ func AllModels() []interface{} {
 		&User{},
 		&UserProfile{},
 		&UserStats{},
+		&Follow{},
 
 		// Permission models
 		&Role{},
@@ -21,11 +22,13 @@ func AllModels() []interface{} {
 		&Comment{},
 		&Vote{},
 		&Favorite{},
+		&FavoriteCollection{},
 		&EntityCount{},
 
 		// Circle models
 		&Circle{},
 		&CircleMember{},
+		&CircleHotnessSettings{},
 
 		// Notification models
 		&Notification{},
@@ -34,6 +37,10 @@ func AllModels() []interface{} {
 
 		// Admin models
 		&AdminLog{},
+
+		// Messaging models
+		&OutboxEvent{},
+		&DeadLetter{},
 	}
 }
 
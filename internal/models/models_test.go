@@ -26,6 +26,34 @@ func TestUserStatsTableName(t *testing.T) {
 	}
 }
 
+func TestFollowTableName(t *testing.T) {
+	follow := Follow{}
+	if follow.TableName() != "follows" {
+		t.Errorf("Expected table name 'follows', got '%s'", follow.TableName())
+	}
+}
+
+func TestFavoriteCollectionTableName(t *testing.T) {
+	collection := FavoriteCollection{}
+	if collection.TableName() != "favorite_collections" {
+		t.Errorf("Expected table name 'favorite_collections', got '%s'", collection.TableName())
+	}
+}
+
+func TestOutboxEventTableName(t *testing.T) {
+	event := OutboxEvent{}
+	if event.TableName() != "outbox" {
+		t.Errorf("Expected table name 'outbox', got '%s'", event.TableName())
+	}
+}
+
+func TestDeadLetterTableName(t *testing.T) {
+	letter := DeadLetter{}
+	if letter.TableName() != "dead_letters" {
+		t.Errorf("Expected table name 'dead_letters', got '%s'", letter.TableName())
+	}
+}
+
 func TestRoleTableName(t *testing.T) {
 	role := Role{}
 	if role.TableName() != "roles" {
@@ -68,6 +96,13 @@ func TestCircleTableName(t *testing.T) {
 	}
 }
 
+func TestCircleHotnessSettingsTableName(t *testing.T) {
+	settings := CircleHotnessSettings{}
+	if settings.TableName() != "circle_hotness_settings" {
+		t.Errorf("Expected table name 'circle_hotness_settings', got '%s'", settings.TableName())
+	}
+}
+
 func TestNotificationTableName(t *testing.T) {
 	notification := Notification{}
 	if notification.TableName() != "notifications" {
@@ -86,8 +121,8 @@ func TestAllModels(t *testing.T) {
 	models := AllModels()
 	
 	// Check that we have all expected models
-	// User: 3, Permission: 4, Content: 5, Circle: 2, Notification: 3, Admin: 1 = 18 total
-	expectedCount := 18
+	// User: 4, Permission: 4, Content: 6, Circle: 3, Notification: 3, Admin: 1, Messaging: 2 = 23 total
+	expectedCount := 23
 	if len(models) != expectedCount {
 		t.Errorf("Expected %d models, got %d", expectedCount, len(models))
 	}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Outbox event statuses
+const (
+	OutboxStatusPending   = "pending"
+	OutboxStatusPublished = "published"
+	// OutboxStatusFailed marks an event the relay gave up on after too many attempts
+	OutboxStatusFailed = "failed"
+)
+
+// OutboxEvent is a domain event stored in the same transaction as the change
+// that raised it, waiting for the outbox relay to publish it to the message queue
+type OutboxEvent struct {
+	ID            int64      `gorm:"primaryKey" json:"id"`
+	EventID       string     `gorm:"size:36;uniqueIndex;not null" json:"event_id"`
+	AggregateType string     `gorm:"size:50;index:idx_outbox_aggregate;not null" json:"aggregate_type"`
+	AggregateID   int64      `gorm:"index:idx_outbox_aggregate;not null" json:"aggregate_id"`
+	Topic         string     `gorm:"size:100;not null" json:"topic"`
+	Payload       string     `gorm:"type:json;not null" json:"payload"`
+	Status        string     `gorm:"size:20;index:idx_outbox_status_next;not null;default:pending" json:"status"` // pending, published, failed
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	LastError     string     `gorm:"type:text" json:"last_error"`
+	NextAttemptAt time.Time  `gorm:"index:idx_outbox_status_next;not null" json:"next_attempt_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	PublishedAt   *time.Time `gorm:"index" json:"published_at"`
+}
+
+// TableName specifies the table name for OutboxEvent model
+func (OutboxEvent) TableName() string {
+	return "outbox"
+}
@@ -2,6 +2,62 @@ package models
 
 import "time"
 
+// Built-in role names
+const (
+	RoleSuperAdmin = "super_admin"
+	RoleModerator  = "moderator"
+	RoleUser       = "user"
+)
+
+// Permission names checked by the RBAC middleware
+const (
+	PermPostCreate            = "post:create"
+	PermPostReview            = "post:review"
+	PermCommentCreate         = "comment:create"
+	PermVoteCast              = "vote:cast"
+	PermCircleCreate          = "circle:create"
+	PermCircleManageMembers   = "circle:manage_members"
+	PermCircleAssignModerator = "circle:assign_moderator"
+	PermAdminAccess           = "admin:access"
+	PermUserBan               = "user:ban"
+	PermAdminLogRead          = "admin_log:read"
+	PermDeadLetterManage      = "dead_letter:manage"
+	PermHotnessManage         = "hotness:manage"
+)
+
+// DefaultRolePermissions lists the permissions granted to each built-in role.
+// super_admin is granted every permission in AllPermissions.
+var DefaultRolePermissions = map[string][]string{
+	RoleUser: {
+		PermPostCreate,
+		PermCommentCreate,
+		PermVoteCast,
+		PermCircleCreate,
+	},
+	RoleModerator: {
+		PermCircleManageMembers,
+		PermCircleAssignModerator,
+	},
+}
+
+// AllPermissions returns every built-in permission name
+func AllPermissions() []string {
+	return []string{
+		PermPostCreate,
+		PermPostReview,
+		PermCommentCreate,
+		PermVoteCast,
+		PermCircleCreate,
+		PermCircleManageMembers,
+		PermCircleAssignModerator,
+		PermAdminAccess,
+		PermUserBan,
+		PermAdminLogRead,
+		PermDeadLetterManage,
+		PermHotnessManage,
+	}
+}
+
 // Role represents a user role in the system
 type Role struct {
 	ID          int64     `gorm:"primaryKey" json:"id"`
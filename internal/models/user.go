@@ -55,3 +55,16 @@ type UserStats struct {
 func (UserStats) TableName() string {
 	return "user_stats"
 }
+
+// Follow represents a user following another user
+type Follow struct {
+	ID          int64     `gorm:"primaryKey" json:"id"`
+	FollowerID  int64     `gorm:"uniqueIndex:idx_follower_following;not null" json:"follower_id"`
+	FollowingID int64     `gorm:"uniqueIndex:idx_follower_following;index;not null" json:"following_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for Follow model
+func (Follow) TableName() string {
+	return "follows"
+}
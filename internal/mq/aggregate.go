@@ -0,0 +1,39 @@
+package mq
+
+// AggregateEvent is implemented by events that describe a change to one aggregate
+// (a post, a comment, ...). The transactional outbox relays the events of an
+// aggregate in the order they were recorded; events of different aggregates may
+// be published in any order.
+type AggregateEvent interface {
+	AggregateKey() (aggregateType string, aggregateID int64)
+}
+
+// AggregateKey returns the post the event belongs to
+func (e PostPublishedEvent) AggregateKey() (string, int64) { return "post", e.PostID }
+
+// AggregateKey returns the post the event belongs to
+func (e PostUpdatedEvent) AggregateKey() (string, int64) { return "post", e.PostID }
+
+// AggregateKey returns the post the event belongs to
+func (e PostDeletedEvent) AggregateKey() (string, int64) { return "post", e.PostID }
+
+// AggregateKey returns the post the event belongs to
+func (e PostFavoritedEvent) AggregateKey() (string, int64) { return "post", e.PostID }
+
+// AggregateKey returns the post the event belongs to
+func (e PostUnfavoritedEvent) AggregateKey() (string, int64) { return "post", e.PostID }
+
+// AggregateKey returns the comment the event belongs to
+func (e CommentCreatedEvent) AggregateKey() (string, int64) { return "comment", e.CommentID }
+
+// AggregateKey returns the comment the event belongs to
+func (e CommentDeletedEvent) AggregateKey() (string, int64) { return "comment", e.CommentID }
+
+// AggregateKey returns the voted entity, so its counter updates apply in order
+func (e VoteCreatedEvent) AggregateKey() (string, int64) { return e.EntityType, e.EntityID }
+
+// AggregateKey returns the voted entity, so its counter updates apply in order
+func (e VoteUpdatedEvent) AggregateKey() (string, int64) { return e.EntityType, e.EntityID }
+
+// AggregateKey returns the voted entity, so its counter updates apply in order
+func (e VoteDeletedEvent) AggregateKey() (string, int64) { return e.EntityType, e.EntityID }
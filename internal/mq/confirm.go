@@ -0,0 +1,330 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+// HeaderSpoolAttempts carries the times a replayed message was spooled, so that a
+// message the broker keeps returning is eventually dropped
+const HeaderSpoolAttempts = "x-spool-attempts"
+
+var (
+	// ErrPublishNacked is returned when the broker refuses a published message
+	ErrPublishNacked = errors.New("message was nacked by the broker")
+	// ErrPublishUnconfirmed is returned when the broker does not confirm a message in time
+	ErrPublishUnconfirmed = errors.New("message was not confirmed by the broker")
+)
+
+var (
+	// mqConfirmedTotal counts messages the broker confirmed
+	mqConfirmedTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "mq_messages_confirmed_total",
+			Help: "Total number of published messages confirmed by the broker",
+		},
+	)
+
+	// mqNackedTotal counts messages the broker refused
+	mqNackedTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "mq_messages_nacked_total",
+			Help: "Total number of published messages nacked by the broker",
+		},
+	)
+
+	// mqSpooledTotal counts messages written to the spool
+	mqSpooledTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mq_messages_spooled_total",
+			Help: "Total number of messages spooled to disk to be published again",
+		},
+		[]string{"reason"},
+	)
+
+	// mqSpoolDroppedTotal counts spooled messages given up on
+	mqSpoolDroppedTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "mq_spool_dropped_total",
+			Help: "Total number of messages dropped after being spooled too many times",
+		},
+	)
+
+	// mqSpooledMessages tracks the number of messages waiting in the spool
+	mqSpooledMessages = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "mq_spooled_messages",
+			Help: "Number of messages waiting in the spool",
+		},
+	)
+)
+
+// Publish publishes a message to the specified topic and waits for the broker to
+// confirm it. A message that cannot be published, or that the broker nacks, does not
+// confirm in time or returns as unroutable, is spooled and published again once the
+// connection is healthy; Publish then succeeds. Without a spool it returns the error.
+// Messages of the unconsumed topics are never returned: no queue is meant to be bound
+// to them, so the broker discards them.
+func (mq *RabbitMQ) Publish(ctx context.Context, topic string, message interface{}) error {
+	// Marshal message to JSON
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	msg := &SpooledMessage{
+		ID:         uuid.New().String(),
+		Exchange:   mq.exchangeName,
+		RoutingKey: topic,
+		Body:       body,
+	}
+	if err := mq.publish(ctx, msg); err != nil {
+		return mq.spoolFailed(msg, err)
+	}
+
+	mq.logger.Debug("published message",
+		zap.String("topic", topic),
+		zap.Int("size", len(body)))
+
+	return nil
+}
+
+// publishRaw publishes an encoded message to an exchange and waits for its confirm;
+// "" is the default exchange, which routes to the queue named by the routing key
+func (mq *RabbitMQ) publishRaw(ctx context.Context, exchange, routingKey string, body []byte, headers amqp.Table) error {
+	return mq.publish(ctx, &SpooledMessage{
+		ID:         uuid.New().String(),
+		Exchange:   exchange,
+		RoutingKey: routingKey,
+		Headers:    headers,
+		Body:       body,
+	})
+}
+
+// publish publishes a message and waits until the broker confirms or nacks it. With a
+// spool the message is mandatory unless its topic is unconsumed, so the broker returns
+// it when no queue is bound to its routing key and handleReturns spools it.
+func (mq *RabbitMQ) publish(ctx context.Context, msg *SpooledMessage) error {
+	mq.mu.RLock()
+	if mq.closed {
+		mq.mu.RUnlock()
+		return ErrQueueClosed
+	}
+	if mq.reconnecting {
+		mq.mu.RUnlock()
+		return fmt.Errorf("message queue is reconnecting")
+	}
+	channel := mq.channel
+	mq.mu.RUnlock()
+
+	confirmation, err := channel.PublishWithDeferredConfirmWithContext(
+		ctx,
+		msg.Exchange,      // exchange
+		msg.RoutingKey,    // routing key
+		mq.mandatory(msg), // mandatory
+		false,             // immediate
+		amqp.Publishing{
+			ContentType:  "application/json",
+			MessageId:    msg.ID,
+			Headers:      msg.Headers,
+			Body:         msg.Body,
+			DeliveryMode: amqp.Persistent,
+			Timestamp:    time.Now(),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to publish message: %w", err)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, mq.confirmTimeout)
+	defer cancel()
+
+	acked, err := confirmation.WaitContext(waitCtx)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPublishUnconfirmed, err)
+	}
+	if !acked {
+		if channel.IsClosed() {
+			// Pending confirms are released as nacks when the channel closes
+			return fmt.Errorf("%w: channel closed", ErrPublishUnconfirmed)
+		}
+		mqNackedTotal.Inc()
+		return ErrPublishNacked
+	}
+
+	mqConfirmedTotal.Inc()
+	return nil
+}
+
+// mandatory reports whether the broker must return a message no queue is bound to, which
+// is worth it only to spool it, and not for topics nothing consumes
+func (mq *RabbitMQ) mandatory(msg *SpooledMessage) bool {
+	return mq.spool != nil && !(msg.Exchange == mq.exchangeName && mq.unconsumedTopics[msg.RoutingKey])
+}
+
+// spoolFailed spools a message that could not be published, returning publishErr
+// when there is no spool, the queue is closed or spooling fails
+func (mq *RabbitMQ) spoolFailed(msg *SpooledMessage, publishErr error) error {
+	if mq.spool == nil || errors.Is(publishErr, ErrQueueClosed) {
+		return publishErr
+	}
+
+	reason := SpoolReasonUnavailable
+	switch {
+	case errors.Is(publishErr, ErrPublishNacked):
+		reason = SpoolReasonNacked
+	case errors.Is(publishErr, ErrPublishUnconfirmed):
+		reason = SpoolReasonUnconfirmed
+	}
+
+	if err := mq.spoolMessage(msg, reason, publishErr); err != nil {
+		return fmt.Errorf("%w; spooling it failed: %v", publishErr, err)
+	}
+	return nil
+}
+
+// spoolMessage writes a message to the spool, or drops it once it has been spooled
+// as often as the spool allows
+func (mq *RabbitMQ) spoolMessage(msg *SpooledMessage, reason string, cause error) error {
+	msg.Attempts++
+	msg.Reason = reason
+	msg.SpooledAt = time.Now()
+
+	if msg.Attempts > mq.spoolMaxAttempts {
+		mqSpoolDroppedTotal.Inc()
+		mq.logger.Error("dropping message spooled too many times",
+			zap.String("id", msg.ID),
+			zap.String("routing_key", msg.RoutingKey),
+			zap.String("reason", reason),
+			zap.Int("attempts", msg.Attempts-1))
+		return nil
+	}
+
+	if err := mq.spool.Put(msg); err != nil {
+		return err
+	}
+	mqSpooledTotal.WithLabelValues(reason).Inc()
+	mqSpooledMessages.Inc()
+
+	mq.logger.Warn("spooled message",
+		zap.String("id", msg.ID),
+		zap.String("routing_key", msg.RoutingKey),
+		zap.String("reason", reason),
+		zap.NamedError("cause", cause))
+	return nil
+}
+
+// handleReturns spools the messages the broker returns as unroutable until the
+// publishing channel closes
+func (mq *RabbitMQ) handleReturns(returns <-chan amqp.Return) {
+	for returned := range returns {
+		mq.logger.Warn("message returned by the broker",
+			zap.String("exchange", returned.Exchange),
+			zap.String("routing_key", returned.RoutingKey),
+			zap.String("reply", returned.ReplyText))
+
+		msg := &SpooledMessage{
+			ID:         returned.MessageId,
+			Exchange:   returned.Exchange,
+			RoutingKey: returned.RoutingKey,
+			Headers:    returned.Headers,
+			Body:       returned.Body,
+			Attempts:   spoolAttempts(returned.Headers),
+		}
+		if msg.ID == "" {
+			msg.ID = uuid.New().String()
+		}
+		delete(msg.Headers, HeaderSpoolAttempts)
+
+		if err := mq.spoolMessage(msg, SpoolReasonUnroutable, errors.New(returned.ReplyText)); err != nil {
+			mq.logger.Error("failed to spool returned message, dropping it",
+				zap.String("id", msg.ID),
+				zap.String("routing_key", msg.RoutingKey),
+				zap.Error(err))
+		}
+	}
+}
+
+// spoolAttempts reads the times a replayed message was spooled from its headers
+func spoolAttempts(headers amqp.Table) int {
+	switch attempts := headers[HeaderSpoolAttempts].(type) {
+	case int32:
+		return int(attempts)
+	case int64:
+		return int(attempts)
+	case int:
+		return attempts
+	default:
+		return 0
+	}
+}
+
+// replaySpool publishes spooled messages again while the connection is up, until Close
+func (mq *RabbitMQ) replaySpool() {
+	ticker := time.NewTicker(mq.spoolInterval)
+	defer ticker.Stop()
+
+	mq.flushSpool()
+	for {
+		select {
+		case <-mq.done:
+			return
+		case <-ticker.C:
+			mq.flushSpool()
+		}
+	}
+}
+
+// flushSpool publishes the spooled messages, oldest first, stopping at the first failure
+// other than a nack
+func (mq *RabbitMQ) flushSpool() {
+	mq.mu.RLock()
+	connected := mq.connectedLocked()
+	mq.mu.RUnlock()
+	if !connected {
+		return
+	}
+
+	replayed, err := mq.spool.Replay(func(msg *SpooledMessage) error {
+		// A message returned again comes back with its attempts in its headers
+		headers := amqp.Table{}
+		for key, value := range msg.Headers {
+			headers[key] = value
+		}
+		headers[HeaderSpoolAttempts] = int32(msg.Attempts)
+		replay := *msg
+		replay.Headers = headers
+
+		ctx, cancel := context.WithTimeout(context.Background(), mq.confirmTimeout)
+		defer cancel()
+
+		err := mq.publish(ctx, &replay)
+		if errors.Is(err, ErrPublishNacked) {
+			// Spool it again at the back, so the broker refusing one message does not
+			// hold up the rest, until it has been spooled too often and is dropped
+			return mq.spoolMessage(msg, SpoolReasonNacked, err)
+		}
+		return err
+	})
+	if err != nil {
+		mq.logger.Warn("failed to replay spooled messages, retrying later",
+			zap.Int("replayed", replayed),
+			zap.Error(err))
+	}
+	if replayed > 0 {
+		mq.logger.Info("replayed spooled messages", zap.Int("count", replayed))
+	}
+
+	if pending, err := mq.spool.Len(); err == nil {
+		mqSpooledMessages.Set(float64(pending))
+	}
+}
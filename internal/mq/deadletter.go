@@ -0,0 +1,109 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Message headers used for retries
+const (
+	HeaderRetryCount = "x-retry-count" // Failed attempts so far
+	HeaderLastError  = "x-last-error"  // Error of the last failed attempt
+)
+
+// DeadLetter is a message whose handler kept failing, with where and why it failed
+type DeadLetter struct {
+	ID        string          `json:"id"`              // Unique per dead-lettering, for deduplication
+	Queue     string          `json:"queue"`           // Queue the message was consumed from
+	Group     string          `json:"group,omitempty"` // Consumer group; empty for Subscribe
+	Topic     string          `json:"topic"`
+	Attempts  int             `json:"attempts"`
+	LastError string          `json:"last_error"`
+	FailedAt  time.Time       `json:"failed_at"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// DeadLetterHandler is a function that processes dead letters
+type DeadLetterHandler func(ctx context.Context, letter *DeadLetter) error
+
+// DeadLetterQueue is implemented by message queues that dead-letter the messages their
+// handlers keep failing instead of retrying them forever
+type DeadLetterQueue interface {
+	// SubscribeDeadLetters shares the dead letters between the handlers of every replica
+	SubscribeDeadLetters(handler DeadLetterHandler) error
+	// Replay delivers a dead-lettered message again: to its consumer group only when it
+	// has one, otherwise to every subscriber of its topic
+	Replay(ctx context.Context, letter *DeadLetter) error
+}
+
+// newDeadLetter records a message that exhausted its attempts
+func newDeadLetter(queue, group, topic string, body []byte, attempts int, lastErr error) *DeadLetter {
+	payload := json.RawMessage(body)
+	if !json.Valid(body) {
+		// Keep non-JSON bodies readable as a JSON string
+		payload, _ = json.Marshal(string(body))
+	}
+
+	letter := &DeadLetter{
+		ID:       uuid.New().String(),
+		Queue:    queue,
+		Group:    group,
+		Topic:    topic,
+		Attempts: attempts,
+		FailedAt: time.Now(),
+		Payload:  payload,
+	}
+	if lastErr != nil {
+		letter.LastError = lastErr.Error()
+	}
+	return letter
+}
+
+// RetryPolicy bounds how often and how fast a failed message is retried
+type RetryPolicy struct {
+	MaxAttempts int           // Attempts, including the first, before a message is dead-lettered
+	BaseDelay   time.Duration // Delay before the first retry, doubled on each further retry
+	MaxDelay    time.Duration // Upper bound of the retry delay
+}
+
+// DefaultRetryPolicy returns the default retry policy
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   time.Second,
+		MaxDelay:    5 * time.Minute,
+	}
+}
+
+// Delay returns how long to wait before retrying after the given number of failed attempts
+func (p RetryPolicy) Delay(failed int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < failed; i++ {
+		delay *= 2
+		if delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	if delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+// withDefaults fills unset fields from DefaultRetryPolicy
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	defaults := DefaultRetryPolicy()
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = defaults.MaxAttempts
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = defaults.BaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = defaults.MaxDelay
+	}
+	return p
+}
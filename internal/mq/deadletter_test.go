@@ -0,0 +1,49 @@
+package mq
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	assert.Equal(t, time.Second, policy.Delay(1))
+	assert.Equal(t, 2*time.Second, policy.Delay(2))
+	assert.Equal(t, 8*time.Second, policy.Delay(4))
+	assert.Equal(t, 10*time.Second, policy.Delay(5))
+	assert.Equal(t, 10*time.Second, policy.Delay(50))
+}
+
+func TestRetryPolicy_WithDefaults(t *testing.T) {
+	assert.Equal(t, DefaultRetryPolicy(), RetryPolicy{}.withDefaults())
+
+	policy := RetryPolicy{MaxAttempts: 3}.withDefaults()
+	assert.Equal(t, 3, policy.MaxAttempts)
+	assert.Equal(t, DefaultRetryPolicy().BaseDelay, policy.BaseDelay)
+}
+
+func TestNewDeadLetter(t *testing.T) {
+	t.Run("keeps JSON payloads as is", func(t *testing.T) {
+		letter := newDeadLetter("airy.search.post.published", "search", TopicPostPublished, []byte(`{"post_id":1}`), 5, errors.New("boom"))
+
+		assert.NotEmpty(t, letter.ID)
+		assert.Equal(t, "search", letter.Group)
+		assert.Equal(t, 5, letter.Attempts)
+		assert.Equal(t, "boom", letter.LastError)
+		assert.JSONEq(t, `{"post_id":1}`, string(letter.Payload))
+	})
+
+	t.Run("wraps other payloads in a JSON string", func(t *testing.T) {
+		letter := newDeadLetter("q", "", "t", []byte("not json"), 1, nil)
+
+		var payload string
+		assert.NoError(t, json.Unmarshal(letter.Payload, &payload))
+		assert.Equal(t, "not json", payload)
+		assert.Empty(t, letter.LastError)
+	})
+}
@@ -0,0 +1,174 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Envelope wraps every published event with its schema version and metadata.
+// The event itself is the payload, in the schema Version of its topic.
+type Envelope struct {
+	ID            string          `json:"id"` // The event's BaseEvent.EventID
+	Topic         string          `json:"topic"`
+	Version       int             `json:"version"`
+	Producer      string          `json:"producer,omitempty"`
+	CorrelationID string          `json:"correlation_id,omitempty"` // Shared by the events a request or event led to
+	TraceParent   string          `json:"traceparent,omitempty"`    // W3C trace context of the publisher
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Payload       json.RawMessage `json:"payload"`
+
+	event interface{} // The event Payload was marshalled from; nil for decoded envelopes
+}
+
+// Event returns the event the envelope was created with, or nil for a decoded envelope
+func (e *Envelope) Event() interface{} {
+	return e.event
+}
+
+// baseEvent is implemented by every event through its embedded BaseEvent
+type baseEvent interface {
+	base() BaseEvent
+}
+
+func (e BaseEvent) base() BaseEvent { return e }
+
+// newEnvelope wraps an event in an envelope of the given schema version, taking the
+// correlation ID and trace context from ctx. An event without a correlation ID starts
+// a new correlation named after itself.
+func newEnvelope(ctx context.Context, topic string, version int, producer string, event interface{}) (*Envelope, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	envelope := &Envelope{
+		Topic:         topic,
+		Version:       version,
+		Producer:      producer,
+		CorrelationID: CorrelationID(ctx),
+		TraceParent:   TraceParent(ctx),
+		OccurredAt:    time.Now(),
+		Payload:       payload,
+		event:         event,
+	}
+	if e, ok := event.(baseEvent); ok {
+		envelope.ID = e.base().EventID
+		if !e.base().Timestamp.IsZero() {
+			envelope.OccurredAt = e.base().Timestamp
+		}
+	}
+	if envelope.ID == "" {
+		envelope.ID = uuid.New().String()
+	}
+	if envelope.CorrelationID == "" {
+		envelope.CorrelationID = envelope.ID
+	}
+	return envelope, nil
+}
+
+// DecodeEnvelope parses a message into its envelope without upcasting it. Messages
+// published before envelopes existed are bare events; they decode as version 1 with
+// their BaseEvent as metadata and the whole message as payload.
+func DecodeEnvelope(message []byte) (*Envelope, error) {
+	var envelope Envelope
+	if err := json.Unmarshal(message, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+	}
+	if envelope.Version > 0 && len(envelope.Payload) > 0 {
+		return &envelope, nil
+	}
+
+	var base BaseEvent
+	if err := json.Unmarshal(message, &base); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+	return &Envelope{
+		ID:         base.EventID,
+		Topic:      base.EventType,
+		Version:    1,
+		OccurredAt: base.Timestamp,
+		Payload:    json.RawMessage(message),
+	}, nil
+}
+
+type contextKey int
+
+const (
+	correlationIDKey contextKey = iota
+	traceParentKey
+	envelopeKey
+)
+
+// ContextWithCorrelationID returns a context whose published events carry the correlation ID
+func ContextWithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	return context.WithValue(ctx, correlationIDKey, correlationID)
+}
+
+// CorrelationID returns the correlation ID of ctx, or ""
+func CorrelationID(ctx context.Context) string {
+	correlationID, _ := ctx.Value(correlationIDKey).(string)
+	return correlationID
+}
+
+// ContextWithTraceParent returns a context whose published events carry the W3C traceparent
+func ContextWithTraceParent(ctx context.Context, traceParent string) context.Context {
+	return context.WithValue(ctx, traceParentKey, traceParent)
+}
+
+// TraceParent returns the W3C traceparent of ctx, or ""
+func TraceParent(ctx context.Context) string {
+	traceParent, _ := ctx.Value(traceParentKey).(string)
+	return traceParent
+}
+
+// ContextWithEnvelope returns a context carrying the envelope of the event being handled,
+// so that the events published while handling it share its correlation ID and trace
+func ContextWithEnvelope(ctx context.Context, envelope *Envelope) context.Context {
+	ctx = context.WithValue(ctx, envelopeKey, envelope)
+	if envelope.CorrelationID != "" {
+		ctx = ContextWithCorrelationID(ctx, envelope.CorrelationID)
+	}
+	if envelope.TraceParent != "" {
+		ctx = ContextWithTraceParent(ctx, envelope.TraceParent)
+	}
+	return ctx
+}
+
+// EnvelopeFromContext returns the envelope of the event being handled, or nil
+func EnvelopeFromContext(ctx context.Context) *Envelope {
+	envelope, _ := ctx.Value(envelopeKey).(*Envelope)
+	return envelope
+}
+
+// envelopeQueue wraps the events published through it in envelopes
+type envelopeQueue struct {
+	MessageQueue
+	registry *Registry
+	producer string
+}
+
+// NewEnvelopeQueue returns a message queue that publishes every event wrapped in an
+// Envelope of the current schema version of its topic in registry, naming producer.
+// Messages that are already encoded, an *Envelope or json.RawMessage (e.g. relayed from
+// the outbox), are published as they are. Subscriptions are passed through.
+func NewEnvelopeQueue(messageQueue MessageQueue, registry *Registry, producer string) MessageQueue {
+	return &envelopeQueue{MessageQueue: messageQueue, registry: registry, producer: producer}
+}
+
+// Publish wraps the event in an envelope and publishes it
+func (q *envelopeQueue) Publish(ctx context.Context, topic string, message interface{}) error {
+	switch message.(type) {
+	case *Envelope, json.RawMessage:
+		return q.MessageQueue.Publish(ctx, topic, message)
+	}
+
+	envelope, err := q.registry.NewEnvelope(ctx, topic, q.producer, message)
+	if err != nil {
+		return err
+	}
+	return q.MessageQueue.Publish(ctx, topic, envelope)
+}
@@ -5,10 +5,12 @@ import "time"
 // Event topics (routing keys)
 const (
 	// Post events
-	TopicPostPublished = "post.published"
-	TopicPostUpdated   = "post.updated"
-	TopicPostDeleted   = "post.deleted"
-	TopicPostVoted     = "post.voted"
+	TopicPostPublished   = "post.published"
+	TopicPostUpdated     = "post.updated"
+	TopicPostDeleted     = "post.deleted"
+	TopicPostVoted       = "post.voted"
+	TopicPostFavorited   = "post.favorited"
+	TopicPostUnfavorited = "post.unfavorited"
 
 	// Comment events
 	TopicCommentCreated = "comment.created"
@@ -30,6 +32,18 @@ const (
 	TopicVoteDeleted = "vote.deleted"
 )
 
+// UnconsumedTopics are the topics no consumer group subscribes to. RabbitMQ publishes
+// them without the mandatory flag, so the broker discards them instead of returning
+// them to the spool. Remove a topic from the list when a consumer subscribes to it.
+var UnconsumedTopics = []string{
+	TopicPostVoted,
+	TopicCommentVoted,
+	TopicUserFollowed,
+	TopicUserUnfollowed,
+	TopicCircleJoined,
+	TopicCircleLeft,
+}
+
 // BaseEvent contains common fields for all events
 type BaseEvent struct {
 	EventID   string    `json:"event_id"`
@@ -69,6 +83,22 @@ type PostVotedEvent struct {
 	VoteType string `json:"vote_type"` // "up" or "down"
 }
 
+// PostFavoritedEvent is published when a user adds a post to their favorites
+type PostFavoritedEvent struct {
+	BaseEvent
+	PostID   int64 `json:"post_id"`
+	AuthorID int64 `json:"author_id"`
+	UserID   int64 `json:"user_id"`
+}
+
+// PostUnfavoritedEvent is published when a user removes a post from their favorites
+type PostUnfavoritedEvent struct {
+	BaseEvent
+	PostID   int64 `json:"post_id"`
+	AuthorID int64 `json:"author_id"`
+	UserID   int64 `json:"user_id"`
+}
+
 // CommentCreatedEvent is published when a comment is created
 type CommentCreatedEvent struct {
 	BaseEvent
@@ -0,0 +1,57 @@
+package mq
+
+import "time"
+
+// HealthReporter is implemented by message queues that report connection and consumer liveness
+type HealthReporter interface {
+	Health() Health
+}
+
+// LagReporter is implemented by message queues that report how many messages wait for a consumer group
+type LagReporter interface {
+	Lag(group, topic string) (int, error)
+}
+
+// Health is a snapshot of a message queue's connection and consumers
+type Health struct {
+	Connected  bool             `json:"connected"`
+	Reconnects int64            `json:"reconnects"` // Successful reconnects since start
+	Consumers  []ConsumerHealth `json:"consumers"`
+}
+
+// ConsumerHealth is the liveness of one subscription
+type ConsumerHealth struct {
+	Queue          string     `json:"queue"`
+	Group          string     `json:"group,omitempty"`
+	Topic          string     `json:"topic,omitempty"`
+	Active         int        `json:"active"`   // Consumers currently receiving
+	Expected       int        `json:"expected"` // Consumers that should be receiving
+	LastDeliveryAt *time.Time `json:"last_delivery_at,omitempty"`
+}
+
+// Alive reports whether all of the subscription's consumers are receiving
+func (c ConsumerHealth) Alive() bool {
+	return c.Active >= c.Expected
+}
+
+// Healthy reports whether the queue is connected and every consumer is alive
+func (h Health) Healthy() bool {
+	if !h.Connected {
+		return false
+	}
+	for _, consumer := range h.Consumers {
+		if !consumer.Alive() {
+			return false
+		}
+	}
+	return true
+}
+
+// lastDelivery converts a delivery time stored as Unix nanoseconds, 0 meaning never
+func lastDelivery(unixNano int64) *time.Time {
+	if unixNano == 0 {
+		return nil
+	}
+	at := time.Unix(0, unixNano)
+	return &at
+}
@@ -0,0 +1,28 @@
+package mq
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth_Healthy(t *testing.T) {
+	alive := ConsumerHealth{Queue: "airy.search.post.published", Active: 2, Expected: 2}
+	dead := ConsumerHealth{Queue: "airy.feed.post.published", Active: 0, Expected: 1}
+
+	assert.True(t, alive.Alive())
+	assert.False(t, dead.Alive())
+
+	assert.True(t, Health{Connected: true, Consumers: []ConsumerHealth{alive}}.Healthy())
+	assert.False(t, Health{Connected: true, Consumers: []ConsumerHealth{alive, dead}}.Healthy())
+	assert.False(t, Health{Connected: false, Consumers: []ConsumerHealth{alive}}.Healthy())
+	assert.True(t, Health{Connected: true}.Healthy())
+}
+
+func TestLastDelivery(t *testing.T) {
+	assert.Nil(t, lastDelivery(0))
+
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	assert.True(t, lastDelivery(at.UnixNano()).Equal(at))
+}
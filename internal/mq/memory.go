@@ -0,0 +1,523 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrQueueClosed is returned when publishing to or subscribing on a closed queue
+var ErrQueueClosed = errors.New("message queue is closed")
+
+// ErrDrainTimeout is returned by Close and Drain when in-flight messages were not handled in time
+var ErrDrainTimeout = errors.New("message queue did not drain before the timeout")
+
+// MemoryConfig holds in-memory message queue configuration
+type MemoryConfig struct {
+	BufferSize   int           // Messages buffered per subscription before Publish blocks
+	Concurrency  int           // Handlers started by each SubscribeGroup call
+	Retry        RetryPolicy   // Redeliveries of a nacked message before it is dead-lettered
+	DrainTimeout time.Duration // How long Close waits for buffered messages to be handled
+	Logger       *zap.Logger
+}
+
+// DefaultMemoryConfig returns the default in-memory message queue configuration
+func DefaultMemoryConfig() MemoryConfig {
+	return MemoryConfig{
+		BufferSize:   1024,
+		Concurrency:  1,
+		Retry:        DefaultRetryPolicy(),
+		DrainTimeout: 10 * time.Second,
+	}
+}
+
+// MemoryQueue implements MessageQueue in process with topic exchange semantics.
+// Every Subscribe call gets its own bounded buffer, like an exclusive queue bound to
+// the exchange with the topic as routing key: "*" matches exactly one word and "#"
+// matches zero or more words. SubscribeGroup calls with the same group and topic share
+// one buffer, so each message is handled by one of the group's handlers. A handler error
+// nacks the message, which is redelivered to the same handler before it takes another.
+type MemoryQueue struct {
+	config MemoryConfig
+	logger *zap.Logger
+
+	mu                 sync.RWMutex
+	closed             bool
+	subscriptions      []*memorySubscription
+	deadLetterHandlers []DeadLetterHandler
+	nextDeadLetter     int
+
+	publishes sync.WaitGroup // Publish calls in progress
+	consumers sync.WaitGroup // subscription workers
+
+	closing chan struct{} // closed when Close starts; unblocks publishers waiting for space
+	drain   chan struct{} // closed once no publish is in progress; workers exit when empty
+
+	// handlerCtx is passed to handlers and cancelled when draining times out
+	handlerCtx    context.Context
+	cancelHandler context.CancelFunc
+}
+
+// memorySubscription is a bounded buffer bound to a topic pattern
+type memorySubscription struct {
+	group    string // empty for Subscribe
+	pattern  []string
+	topic    string
+	messages chan []byte
+	stop     chan struct{} // closed by Drain; handlers exit once the buffer is empty
+	stopped  bool          // guarded by MemoryQueue.mu
+
+	workers      int          // Handlers started on the buffer; guarded by MemoryQueue.mu
+	active       atomic.Int32 // Handlers currently consuming
+	lastDelivery atomic.Int64 // Unix nanoseconds of the last delivery
+}
+
+// queue names the subscription in dead letters and health reports, like the RabbitMQ queue it stands for
+func (sub *memorySubscription) queue() string {
+	if sub.group == "" {
+		return sub.topic
+	}
+	return QueueName("memory", sub.group, sub.topic)
+}
+
+// NewMemoryQueue creates a new in-memory message queue
+func NewMemoryQueue(config MemoryConfig) *MemoryQueue {
+	defaults := DefaultMemoryConfig()
+	if config.BufferSize <= 0 {
+		config.BufferSize = defaults.BufferSize
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = defaults.Concurrency
+	}
+	config.Retry = config.Retry.withDefaults()
+	if config.DrainTimeout <= 0 {
+		config.DrainTimeout = defaults.DrainTimeout
+	}
+	if config.Logger == nil {
+		config.Logger = zap.NewNop()
+	}
+
+	handlerCtx, cancel := context.WithCancel(context.Background())
+	return &MemoryQueue{
+		config:        config,
+		logger:        config.Logger,
+		closing:       make(chan struct{}),
+		drain:         make(chan struct{}),
+		handlerCtx:    handlerCtx,
+		cancelHandler: cancel,
+	}
+}
+
+// Publish delivers a message to every subscription whose topic matches. It blocks
+// while a matching subscription's buffer is full, until ctx is done or the queue closes.
+func (q *MemoryQueue) Publish(ctx context.Context, topic string, message interface{}) error {
+	q.mu.RLock()
+	if q.closed {
+		q.mu.RUnlock()
+		return ErrQueueClosed
+	}
+	q.publishes.Add(1)
+	var targets []*memorySubscription
+	key := strings.Split(topic, ".")
+	for _, sub := range q.subscriptions {
+		if !sub.stopped && matchTopic(sub.pattern, key) {
+			targets = append(targets, sub)
+		}
+	}
+	q.mu.RUnlock()
+	defer q.publishes.Done()
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	for _, sub := range targets {
+		select {
+		case sub.messages <- body:
+		case <-ctx.Done():
+			return fmt.Errorf("failed to publish message: %w", ctx.Err())
+		case <-q.closing:
+			return ErrQueueClosed
+		}
+	}
+
+	q.logger.Debug("published message",
+		zap.String("topic", topic),
+		zap.Int("size", len(body)),
+		zap.Int("subscriptions", len(targets)))
+
+	return nil
+}
+
+// Subscribe binds a new buffer to the topic pattern and starts handling its messages
+func (q *MemoryQueue) Subscribe(topic string, handler MessageHandler) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+
+	sub := q.bind("", topic)
+
+	sub.workers++
+	sub.active.Add(1)
+	q.consumers.Add(1)
+	go q.consume(sub, handler)
+
+	q.logger.Info("subscribed to topic", zap.String("topic", topic))
+	return nil
+}
+
+// SubscribeGroup adds handlers to the consumer group's buffer for the topic, creating it
+// on first use. Messages published before the first call are not kept for the group.
+func (q *MemoryQueue) SubscribeGroup(group, topic string, handler MessageHandler) error {
+	return q.SubscribeGroupConcurrency(group, topic, q.config.Concurrency, handler)
+}
+
+// SubscribeGroupConcurrency is SubscribeGroup starting concurrency handlers instead of
+// the configured number; a concurrency of 0 uses the configured number
+func (q *MemoryQueue) SubscribeGroupConcurrency(group, topic string, concurrency int, handler MessageHandler) error {
+	if concurrency <= 0 {
+		concurrency = q.config.Concurrency
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+
+	sub := q.groupSubscription(group, topic)
+	if sub == nil {
+		sub = q.bind(group, topic)
+	}
+
+	for i := 0; i < concurrency; i++ {
+		sub.workers++
+		sub.active.Add(1)
+		q.consumers.Add(1)
+		go q.consume(sub, handler)
+	}
+
+	q.logger.Info("subscribed consumer group to topic",
+		zap.String("group", group),
+		zap.String("topic", topic),
+		zap.Int("consumers", concurrency))
+	return nil
+}
+
+// groupSubscription returns the consumer group's live buffer for the topic, or nil;
+// the caller holds q.mu
+func (q *MemoryQueue) groupSubscription(group, topic string) *memorySubscription {
+	for _, sub := range q.subscriptions {
+		if sub.group == group && sub.topic == topic && !sub.stopped {
+			return sub
+		}
+	}
+	return nil
+}
+
+// bind creates a subscription buffer for the topic; the caller holds q.mu
+func (q *MemoryQueue) bind(group, topic string) *memorySubscription {
+	sub := &memorySubscription{
+		group:    group,
+		pattern:  strings.Split(topic, "."),
+		topic:    topic,
+		messages: make(chan []byte, q.config.BufferSize),
+		stop:     make(chan struct{}),
+	}
+	q.subscriptions = append(q.subscriptions, sub)
+	return sub
+}
+
+// consume handles the messages of one subscription until the queue or the subscription is
+// drained. The caller counts the handler in sub.active, so Drain waits for it even before it runs.
+func (q *MemoryQueue) consume(sub *memorySubscription, handler MessageHandler) {
+	defer q.consumers.Done()
+	defer sub.active.Add(-1)
+
+	for {
+		select {
+		case body := <-sub.messages:
+			q.deliver(sub, handler, body)
+		case <-q.drain:
+			// No more publishes can arrive; handle what is buffered and stop
+			q.flush(sub, handler)
+			return
+		case <-sub.stop:
+			q.flush(sub, handler)
+			return
+		}
+	}
+}
+
+// flush handles the messages left in a subscription's buffer
+func (q *MemoryQueue) flush(sub *memorySubscription, handler MessageHandler) {
+	for {
+		select {
+		case body := <-sub.messages:
+			q.deliver(sub, handler, body)
+		default:
+			return
+		}
+	}
+}
+
+// deliver hands a message to the handler, redelivering it with backoff while the handler
+// nacks it and dead-lettering it once the retry policy is exhausted
+func (q *MemoryQueue) deliver(sub *memorySubscription, handler MessageHandler, body []byte) {
+	sub.lastDelivery.Store(time.Now().UnixNano())
+
+	for failed := 1; ; failed++ {
+		err := q.handle(sub, handler, body)
+		if err == nil {
+			return
+		}
+
+		q.logger.Error("failed to handle message",
+			zap.String("topic", sub.topic),
+			zap.Int("attempt", failed),
+			zap.Error(err))
+
+		if failed >= q.config.Retry.MaxAttempts {
+			q.deadLetter(sub, body, failed, err)
+			return
+		}
+
+		timer := time.NewTimer(q.config.Retry.Delay(failed))
+		select {
+		case <-timer.C:
+		case <-q.handlerCtx.Done():
+			timer.Stop()
+			q.logger.Warn("dropping nacked message, queue closed before redelivery",
+				zap.String("topic", sub.topic))
+			return
+		}
+	}
+}
+
+// handle calls the handler, turning a panic into a nack
+func (q *MemoryQueue) handle(sub *memorySubscription, handler MessageHandler, body []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+
+	q.logger.Debug("received message",
+		zap.String("topic", sub.topic),
+		zap.Int("size", len(body)))
+
+	return handler(q.handlerCtx, body)
+}
+
+// deadLetter hands a message that exhausted its redeliveries to a dead-letter handler,
+// taking turns between them, or drops it when nobody subscribed to dead letters
+func (q *MemoryQueue) deadLetter(sub *memorySubscription, body []byte, attempts int, lastErr error) {
+	letter := newDeadLetter(sub.queue(), sub.group, sub.topic, body, attempts, lastErr)
+
+	q.mu.Lock()
+	var handler DeadLetterHandler
+	if len(q.deadLetterHandlers) > 0 {
+		handler = q.deadLetterHandlers[q.nextDeadLetter%len(q.deadLetterHandlers)]
+		q.nextDeadLetter++
+	}
+	q.mu.Unlock()
+
+	if handler == nil {
+		q.logger.Error("dropping message after exhausting redeliveries",
+			zap.String("topic", sub.topic),
+			zap.Int("attempts", attempts))
+		return
+	}
+
+	if err := handler(q.handlerCtx, letter); err != nil {
+		q.logger.Error("failed to handle dead letter, dropping message",
+			zap.String("id", letter.ID),
+			zap.String("topic", sub.topic),
+			zap.Error(err))
+	}
+}
+
+// SubscribeDeadLetters adds a handler for messages that exhausted their redeliveries
+func (q *MemoryQueue) SubscribeDeadLetters(handler DeadLetterHandler) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+
+	q.deadLetterHandlers = append(q.deadLetterHandlers, handler)
+	return nil
+}
+
+// Replay delivers a dead-lettered message again, to its consumer group's buffer when it
+// has one and otherwise to every subscription matching its topic
+func (q *MemoryQueue) Replay(ctx context.Context, letter *DeadLetter) error {
+	if letter.Group == "" {
+		return q.Publish(ctx, letter.Topic, letter.Payload)
+	}
+
+	q.mu.RLock()
+	if q.closed {
+		q.mu.RUnlock()
+		return ErrQueueClosed
+	}
+	target := q.groupSubscription(letter.Group, letter.Topic)
+	if target == nil {
+		q.mu.RUnlock()
+		return fmt.Errorf("no consumer group %s subscribed to %s", letter.Group, letter.Topic)
+	}
+	q.publishes.Add(1)
+	q.mu.RUnlock()
+	defer q.publishes.Done()
+
+	select {
+	case target.messages <- []byte(letter.Payload):
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to replay message: %w", ctx.Err())
+	case <-q.closing:
+		return ErrQueueClosed
+	}
+}
+
+// Drain stops the handlers of the given consumer groups, or of every subscription when
+// none are given, once they have handled what is buffered, and waits for them. Drained
+// subscriptions receive no more messages; a later SubscribeGroup starts a new buffer.
+func (q *MemoryQueue) Drain(ctx context.Context, groups ...string) error {
+	q.mu.Lock()
+	var drained []*memorySubscription
+	for _, sub := range q.subscriptions {
+		if !sub.stopped && drains(groups, sub.group) {
+			sub.stopped = true
+			close(sub.stop)
+			drained = append(drained, sub)
+		}
+	}
+	q.mu.Unlock()
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		busy := 0
+		for _, sub := range drained {
+			busy += int(sub.active.Load())
+		}
+		if busy == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %d handlers still running", ErrDrainTimeout, busy)
+		case <-ticker.C:
+		}
+	}
+}
+
+// Lag returns the number of messages waiting in a consumer group's buffer for the topic
+func (q *MemoryQueue) Lag(group, topic string) (int, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	sub := q.groupSubscription(group, topic)
+	if sub == nil {
+		return 0, fmt.Errorf("no consumer group %s subscribed to %s", group, topic)
+	}
+	return len(sub.messages), nil
+}
+
+// Close stops accepting messages and waits for the buffered ones to be handled.
+// After the drain timeout the handlers' context is cancelled and ErrDrainTimeout returned.
+func (q *MemoryQueue) Close() error {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return nil
+	}
+	q.closed = true
+	q.mu.Unlock()
+
+	close(q.closing)
+	q.publishes.Wait()
+	close(q.drain)
+
+	done := make(chan struct{})
+	go func() {
+		q.consumers.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(q.config.DrainTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		q.cancelHandler()
+		q.logger.Info("closed in-memory message queue")
+		return nil
+	case <-timer.C:
+		// Handlers still running see their context cancelled and stop on their own
+		q.cancelHandler()
+		q.logger.Warn("in-memory message queue closed before draining")
+		return ErrDrainTimeout
+	}
+}
+
+// matchTopic reports whether a routing key matches a binding pattern, both split on ".".
+// "*" matches exactly one word and "#" matches zero or more words.
+func matchTopic(pattern, key []string) bool {
+	if len(pattern) == 0 {
+		return len(key) == 0
+	}
+
+	switch pattern[0] {
+	case "#":
+		for i := 0; i <= len(key); i++ {
+			if matchTopic(pattern[1:], key[i:]) {
+				return true
+			}
+		}
+		return false
+	case "*":
+		return len(key) > 0 && matchTopic(pattern[1:], key[1:])
+	default:
+		return len(key) > 0 && pattern[0] == key[0] && matchTopic(pattern[1:], key[1:])
+	}
+}
+
+// Health reports whether the queue is open and the liveness of every subscription's handlers
+func (q *MemoryQueue) Health() Health {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	health := Health{
+		Connected: !q.closed,
+		Consumers: make([]ConsumerHealth, 0, len(q.subscriptions)),
+	}
+	for _, sub := range q.subscriptions {
+		expected := sub.workers
+		if sub.stopped {
+			expected = 0
+		}
+		health.Consumers = append(health.Consumers, ConsumerHealth{
+			Queue:          sub.queue(),
+			Group:          sub.group,
+			Topic:          sub.topic,
+			Active:         int(sub.active.Load()),
+			Expected:       expected,
+			LastDeliveryAt: lastDelivery(sub.lastDelivery.Load()),
+		})
+	}
+	return health
+}
@@ -0,0 +1,349 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchTopic(t *testing.T) {
+	tests := []struct {
+		pattern string
+		key     string
+		want    bool
+	}{
+		{"post.published", "post.published", true},
+		{"post.published", "post.deleted", false},
+		{"post.*", "post.published", true},
+		{"post.*", "post.published.v2", false},
+		{"post.*", "post", false},
+		{"*.created", "comment.created", true},
+		{"post.#", "post", true},
+		{"post.#", "post.published", true},
+		{"post.#", "post.published.v2", true},
+		{"post.#", "comment.created", false},
+		{"#", "vote.created", true},
+		{"#.created", "comment.created", true},
+		{"#.created", "comment.reply.created", true},
+		{"#.created", "comment.deleted", false},
+		{"*.#.v2", "post.published.v2", true},
+		{"*.#.v2", "v2", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.key, func(t *testing.T) {
+			got := matchTopic(strings.Split(tt.pattern, "."), strings.Split(tt.key, "."))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMemoryQueue_Routing(t *testing.T) {
+	q := NewMemoryQueue(MemoryConfig{})
+
+	var mu sync.Mutex
+	received := make(map[string][]string)
+	subscribe := func(name, topic string) {
+		require.NoError(t, q.Subscribe(topic, func(ctx context.Context, message []byte) error {
+			var payload map[string]string
+			require.NoError(t, json.Unmarshal(message, &payload))
+			mu.Lock()
+			received[name] = append(received[name], payload["topic"])
+			mu.Unlock()
+			return nil
+		}))
+	}
+	subscribe("exact", TopicPostPublished)
+	subscribe("posts", "post.*")
+	subscribe("all", "#")
+
+	for _, topic := range []string{TopicPostPublished, TopicPostDeleted, TopicCommentCreated} {
+		require.NoError(t, q.Publish(context.Background(), topic, map[string]string{"topic": topic}))
+	}
+
+	// Close drains the buffers before returning
+	require.NoError(t, q.Close())
+
+	assert.Equal(t, []string{TopicPostPublished}, received["exact"])
+	assert.Equal(t, []string{TopicPostPublished, TopicPostDeleted}, received["posts"])
+	assert.Equal(t, []string{TopicPostPublished, TopicPostDeleted, TopicCommentCreated}, received["all"])
+}
+
+func TestMemoryQueue_SubscribeGroup(t *testing.T) {
+	q := NewMemoryQueue(MemoryConfig{Concurrency: 2})
+
+	var group, broadcast atomic.Int32
+	for i := 0; i < 2; i++ {
+		require.NoError(t, q.SubscribeGroup("counter", TopicVoteCreated, func(ctx context.Context, message []byte) error {
+			group.Add(1)
+			return nil
+		}))
+	}
+	require.NoError(t, q.SubscribeGroup("notification", TopicVoteCreated, func(ctx context.Context, message []byte) error {
+		broadcast.Add(1)
+		return nil
+	}))
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, q.Publish(context.Background(), TopicVoteCreated, i))
+	}
+	require.NoError(t, q.Close())
+
+	// Each group sees every message once, however many handlers it has
+	assert.Equal(t, int32(10), group.Load())
+	assert.Equal(t, int32(10), broadcast.Load())
+}
+
+func TestMemoryQueue_Health(t *testing.T) {
+	q := NewMemoryQueue(MemoryConfig{Concurrency: 2})
+
+	require.NoError(t, q.Subscribe(TopicVoteCreated, func(ctx context.Context, message []byte) error { return nil }))
+	require.NoError(t, q.SubscribeGroup("counter", TopicVoteCreated, func(ctx context.Context, message []byte) error { return nil }))
+	require.NoError(t, q.Publish(context.Background(), TopicVoteCreated, 1))
+
+	require.Eventually(t, func() bool {
+		health := q.Health()
+		return health.Healthy() && health.Consumers[0].LastDeliveryAt != nil && health.Consumers[1].LastDeliveryAt != nil
+	}, time.Second, 10*time.Millisecond)
+
+	health := q.Health()
+	assert.True(t, health.Connected)
+	require.Len(t, health.Consumers, 2)
+	assert.Equal(t, TopicVoteCreated, health.Consumers[0].Queue)
+	assert.Equal(t, 1, health.Consumers[0].Expected)
+	assert.Equal(t, "memory.counter.vote.created", health.Consumers[1].Queue)
+	assert.Equal(t, 2, health.Consumers[1].Active)
+
+	// Closing stops every handler
+	require.NoError(t, q.Close())
+	health = q.Health()
+	assert.False(t, health.Healthy())
+	assert.Equal(t, 0, health.Consumers[1].Active)
+}
+
+func TestQueueName(t *testing.T) {
+	assert.Equal(t, "airy.search.post.published", QueueName("airy", "search", TopicPostPublished))
+}
+
+func TestMemoryQueue_Redelivery(t *testing.T) {
+	t.Run("redelivers a nacked message until it is acked", func(t *testing.T) {
+		q := NewMemoryQueue(MemoryConfig{Retry: RetryPolicy{BaseDelay: time.Millisecond}})
+
+		var calls atomic.Int32
+		require.NoError(t, q.Subscribe("test.redeliver", func(ctx context.Context, message []byte) error {
+			if calls.Add(1) < 3 {
+				return errors.New("not yet")
+			}
+			return nil
+		}))
+
+		require.NoError(t, q.Publish(context.Background(), "test.redeliver", "payload"))
+		require.NoError(t, q.Close())
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("drops the message after max attempts", func(t *testing.T) {
+		q := NewMemoryQueue(MemoryConfig{Retry: RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}})
+
+		var calls atomic.Int32
+		require.NoError(t, q.Subscribe("test.poison", func(ctx context.Context, message []byte) error {
+			calls.Add(1)
+			panic("poison message")
+		}))
+
+		require.NoError(t, q.Publish(context.Background(), "test.poison", "payload"))
+		require.NoError(t, q.Close())
+		assert.Equal(t, int32(3), calls.Load())
+	})
+}
+
+func TestMemoryQueue_DeadLetters(t *testing.T) {
+	q := NewMemoryQueue(MemoryConfig{Retry: RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}})
+
+	var letters []*DeadLetter
+	var mu sync.Mutex
+	require.NoError(t, q.SubscribeDeadLetters(func(ctx context.Context, letter *DeadLetter) error {
+		mu.Lock()
+		letters = append(letters, letter)
+		mu.Unlock()
+		return nil
+	}))
+
+	var failing atomic.Bool
+	failing.Store(true)
+	var handled atomic.Int32
+	require.NoError(t, q.SubscribeGroup("search", TopicPostPublished, func(ctx context.Context, message []byte) error {
+		if failing.Load() {
+			return errors.New("index unavailable")
+		}
+		handled.Add(1)
+		return nil
+	}))
+
+	require.NoError(t, q.Publish(context.Background(), TopicPostPublished, map[string]int{"post_id": 7}))
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(letters) == 1
+	}, time.Second, time.Millisecond)
+
+	mu.Lock()
+	letter := letters[0]
+	mu.Unlock()
+	assert.Equal(t, "search", letter.Group)
+	assert.Equal(t, TopicPostPublished, letter.Topic)
+	assert.Equal(t, 2, letter.Attempts)
+	assert.Equal(t, "index unavailable", letter.LastError)
+	assert.JSONEq(t, `{"post_id":7}`, string(letter.Payload))
+
+	// Replaying after the cause is fixed delivers it to the group again
+	failing.Store(false)
+	require.NoError(t, q.Replay(context.Background(), letter))
+	require.NoError(t, q.Close())
+	assert.Equal(t, int32(1), handled.Load())
+
+	err := NewMemoryQueue(MemoryConfig{}).Replay(context.Background(), letter)
+	assert.Error(t, err, "replaying to a group nobody subscribed should fail")
+}
+
+func TestMemoryQueue_BoundedBuffer(t *testing.T) {
+	q := NewMemoryQueue(MemoryConfig{BufferSize: 1})
+
+	release := make(chan struct{})
+	require.NoError(t, q.Subscribe("test.slow", func(ctx context.Context, message []byte) error {
+		<-release
+		return nil
+	}))
+
+	// One message is being handled and one fills the buffer
+	require.NoError(t, q.Publish(context.Background(), "test.slow", 1))
+	require.Eventually(t, func() bool {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+		return q.Publish(ctx, "test.slow", 2) == nil
+	}, time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := q.Publish(ctx, "test.slow", 3)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(release)
+	require.NoError(t, q.Close())
+}
+
+func TestMemoryQueue_Close(t *testing.T) {
+	t.Run("rejects publish and subscribe after close", func(t *testing.T) {
+		q := NewMemoryQueue(MemoryConfig{})
+		require.NoError(t, q.Close())
+
+		assert.ErrorIs(t, q.Publish(context.Background(), "test.closed", 1), ErrQueueClosed)
+		assert.ErrorIs(t, q.Subscribe("test.closed", func(ctx context.Context, message []byte) error { return nil }), ErrQueueClosed)
+		assert.NoError(t, q.Close())
+	})
+
+	t.Run("cancels handlers after the drain timeout", func(t *testing.T) {
+		q := NewMemoryQueue(MemoryConfig{DrainTimeout: 20 * time.Millisecond})
+
+		cancelled := make(chan struct{})
+		require.NoError(t, q.Subscribe("test.stuck", func(ctx context.Context, message []byte) error {
+			<-ctx.Done()
+			close(cancelled)
+			return ctx.Err()
+		}))
+
+		require.NoError(t, q.Publish(context.Background(), "test.stuck", 1))
+		assert.ErrorIs(t, q.Close(), ErrDrainTimeout)
+
+		select {
+		case <-cancelled:
+		case <-time.After(time.Second):
+			t.Fatal("handler context was not cancelled")
+		}
+	})
+}
+
+func TestMemoryQueue_Drain(t *testing.T) {
+	t.Run("handles buffered messages of the drained group and stops it", func(t *testing.T) {
+		q := NewMemoryQueue(MemoryConfig{})
+		defer q.Close()
+
+		release := make(chan struct{})
+		var drained, other atomic.Int32
+		require.NoError(t, q.SubscribeGroup("drained", "test.drain", func(ctx context.Context, message []byte) error {
+			<-release
+			drained.Add(1)
+			return nil
+		}))
+		require.NoError(t, q.SubscribeGroup("other", "test.drain", func(ctx context.Context, message []byte) error {
+			other.Add(1)
+			return nil
+		}))
+
+		for i := 0; i < 3; i++ {
+			require.NoError(t, q.Publish(context.Background(), "test.drain", i))
+		}
+		lag, err := q.Lag("drained", "test.drain")
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, lag, 2)
+
+		done := make(chan error, 1)
+		go func() { done <- q.Drain(context.Background(), "drained") }()
+		close(release)
+		require.NoError(t, <-done)
+		assert.Equal(t, int32(3), drained.Load())
+
+		// The drained group gets nothing more; the other group keeps consuming
+		require.NoError(t, q.Publish(context.Background(), "test.drain", 3))
+		require.Eventually(t, func() bool { return other.Load() == 4 }, time.Second, 10*time.Millisecond)
+		assert.Equal(t, int32(3), drained.Load())
+
+		_, err = q.Lag("drained", "test.drain")
+		assert.Error(t, err)
+		for _, consumer := range q.Health().Consumers {
+			if consumer.Group == "drained" {
+				assert.Equal(t, 0, consumer.Expected)
+			}
+		}
+	})
+
+	t.Run("times out while a handler is running", func(t *testing.T) {
+		q := NewMemoryQueue(MemoryConfig{})
+		release := make(chan struct{})
+		handling := make(chan struct{})
+		require.NoError(t, q.SubscribeGroup("stuck", "test.drain", func(ctx context.Context, message []byte) error {
+			close(handling)
+			<-release
+			return nil
+		}))
+		require.NoError(t, q.Publish(context.Background(), "test.drain", 1))
+		<-handling
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, q.Drain(ctx), ErrDrainTimeout)
+
+		close(release)
+		assert.NoError(t, q.Close())
+	})
+}
+
+func TestMemoryQueue_SubscribeGroupConcurrency(t *testing.T) {
+	q := NewMemoryQueue(MemoryConfig{Concurrency: 1})
+	defer q.Close()
+
+	require.NoError(t, q.SubscribeGroupConcurrency("wide", "test.concurrency", 3, func(ctx context.Context, message []byte) error { return nil }))
+	require.Eventually(t, func() bool {
+		health := q.Health()
+		return len(health.Consumers) == 1 && health.Consumers[0].Active == 3
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 3, q.Health().Consumers[0].Expected)
+}
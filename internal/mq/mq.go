@@ -3,10 +3,14 @@ package mq
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/google/uuid"
 	amqp "github.com/rabbitmq/amqp091-go"
 	"go.uber.org/zap"
 )
@@ -14,30 +18,91 @@ import (
 // MessageQueue defines the interface for message queue operations
 type MessageQueue interface {
 	Publish(ctx context.Context, topic string, message interface{}) error
+	// Subscribe delivers every message on the topic to this handler, on every replica
 	Subscribe(topic string, handler MessageHandler) error
+	// SubscribeGroup shares the topic's messages between the handlers of a consumer group,
+	// across replicas, through a durable queue that keeps them while no handler is running
+	SubscribeGroup(group, topic string, handler MessageHandler) error
 	Close() error
 }
 
+// ConcurrentGroupSubscriber is implemented by message queues that let each consumer group
+// subscription choose its number of consumers instead of the queue-wide default
+type ConcurrentGroupSubscriber interface {
+	SubscribeGroupConcurrency(group, topic string, concurrency int, handler MessageHandler) error
+}
+
+// Drainer is implemented by message queues that can stop consuming before they close.
+// Drain stops the consumers of the given groups, or of every subscription when none are
+// given, and waits until the messages already delivered to them are handled, or until
+// ctx is done, returning ErrDrainTimeout. Drained subscriptions receive nothing more.
+type Drainer interface {
+	Drain(ctx context.Context, groups ...string) error
+}
+
+// drains reports whether Drain with the given groups stops a subscription of group
+func drains(groups []string, group string) bool {
+	if len(groups) == 0 {
+		return true
+	}
+	for _, g := range groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// QueueName returns the name of a consumer group's queue for a topic, e.g. airy.search.post.published
+func QueueName(prefix, group, topic string) string {
+	return fmt.Sprintf("%s.%s.%s", prefix, group, topic)
+}
+
 // MessageHandler is a function that processes messages
 type MessageHandler func(ctx context.Context, message []byte) error
 
 // RabbitMQ implements MessageQueue interface using RabbitMQ
 type RabbitMQ struct {
-	conn         *amqp.Connection
-	channel      *amqp.Channel
-	logger       *zap.Logger
-	url          string
-	exchangeName string
-	mu           sync.RWMutex
-	closed       bool
-	reconnecting bool
+	conn               *amqp.Connection
+	channel            *amqp.Channel
+	logger             *zap.Logger
+	url                string
+	exchangeName       string
+	queuePrefix        string
+	prefetch           int
+	concurrency        int
+	retryPolicy        RetryPolicy
+	confirmTimeout     time.Duration
+	spool              *Spool // nil when failed publishes are not spooled
+	spoolMaxAttempts   int
+	spoolInterval      time.Duration
+	unconsumedTopics   map[string]bool // Published without mandatory, so they are never returned
+	done               chan struct{}   // Closed by Close; stops the spool replay
+	reconnectBaseDelay time.Duration
+	reconnectMaxDelay  time.Duration
+	consumers          []*rabbitConsumer // Subscriptions to restore after a reconnect
+	reconnects         atomic.Int64
+	mu                 sync.RWMutex
+	closed             bool
+	reconnecting       bool
 }
 
 // Config holds RabbitMQ configuration
 type Config struct {
-	URL          string
-	ExchangeName string
-	Logger       *zap.Logger
+	URL                string
+	ExchangeName       string
+	QueuePrefix        string        // Prefix of consumer group queue names (default "airy")
+	Prefetch           int           // Unacknowledged messages each group consumer may hold (default 10)
+	Concurrency        int           // Consumers per consumer group queue (default 1)
+	Retry              RetryPolicy   // Retries of failed messages before they are dead-lettered
+	ConfirmTimeout     time.Duration // How long Publish waits for the broker to confirm a message (default 5s)
+	SpoolDir           string        // Directory spooling messages that could not be published; empty disables spooling
+	SpoolMaxAttempts   int           // Times a message may be spooled before it is dropped (default 5)
+	SpoolInterval      time.Duration // How often spooled messages are published again (default 5s)
+	UnconsumedTopics   []string      // Topics no consumer group subscribes to, never spooled as unroutable (default UnconsumedTopics)
+	ReconnectBaseDelay time.Duration // Delay before the first reconnect attempt (default 1s)
+	ReconnectMaxDelay  time.Duration // Cap on the delay between reconnect attempts (default 30s)
+	Logger             *zap.Logger
 }
 
 // NewRabbitMQ creates a new RabbitMQ instance
@@ -50,16 +115,84 @@ func NewRabbitMQ(config *Config) (*RabbitMQ, error) {
 		config.ExchangeName = "airy.events"
 	}
 
+	if config.QueuePrefix == "" {
+		config.QueuePrefix = "airy"
+	}
+
+	if config.Prefetch <= 0 {
+		config.Prefetch = 10
+	}
+
+	if config.Concurrency <= 0 {
+		config.Concurrency = 1
+	}
+
+	if config.ConfirmTimeout <= 0 {
+		config.ConfirmTimeout = 5 * time.Second
+	}
+
+	if config.SpoolMaxAttempts <= 0 {
+		config.SpoolMaxAttempts = 5
+	}
+
+	if config.SpoolInterval <= 0 {
+		config.SpoolInterval = 5 * time.Second
+	}
+
+	if config.UnconsumedTopics == nil {
+		config.UnconsumedTopics = UnconsumedTopics
+	}
+
+	if config.ReconnectBaseDelay <= 0 {
+		config.ReconnectBaseDelay = time.Second
+	}
+
+	if config.ReconnectMaxDelay <= 0 {
+		config.ReconnectMaxDelay = 30 * time.Second
+	}
+
+	if config.ReconnectMaxDelay < config.ReconnectBaseDelay {
+		config.ReconnectMaxDelay = config.ReconnectBaseDelay
+	}
+
 	mq := &RabbitMQ{
-		url:          config.URL,
-		exchangeName: config.ExchangeName,
-		logger:       config.Logger,
+		url:                config.URL,
+		exchangeName:       config.ExchangeName,
+		queuePrefix:        config.QueuePrefix,
+		prefetch:           config.Prefetch,
+		concurrency:        config.Concurrency,
+		retryPolicy:        config.Retry.withDefaults(),
+		confirmTimeout:     config.ConfirmTimeout,
+		spoolMaxAttempts:   config.SpoolMaxAttempts,
+		spoolInterval:      config.SpoolInterval,
+		unconsumedTopics:   make(map[string]bool, len(config.UnconsumedTopics)),
+		done:               make(chan struct{}),
+		reconnectBaseDelay: config.ReconnectBaseDelay,
+		reconnectMaxDelay:  config.ReconnectMaxDelay,
+		logger:             config.Logger,
+	}
+
+	for _, topic := range config.UnconsumedTopics {
+		mq.unconsumedTopics[topic] = true
+	}
+
+	if config.SpoolDir != "" {
+		spool, err := NewSpool(config.SpoolDir)
+		if err != nil {
+			return nil, err
+		}
+		mq.spool = spool
 	}
 
 	if err := mq.connect(); err != nil {
 		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
 	}
 
+	if mq.spool != nil {
+		// Also replays what an earlier run left in the spool
+		go mq.replaySpool()
+	}
+
 	return mq, nil
 }
 
@@ -69,7 +202,7 @@ func (mq *RabbitMQ) connect() error {
 	defer mq.mu.Unlock()
 
 	if mq.closed {
-		return fmt.Errorf("message queue is closed")
+		return ErrQueueClosed
 	}
 
 	conn, err := amqp.Dial(mq.url)
@@ -99,6 +232,21 @@ func (mq *RabbitMQ) connect() error {
 		return fmt.Errorf("failed to declare exchange: %w", err)
 	}
 
+	// Dead letters go through their own exchange to one durable queue
+	if err := mq.declareDeadLetterQueue(channel); err != nil {
+		channel.Close()
+		conn.Close()
+		return err
+	}
+
+	// The broker confirms every message published on the channel
+	if err := channel.Confirm(false); err != nil {
+		channel.Close()
+		conn.Close()
+		return fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+	go mq.handleReturns(channel.NotifyReturn(make(chan amqp.Return, 64)))
+
 	mq.conn = conn
 	mq.channel = channel
 
@@ -106,14 +254,15 @@ func (mq *RabbitMQ) connect() error {
 		zap.String("exchange", mq.exchangeName))
 
 	// Set up connection close notification
-	go mq.handleConnectionClose()
+	go mq.handleConnectionClose(conn)
 
 	return nil
 }
 
-// handleConnectionClose handles connection close events and attempts reconnection
-func (mq *RabbitMQ) handleConnectionClose() {
-	closeErr := <-mq.conn.NotifyClose(make(chan *amqp.Error))
+// handleConnectionClose waits for the connection to close, then reconnects with jittered
+// exponential backoff until it succeeds or the queue is closed, and restores the subscriptions
+func (mq *RabbitMQ) handleConnectionClose(conn *amqp.Connection) {
+	closeErr := <-conn.NotifyClose(make(chan *amqp.Error, 1))
 	if closeErr != nil {
 		mq.logger.Error("RabbitMQ connection closed", zap.Error(closeErr))
 	}
@@ -126,153 +275,627 @@ func (mq *RabbitMQ) handleConnectionClose() {
 	mq.reconnecting = true
 	mq.mu.Unlock()
 
-	// Attempt to reconnect
-	for i := 0; i < 10; i++ {
+	for attempt := 1; ; attempt++ {
+		delay := mq.reconnectDelay(attempt)
 		mq.logger.Info("attempting to reconnect to RabbitMQ",
-			zap.Int("attempt", i+1))
+			zap.Int("attempt", attempt),
+			zap.Duration("delay", delay))
 
-		time.Sleep(time.Duration(i+1) * time.Second)
+		time.Sleep(delay)
 
 		if err := mq.connect(); err != nil {
+			if errors.Is(err, ErrQueueClosed) {
+				return
+			}
 			mq.logger.Error("failed to reconnect", zap.Error(err))
 			continue
 		}
+		break
+	}
+
+	mq.mu.Lock()
+	mq.reconnecting = false
+	consumers := append([]*rabbitConsumer(nil), mq.consumers...)
+	mq.mu.Unlock()
+	mq.reconnects.Add(1)
+
+	mq.logger.Info("successfully reconnected to RabbitMQ",
+		zap.Int("subscriptions", len(consumers)))
+
+	for _, c := range consumers {
+		go mq.resubscribe(c)
+	}
+}
+
+// reconnectDelay returns the delay before a reconnect attempt: exponential from the base
+// delay up to the maximum, with jitter so that replicas don't reconnect in lockstep
+func (mq *RabbitMQ) reconnectDelay(attempt int) time.Duration {
+	delay := mq.reconnectBaseDelay
+	for i := 1; i < attempt && delay < mq.reconnectMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > mq.reconnectMaxDelay {
+		delay = mq.reconnectMaxDelay
+	}
+	// Anywhere between half and the full delay
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
 
-		mq.mu.Lock()
-		mq.reconnecting = false
+// Subscribe subscribes to messages on the specified topic
+func (mq *RabbitMQ) Subscribe(topic string, handler MessageHandler) error {
+	return mq.register(&rabbitConsumer{topic: topic, handler: handler, consumers: 1})
+}
+
+// SubscribeGroup consumes the topic through the consumer group's durable queue.
+// Every replica subscribing with the same group shares the queue, so each message is
+// handled once per group; the queue keeps messages published while no replica runs.
+func (mq *RabbitMQ) SubscribeGroup(group, topic string, handler MessageHandler) error {
+	return mq.SubscribeGroupConcurrency(group, topic, mq.concurrency, handler)
+}
+
+// SubscribeGroupConcurrency is SubscribeGroup with its own number of consumers on the
+// group queue; a concurrency of 0 uses the queue's default
+func (mq *RabbitMQ) SubscribeGroupConcurrency(group, topic string, concurrency int, handler MessageHandler) error {
+	if concurrency <= 0 {
+		concurrency = mq.concurrency
+	}
+	return mq.register(&rabbitConsumer{group: group, topic: topic, handler: handler, consumers: concurrency})
+}
+
+// rabbitConsumer is a subscription the queue remembers, so that it can be restored on
+// a new channel after a reconnect
+type rabbitConsumer struct {
+	group       string            // empty for Subscribe
+	topic       string            // empty for SubscribeDeadLetters
+	handler     MessageHandler    // nil for SubscribeDeadLetters
+	deadLetters DeadLetterHandler // set for SubscribeDeadLetters
+	consumers   int               // Consumers on the queue
+
+	active       atomic.Int32 // Consumers currently receiving
+	lastDelivery atomic.Int64 // Unix nanoseconds of the last delivery
+
+	mu      sync.Mutex // Guards channel, tags, queue and stopped while (re)starting
+	channel *amqp.Channel
+	tags    []string // Consumer tags on channel, to cancel them on Drain
+	queue   string
+	stopped bool // Drained; never started again
+}
+
+// register remembers a subscription and starts consuming it
+func (mq *RabbitMQ) register(c *rabbitConsumer) error {
+	mq.mu.Lock()
+	if mq.closed {
 		mq.mu.Unlock()
+		return ErrQueueClosed
+	}
+	mq.consumers = append(mq.consumers, c)
+	mq.mu.Unlock()
 
-		mq.logger.Info("successfully reconnected to RabbitMQ")
-		return
+	if err := mq.start(c); err != nil {
+		mq.unregister(c)
+		return err
 	}
+	return nil
+}
 
-	mq.logger.Error("failed to reconnect to RabbitMQ after multiple attempts")
+// unregister forgets a subscription that could not be started
+func (mq *RabbitMQ) unregister(c *rabbitConsumer) {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	for i, registered := range mq.consumers {
+		if registered == c {
+			mq.consumers = append(mq.consumers[:i], mq.consumers[i+1:]...)
+			return
+		}
+	}
 }
 
-// Publish publishes a message to the specified topic
-func (mq *RabbitMQ) Publish(ctx context.Context, topic string, message interface{}) error {
+// start opens a channel of its own for the subscription, so the prefetch applies to it
+// only and a failing subscription doesn't close the others, binds its queue and starts
+// its consumers. A channel left from an earlier start is closed first.
+func (mq *RabbitMQ) start(c *rabbitConsumer) error {
 	mq.mu.RLock()
 	if mq.closed {
 		mq.mu.RUnlock()
-		return fmt.Errorf("message queue is closed")
+		return ErrQueueClosed
 	}
 	if mq.reconnecting {
 		mq.mu.RUnlock()
 		return fmt.Errorf("message queue is reconnecting")
 	}
-	channel := mq.channel
+	conn := mq.conn
 	mq.mu.RUnlock()
 
-	// Marshal message to JSON
-	body, err := json.Marshal(message)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopped {
+		return nil
+	}
+
+	if c.channel != nil {
+		c.channel.Close()
+		c.channel = nil
+	}
+
+	channel, err := conn.Channel()
 	if err != nil {
-		return fmt.Errorf("failed to marshal message: %w", err)
+		return fmt.Errorf("failed to open channel: %w", err)
 	}
+	closed := channel.NotifyClose(make(chan *amqp.Error, 1))
 
-	// Publish message
-	err = channel.PublishWithContext(
-		ctx,
-		mq.exchangeName, // exchange
-		topic,           // routing key
-		false,           // mandatory
-		false,           // immediate
-		amqp.Publishing{
-			ContentType:  "application/json",
-			Body:         body,
-			DeliveryMode: amqp.Persistent,
-			Timestamp:    time.Now(),
-		},
-	)
+	if err := channel.Qos(mq.prefetch, 0, false); err != nil {
+		channel.Close()
+		return fmt.Errorf("failed to set prefetch: %w", err)
+	}
+
+	sub, err := mq.bind(channel, c)
 	if err != nil {
-		return fmt.Errorf("failed to publish message: %w", err)
+		channel.Close()
+		return err
 	}
 
-	mq.logger.Debug("published message",
-		zap.String("topic", topic),
-		zap.Int("size", len(body)))
+	// Several consumers on one queue; RabbitMQ hands each message to one of them
+	tags := make([]string, 0, c.consumers)
+	for i := 0; i < c.consumers; i++ {
+		tag := uuid.New().String()
+		msgs, err := channel.Consume(
+			sub.queue, // queue
+			tag,       // consumer
+			false,     // auto-ack
+			false,     // exclusive
+			false,     // no-local
+			false,     // no-wait
+			nil,       // args
+		)
+		if err != nil {
+			channel.Close()
+			return fmt.Errorf("failed to register consumer on %s: %w", sub.queue, err)
+		}
+		c.active.Add(1)
+		tags = append(tags, tag)
+		go mq.consume(c, sub, msgs)
+	}
+
+	c.channel = channel
+	c.tags = tags
+	c.queue = sub.queue
+	go mq.watch(c, conn, closed)
+
+	mq.logger.Info("subscribed to topic",
+		zap.String("group", c.group),
+		zap.String("topic", c.topic),
+		zap.String("queue", sub.queue),
+		zap.Int("prefetch", mq.prefetch),
+		zap.Int("consumers", c.consumers))
 
 	return nil
 }
 
-// Subscribe subscribes to messages on the specified topic
-func (mq *RabbitMQ) Subscribe(topic string, handler MessageHandler) error {
-	mq.mu.RLock()
-	if mq.closed {
-		mq.mu.RUnlock()
-		return fmt.Errorf("message queue is closed")
+// bind declares the queue a subscription reads from and binds it to the exchange
+func (mq *RabbitMQ) bind(channel *amqp.Channel, c *rabbitConsumer) (rabbitSubscription, error) {
+	if c.deadLetters != nil {
+		// Declared on connect
+		return rabbitSubscription{queue: mq.deadLetterQueue()}, nil
+	}
+
+	if c.group == "" {
+		// An exclusive queue of this connection, gone with it
+		queue, err := channel.QueueDeclare(
+			"",    // name (empty for auto-generated)
+			false, // durable
+			true,  // delete when unused
+			true,  // exclusive
+			false, // no-wait
+			nil,   // arguments
+		)
+		if err != nil {
+			return rabbitSubscription{}, fmt.Errorf("failed to declare queue: %w", err)
+		}
+
+		// Bind queue to exchange with routing key
+		err = channel.QueueBind(
+			queue.Name,      // queue name
+			c.topic,         // routing key
+			mq.exchangeName, // exchange
+			false,           // no-wait
+			nil,             // arguments
+		)
+		if err != nil {
+			return rabbitSubscription{}, fmt.Errorf("failed to bind queue: %w", err)
+		}
+		return rabbitSubscription{queue: queue.Name, topic: c.topic}, nil
 	}
-	channel := mq.channel
-	mq.mu.RUnlock()
 
-	// Declare queue
-	queue, err := channel.QueueDeclare(
-		"",    // name (empty for auto-generated)
-		false, // durable
-		true,  // delete when unused
-		true,  // exclusive
-		false, // no-wait
-		nil,   // arguments
+	queueName := QueueName(mq.queuePrefix, c.group, c.topic)
+	_, err := channel.QueueDeclare(
+		queueName, // name
+		true,      // durable
+		false,     // delete when unused
+		false,     // exclusive
+		false,     // no-wait
+		nil,       // arguments
 	)
 	if err != nil {
-		return fmt.Errorf("failed to declare queue: %w", err)
+		return rabbitSubscription{}, fmt.Errorf("failed to declare queue %s: %w", queueName, err)
 	}
 
-	// Bind queue to exchange with routing key
 	err = channel.QueueBind(
-		queue.Name,      // queue name
-		topic,           // routing key
+		queueName,       // queue name
+		c.topic,         // routing key
 		mq.exchangeName, // exchange
 		false,           // no-wait
 		nil,             // arguments
 	)
 	if err != nil {
-		return fmt.Errorf("failed to bind queue: %w", err)
-	}
-
-	// Start consuming messages
-	msgs, err := channel.Consume(
-		queue.Name, // queue
-		"",         // consumer
-		false,      // auto-ack
-		false,      // exclusive
-		false,      // no-local
-		false,      // no-wait
-		nil,        // args
+		return rabbitSubscription{}, fmt.Errorf("failed to bind queue %s: %w", queueName, err)
+	}
+
+	retryQueues, err := mq.declareRetryQueues(channel, queueName)
+	if err != nil {
+		return rabbitSubscription{}, err
+	}
+	return rabbitSubscription{queue: queueName, group: c.group, topic: c.topic, retryQueues: retryQueues}, nil
+}
+
+// watch restarts a subscription whose channel the broker closed while the connection
+// stayed up, e.g. after a channel error. Connection losses are left to handleConnectionClose.
+func (mq *RabbitMQ) watch(c *rabbitConsumer, conn *amqp.Connection, closed <-chan *amqp.Error) {
+	closeErr := <-closed
+	if closeErr == nil || conn.IsClosed() {
+		// Closed by us, or the whole connection is going
+		return
+	}
+
+	mq.logger.Error("RabbitMQ channel closed",
+		zap.String("queue", c.queue),
+		zap.Error(closeErr))
+	mq.resubscribe(c)
+}
+
+// resubscribe restarts a subscription, backing off between attempts until it succeeds,
+// the queue is closed or the connection is lost again, which restarts it once more
+func (mq *RabbitMQ) resubscribe(c *rabbitConsumer) {
+	for attempt := 1; ; attempt++ {
+		err := mq.start(c)
+		if err == nil {
+			return
+		}
+
+		mq.mu.RLock()
+		stop := mq.closed || mq.reconnecting
+		mq.mu.RUnlock()
+		if stop {
+			return
+		}
+
+		delay := mq.reconnectDelay(attempt)
+		mq.logger.Error("failed to restore subscription",
+			zap.String("group", c.group),
+			zap.String("topic", c.topic),
+			zap.Int("attempt", attempt),
+			zap.Duration("retry_in", delay),
+			zap.Error(err))
+		time.Sleep(delay)
+	}
+}
+
+// declareRetryQueues declares the delay queues of a consumer group queue, one per retry.
+// A message waits out the queue's TTL there and is then dead-lettered back to the group
+// queue through the default exchange. The delay is part of the name because RabbitMQ
+// refuses to redeclare a queue with a different TTL.
+func (mq *RabbitMQ) declareRetryQueues(channel *amqp.Channel, queueName string) ([]string, error) {
+	retryQueues := make([]string, 0, mq.retryPolicy.MaxAttempts-1)
+	for failed := 1; failed < mq.retryPolicy.MaxAttempts; failed++ {
+		delay := mq.retryPolicy.Delay(failed)
+		retryQueue := fmt.Sprintf("%s.retry.%dms", queueName, delay.Milliseconds())
+		_, err := channel.QueueDeclare(
+			retryQueue, // name
+			true,       // durable
+			false,      // delete when unused
+			false,      // exclusive
+			false,      // no-wait
+			amqp.Table{
+				"x-message-ttl":             delay.Milliseconds(),
+				"x-dead-letter-exchange":    "",
+				"x-dead-letter-routing-key": queueName,
+			},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to declare retry queue %s: %w", retryQueue, err)
+		}
+		retryQueues = append(retryQueues, retryQueue)
+	}
+	return retryQueues, nil
+}
+
+// declareDeadLetterQueue declares the dead-letter exchange and the queue collecting its messages
+func (mq *RabbitMQ) declareDeadLetterQueue(channel *amqp.Channel) error {
+	err := channel.ExchangeDeclare(
+		mq.deadLetterExchange(), // name
+		"topic",                 // type
+		true,                    // durable
+		false,                   // auto-deleted
+		false,                   // internal
+		false,                   // no-wait
+		nil,                     // arguments
+	)
+	if err != nil {
+		return fmt.Errorf("failed to declare dead-letter exchange: %w", err)
+	}
+
+	_, err = channel.QueueDeclare(
+		mq.deadLetterQueue(), // name
+		true,                 // durable
+		false,                // delete when unused
+		false,                // exclusive
+		false,                // no-wait
+		nil,                  // arguments
 	)
 	if err != nil {
-		return fmt.Errorf("failed to register consumer: %w", err)
+		return fmt.Errorf("failed to declare dead-letter queue: %w", err)
 	}
 
-	mq.logger.Info("subscribed to topic",
-		zap.String("topic", topic),
-		zap.String("queue", queue.Name))
-
-	// Process messages in a goroutine
-	go func() {
-		for msg := range msgs {
-			ctx := context.Background()
-
-			mq.logger.Debug("received message",
-				zap.String("topic", topic),
-				zap.Int("size", len(msg.Body)))
-
-			// Call handler
-			if err := handler(ctx, msg.Body); err != nil {
-				mq.logger.Error("failed to handle message",
-					zap.String("topic", topic),
-					zap.Error(err))
-				// Reject message and requeue
-				msg.Nack(false, true)
-			} else {
-				// Acknowledge message
-				msg.Ack(false)
+	err = channel.QueueBind(
+		mq.deadLetterQueue(),    // queue name
+		"#",                     // routing key
+		mq.deadLetterExchange(), // exchange
+		false,                   // no-wait
+		nil,                     // arguments
+	)
+	if err != nil {
+		return fmt.Errorf("failed to bind dead-letter queue: %w", err)
+	}
+	return nil
+}
+
+// deadLetterExchange returns the name of the exchange dead letters are published to
+func (mq *RabbitMQ) deadLetterExchange() string {
+	return mq.exchangeName + ".dlx"
+}
+
+// deadLetterQueue returns the name of the queue holding dead letters
+func (mq *RabbitMQ) deadLetterQueue() string {
+	return mq.queuePrefix + ".dead-letter"
+}
+
+// rabbitSubscription describes the queue a consumer reads from
+type rabbitSubscription struct {
+	queue       string
+	group       string // empty for Subscribe
+	topic       string
+	retryQueues []string // delay queue per failed attempt; nil retries in the consumer
+}
+
+// consume hands deliveries to the subscription's handler, acknowledging them once handled,
+// retried or dead-lettered. It returns when the channel closes.
+func (mq *RabbitMQ) consume(c *rabbitConsumer, sub rabbitSubscription, msgs <-chan amqp.Delivery) {
+	defer c.active.Add(-1)
+
+	for msg := range msgs {
+		c.lastDelivery.Store(time.Now().UnixNano())
+
+		if c.deadLetters != nil {
+			mq.consumeDeadLetter(msg, c.deadLetters)
+			continue
+		}
+
+		ctx := context.Background()
+
+		mq.logger.Debug("received message",
+			zap.String("topic", sub.topic),
+			zap.Int("size", len(msg.Body)))
+
+		// Call handler
+		err := c.handler(ctx, msg.Body)
+		if err == nil {
+			msg.Ack(false)
+			continue
+		}
+
+		mq.logger.Error("failed to handle message",
+			zap.String("topic", sub.topic),
+			zap.String("queue", sub.queue),
+			zap.Error(err))
+
+		if err := mq.retry(ctx, sub, msg, c.handler, err); err != nil {
+			// Neither retried nor dead-lettered; back off, then let RabbitMQ redeliver it
+			mq.logger.Error("failed to schedule retry",
+				zap.String("topic", sub.topic),
+				zap.String("queue", sub.queue),
+				zap.Error(err))
+			time.Sleep(mq.retryPolicy.BaseDelay)
+			msg.Nack(false, true)
+			continue
+		}
+		msg.Ack(false)
+	}
+}
+
+// retry schedules the next attempt of a failed message, or dead-letters it once the retry
+// policy is exhausted. The attempts made so far travel in the x-retry-count header.
+func (mq *RabbitMQ) retry(ctx context.Context, sub rabbitSubscription, msg amqp.Delivery, handler MessageHandler, handleErr error) error {
+	failed := retryCount(msg.Headers) + 1
+
+	if sub.retryQueues == nil {
+		// Exclusive queues are gone after a restart, so they have no delay queues;
+		// retry in the consumer instead
+		for ; failed < mq.retryPolicy.MaxAttempts; failed++ {
+			time.Sleep(mq.retryPolicy.Delay(failed))
+			if handleErr = handler(ctx, msg.Body); handleErr == nil {
+				return nil
 			}
+			mq.logger.Error("failed to handle message",
+				zap.String("topic", sub.topic),
+				zap.Int("attempt", failed+1),
+				zap.Error(handleErr))
 		}
-	}()
+		return mq.deadLetter(ctx, newDeadLetter(sub.queue, sub.group, sub.topic, msg.Body, failed, handleErr))
+	}
+
+	if failed < mq.retryPolicy.MaxAttempts {
+		return mq.publishRaw(ctx, "", sub.retryQueues[failed-1], msg.Body, amqp.Table{
+			HeaderRetryCount: int32(failed),
+			HeaderLastError:  handleErr.Error(),
+		})
+	}
+	return mq.deadLetter(ctx, newDeadLetter(sub.queue, sub.group, sub.topic, msg.Body, failed, handleErr))
+}
+
+// deadLetter publishes a dead letter to the dead-letter exchange
+func (mq *RabbitMQ) deadLetter(ctx context.Context, letter *DeadLetter) error {
+	body, err := json.Marshal(letter)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead letter: %w", err)
+	}
+	if err := mq.publishRaw(ctx, mq.deadLetterExchange(), letter.Topic, body, nil); err != nil {
+		return err
+	}
 
+	mq.logger.Warn("dead-lettered message",
+		zap.String("id", letter.ID),
+		zap.String("topic", letter.Topic),
+		zap.String("queue", letter.Queue),
+		zap.Int("attempts", letter.Attempts),
+		zap.String("last_error", letter.LastError))
 	return nil
 }
 
+// retryCount reads the number of failed attempts from the message headers
+func retryCount(headers amqp.Table) int {
+	switch count := headers[HeaderRetryCount].(type) {
+	case int32:
+		return int(count)
+	case int64:
+		return int(count)
+	case int:
+		return count
+	case float64:
+		// Headers of spooled messages went through JSON
+		return int(count)
+	default:
+		return 0
+	}
+}
+
+// SubscribeDeadLetters consumes the dead-letter queue, shared by every replica
+func (mq *RabbitMQ) SubscribeDeadLetters(handler DeadLetterHandler) error {
+	return mq.register(&rabbitConsumer{deadLetters: handler, consumers: 1})
+}
+
+// consumeDeadLetter hands a dead letter to the handler, redelivering it if the handler fails
+func (mq *RabbitMQ) consumeDeadLetter(msg amqp.Delivery, handler DeadLetterHandler) {
+	var letter DeadLetter
+	if err := json.Unmarshal(msg.Body, &letter); err != nil {
+		mq.logger.Error("discarding malformed dead letter", zap.Error(err))
+		msg.Ack(false)
+		return
+	}
+
+	if err := handler(context.Background(), &letter); err != nil {
+		mq.logger.Error("failed to handle dead letter",
+			zap.String("id", letter.ID),
+			zap.Error(err))
+		time.Sleep(mq.retryPolicy.BaseDelay)
+		msg.Nack(false, true)
+		return
+	}
+	msg.Ack(false)
+}
+
+// Replay publishes a dead-lettered message again. Consumer group messages go straight to
+// the group's queue; messages from Subscribe go to the topic, as their queue is gone.
+func (mq *RabbitMQ) Replay(ctx context.Context, letter *DeadLetter) error {
+	if letter.Group != "" {
+		return mq.publishRaw(ctx, "", letter.Queue, letter.Payload, nil)
+	}
+	return mq.publishRaw(ctx, mq.exchangeName, letter.Topic, letter.Payload, nil)
+}
+
+// Drain cancels the consumers of the given consumer groups, or of every subscription when
+// none are given, and waits until they have handled the messages the broker already
+// delivered to them. Drained subscriptions are not restored after a reconnect.
+func (mq *RabbitMQ) Drain(ctx context.Context, groups ...string) error {
+	mq.mu.RLock()
+	consumers := make([]*rabbitConsumer, 0, len(mq.consumers))
+	for _, c := range mq.consumers {
+		if drains(groups, c.group) {
+			consumers = append(consumers, c)
+		}
+	}
+	mq.mu.RUnlock()
+
+	for _, c := range consumers {
+		c.mu.Lock()
+		c.stopped = true
+		if c.channel != nil {
+			for _, tag := range c.tags {
+				// The delivery channel closes once the deliveries it buffered are taken
+				if err := c.channel.Cancel(tag, false); err != nil {
+					mq.logger.Warn("failed to cancel consumer",
+						zap.String("queue", c.queue),
+						zap.Error(err))
+				}
+			}
+		}
+		c.mu.Unlock()
+	}
+
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		busy := 0
+		for _, c := range consumers {
+			busy += int(c.active.Load())
+		}
+		if busy == 0 {
+			mq.logger.Info("drained RabbitMQ consumers", zap.Strings("groups", groups))
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %d consumers still handling messages", ErrDrainTimeout, busy)
+		case <-ticker.C:
+		}
+	}
+}
+
+// Lag returns the number of messages ready in a consumer group's queue for the topic
+func (mq *RabbitMQ) Lag(group, topic string) (int, error) {
+	mq.mu.RLock()
+	if !mq.connectedLocked() {
+		mq.mu.RUnlock()
+		return 0, fmt.Errorf("message queue is not connected")
+	}
+	conn := mq.conn
+	mq.mu.RUnlock()
+
+	// A failed passive declare closes the channel, so use one of its own
+	channel, err := conn.Channel()
+	if err != nil {
+		return 0, fmt.Errorf("failed to open channel: %w", err)
+	}
+	defer channel.Close()
+
+	queueName := QueueName(mq.queuePrefix, group, topic)
+	queue, err := channel.QueueDeclarePassive(
+		queueName, // name
+		true,      // durable
+		false,     // delete when unused
+		false,     // exclusive
+		false,     // no-wait
+		nil,       // arguments
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to inspect queue %s: %w", queueName, err)
+	}
+	return queue.Messages, nil
+}
+
 // Close closes the RabbitMQ connection
 func (mq *RabbitMQ) Close() error {
 	mq.mu.Lock()
@@ -283,9 +906,20 @@ func (mq *RabbitMQ) Close() error {
 	}
 
 	mq.closed = true
+	close(mq.done)
 
 	var errs []error
 
+	// Subscription channels close with the connection; closing them first stops their consumers cleanly
+	for _, c := range mq.consumers {
+		c.mu.Lock()
+		if c.channel != nil {
+			c.channel.Close()
+			c.channel = nil
+		}
+		c.mu.Unlock()
+	}
+
 	if mq.channel != nil {
 		if err := mq.channel.Close(); err != nil {
 			errs = append(errs, fmt.Errorf("failed to close channel: %w", err))
@@ -305,3 +939,40 @@ func (mq *RabbitMQ) Close() error {
 	mq.logger.Info("closed RabbitMQ connection")
 	return nil
 }
+
+// connectedLocked reports whether the connection is up; the caller holds mq.mu
+func (mq *RabbitMQ) connectedLocked() bool {
+	return !mq.closed && !mq.reconnecting && mq.conn != nil && !mq.conn.IsClosed()
+}
+
+// Health reports the connection and the liveness of every subscription's consumers
+func (mq *RabbitMQ) Health() Health {
+	mq.mu.RLock()
+	health := Health{
+		Connected:  mq.connectedLocked(),
+		Reconnects: mq.reconnects.Load(),
+		Consumers:  make([]ConsumerHealth, 0, len(mq.consumers)),
+	}
+	consumers := append([]*rabbitConsumer(nil), mq.consumers...)
+	mq.mu.RUnlock()
+
+	for _, c := range consumers {
+		c.mu.Lock()
+		queue := c.queue
+		expected := c.consumers
+		if c.stopped {
+			expected = 0
+		}
+		c.mu.Unlock()
+
+		health.Consumers = append(health.Consumers, ConsumerHealth{
+			Queue:          queue,
+			Group:          c.group,
+			Topic:          c.topic,
+			Active:         int(c.active.Load()),
+			Expected:       expected,
+			LastDeliveryAt: lastDelivery(c.lastDelivery.Load()),
+		})
+	}
+	return health
+}
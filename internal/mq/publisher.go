@@ -107,6 +107,28 @@ func (p *Publisher) PublishCommentVoted(ctx context.Context, commentID, postID,
 	return p.mq.Publish(ctx, TopicCommentVoted, event)
 }
 
+// PublishPostFavorited publishes a post favorited event
+func (p *Publisher) PublishPostFavorited(ctx context.Context, postID, authorID, userID int64) error {
+	event := PostFavoritedEvent{
+		BaseEvent: newBaseEvent(TopicPostFavorited),
+		PostID:    postID,
+		AuthorID:  authorID,
+		UserID:    userID,
+	}
+	return p.mq.Publish(ctx, TopicPostFavorited, event)
+}
+
+// PublishPostUnfavorited publishes a post unfavorited event
+func (p *Publisher) PublishPostUnfavorited(ctx context.Context, postID, authorID, userID int64) error {
+	event := PostUnfavoritedEvent{
+		BaseEvent: newBaseEvent(TopicPostUnfavorited),
+		PostID:    postID,
+		AuthorID:  authorID,
+		UserID:    userID,
+	}
+	return p.mq.Publish(ctx, TopicPostUnfavorited, event)
+}
+
 // PublishUserFollowed publishes a user followed event
 func (p *Publisher) PublishUserFollowed(ctx context.Context, followerID, followingID int64) error {
 	event := UserFollowedEvent{
@@ -0,0 +1,250 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var (
+	// ErrUnsupportedEventVersion is returned for an event newer than the registry knows,
+	// e.g. published by a newer release during a rolling deploy; it is retried later
+	ErrUnsupportedEventVersion = errors.New("unsupported event version")
+	// ErrMissingUpcaster is returned when an old event version cannot be upcast to the current one
+	ErrMissingUpcaster = errors.New("no upcaster for event version")
+	// ErrEventTypeMismatch is returned when an event's Go type is not the one registered for its topic
+	ErrEventTypeMismatch = errors.New("event type does not match its topic")
+)
+
+// Upcaster converts an event payload from one schema version to the next
+type Upcaster func(payload json.RawMessage) (json.RawMessage, error)
+
+// schemaKey identifies the schema of a topic's events at one version
+type schemaKey struct {
+	topic   string
+	version int
+}
+
+// Registry maps each topic and schema version to the Go type of its events, and keeps
+// the upcasters that convert old versions to the current one. The current version of a
+// topic is the highest registered; topics never registered are at version 1.
+type Registry struct {
+	mu        sync.RWMutex
+	types     map[schemaKey]reflect.Type
+	current   map[string]int
+	upcasters map[schemaKey]Upcaster // By the version they upcast from
+}
+
+// NewRegistry creates an empty event registry
+func NewRegistry() *Registry {
+	return &Registry{
+		types:     make(map[schemaKey]reflect.Type),
+		current:   make(map[string]int),
+		upcasters: make(map[schemaKey]Upcaster),
+	}
+}
+
+// Register maps a topic's schema version to the Go type of event, a struct value
+func (r *Registry) Register(topic string, version int, event interface{}) error {
+	if version < 1 {
+		return fmt.Errorf("invalid version %d for %s", version, topic)
+	}
+	eventType := reflect.TypeOf(event)
+	if eventType == nil || eventType.Kind() != reflect.Struct {
+		return fmt.Errorf("event of %s v%d must be a struct, got %T", topic, version, event)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := schemaKey{topic: topic, version: version}
+	if _, ok := r.types[key]; ok {
+		return fmt.Errorf("%s v%d is already registered", topic, version)
+	}
+	r.types[key] = eventType
+	if version > r.current[topic] {
+		r.current[topic] = version
+	}
+	return nil
+}
+
+// RegisterUpcaster registers the conversion of a topic's events from version from to from+1
+func (r *Registry) RegisterUpcaster(topic string, from int, upcaster Upcaster) error {
+	if from < 1 {
+		return fmt.Errorf("invalid version %d for %s", from, topic)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := schemaKey{topic: topic, version: from}
+	if _, ok := r.upcasters[key]; ok {
+		return fmt.Errorf("upcaster of %s v%d is already registered", topic, from)
+	}
+	r.upcasters[key] = upcaster
+	return nil
+}
+
+// Version returns the current schema version of a topic
+func (r *Registry) Version(topic string) int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.version(topic)
+}
+
+// version returns the current schema version of a topic; the caller holds r.mu
+func (r *Registry) version(topic string) int {
+	if version, ok := r.current[topic]; ok {
+		return version
+	}
+	return 1
+}
+
+// Type returns the Go type of a topic's events at a schema version
+func (r *Registry) Type(topic string, version int) (reflect.Type, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	eventType, ok := r.types[schemaKey{topic: topic, version: version}]
+	return eventType, ok
+}
+
+// NewEnvelope wraps an event in an envelope of its topic's current schema version.
+// The event must be of the type registered for that version, when there is one.
+func (r *Registry) NewEnvelope(ctx context.Context, topic, producer string, event interface{}) (*Envelope, error) {
+	version := r.Version(topic)
+	if expected, ok := r.Type(topic, version); ok && reflect.Indirect(reflect.ValueOf(event)).Type() != expected {
+		return nil, fmt.Errorf("%w: %s v%d is %s, got %T", ErrEventTypeMismatch, topic, version, expected, event)
+	}
+	return newEnvelope(ctx, topic, version, producer, event)
+}
+
+// Decode parses a message into its envelope, upcasting the payload to the current
+// schema version of its topic. Bare events published before envelopes are version 1.
+func (r *Registry) Decode(message []byte) (*Envelope, error) {
+	envelope, err := DecodeEnvelope(message)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, version, err := r.Upcast(envelope.Topic, envelope.Version, envelope.Payload)
+	if err != nil {
+		return nil, err
+	}
+	envelope.Payload = payload
+	envelope.Version = version
+	return envelope, nil
+}
+
+// Upcast converts a payload of a topic's schema version to the current version,
+// one version at a time, returning the payload and the version it is now in
+func (r *Registry) Upcast(topic string, version int, payload json.RawMessage) (json.RawMessage, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	current := r.version(topic)
+	if version > current {
+		return nil, version, fmt.Errorf("%w: %s v%d, current is v%d", ErrUnsupportedEventVersion, topic, version, current)
+	}
+
+	for ; version < current; version++ {
+		upcaster, ok := r.upcasters[schemaKey{topic: topic, version: version}]
+		if !ok {
+			return nil, version, fmt.Errorf("%w: %s v%d", ErrMissingUpcaster, topic, version)
+		}
+		upcast, err := upcaster(payload)
+		if err != nil {
+			return nil, version, fmt.Errorf("failed to upcast %s v%d: %w", topic, version, err)
+		}
+		payload = upcast
+	}
+	return payload, version, nil
+}
+
+// EventHandler handles a decoded event
+type EventHandler[T any] func(ctx context.Context, event *T) error
+
+// Handle adapts a typed event handler to a MessageHandler. Each message is decoded with
+// the registry, upcast to the current version of its topic and unmarshalled into T. The
+// handler finds the envelope with EnvelopeFromContext, and the events it publishes share
+// its correlation ID.
+func Handle[T any](registry *Registry, handler EventHandler[T]) MessageHandler {
+	return func(ctx context.Context, message []byte) error {
+		envelope, err := registry.Decode(message)
+		if err != nil {
+			return err
+		}
+
+		var event T
+		if err := json.Unmarshal(envelope.Payload, &event); err != nil {
+			return fmt.Errorf("failed to unmarshal %s event: %w", envelope.Topic, err)
+		}
+		return handler(ContextWithEnvelope(ctx, envelope), &event)
+	}
+}
+
+// SubscribeEvents subscribes a typed handler to every event on the topic, decoded with DefaultRegistry
+func SubscribeEvents[T any](messageQueue MessageQueue, topic string, handler EventHandler[T]) error {
+	if err := checkEventType[T](DefaultRegistry, topic); err != nil {
+		return err
+	}
+	return messageQueue.Subscribe(topic, Handle(DefaultRegistry, handler))
+}
+
+// SubscribeGroupEvents subscribes a typed handler to the topic's events for the consumer
+// group, decoded with DefaultRegistry
+func SubscribeGroupEvents[T any](messageQueue MessageQueue, group, topic string, handler EventHandler[T]) error {
+	if err := checkEventType[T](DefaultRegistry, topic); err != nil {
+		return err
+	}
+	return messageQueue.SubscribeGroup(group, topic, Handle(DefaultRegistry, handler))
+}
+
+// checkEventType verifies that T is the type registered for the topic's current version
+func checkEventType[T any](registry *Registry, topic string) error {
+	version := registry.Version(topic)
+	expected, ok := registry.Type(topic, version)
+	if !ok {
+		return nil
+	}
+	if actual := reflect.TypeOf((*T)(nil)).Elem(); actual != expected {
+		return fmt.Errorf("%w: %s v%d is %s, handler takes %s", ErrEventTypeMismatch, topic, version, expected, actual)
+	}
+	return nil
+}
+
+// DefaultRegistry holds the schemas of the events in this package
+var DefaultRegistry = newDefaultRegistry()
+
+// newDefaultRegistry registers every event at its current schema version. To change an
+// event incompatibly, add a new struct as the next version and an upcaster from the
+// previous one, so that queued and dead-lettered events of the old version still decode.
+func newDefaultRegistry() *Registry {
+	registry := NewRegistry()
+	for topic, event := range map[string]interface{}{
+		TopicPostPublished:   PostPublishedEvent{},
+		TopicPostUpdated:     PostUpdatedEvent{},
+		TopicPostDeleted:     PostDeletedEvent{},
+		TopicPostVoted:       PostVotedEvent{},
+		TopicPostFavorited:   PostFavoritedEvent{},
+		TopicPostUnfavorited: PostUnfavoritedEvent{},
+		TopicCommentCreated:  CommentCreatedEvent{},
+		TopicCommentDeleted:  CommentDeletedEvent{},
+		TopicCommentVoted:    CommentVotedEvent{},
+		TopicUserFollowed:    UserFollowedEvent{},
+		TopicUserUnfollowed:  UserUnfollowedEvent{},
+		TopicUserRegistered:  UserRegisteredEvent{},
+		TopicCircleJoined:    CircleJoinedEvent{},
+		TopicCircleLeft:      CircleLeftEvent{},
+		TopicVoteCreated:     VoteCreatedEvent{},
+		TopicVoteUpdated:     VoteUpdatedEvent{},
+		TopicVoteDeleted:     VoteDeletedEvent{},
+	} {
+		if err := registry.Register(topic, 1, event); err != nil {
+			panic(err)
+		}
+	}
+	return registry
+}
@@ -0,0 +1,153 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvelopeQueue_TypedSubscribe(t *testing.T) {
+	queue := NewMemoryQueue(MemoryConfig{})
+	defer queue.Close()
+
+	received := make(chan *PostPublishedEvent, 1)
+	envelopes := make(chan *Envelope, 1)
+	require.NoError(t, SubscribeGroupEvents(queue, "test", TopicPostPublished, func(ctx context.Context, event *PostPublishedEvent) error {
+		envelopes <- EnvelopeFromContext(ctx)
+		received <- event
+		return nil
+	}))
+
+	publisher := NewEnvelopeQueue(queue, DefaultRegistry, "airy/test")
+	ctx := ContextWithTraceParent(ContextWithCorrelationID(context.Background(), "request-1"), "00-trace-span-01")
+	event := PostPublishedEvent{BaseEvent: newBaseEvent(TopicPostPublished), PostID: 7, AuthorID: 3}
+	require.NoError(t, publisher.Publish(ctx, TopicPostPublished, event))
+
+	select {
+	case got := <-received:
+		assert.Equal(t, int64(7), got.PostID)
+		assert.Equal(t, int64(3), got.AuthorID)
+	case <-time.After(time.Second):
+		t.Fatal("event not delivered")
+	}
+
+	envelope := <-envelopes
+	require.NotNil(t, envelope)
+	assert.Equal(t, event.EventID, envelope.ID)
+	assert.Equal(t, TopicPostPublished, envelope.Topic)
+	assert.Equal(t, 1, envelope.Version)
+	assert.Equal(t, "airy/test", envelope.Producer)
+	assert.Equal(t, "request-1", envelope.CorrelationID)
+	assert.Equal(t, "00-trace-span-01", envelope.TraceParent)
+}
+
+func TestEnvelopeQueue_RejectsUnregisteredType(t *testing.T) {
+	queue := NewMemoryQueue(MemoryConfig{})
+	defer queue.Close()
+
+	publisher := NewEnvelopeQueue(queue, DefaultRegistry, "")
+	err := publisher.Publish(context.Background(), TopicPostPublished, PostDeletedEvent{PostID: 1})
+	assert.ErrorIs(t, err, ErrEventTypeMismatch)
+
+	err = SubscribeGroupEvents(queue, "test", TopicPostPublished, func(ctx context.Context, event *PostDeletedEvent) error {
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrEventTypeMismatch)
+}
+
+func TestDecodeEnvelope_LegacyEvent(t *testing.T) {
+	event := PostDeletedEvent{BaseEvent: newBaseEvent(TopicPostDeleted), PostID: 9}
+	message, err := json.Marshal(event)
+	require.NoError(t, err)
+
+	envelope, err := DecodeEnvelope(message)
+	require.NoError(t, err)
+	assert.Equal(t, event.EventID, envelope.ID)
+	assert.Equal(t, TopicPostDeleted, envelope.Topic)
+	assert.Equal(t, 1, envelope.Version)
+	assert.JSONEq(t, string(message), string(envelope.Payload))
+
+	// Handlers decode bare events published before envelopes
+	var got *PostDeletedEvent
+	handler := Handle(DefaultRegistry, func(ctx context.Context, event *PostDeletedEvent) error {
+		got = event
+		return nil
+	})
+	require.NoError(t, handler(context.Background(), message))
+	require.NotNil(t, got)
+	assert.Equal(t, int64(9), got.PostID)
+}
+
+// postPublishedV2 renames PostPublishedEvent's author_id for the upcasting tests
+type postPublishedV2 struct {
+	BaseEvent
+	PostID    int64 `json:"post_id"`
+	CreatorID int64 `json:"creator_id"`
+}
+
+func TestRegistry_Upcast(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(TopicPostPublished, 1, PostPublishedEvent{}))
+	require.NoError(t, registry.Register(TopicPostPublished, 2, postPublishedV2{}))
+	assert.Equal(t, 2, registry.Version(TopicPostPublished))
+	assert.Equal(t, 1, registry.Version(TopicPostDeleted))
+	assert.Error(t, registry.Register(TopicPostPublished, 2, postPublishedV2{}))
+
+	old, err := newEnvelope(context.Background(), TopicPostPublished, 1, "", PostPublishedEvent{PostID: 5, AuthorID: 8})
+	require.NoError(t, err)
+	message, err := json.Marshal(old)
+	require.NoError(t, err)
+
+	t.Run("fails without an upcaster", func(t *testing.T) {
+		_, err := registry.Decode(message)
+		assert.ErrorIs(t, err, ErrMissingUpcaster)
+	})
+
+	require.NoError(t, registry.RegisterUpcaster(TopicPostPublished, 1, func(payload json.RawMessage) (json.RawMessage, error) {
+		var fields map[string]interface{}
+		if err := json.Unmarshal(payload, &fields); err != nil {
+			return nil, err
+		}
+		fields["creator_id"] = fields["author_id"]
+		delete(fields, "author_id")
+		return json.Marshal(fields)
+	}))
+
+	t.Run("upcasts old versions to the current one", func(t *testing.T) {
+		var got *postPublishedV2
+		handler := Handle(registry, func(ctx context.Context, event *postPublishedV2) error {
+			got = event
+			assert.Equal(t, 2, EnvelopeFromContext(ctx).Version)
+			return nil
+		})
+		require.NoError(t, handler(context.Background(), message))
+		require.NotNil(t, got)
+		assert.Equal(t, int64(5), got.PostID)
+		assert.Equal(t, int64(8), got.CreatorID)
+	})
+
+	t.Run("rejects versions newer than the current one", func(t *testing.T) {
+		newer, err := newEnvelope(context.Background(), TopicPostPublished, 3, "", postPublishedV2{PostID: 5})
+		require.NoError(t, err)
+		message, err := json.Marshal(newer)
+		require.NoError(t, err)
+
+		_, err = registry.Decode(message)
+		assert.ErrorIs(t, err, ErrUnsupportedEventVersion)
+	})
+
+	t.Run("new envelopes use the current version", func(t *testing.T) {
+		envelope, err := registry.NewEnvelope(context.Background(), TopicPostPublished, "", postPublishedV2{PostID: 5})
+		require.NoError(t, err)
+		assert.Equal(t, 2, envelope.Version)
+		assert.NotEmpty(t, envelope.ID)
+		assert.Equal(t, envelope.ID, envelope.CorrelationID)
+
+		_, err = registry.NewEnvelope(context.Background(), TopicPostPublished, "", PostPublishedEvent{})
+		assert.ErrorIs(t, err, ErrEventTypeMismatch)
+	})
+}
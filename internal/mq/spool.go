@@ -0,0 +1,139 @@
+package mq
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Reasons a message was spooled
+const (
+	SpoolReasonUnavailable = "unavailable" // No connection or channel to publish on
+	SpoolReasonNacked      = "nacked"      // The broker refused the message
+	SpoolReasonUnconfirmed = "unconfirmed" // No confirm arrived in time
+	SpoolReasonUnroutable  = "unroutable"  // The broker returned the message: no queue is bound to its topic
+)
+
+// SpooledMessage is a message waiting in the spool to be published again
+type SpooledMessage struct {
+	ID         string     `json:"id"`
+	Exchange   string     `json:"exchange"` // "" for the default exchange
+	RoutingKey string     `json:"routing_key"`
+	Headers    amqp.Table `json:"headers,omitempty"`
+	Body       []byte     `json:"body"`
+	Reason     string     `json:"reason"`
+	Attempts   int        `json:"attempts"` // Times the message was spooled
+	SpooledAt  time.Time  `json:"spooled_at"`
+}
+
+// Spool keeps messages that could not be published in a directory, one file per
+// message, so that they survive a restart and can be published again later.
+// Files are named after the time they were spooled, so they replay in that order.
+type Spool struct {
+	dir string
+	mu  sync.Mutex // Serializes Replay, so a message is not published twice at once
+}
+
+// NewSpool opens the spool in dir, creating the directory if needed
+func NewSpool(dir string) (*Spool, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create spool directory: %w", err)
+	}
+	return &Spool{dir: dir}, nil
+}
+
+// Put writes a message to the spool. The file is written under a temporary name
+// and renamed, so Replay never reads a partial message.
+func (s *Spool) Put(msg *SpooledMessage) error {
+	if msg.SpooledAt.IsZero() {
+		msg.SpooledAt = time.Now()
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal spooled message: %w", err)
+	}
+
+	name := fmt.Sprintf("%020d-%s.json", msg.SpooledAt.UnixNano(), msg.ID)
+	tmp := filepath.Join(s.dir, "."+name+".tmp")
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write spooled message: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(s.dir, name)); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write spooled message: %w", err)
+	}
+	return nil
+}
+
+// Len returns the number of spooled messages
+func (s *Spool) Len() (int, error) {
+	names, err := s.names()
+	return len(names), err
+}
+
+// Replay hands the spooled messages to publish, oldest first, removing each one it
+// accepts. It stops at the first error, leaving that message and the rest spooled,
+// and returns the number of messages replayed. Unreadable files are renamed with
+// a .corrupt suffix and skipped.
+func (s *Spool) Replay(publish func(msg *SpooledMessage) error) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names, err := s.names()
+	if err != nil {
+		return 0, err
+	}
+
+	replayed := 0
+	for _, name := range names {
+		path := filepath.Join(s.dir, name)
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return replayed, fmt.Errorf("failed to read spooled message: %w", err)
+		}
+
+		var msg SpooledMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			os.Rename(path, path+".corrupt")
+			continue
+		}
+
+		if err := publish(&msg); err != nil {
+			return replayed, err
+		}
+
+		if err := os.Remove(path); err != nil {
+			return replayed, fmt.Errorf("failed to remove spooled message: %w", err)
+		}
+		replayed++
+	}
+	return replayed, nil
+}
+
+// names lists the spooled message files in spool order
+func (s *Spool) names() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spool directory: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,105 @@
+package mq
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpool(t *testing.T) {
+	t.Run("replays messages in spool order and removes them", func(t *testing.T) {
+		spool, err := NewSpool(t.TempDir())
+		require.NoError(t, err)
+
+		now := time.Now()
+		require.NoError(t, spool.Put(&SpooledMessage{ID: "second", RoutingKey: TopicPostPublished, Body: []byte(`{"post_id":2}`), SpooledAt: now}))
+		require.NoError(t, spool.Put(&SpooledMessage{ID: "first", RoutingKey: TopicPostPublished, Body: []byte(`{"post_id":1}`), SpooledAt: now.Add(-time.Second)}))
+
+		pending, err := spool.Len()
+		require.NoError(t, err)
+		assert.Equal(t, 2, pending)
+
+		var ids []string
+		replayed, err := spool.Replay(func(msg *SpooledMessage) error {
+			ids = append(ids, msg.ID)
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, replayed)
+		assert.Equal(t, []string{"first", "second"}, ids)
+		pending, _ = spool.Len()
+		assert.Zero(t, pending)
+	})
+
+	t.Run("keeps the messages from the first failure on", func(t *testing.T) {
+		spool, err := NewSpool(t.TempDir())
+		require.NoError(t, err)
+
+		now := time.Now()
+		for i, id := range []string{"a", "b", "c"} {
+			require.NoError(t, spool.Put(&SpooledMessage{ID: id, Body: []byte(`{}`), SpooledAt: now.Add(time.Duration(i) * time.Second)}))
+		}
+
+		replayed, err := spool.Replay(func(msg *SpooledMessage) error {
+			if msg.ID == "b" {
+				return errors.New("message queue is reconnecting")
+			}
+			return nil
+		})
+
+		assert.Error(t, err)
+		assert.Equal(t, 1, replayed)
+		pending, _ := spool.Len()
+		assert.Equal(t, 2, pending)
+	})
+
+	t.Run("keeps headers and body across a restart", func(t *testing.T) {
+		dir := t.TempDir()
+		spool, err := NewSpool(dir)
+		require.NoError(t, err)
+		require.NoError(t, spool.Put(&SpooledMessage{
+			ID:         "retry",
+			RoutingKey: "airy.search.post.published.retry.1000ms",
+			Headers:    map[string]interface{}{HeaderRetryCount: int32(2)},
+			Body:       []byte(`{"post_id":1}`),
+			Reason:     SpoolReasonUnroutable,
+			Attempts:   1,
+		}))
+
+		reopened, err := NewSpool(dir)
+		require.NoError(t, err)
+
+		var got *SpooledMessage
+		_, err = reopened.Replay(func(msg *SpooledMessage) error {
+			got = msg
+			return nil
+		})
+
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, `{"post_id":1}`, string(got.Body))
+		assert.Equal(t, 2, retryCount(got.Headers))
+		assert.Equal(t, SpoolReasonUnroutable, got.Reason)
+		assert.Equal(t, 1, got.Attempts)
+	})
+
+	t.Run("sets aside unreadable files", func(t *testing.T) {
+		dir := t.TempDir()
+		spool, err := NewSpool(dir)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "00000000000000000001-broken.json"), []byte("{"), 0o644))
+		require.NoError(t, spool.Put(&SpooledMessage{ID: "ok", Body: []byte(`{}`)}))
+
+		replayed, err := spool.Replay(func(msg *SpooledMessage) error { return nil })
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, replayed)
+		assert.FileExists(t, filepath.Join(dir, "00000000000000000001-broken.json.corrupt"))
+	})
+}
@@ -0,0 +1,70 @@
+package pagination
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncodeDecode_RoundTrip(t *testing.T) {
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 6000, time.UTC)
+
+	cursor, err := Encode("posts:created_at:desc", createdAt, int64(9007199254740993))
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+
+	var gotTime time.Time
+	var gotID int64
+	if err := Decode(cursor, "posts:created_at:desc", &gotTime, &gotID); err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if !gotTime.Equal(createdAt) {
+		t.Errorf("Expected time %v, got %v", createdAt, gotTime)
+	}
+	if gotID != 9007199254740993 {
+		t.Errorf("Expected ID to survive without float rounding, got %d", gotID)
+	}
+}
+
+func TestDecode_RejectsTamperedCursor(t *testing.T) {
+	cursor, _ := Encode("logs", int64(10))
+	other, _ := Encode("logs", int64(11))
+
+	// Pair the payload of one cursor with the signature of another
+	tampered := strings.Split(other, ".")[0] + "." + strings.Split(cursor, ".")[1]
+
+	var id int64
+	if err := Decode(tampered, "logs", &id); err != ErrInvalidCursor {
+		t.Errorf("Expected ErrInvalidCursor for tampered cursor, got %v", err)
+	}
+	if err := Decode("not-a-cursor", "logs", &id); err != ErrInvalidCursor {
+		t.Errorf("Expected ErrInvalidCursor for garbage, got %v", err)
+	}
+}
+
+func TestDecode_RejectsOtherOrdering(t *testing.T) {
+	cursor, _ := Encode("posts:hotness_score:desc", 1.5, int64(3))
+
+	var score float64
+	var id int64
+	if err := Decode(cursor, "posts:created_at:desc", &score, &id); err != ErrInvalidCursor {
+		t.Errorf("Expected ErrInvalidCursor for a cursor of another ordering, got %v", err)
+	}
+	if SortName(cursor) != "posts:hotness_score:desc" {
+		t.Errorf("Expected SortName to report the issuing ordering, got %q", SortName(cursor))
+	}
+}
+
+func TestSetSecret_InvalidatesOldCursors(t *testing.T) {
+	SetSecret([]byte("first-secret"))
+	cursor, _ := Encode("logs", int64(1))
+
+	SetSecret([]byte("second-secret"))
+	defer SetSecret(randomKey())
+
+	var id int64
+	if err := Decode(cursor, "logs", &id); err != ErrInvalidCursor {
+		t.Errorf("Expected cursor signed with an old secret to be rejected, got %v", err)
+	}
+}
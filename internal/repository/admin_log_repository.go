@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"strings"
 
 	"github.com/kobayashirei/airy/internal/models"
 	"gorm.io/gorm"
@@ -18,6 +19,8 @@ type AdminLogListOptions struct {
 	Order      string // "asc", "desc"
 	Limit      int
 	Offset     int
+	After      *Keyset // Keyset position to continue after; takes precedence over Offset
+	Before     *Keyset // Keyset position to page back from
 }
 
 // AdminLogRepository defines the interface for admin log data operations
@@ -52,21 +55,21 @@ func (r *adminLogRepository) List(ctx context.Context, opts AdminLogListOptions)
 	if opts.SortBy != "" {
 		sortBy = opts.SortBy
 	}
-	order := "DESC"
-	if opts.Order != "" {
-		order = opts.Order
-	}
-	query = query.Order(sortBy + " " + order)
+	desc := !strings.EqualFold(opts.Order, "asc")
+	query, reversed := applyKeyset(query, []SortColumn{{Name: sortBy, Desc: desc}}, opts.After, opts.Before)
 
 	// Apply pagination
 	if opts.Limit > 0 {
 		query = query.Limit(opts.Limit)
 	}
-	if opts.Offset > 0 {
+	if opts.Offset > 0 && opts.After == nil && opts.Before == nil {
 		query = query.Offset(opts.Offset)
 	}
 
 	err := query.Find(&logs).Error
+	if reversed {
+		reverse(logs)
+	}
 	return logs, err
 }
 
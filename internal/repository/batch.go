@@ -19,6 +19,8 @@ type BatchRepository interface {
 	FindCommentsByIDs(ctx context.Context, ids []int64) (map[int64]*models.Comment, error)
 	// FindEntityCountsByIDs retrieves entity counts for multiple entities
 	FindEntityCountsByIDs(ctx context.Context, entityType string, ids []int64) (map[int64]*models.EntityCount, error)
+	// FindUserVotesByEntityIDs retrieves a user's votes on multiple entities
+	FindUserVotesByEntityIDs(ctx context.Context, userID int64, entityType string, ids []int64) (map[int64]*models.Vote, error)
 	// FindUserProfilesByIDs retrieves user profiles for multiple users
 	FindUserProfilesByIDs(ctx context.Context, userIDs []int64) (map[int64]*models.UserProfile, error)
 	// FindUserStatsByIDs retrieves user stats for multiple users
@@ -132,6 +134,27 @@ func (r *batchRepository) FindEntityCountsByIDs(ctx context.Context, entityType
 	return result, nil
 }
 
+// FindUserVotesByEntityIDs retrieves a user's votes on multiple entities, keyed by entity ID
+func (r *batchRepository) FindUserVotesByEntityIDs(ctx context.Context, userID int64, entityType string, ids []int64) (map[int64]*models.Vote, error) {
+	if len(ids) == 0 {
+		return make(map[int64]*models.Vote), nil
+	}
+
+	var votes []*models.Vote
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND entity_type = ? AND entity_id IN ?", userID, entityType, ids).
+		Find(&votes).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[int64]*models.Vote, len(votes))
+	for _, vote := range votes {
+		result[vote.EntityID] = vote
+	}
+	return result, nil
+}
+
 // FindUserProfilesByIDs retrieves user profiles for multiple users
 func (r *batchRepository) FindUserProfilesByIDs(ctx context.Context, userIDs []int64) (map[int64]*models.UserProfile, error) {
 	if len(userIDs) == 0 {
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kobayashirei/airy/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CircleHotnessRepository defines the interface for per-circle hotness settings data operations
+type CircleHotnessRepository interface {
+	FindByCircleID(ctx context.Context, circleID int64) (*models.CircleHotnessSettings, error)
+	// List retrieves the settings of every circle that has them
+	List(ctx context.Context) ([]*models.CircleHotnessSettings, error)
+	// Upsert stores the settings of a circle, replacing any it had
+	Upsert(ctx context.Context, settings *models.CircleHotnessSettings) error
+	Delete(ctx context.Context, circleID int64) error
+}
+
+// circleHotnessRepository implements CircleHotnessRepository interface
+type circleHotnessRepository struct {
+	db *gorm.DB
+}
+
+// NewCircleHotnessRepository creates a new circle hotness settings repository
+func NewCircleHotnessRepository(db *gorm.DB) CircleHotnessRepository {
+	return &circleHotnessRepository{db: db}
+}
+
+// FindByCircleID finds the settings of a circle
+func (r *circleHotnessRepository) FindByCircleID(ctx context.Context, circleID int64) (*models.CircleHotnessSettings, error) {
+	var settings models.CircleHotnessSettings
+	err := r.db.WithContext(ctx).Where("circle_id = ?", circleID).First(&settings).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// List retrieves the settings of every circle, by circle ID
+func (r *circleHotnessRepository) List(ctx context.Context) ([]*models.CircleHotnessSettings, error) {
+	var settings []*models.CircleHotnessSettings
+	err := r.db.WithContext(ctx).Order("circle_id").Find(&settings).Error
+	return settings, err
+}
+
+// Upsert creates or replaces the settings of a circle
+func (r *circleHotnessRepository) Upsert(ctx context.Context, settings *models.CircleHotnessSettings) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "circle_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"settings", "updated_by", "updated_at"}),
+	}).Create(settings).Error
+}
+
+// Delete deletes the settings of a circle
+func (r *circleHotnessRepository) Delete(ctx context.Context, circleID int64) error {
+	return r.db.WithContext(ctx).Where("circle_id = ?", circleID).Delete(&models.CircleHotnessSettings{}).Error
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"strings"
 
 	"github.com/kobayashirei/airy/internal/models"
 	"gorm.io/gorm"
@@ -12,11 +13,27 @@ import (
 type CommentListOptions struct {
 	PostID   *int64
 	AuthorID *int64
+	ParentID *int64
+	RootOnly bool   // Only top-level comments
 	Status   string
+	SortBy   string // "created_at"
+	Order    string // "asc", "desc"
 	Limit    int
 	Offset   int
+	After    *Keyset // Keyset position to continue after; takes precedence over Offset
+	Before   *Keyset // Keyset position to page back from
 }
 
+// ReplyListOptions defines options for listing the replies to several comments at once
+type ReplyListOptions struct {
+	SortBy    string // "created_at", "score", "best_score"
+	Order     string // "asc", "desc"
+	PerParent int    // Replies returned per parent comment
+}
+
+// replySortColumns are the columns replies may be sorted by
+var replySortColumns = map[string]bool{"created_at": true, "score": true, "best_score": true}
+
 // CommentRepository defines the interface for comment data operations
 type CommentRepository interface {
 	Create(ctx context.Context, comment *models.Comment) error
@@ -24,9 +41,16 @@ type CommentRepository interface {
 	FindByPostID(ctx context.Context, postID int64) ([]*models.Comment, error)
 	FindByParentID(ctx context.Context, parentID int64) ([]*models.Comment, error)
 	FindRootComments(ctx context.Context, postID int64, limit, offset int) ([]*models.Comment, error)
+	List(ctx context.Context, opts CommentListOptions) ([]*models.Comment, error)
 	Update(ctx context.Context, comment *models.Comment) error
 	Delete(ctx context.Context, id int64) error
 	UpdateStatus(ctx context.Context, id int64, status string) error
+	// ListReplies retrieves the first published replies to each of the parent comments
+	ListReplies(ctx context.Context, parentIDs []int64, opts ReplyListOptions) ([]*models.Comment, error)
+	// FindRepliedIDs returns which of the comments have published replies
+	FindRepliedIDs(ctx context.Context, ids []int64) ([]int64, error)
+	// UpdateScores recomputes the vote scores a comment is sorted by from its vote counts
+	UpdateScores(ctx context.Context, id int64) error
 	CountByPostID(ctx context.Context, postID int64) (int64, error)
 	Count(ctx context.Context, opts CommentListOptions) (int64, error)
 }
@@ -43,13 +67,13 @@ func NewCommentRepository(db *gorm.DB) CommentRepository {
 
 // Create creates a new comment
 func (r *commentRepository) Create(ctx context.Context, comment *models.Comment) error {
-	return r.db.WithContext(ctx).Create(comment).Error
+	return dbFor(ctx, r.db).Create(comment).Error
 }
 
 // FindByID finds a comment by ID
 func (r *commentRepository) FindByID(ctx context.Context, id int64) (*models.Comment, error) {
 	var comment models.Comment
-	err := r.db.WithContext(ctx).Where("id = ?", id).First(&comment).Error
+	err := dbFor(ctx, r.db).Where("id = ?", id).First(&comment).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -62,7 +86,7 @@ func (r *commentRepository) FindByID(ctx context.Context, id int64) (*models.Com
 // FindByPostID finds all comments for a post
 func (r *commentRepository) FindByPostID(ctx context.Context, postID int64) ([]*models.Comment, error) {
 	var comments []*models.Comment
-	err := r.db.WithContext(ctx).
+	err := dbFor(ctx, r.db).
 		Where("post_id = ? AND status = ?", postID, "published").
 		Order("path ASC").
 		Find(&comments).Error
@@ -72,7 +96,7 @@ func (r *commentRepository) FindByPostID(ctx context.Context, postID int64) ([]*
 // FindByParentID finds all direct replies to a comment
 func (r *commentRepository) FindByParentID(ctx context.Context, parentID int64) ([]*models.Comment, error) {
 	var comments []*models.Comment
-	err := r.db.WithContext(ctx).
+	err := dbFor(ctx, r.db).
 		Where("parent_id = ? AND status = ?", parentID, "published").
 		Order("created_at ASC").
 		Find(&comments).Error
@@ -82,7 +106,7 @@ func (r *commentRepository) FindByParentID(ctx context.Context, parentID int64)
 // FindRootComments finds root-level comments for a post
 func (r *commentRepository) FindRootComments(ctx context.Context, postID int64, limit, offset int) ([]*models.Comment, error) {
 	var comments []*models.Comment
-	query := r.db.WithContext(ctx).
+	query := dbFor(ctx, r.db).
 		Where("post_id = ? AND parent_id IS NULL AND status = ?", postID, "published").
 		Order("created_at DESC")
 	
@@ -97,29 +121,124 @@ func (r *commentRepository) FindRootComments(ctx context.Context, postID int64,
 	return comments, err
 }
 
+// List retrieves comments based on options
+func (r *commentRepository) List(ctx context.Context, opts CommentListOptions) ([]*models.Comment, error) {
+	var comments []*models.Comment
+	query := r.buildListQuery(ctx, opts)
+
+	// Apply sorting
+	sortBy := "created_at"
+	if opts.SortBy != "" {
+		sortBy = opts.SortBy
+	}
+	desc := !strings.EqualFold(opts.Order, "asc")
+	query, reversed := applyKeyset(query, []SortColumn{{Name: sortBy, Desc: desc}}, opts.After, opts.Before)
+
+	// Apply pagination
+	if opts.Limit > 0 {
+		query = query.Limit(opts.Limit)
+	}
+	if opts.Offset > 0 && opts.After == nil && opts.Before == nil {
+		query = query.Offset(opts.Offset)
+	}
+
+	err := query.Find(&comments).Error
+	if reversed {
+		reverse(comments)
+	}
+	return comments, err
+}
+
 // Update updates a comment
 func (r *commentRepository) Update(ctx context.Context, comment *models.Comment) error {
-	return r.db.WithContext(ctx).Save(comment).Error
+	return dbFor(ctx, r.db).Save(comment).Error
 }
 
 // Delete soft deletes a comment by ID
 func (r *commentRepository) Delete(ctx context.Context, id int64) error {
-	return r.db.WithContext(ctx).Model(&models.Comment{}).
+	return dbFor(ctx, r.db).Model(&models.Comment{}).
 		Where("id = ?", id).
 		Update("status", "deleted").Error
 }
 
 // UpdateStatus updates the status of a comment
 func (r *commentRepository) UpdateStatus(ctx context.Context, id int64, status string) error {
-	return r.db.WithContext(ctx).Model(&models.Comment{}).
+	return dbFor(ctx, r.db).Model(&models.Comment{}).
 		Where("id = ?", id).
 		Update("status", status).Error
 }
 
+// ListReplies retrieves up to opts.PerParent published replies to each parent, in the
+// sort order with id as the tiebreaker, in one query. Replies are grouped by parent.
+func (r *commentRepository) ListReplies(ctx context.Context, parentIDs []int64, opts ReplyListOptions) ([]*models.Comment, error) {
+	var comments []*models.Comment
+	if len(parentIDs) == 0 || opts.PerParent <= 0 {
+		return comments, nil
+	}
+
+	sortBy := "created_at"
+	if replySortColumns[opts.SortBy] {
+		sortBy = opts.SortBy
+	}
+	direction := "DESC"
+	if strings.EqualFold(opts.Order, "asc") {
+		direction = "ASC"
+	}
+
+	ranked := dbFor(ctx, r.db).Model(&models.Comment{}).
+		Select("comments.*, ROW_NUMBER() OVER (PARTITION BY parent_id ORDER BY "+sortBy+" "+direction+", id "+direction+") AS reply_rank").
+		Where("parent_id IN ? AND status = ?", parentIDs, "published")
+	err := dbFor(ctx, r.db).Table("(?) AS replies", ranked).
+		Where("reply_rank <= ?", opts.PerParent).
+		Order("parent_id, reply_rank").
+		Find(&comments).Error
+	return comments, err
+}
+
+// FindRepliedIDs returns the IDs among ids of comments with at least one published reply
+func (r *commentRepository) FindRepliedIDs(ctx context.Context, ids []int64) ([]int64, error) {
+	var replied []int64
+	if len(ids) == 0 {
+		return replied, nil
+	}
+
+	err := dbFor(ctx, r.db).Model(&models.Comment{}).
+		Distinct("parent_id").
+		Where("parent_id IN ? AND status = ?", ids, "published").
+		Pluck("parent_id", &replied).Error
+	return replied, err
+}
+
+// updateCommentScoresSQL sets a comment's net votes and the Wilson lower bound of its
+// share of upvotes at 95% confidence (z = 1.96), as migration 000014 backfills them
+const updateCommentScoresSQL = `UPDATE comments c
+JOIN entity_counts ec ON ec.entity_type = 'comment' AND ec.entity_id = c.id
+SET c.score = ec.upvote_count - ec.downvote_count,
+    c.best_score = CASE
+        WHEN ec.upvote_count + ec.downvote_count > 0 THEN (
+            ec.upvote_count / (ec.upvote_count + ec.downvote_count)
+            + 1.96 * 1.96 / (2 * (ec.upvote_count + ec.downvote_count))
+            - 1.96 * SQRT(
+                (ec.upvote_count * ec.downvote_count / POW(ec.upvote_count + ec.downvote_count, 2)
+                 + 1.96 * 1.96 / (4 * (ec.upvote_count + ec.downvote_count)))
+                / (ec.upvote_count + ec.downvote_count)
+            )
+        ) / (1 + 1.96 * 1.96 / (ec.upvote_count + ec.downvote_count))
+        ELSE 0
+    END
+WHERE c.id = ?`
+
+// UpdateScores recomputes the vote scores of a comment from its vote counts, leaving
+// updated_at to edits. Counts are read and scores written in one statement, so
+// concurrent vote events cannot overwrite newer scores with older ones.
+func (r *commentRepository) UpdateScores(ctx context.Context, id int64) error {
+	return dbFor(ctx, r.db).Exec(updateCommentScoresSQL, id).Error
+}
+
 // CountByPostID counts comments for a post
 func (r *commentRepository) CountByPostID(ctx context.Context, postID int64) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&models.Comment{}).
+	err := dbFor(ctx, r.db).Model(&models.Comment{}).
 		Where("post_id = ? AND status = ?", postID, "published").
 		Count(&count).Error
 	return count, err
@@ -128,7 +247,14 @@ func (r *commentRepository) CountByPostID(ctx context.Context, postID int64) (in
 // Count counts comments based on options
 func (r *commentRepository) Count(ctx context.Context, opts CommentListOptions) (int64, error) {
 	var count int64
-	query := r.db.WithContext(ctx).Model(&models.Comment{})
+	query := r.buildListQuery(ctx, opts)
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// buildListQuery builds the base query for listing comments
+func (r *commentRepository) buildListQuery(ctx context.Context, opts CommentListOptions) *gorm.DB {
+	query := dbFor(ctx, r.db).Model(&models.Comment{})
 
 	if opts.PostID != nil {
 		query = query.Where("post_id = ?", *opts.PostID)
@@ -136,10 +262,15 @@ func (r *commentRepository) Count(ctx context.Context, opts CommentListOptions)
 	if opts.AuthorID != nil {
 		query = query.Where("author_id = ?", *opts.AuthorID)
 	}
+	if opts.ParentID != nil {
+		query = query.Where("parent_id = ?", *opts.ParentID)
+	}
+	if opts.RootOnly {
+		query = query.Where("parent_id IS NULL")
+	}
 	if opts.Status != "" {
 		query = query.Where("status = ?", opts.Status)
 	}
 
-	err := query.Count(&count).Error
-	return count, err
+	return query
 }
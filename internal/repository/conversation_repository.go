@@ -13,7 +13,8 @@ type ConversationRepository interface {
 	Create(ctx context.Context, conversation *models.Conversation) error
 	FindByID(ctx context.Context, id int64) (*models.Conversation, error)
 	FindByUsers(ctx context.Context, user1ID, user2ID int64) (*models.Conversation, error)
-	FindByUserID(ctx context.Context, userID int64, limit, offset int) ([]*models.Conversation, error)
+	// FindByUserID lists a user's conversations by most recent message, continuing after the last_message_at keyset when after is set
+	FindByUserID(ctx context.Context, userID int64, limit int, after *Keyset) ([]*models.Conversation, error)
 	Update(ctx context.Context, conversation *models.Conversation) error
 	UpdateLastMessageAt(ctx context.Context, id int64) error
 	Delete(ctx context.Context, id int64) error
@@ -69,19 +70,15 @@ func (r *conversationRepository) FindByUsers(ctx context.Context, user1ID, user2
 	return &conversation, nil
 }
 
-// FindByUserID finds all conversations for a user, sorted by last message time
-func (r *conversationRepository) FindByUserID(ctx context.Context, userID int64, limit, offset int) ([]*models.Conversation, error) {
+// FindByUserID finds conversations for a user, sorted by last message time
+func (r *conversationRepository) FindByUserID(ctx context.Context, userID int64, limit int, after *Keyset) ([]*models.Conversation, error) {
 	var conversations []*models.Conversation
-	query := r.db.WithContext(ctx).
-		Where("user1_id = ? OR user2_id = ?", userID, userID).
-		Order("last_message_at DESC")
+	query := r.db.WithContext(ctx).Where("user1_id = ? OR user2_id = ?", userID, userID)
+	query, _ = applyKeyset(query, []SortColumn{{Name: "last_message_at", Desc: true}}, after, nil)
 	
 	if limit > 0 {
 		query = query.Limit(limit)
 	}
-	if offset > 0 {
-		query = query.Offset(offset)
-	}
 	
 	err := query.Find(&conversations).Error
 	return conversations, err
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/kobayashirei/airy/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DeadLetterListOptions defines options for listing dead letters
+type DeadLetterListOptions struct {
+	ConsumerGroup string
+	Topic         string
+	Status        string // "dead", "replayed"; empty for both
+	Limit         int
+	After         *Keyset // Keyset position (created_at, id) to continue after, newest first
+}
+
+// DeadLetterRepository defines the interface for dead letter data operations
+type DeadLetterRepository interface {
+	// Create stores a dead letter, ignoring one whose letter ID is already stored
+	Create(ctx context.Context, letter *models.DeadLetter) error
+	FindByID(ctx context.Context, id int64) (*models.DeadLetter, error)
+	List(ctx context.Context, opts DeadLetterListOptions) ([]*models.DeadLetter, error)
+	Count(ctx context.Context, opts DeadLetterListOptions) (int64, error)
+	MarkReplayed(ctx context.Context, id int64, replayedAt time.Time) error
+	Delete(ctx context.Context, id int64) error
+	// DeleteMatching removes the dead letters matching the filters of opts (Limit and After are ignored)
+	DeleteMatching(ctx context.Context, opts DeadLetterListOptions) (int64, error)
+}
+
+// deadLetterRepository implements DeadLetterRepository interface
+type deadLetterRepository struct {
+	db *gorm.DB
+}
+
+// NewDeadLetterRepository creates a new dead letter repository
+func NewDeadLetterRepository(db *gorm.DB) DeadLetterRepository {
+	return &deadLetterRepository{db: db}
+}
+
+// Create creates a new dead letter
+func (r *deadLetterRepository) Create(ctx context.Context, letter *models.DeadLetter) error {
+	// The dead-letter queue delivers at least once, so the same letter may arrive twice
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(letter).Error
+}
+
+// FindByID finds a dead letter by ID
+func (r *deadLetterRepository) FindByID(ctx context.Context, id int64) (*models.DeadLetter, error) {
+	var letter models.DeadLetter
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&letter).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &letter, nil
+}
+
+// List retrieves dead letters, newest first
+func (r *deadLetterRepository) List(ctx context.Context, opts DeadLetterListOptions) ([]*models.DeadLetter, error) {
+	var letters []*models.DeadLetter
+	query := r.buildListQuery(ctx, opts)
+	query, _ = applyKeyset(query, []SortColumn{{Name: "created_at", Desc: true}}, opts.After, nil)
+
+	if opts.Limit > 0 {
+		query = query.Limit(opts.Limit)
+	}
+
+	err := query.Find(&letters).Error
+	return letters, err
+}
+
+// Count counts dead letters based on options
+func (r *deadLetterRepository) Count(ctx context.Context, opts DeadLetterListOptions) (int64, error) {
+	var count int64
+	err := r.buildListQuery(ctx, opts).Count(&count).Error
+	return count, err
+}
+
+// MarkReplayed records that a dead letter was replayed
+func (r *deadLetterRepository) MarkReplayed(ctx context.Context, id int64, replayedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.DeadLetter{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       models.DeadLetterStatusReplayed,
+			"replay_count": gorm.Expr("replay_count + 1"),
+			"replayed_at":  replayedAt,
+		}).Error
+}
+
+// Delete deletes a dead letter
+func (r *deadLetterRepository) Delete(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).Delete(&models.DeadLetter{}, id).Error
+}
+
+// DeleteMatching deletes the dead letters matching the filters
+func (r *deadLetterRepository) DeleteMatching(ctx context.Context, opts DeadLetterListOptions) (int64, error) {
+	// Without a filter GORM refuses a bare DELETE; 1 = 1 makes purging everything explicit
+	result := r.buildListQuery(ctx, opts).Where("1 = 1").Delete(&models.DeadLetter{})
+	return result.RowsAffected, result.Error
+}
+
+// buildListQuery builds the base query for listing dead letters
+func (r *deadLetterRepository) buildListQuery(ctx context.Context, opts DeadLetterListOptions) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.DeadLetter{})
+
+	if opts.ConsumerGroup != "" {
+		query = query.Where("consumer_group = ?", opts.ConsumerGroup)
+	}
+	if opts.Topic != "" {
+		query = query.Where("topic = ?", opts.Topic)
+	}
+	if opts.Status != "" {
+		query = query.Where("status = ?", opts.Status)
+	}
+
+	return query
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kobayashirei/airy/internal/models"
+	"gorm.io/gorm"
+)
+
+// FavoriteCollectionRepository defines the interface for favorite collection data operations
+type FavoriteCollectionRepository interface {
+	Create(ctx context.Context, collection *models.FavoriteCollection) error
+	FindByID(ctx context.Context, id int64) (*models.FavoriteCollection, error)
+	FindByUserAndName(ctx context.Context, userID int64, name string) (*models.FavoriteCollection, error)
+	FindByUserID(ctx context.Context, userID int64) ([]*models.FavoriteCollection, error)
+	Update(ctx context.Context, collection *models.FavoriteCollection) error
+	// Delete removes a collection; its favorites are kept and become uncategorized
+	Delete(ctx context.Context, id int64) error
+}
+
+// favoriteCollectionRepository implements FavoriteCollectionRepository interface
+type favoriteCollectionRepository struct {
+	db *gorm.DB
+}
+
+// NewFavoriteCollectionRepository creates a new favorite collection repository
+func NewFavoriteCollectionRepository(db *gorm.DB) FavoriteCollectionRepository {
+	return &favoriteCollectionRepository{db: db}
+}
+
+// Create creates a new favorite collection
+func (r *favoriteCollectionRepository) Create(ctx context.Context, collection *models.FavoriteCollection) error {
+	return r.db.WithContext(ctx).Create(collection).Error
+}
+
+// FindByID finds a favorite collection by ID
+func (r *favoriteCollectionRepository) FindByID(ctx context.Context, id int64) (*models.FavoriteCollection, error) {
+	var collection models.FavoriteCollection
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&collection).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &collection, nil
+}
+
+// FindByUserAndName finds a user's collection by name
+func (r *favoriteCollectionRepository) FindByUserAndName(ctx context.Context, userID int64, name string) (*models.FavoriteCollection, error) {
+	var collection models.FavoriteCollection
+	err := r.db.WithContext(ctx).Where("user_id = ? AND name = ?", userID, name).First(&collection).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &collection, nil
+}
+
+// FindByUserID finds all collections of a user, ordered by name
+func (r *favoriteCollectionRepository) FindByUserID(ctx context.Context, userID int64) ([]*models.FavoriteCollection, error) {
+	var collections []*models.FavoriteCollection
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("name ASC").
+		Find(&collections).Error
+	return collections, err
+}
+
+// Update updates a favorite collection
+func (r *favoriteCollectionRepository) Update(ctx context.Context, collection *models.FavoriteCollection) error {
+	return r.db.WithContext(ctx).Save(collection).Error
+}
+
+// Delete uncategorizes the collection's favorites and deletes it in one transaction
+func (r *favoriteCollectionRepository) Delete(ctx context.Context, id int64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Favorite{}).
+			Where("collection_id = ?", id).
+			Update("collection_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.FavoriteCollection{}, id).Error
+	})
+}
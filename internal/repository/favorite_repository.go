@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kobayashirei/airy/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FavoriteListOptions defines options for listing a user's favorites
+type FavoriteListOptions struct {
+	UserID       int64
+	CollectionID *int64 // Only favorites filed in this collection
+	Limit        int
+	After        *Keyset // Keyset position (created_at) to continue after
+}
+
+// FavoriteRepository defines the interface for favorite data operations
+type FavoriteRepository interface {
+	// Create stores a favorite. It returns false when the user already favorited the post.
+	Create(ctx context.Context, favorite *models.Favorite) (bool, error)
+	// Delete removes a favorite. It returns false when there was nothing to remove.
+	Delete(ctx context.Context, userID, postID int64) (bool, error)
+	FindByUserAndPost(ctx context.Context, userID, postID int64) (*models.Favorite, error)
+	// FindFavoritedPostIDs returns which of postIDs the user has favorited
+	FindFavoritedPostIDs(ctx context.Context, userID int64, postIDs []int64) (map[int64]bool, error)
+	// List lists a user's favorites, newest first
+	List(ctx context.Context, opts FavoriteListOptions) ([]*models.Favorite, error)
+	UpdateCollection(ctx context.Context, userID, postID int64, collectionID *int64) error
+}
+
+// favoriteRepository implements FavoriteRepository interface
+type favoriteRepository struct {
+	db *gorm.DB
+}
+
+// NewFavoriteRepository creates a new favorite repository
+func NewFavoriteRepository(db *gorm.DB) FavoriteRepository {
+	return &favoriteRepository{db: db}
+}
+
+// Create creates a favorite, ignoring duplicates
+func (r *favoriteRepository) Create(ctx context.Context, favorite *models.Favorite) (bool, error) {
+	result := dbFor(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(favorite)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Delete deletes the favorite of a post by a user
+func (r *favoriteRepository) Delete(ctx context.Context, userID, postID int64) (bool, error) {
+	result := dbFor(ctx, r.db).
+		Where("user_id = ? AND post_id = ?", userID, postID).
+		Delete(&models.Favorite{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// FindByUserAndPost finds the favorite of a post by a user
+func (r *favoriteRepository) FindByUserAndPost(ctx context.Context, userID, postID int64) (*models.Favorite, error) {
+	var favorite models.Favorite
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND post_id = ?", userID, postID).
+		First(&favorite).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &favorite, nil
+}
+
+// FindFavoritedPostIDs checks a batch of posts against a user's favorites
+func (r *favoriteRepository) FindFavoritedPostIDs(ctx context.Context, userID int64, postIDs []int64) (map[int64]bool, error) {
+	result := make(map[int64]bool, len(postIDs))
+	if len(postIDs) == 0 {
+		return result, nil
+	}
+
+	var ids []int64
+	err := r.db.WithContext(ctx).Model(&models.Favorite{}).
+		Where("user_id = ? AND post_id IN ?", userID, postIDs).
+		Pluck("post_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		result[id] = true
+	}
+	return result, nil
+}
+
+// List retrieves a page of a user's favorites
+func (r *favoriteRepository) List(ctx context.Context, opts FavoriteListOptions) ([]*models.Favorite, error) {
+	var favorites []*models.Favorite
+	query := r.db.WithContext(ctx).Where("user_id = ?", opts.UserID)
+	if opts.CollectionID != nil {
+		query = query.Where("collection_id = ?", *opts.CollectionID)
+	}
+	query, _ = applyKeyset(query, []SortColumn{{Name: "created_at", Desc: true}}, opts.After, nil)
+
+	if opts.Limit > 0 {
+		query = query.Limit(opts.Limit)
+	}
+
+	err := query.Find(&favorites).Error
+	return favorites, err
+}
+
+// UpdateCollection files a user's favorite into a collection (nil to uncategorize)
+func (r *favoriteRepository) UpdateCollection(ctx context.Context, userID, postID int64, collectionID *int64) error {
+	return dbFor(ctx, r.db).Model(&models.Favorite{}).
+		Where("user_id = ? AND post_id = ?", userID, postID).
+		Update("collection_id", collectionID).Error
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/kobayashirei/airy/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FollowRepository defines the interface for follow relationship data operations
+type FollowRepository interface {
+	// Create stores a follow relationship and bumps both profile counters in one transaction.
+	// It returns false without touching the counters when the relationship already exists.
+	Create(ctx context.Context, follow *models.Follow) (bool, error)
+	// Delete removes a follow relationship and decrements both profile counters in one transaction.
+	// It returns false when there was no relationship to remove. Both join the transaction
+	// carried by ctx, so events recorded alongside commit with the counters.
+	Delete(ctx context.Context, followerID, followingID int64) (bool, error)
+	Exists(ctx context.Context, followerID, followingID int64) (bool, error)
+	// FindFollowers lists who follows userID, newest first, starting after the follow ID cursor (0 for the first page)
+	FindFollowers(ctx context.Context, userID int64, cursor int64, limit int) ([]*models.Follow, error)
+	// FindFollowing lists who userID follows, newest first, starting after the follow ID cursor (0 for the first page)
+	FindFollowing(ctx context.Context, userID int64, cursor int64, limit int) ([]*models.Follow, error)
+	FindFollowerIDs(ctx context.Context, userID int64) ([]int64, error)
+	FindFollowingIDs(ctx context.Context, userID int64) ([]int64, error)
+	// FindPopularFollowingIDs returns the users followed by userID that have more than minFollowers followers
+	FindPopularFollowingIDs(ctx context.Context, userID int64, minFollowers int) ([]int64, error)
+}
+
+// followRepository implements FollowRepository interface
+type followRepository struct {
+	db *gorm.DB
+}
+
+// NewFollowRepository creates a new follow repository
+func NewFollowRepository(db *gorm.DB) FollowRepository {
+	return &followRepository{db: db}
+}
+
+// Create creates a follow relationship and updates the follower/following counters
+func (r *followRepository) Create(ctx context.Context, follow *models.Follow) (bool, error) {
+	created := false
+	err := dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(follow)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		created = true
+		return r.updateCounters(tx, follow.FollowerID, follow.FollowingID, 1)
+	})
+	if err != nil {
+		return false, err
+	}
+	return created, nil
+}
+
+// Delete deletes a follow relationship and updates the follower/following counters
+func (r *followRepository) Delete(ctx context.Context, followerID, followingID int64) (bool, error) {
+	deleted := false
+	err := dbFor(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("follower_id = ? AND following_id = ?", followerID, followingID).
+			Delete(&models.Follow{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		deleted = true
+		return r.updateCounters(tx, followerID, followingID, -1)
+	})
+	if err != nil {
+		return false, err
+	}
+	return deleted, nil
+}
+
+// updateCounters applies delta to the follower's following count and the followee's follower count.
+// Profiles are created lazily elsewhere, so missing rows are inserted first to keep the counts exact.
+func (r *followRepository) updateCounters(tx *gorm.DB, followerID, followingID int64, delta int) error {
+	for _, userID := range []int64{followerID, followingID} {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.UserProfile{UserID: userID, Level: 1}).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Model(&models.UserProfile{}).
+		Where("user_id = ?", followerID).
+		UpdateColumn("following_count", gorm.Expr("GREATEST(following_count + ?, 0)", delta)).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.UserProfile{}).
+		Where("user_id = ?", followingID).
+		UpdateColumn("follower_count", gorm.Expr("GREATEST(follower_count + ?, 0)", delta)).Error
+}
+
+// Exists checks whether followerID follows followingID
+func (r *followRepository) Exists(ctx context.Context, followerID, followingID int64) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Follow{}).
+		Where("follower_id = ? AND following_id = ?", followerID, followingID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// FindFollowers retrieves a page of users following userID
+func (r *followRepository) FindFollowers(ctx context.Context, userID int64, cursor int64, limit int) ([]*models.Follow, error) {
+	return r.findPage(ctx, "following_id", userID, cursor, limit)
+}
+
+// FindFollowing retrieves a page of users followed by userID
+func (r *followRepository) FindFollowing(ctx context.Context, userID int64, cursor int64, limit int) ([]*models.Follow, error) {
+	return r.findPage(ctx, "follower_id", userID, cursor, limit)
+}
+
+// findPage runs a keyset query on the follow ID so deep pages stay cheap
+func (r *followRepository) findPage(ctx context.Context, column string, userID int64, cursor int64, limit int) ([]*models.Follow, error) {
+	var follows []*models.Follow
+	query := r.db.WithContext(ctx).Where(column+" = ?", userID)
+	if cursor > 0 {
+		query = query.Where("id < ?", cursor)
+	}
+	err := query.Order("id DESC").Limit(limit).Find(&follows).Error
+	return follows, err
+}
+
+// FindFollowerIDs retrieves the IDs of all users following userID
+func (r *followRepository) FindFollowerIDs(ctx context.Context, userID int64) ([]int64, error) {
+	var ids []int64
+	err := r.db.WithContext(ctx).Model(&models.Follow{}).
+		Where("following_id = ?", userID).
+		Pluck("follower_id", &ids).Error
+	return ids, err
+}
+
+// FindFollowingIDs retrieves the IDs of all users followed by userID
+func (r *followRepository) FindFollowingIDs(ctx context.Context, userID int64) ([]int64, error) {
+	var ids []int64
+	err := r.db.WithContext(ctx).Model(&models.Follow{}).
+		Where("follower_id = ?", userID).
+		Pluck("following_id", &ids).Error
+	return ids, err
+}
+
+// FindPopularFollowingIDs retrieves the IDs of followed users above a follower count
+func (r *followRepository) FindPopularFollowingIDs(ctx context.Context, userID int64, minFollowers int) ([]int64, error) {
+	var ids []int64
+	err := r.db.WithContext(ctx).Model(&models.Follow{}).
+		Joins("JOIN user_profiles ON user_profiles.user_id = follows.following_id").
+		Where("follows.follower_id = ? AND user_profiles.follower_count > ?", userID, minFollowers).
+		Pluck("follows.following_id", &ids).Error
+	return ids, err
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SortColumn is one column of a keyset ordering
+type SortColumn struct {
+	Name string
+	Desc bool
+}
+
+// Keyset is the position of a row in a keyset ordering: the row's value for
+// each sort column, in order, followed by its ID as the final tiebreaker
+type Keyset struct {
+	Values []interface{}
+	ID     int64
+}
+
+// values returns the sort values followed by the ID
+func (k *Keyset) values() []interface{} {
+	values := make([]interface{}, 0, len(k.Values)+1)
+	values = append(values, k.Values...)
+	return append(values, k.ID)
+}
+
+// applyKeyset orders query by columns plus id and, when after or before is set,
+// keeps only the rows past that position. The id column follows the direction
+// of the last sort column.
+//
+// Rows before a position are fetched in reverse order so LIMIT takes the rows
+// closest to it; the returned flag tells the caller to reverse the result.
+func applyKeyset(query *gorm.DB, columns []SortColumn, after, before *Keyset) (*gorm.DB, bool) {
+	idDesc := len(columns) > 0 && columns[len(columns)-1].Desc
+	all := append(append([]SortColumn(nil), columns...), SortColumn{Name: "id", Desc: idDesc})
+
+	reversed := false
+	switch {
+	case after != nil:
+		query = query.Where(keysetCondition(all, after.values(), false))
+	case before != nil:
+		query = query.Where(keysetCondition(all, before.values(), true))
+		reversed = true
+	}
+
+	for _, column := range all {
+		desc := column.Desc
+		if reversed {
+			desc = !desc
+		}
+		if desc {
+			query = query.Order(column.Name + " DESC")
+		} else {
+			query = query.Order(column.Name + " ASC")
+		}
+	}
+
+	return query, reversed
+}
+
+// keysetCondition builds "rows strictly past values" for a lexicographic ordering:
+// (c1 op v1) OR (c1 = v1 AND c2 op v2) OR ...
+func keysetCondition(columns []SortColumn, values []interface{}, backwards bool) clause.Expr {
+	clauses := make([]string, 0, len(columns))
+	args := make([]interface{}, 0, len(columns)*(len(columns)+1)/2)
+
+	for i, column := range columns {
+		parts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			parts = append(parts, columns[j].Name+" = ?")
+			args = append(args, values[j])
+		}
+
+		op := ">"
+		if column.Desc != backwards {
+			op = "<"
+		}
+		parts = append(parts, column.Name+" "+op+" ?")
+		args = append(args, values[i])
+
+		clauses = append(clauses, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	return gorm.Expr("("+strings.Join(clauses, " OR ")+")", args...)
+}
+
+// reverse flips a page fetched backwards by applyKeyset into display order
+func reverse[T any](rows []T) {
+	for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+		rows[i], rows[j] = rows[j], rows[i]
+	}
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeysetCondition(t *testing.T) {
+	columns := []SortColumn{
+		{Name: "created_at", Desc: true},
+		{Name: "id", Desc: true},
+	}
+
+	t.Run("after position", func(t *testing.T) {
+		expr := keysetCondition(columns, []interface{}{"2024-01-01", int64(9)}, false)
+		assert.Equal(t, "((created_at < ?) OR (created_at = ? AND id < ?))", expr.SQL)
+		assert.Equal(t, []interface{}{"2024-01-01", "2024-01-01", int64(9)}, expr.Vars)
+	})
+
+	t.Run("before position flips comparisons", func(t *testing.T) {
+		expr := keysetCondition(columns, []interface{}{"2024-01-01", int64(9)}, true)
+		assert.Equal(t, "((created_at > ?) OR (created_at = ? AND id > ?))", expr.SQL)
+	})
+
+	t.Run("mixed directions", func(t *testing.T) {
+		mixed := []SortColumn{{Name: "is_read"}, {Name: "created_at", Desc: true}, {Name: "id", Desc: true}}
+		expr := keysetCondition(mixed, []interface{}{false, "t", int64(3)}, false)
+		assert.Equal(t, "((is_read > ?) OR (is_read = ? AND created_at < ?) OR (is_read = ? AND created_at = ? AND id < ?))", expr.SQL)
+	})
+}
+
+func TestReverse(t *testing.T) {
+	rows := []int{1, 2, 3, 4}
+	reverse(rows)
+	assert.Equal(t, []int{4, 3, 2, 1}, rows)
+}
@@ -12,7 +12,8 @@ import (
 type MessageRepository interface {
 	Create(ctx context.Context, message *models.Message) error
 	FindByID(ctx context.Context, id int64) (*models.Message, error)
-	FindByConversationID(ctx context.Context, conversationID int64, limit, offset int) ([]*models.Message, error)
+	// FindByConversationID lists a conversation's messages newest first, continuing after the created_at keyset when after is set
+	FindByConversationID(ctx context.Context, conversationID int64, limit int, after *Keyset) ([]*models.Message, error)
 	FindUnreadByConversationAndReceiver(ctx context.Context, conversationID, receiverID int64) ([]*models.Message, error)
 	Update(ctx context.Context, message *models.Message) error
 	MarkAsRead(ctx context.Context, id int64) error
@@ -49,19 +50,15 @@ func (r *messageRepository) FindByID(ctx context.Context, id int64) (*models.Mes
 	return &message, nil
 }
 
-// FindByConversationID finds all messages in a conversation with pagination
-func (r *messageRepository) FindByConversationID(ctx context.Context, conversationID int64, limit, offset int) ([]*models.Message, error) {
+// FindByConversationID finds messages in a conversation with keyset pagination
+func (r *messageRepository) FindByConversationID(ctx context.Context, conversationID int64, limit int, after *Keyset) ([]*models.Message, error) {
 	var messages []*models.Message
-	query := r.db.WithContext(ctx).
-		Where("conversation_id = ?", conversationID).
-		Order("created_at DESC")
+	query := r.db.WithContext(ctx).Where("conversation_id = ?", conversationID)
+	query, _ = applyKeyset(query, []SortColumn{{Name: "created_at", Desc: true}}, after, nil)
 	
 	if limit > 0 {
 		query = query.Limit(limit)
 	}
-	if offset > 0 {
-		query = query.Offset(offset)
-	}
 	
 	err := query.Find(&messages).Error
 	return messages, err
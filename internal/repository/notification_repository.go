@@ -12,7 +12,9 @@ import (
 type NotificationRepository interface {
 	Create(ctx context.Context, notification *models.Notification) error
 	FindByID(ctx context.Context, id int64) (*models.Notification, error)
-	FindByReceiverID(ctx context.Context, receiverID int64, limit, offset int) ([]*models.Notification, error)
+	// FindByReceiverID lists a receiver's notifications, unread first then newest first,
+	// continuing after the (is_read, created_at) keyset when after is set
+	FindByReceiverID(ctx context.Context, receiverID int64, limit int, after *Keyset) ([]*models.Notification, error)
 	FindUnreadByReceiverID(ctx context.Context, receiverID int64, limit, offset int) ([]*models.Notification, error)
 	Update(ctx context.Context, notification *models.Notification) error
 	MarkAsRead(ctx context.Context, id int64) error
@@ -49,19 +51,18 @@ func (r *notificationRepository) FindByID(ctx context.Context, id int64) (*model
 	return &notification, nil
 }
 
-// FindByReceiverID finds all notifications for a receiver with pagination
-func (r *notificationRepository) FindByReceiverID(ctx context.Context, receiverID int64, limit, offset int) ([]*models.Notification, error) {
+// FindByReceiverID finds notifications for a receiver with keyset pagination
+func (r *notificationRepository) FindByReceiverID(ctx context.Context, receiverID int64, limit int, after *Keyset) ([]*models.Notification, error) {
 	var notifications []*models.Notification
-	query := r.db.WithContext(ctx).
-		Where("receiver_id = ?", receiverID).
-		Order("is_read ASC, created_at DESC")
+	query := r.db.WithContext(ctx).Where("receiver_id = ?", receiverID)
+	query, _ = applyKeyset(query, []SortColumn{
+		{Name: "is_read"},
+		{Name: "created_at", Desc: true},
+	}, after, nil)
 	
 	if limit > 0 {
 		query = query.Limit(limit)
 	}
-	if offset > 0 {
-		query = query.Offset(offset)
-	}
 	
 	err := query.Find(&notifications).Error
 	return notifications, err
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/kobayashirei/airy/internal/models"
+	"gorm.io/gorm"
+)
+
+// outboxRelayLock is the MySQL named lock held by the relay that currently owns the outbox
+const outboxRelayLock = "airy.outbox.relay"
+
+// OutboxRepository defines the interface for transactional outbox data operations
+type OutboxRepository interface {
+	// Create stores an event, joining the transaction carried by ctx if any
+	Create(ctx context.Context, event *models.OutboxEvent) error
+	// FindDue returns up to limit pending events that are due at now, oldest first.
+	// An event is left out while an earlier pending event of its aggregate is waiting
+	// for a retry, so the events of one aggregate are published in order.
+	FindDue(ctx context.Context, now time.Time, limit int) ([]*models.OutboxEvent, error)
+	MarkPublished(ctx context.Context, id int64, publishedAt time.Time) error
+	// MarkAttemptFailed records a failed publish attempt; status is pending to retry at
+	// nextAttemptAt or failed to give up
+	MarkAttemptFailed(ctx context.Context, id int64, status string, nextAttemptAt time.Time, lastError string) error
+	// PendingStats returns the number of pending events and the creation time of the oldest (nil if none)
+	PendingStats(ctx context.Context) (int64, *time.Time, error)
+	// DeletePublishedBefore removes up to limit events published before the given time
+	DeletePublishedBefore(ctx context.Context, before time.Time, limit int) (int64, error)
+	// WithRelayLock calls fn while holding the relay lock so only one relay publishes at a time.
+	// It returns false without calling fn when another relay holds the lock.
+	WithRelayLock(ctx context.Context, fn func(ctx context.Context) error) (bool, error)
+}
+
+// outboxRepository implements OutboxRepository interface
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+// NewOutboxRepository creates a new outbox repository
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+// Create creates a new outbox event
+func (r *outboxRepository) Create(ctx context.Context, event *models.OutboxEvent) error {
+	return dbFor(ctx, r.db).Create(event).Error
+}
+
+// FindDue finds the pending events ready to be published
+func (r *outboxRepository) FindDue(ctx context.Context, now time.Time, limit int) ([]*models.OutboxEvent, error) {
+	var events []*models.OutboxEvent
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", models.OutboxStatusPending, now).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM outbox earlier
+			WHERE earlier.aggregate_type = outbox.aggregate_type
+			AND earlier.aggregate_id = outbox.aggregate_id
+			AND earlier.id < outbox.id
+			AND earlier.status = ?
+			AND earlier.next_attempt_at > ?
+		)`, models.OutboxStatusPending, now).
+		Order("id ASC").
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
+
+// MarkPublished marks an event as published
+func (r *outboxRepository) MarkPublished(ctx context.Context, id int64, publishedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       models.OutboxStatusPublished,
+			"attempts":     gorm.Expr("attempts + 1"),
+			"published_at": publishedAt,
+			"last_error":   "",
+		}).Error
+}
+
+// MarkAttemptFailed records a failed publish attempt
+func (r *outboxRepository) MarkAttemptFailed(ctx context.Context, id int64, status string, nextAttemptAt time.Time, lastError string) error {
+	return r.db.WithContext(ctx).Model(&models.OutboxEvent{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":          status,
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": nextAttemptAt,
+			"last_error":      lastError,
+		}).Error
+}
+
+// PendingStats counts pending events and finds the oldest one
+func (r *outboxRepository) PendingStats(ctx context.Context) (int64, *time.Time, error) {
+	var stats struct {
+		Count  int64
+		Oldest sql.NullTime
+	}
+	err := r.db.WithContext(ctx).Model(&models.OutboxEvent{}).
+		Select("COUNT(*) AS count, MIN(created_at) AS oldest").
+		Where("status = ?", models.OutboxStatusPending).
+		Scan(&stats).Error
+	if err != nil {
+		return 0, nil, err
+	}
+	if !stats.Oldest.Valid {
+		return stats.Count, nil, nil
+	}
+	return stats.Count, &stats.Oldest.Time, nil
+}
+
+// DeletePublishedBefore deletes old published events
+func (r *outboxRepository) DeletePublishedBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("status = ? AND published_at < ?", models.OutboxStatusPublished, before).
+		Limit(limit).
+		Delete(&models.OutboxEvent{})
+	return result.RowsAffected, result.Error
+}
+
+// WithRelayLock runs fn under a MySQL named lock. The lock belongs to a database
+// session, so it is taken and released on one pinned connection.
+func (r *outboxRepository) WithRelayLock(ctx context.Context, fn func(ctx context.Context) error) (bool, error) {
+	acquired := false
+	err := r.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		var got sql.NullInt64
+		if err := conn.Raw("SELECT GET_LOCK(?, 0)", outboxRelayLock).Scan(&got).Error; err != nil {
+			return err
+		}
+		if !got.Valid || got.Int64 != 1 {
+			return nil
+		}
+		acquired = true
+		// Release even when ctx is done; a lock left on a pooled connection would block other relays
+		defer conn.WithContext(context.Background()).Exec("SELECT RELEASE_LOCK(?)", outboxRelayLock)
+		return fn(ctx)
+	})
+	return acquired, err
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/kobayashirei/airy/internal/models"
 	"gorm.io/gorm"
@@ -11,12 +13,15 @@ import (
 // PostListOptions defines options for listing posts
 type PostListOptions struct {
 	AuthorID   *int64
+	AuthorIDs  []int64 // Posts by any of these authors (e.g. followed users)
 	CircleID   *int64
 	Status     string
 	SortBy     string // "created_at", "hotness_score", "view_count"
 	Order      string // "asc", "desc"
 	Limit      int
 	Offset     int
+	After      *Keyset // Keyset position to continue after; takes precedence over Offset
+	Before     *Keyset // Keyset position to page back from
 }
 
 // PostRepository defines the interface for post data operations
@@ -29,8 +34,14 @@ type PostRepository interface {
 	Count(ctx context.Context, opts PostListOptions) (int64, error)
 	IncrementViewCount(ctx context.Context, id int64) error
 	UpdateHotnessScore(ctx context.Context, id int64, score float64) error
+	UpdateHotnessScores(ctx context.Context, scores map[int64]float64) error
+	ListPublishedSince(ctx context.Context, since time.Time, shard, shards int, afterID int64, limit int) ([]*models.Post, error)
 	UpdateStatus(ctx context.Context, id int64, status string) error
 	CountByDate(ctx context.Context, date string) (int64, error)
+	PublishScheduled(ctx context.Context, id int64, publishedAt time.Time) (bool, error)
+	ListScheduled(ctx context.Context, afterID int64, limit int) ([]*models.Post, error)
+	ListByID(ctx context.Context, status string, afterID int64, limit int) ([]*models.Post, error)
+	ListUpdatedSince(ctx context.Context, since time.Time, afterID int64, limit int) ([]*models.Post, error)
 }
 
 // postRepository implements PostRepository interface
@@ -45,13 +56,13 @@ func NewPostRepository(db *gorm.DB) PostRepository {
 
 // Create creates a new post
 func (r *postRepository) Create(ctx context.Context, post *models.Post) error {
-	return r.db.WithContext(ctx).Create(post).Error
+	return dbFor(ctx, r.db).Create(post).Error
 }
 
 // FindByID finds a post by ID
 func (r *postRepository) FindByID(ctx context.Context, id int64) (*models.Post, error) {
 	var post models.Post
-	err := r.db.WithContext(ctx).Where("id = ?", id).First(&post).Error
+	err := dbFor(ctx, r.db).Where("id = ?", id).First(&post).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -63,12 +74,12 @@ func (r *postRepository) FindByID(ctx context.Context, id int64) (*models.Post,
 
 // Update updates a post
 func (r *postRepository) Update(ctx context.Context, post *models.Post) error {
-	return r.db.WithContext(ctx).Save(post).Error
+	return dbFor(ctx, r.db).Save(post).Error
 }
 
 // Delete soft deletes a post by ID
 func (r *postRepository) Delete(ctx context.Context, id int64) error {
-	return r.db.WithContext(ctx).Model(&models.Post{}).
+	return dbFor(ctx, r.db).Model(&models.Post{}).
 		Where("id = ?", id).
 		Update("status", "deleted").Error
 }
@@ -83,21 +94,21 @@ func (r *postRepository) List(ctx context.Context, opts PostListOptions) ([]*mod
 	if opts.SortBy != "" {
 		sortBy = opts.SortBy
 	}
-	order := "DESC"
-	if opts.Order != "" {
-		order = opts.Order
-	}
-	query = query.Order(sortBy + " " + order)
+	desc := !strings.EqualFold(opts.Order, "asc")
+	query, reversed := applyKeyset(query, []SortColumn{{Name: sortBy, Desc: desc}}, opts.After, opts.Before)
 	
 	// Apply pagination
 	if opts.Limit > 0 {
 		query = query.Limit(opts.Limit)
 	}
-	if opts.Offset > 0 {
+	if opts.Offset > 0 && opts.After == nil && opts.Before == nil {
 		query = query.Offset(opts.Offset)
 	}
 	
 	err := query.Find(&posts).Error
+	if reversed {
+		reverse(posts)
+	}
 	return posts, err
 }
 
@@ -111,11 +122,14 @@ func (r *postRepository) Count(ctx context.Context, opts PostListOptions) (int64
 
 // buildListQuery builds the base query for listing posts
 func (r *postRepository) buildListQuery(ctx context.Context, opts PostListOptions) *gorm.DB {
-	query := r.db.WithContext(ctx).Model(&models.Post{})
+	query := dbFor(ctx, r.db).Model(&models.Post{})
 	
 	if opts.AuthorID != nil {
 		query = query.Where("author_id = ?", *opts.AuthorID)
 	}
+	if len(opts.AuthorIDs) > 0 {
+		query = query.Where("author_id IN ?", opts.AuthorIDs)
+	}
 	if opts.CircleID != nil {
 		query = query.Where("circle_id = ?", *opts.CircleID)
 	}
@@ -128,21 +142,55 @@ func (r *postRepository) buildListQuery(ctx context.Context, opts PostListOption
 
 // IncrementViewCount increments the view count for a post
 func (r *postRepository) IncrementViewCount(ctx context.Context, id int64) error {
-	return r.db.WithContext(ctx).Model(&models.Post{}).
+	return dbFor(ctx, r.db).Model(&models.Post{}).
 		Where("id = ?", id).
 		UpdateColumn("view_count", gorm.Expr("view_count + 1")).Error
 }
 
 // UpdateHotnessScore updates the hotness score for a post
 func (r *postRepository) UpdateHotnessScore(ctx context.Context, id int64, score float64) error {
-	return r.db.WithContext(ctx).Model(&models.Post{}).
+	return dbFor(ctx, r.db).Model(&models.Post{}).
 		Where("id = ?", id).
 		Update("hotness_score", score).Error
 }
 
+// UpdateHotnessScores updates the hotness scores of many posts in one statement
+func (r *postRepository) UpdateHotnessScores(ctx context.Context, scores map[int64]float64) error {
+	if len(scores) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(scores))
+	args := make([]interface{}, 0, 2*len(scores)+1)
+	var cases strings.Builder
+	for id, score := range scores {
+		ids = append(ids, id)
+		cases.WriteString(" WHEN ? THEN ?")
+		args = append(args, id, score)
+	}
+	args = append(args, ids)
+
+	return dbFor(ctx, r.db).Exec(
+		"UPDATE posts SET hotness_score = CASE id"+cases.String()+" END WHERE id IN ?",
+		args...,
+	).Error
+}
+
+// ListPublishedSince lists up to limit posts published since the given time with IDs
+// above afterID, by ID, from one of shards shards of the posts split by ID
+func (r *postRepository) ListPublishedSince(ctx context.Context, since time.Time, shard, shards int, afterID int64, limit int) ([]*models.Post, error) {
+	var posts []*models.Post
+	err := dbFor(ctx, r.db).
+		Where("status = ? AND published_at >= ? AND id > ? AND MOD(id, ?) = ?", "published", since, afterID, shards, shard).
+		Order("id ASC").
+		Limit(limit).
+		Find(&posts).Error
+	return posts, err
+}
+
 // UpdateStatus updates the status of a post
 func (r *postRepository) UpdateStatus(ctx context.Context, id int64, status string) error {
-	return r.db.WithContext(ctx).Model(&models.Post{}).
+	return dbFor(ctx, r.db).Model(&models.Post{}).
 		Where("id = ?", id).
 		Update("status", status).Error
 }
@@ -150,8 +198,56 @@ func (r *postRepository) UpdateStatus(ctx context.Context, id int64, status stri
 // CountByDate counts posts created on a specific date
 func (r *postRepository) CountByDate(ctx context.Context, date string) (int64, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&models.Post{}).
+	err := dbFor(ctx, r.db).Model(&models.Post{}).
 		Where("DATE(created_at) = ?", date).
 		Count(&count).Error
 	return count, err
 }
+
+// PublishScheduled publishes a scheduled post whose time has come. It reports false,
+// changing nothing, when the post is no longer scheduled or is scheduled for later,
+// so that of several replicas publishing the same post only one succeeds.
+func (r *postRepository) PublishScheduled(ctx context.Context, id int64, publishedAt time.Time) (bool, error) {
+	result := dbFor(ctx, r.db).Model(&models.Post{}).
+		Where("id = ? AND status = ? AND scheduled_at <= ?", id, "scheduled", publishedAt).
+		Updates(map[string]interface{}{
+			"status":       "published",
+			"published_at": publishedAt,
+			"updated_at":   publishedAt,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// ListScheduled lists up to limit scheduled posts with IDs above afterID, by ID
+func (r *postRepository) ListScheduled(ctx context.Context, afterID int64, limit int) ([]*models.Post, error) {
+	var posts []*models.Post
+	err := dbFor(ctx, r.db).
+		Where("status = ? AND id > ?", "scheduled", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&posts).Error
+	return posts, err
+}
+
+// ListByID lists up to limit posts with the status and IDs above afterID, by ID
+func (r *postRepository) ListByID(ctx context.Context, status string, afterID int64, limit int) ([]*models.Post, error) {
+	var posts []*models.Post
+	err := dbFor(ctx, r.db).
+		Where("status = ? AND id > ?", status, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&posts).Error
+	return posts, err
+}
+
+// ListUpdatedSince lists up to limit posts of any status updated since the given time
+// with IDs above afterID, by ID
+func (r *postRepository) ListUpdatedSince(ctx context.Context, since time.Time, afterID int64, limit int) ([]*models.Post, error) {
+	var posts []*models.Post
+	err := dbFor(ctx, r.db).
+		Where("updated_at >= ? AND id > ?", since, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&posts).Error
+	return posts, err
+}
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/kobayashirei/airy/internal/models"
+	"gorm.io/gorm"
+)
+
+// fullTextMinTokenSize is InnoDB's default innodb_ft_min_token_size; shorter words
+// are not in FULLTEXT indexes, so keywords made only of them are matched with LIKE
+const fullTextMinTokenSize = 3
+
+// mysqlErrNoFullTextIndex is ER_FT_MATCHING_KEY_NOT_FOUND, returned by MATCH without a FULLTEXT index
+const mysqlErrNoFullTextIndex = 1191
+
+// likeEscaper escapes the LIKE wildcards in a keyword
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SearchOptions defines options for searching posts and users in the database
+type SearchOptions struct {
+	Keyword  string
+	CircleID *int64   // Posts only
+	Tags     []string // Posts only; a post matches when it has any of the tags
+	SortBy   string   // Posts only: "time", "hotness" or "relevance"; users sort by relevance, then follower count
+	FullText bool     // Matches keywords with the FULLTEXT indexes; LIKE otherwise
+	Limit    int
+	Offset   int
+}
+
+// PostSearchHit is a published post matching a search, with its relevance
+type PostSearchHit struct {
+	models.Post
+	Score float64 `gorm:"column:score"`
+}
+
+// UserSearchHit is an active user matching a search, with its relevance
+type UserSearchHit struct {
+	models.User
+	Score float64 `gorm:"column:score"`
+}
+
+// SearchRepository defines the interface for searching posts and users in the
+// database, for when the search cluster is not available
+type SearchRepository interface {
+	SearchPosts(ctx context.Context, opts SearchOptions) ([]*PostSearchHit, int64, error)
+	SearchUsers(ctx context.Context, opts SearchOptions) ([]*UserSearchHit, int64, error)
+}
+
+// IsMissingFullTextIndex reports whether a search failed because the FULLTEXT index
+// it matches keywords with does not exist, e.g. in a schema from GORM auto-migrate
+func IsMissingFullTextIndex(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrNoFullTextIndex
+}
+
+// searchRepository implements SearchRepository interface
+type searchRepository struct {
+	db *gorm.DB
+}
+
+// NewSearchRepository creates a new search repository
+func NewSearchRepository(db *gorm.DB) SearchRepository {
+	return &searchRepository{db: db}
+}
+
+// keywordMatch is how a keyword is matched against some columns: a condition and a
+// relevance expression, with their arguments
+type keywordMatch struct {
+	condition     string
+	conditionArgs []interface{}
+	score         string
+	scoreArgs     []interface{}
+}
+
+// matchKeyword matches a keyword with a FULLTEXT index over all the columns when
+// enabled and it has a long enough word, or with LIKE on each column otherwise. Under
+// LIKE, a match in a column scores the column's weight.
+func matchKeyword(keyword string, fullText bool, columns []string, weights []int) *keywordMatch {
+	keyword = strings.TrimSpace(keyword)
+	if keyword == "" {
+		return &keywordMatch{score: "0"}
+	}
+
+	if fullText && hasFullTextToken(keyword) {
+		match := "MATCH(" + strings.Join(columns, ", ") + ") AGAINST (? IN NATURAL LANGUAGE MODE)"
+		return &keywordMatch{
+			condition:     match,
+			conditionArgs: []interface{}{keyword},
+			score:         match,
+			scoreArgs:     []interface{}{keyword},
+		}
+	}
+
+	pattern := "%" + likeEscaper.Replace(keyword) + "%"
+	conditions := make([]string, len(columns))
+	scores := make([]string, len(columns))
+	m := &keywordMatch{}
+	for i, column := range columns {
+		conditions[i] = column + " LIKE ?"
+		scores[i] = "CASE WHEN " + column + " LIKE ? THEN " + strconv.Itoa(weights[i]) + " ELSE 0 END"
+		m.conditionArgs = append(m.conditionArgs, pattern)
+		m.scoreArgs = append(m.scoreArgs, pattern)
+	}
+	m.condition = "(" + strings.Join(conditions, " OR ") + ")"
+	m.score = "(" + strings.Join(scores, " + ") + ")"
+	return m
+}
+
+// hasFullTextToken reports whether a keyword has a word a FULLTEXT index can match
+func hasFullTextToken(keyword string) bool {
+	for _, word := range strings.Fields(keyword) {
+		if utf8.RuneCountInString(word) >= fullTextMinTokenSize {
+			return true
+		}
+	}
+	return false
+}
+
+// SearchPosts searches published posts, returning a page of them and the total matches.
+// Keywords match the title (weight 3), summary (2) and content (1).
+func (r *searchRepository) SearchPosts(ctx context.Context, opts SearchOptions) ([]*PostSearchHit, int64, error) {
+	match := matchKeyword(opts.Keyword, opts.FullText,
+		[]string{"posts.title", "posts.summary", "posts.content_markdown"}, []int{3, 2, 1})
+
+	var total int64
+	if err := r.buildPostSearchQuery(ctx, opts, match).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return []*PostSearchHit{}, 0, nil
+	}
+
+	query := r.buildPostSearchQuery(ctx, opts, match).
+		Select("posts.*, "+match.score+" AS score", match.scoreArgs...)
+	switch opts.SortBy {
+	case "time":
+		query = query.Order("posts.published_at DESC")
+	case "hotness":
+		query = query.Order("posts.hotness_score DESC")
+	default: // relevance
+		query = query.Order("score DESC").Order("posts.published_at DESC")
+	}
+
+	var hits []*PostSearchHit
+	err := query.Order("posts.id DESC").
+		Limit(opts.Limit).
+		Offset(opts.Offset).
+		Scan(&hits).Error
+	return hits, total, err
+}
+
+// buildPostSearchQuery builds the filtered query of a post search
+func (r *searchRepository) buildPostSearchQuery(ctx context.Context, opts SearchOptions, match *keywordMatch) *gorm.DB {
+	query := r.db.WithContext(ctx).Table("posts").Where("posts.status = ?", "published")
+
+	if match.condition != "" {
+		query = query.Where(match.condition, match.conditionArgs...)
+	}
+	if opts.CircleID != nil {
+		query = query.Where("posts.circle_id = ?", *opts.CircleID)
+	}
+	if len(opts.Tags) > 0 {
+		conditions := make([]string, len(opts.Tags))
+		args := make([]interface{}, len(opts.Tags))
+		for i, tag := range opts.Tags {
+			conditions[i] = "JSON_CONTAINS(posts.tags, JSON_QUOTE(?))"
+			args[i] = tag
+		}
+		query = query.Where("("+strings.Join(conditions, " OR ")+")", args...)
+	}
+
+	return query
+}
+
+// SearchUsers searches active users, returning a page of them and the total matches.
+// Keywords match the username (weight 3) and bio (1).
+func (r *searchRepository) SearchUsers(ctx context.Context, opts SearchOptions) ([]*UserSearchHit, int64, error) {
+	match := matchKeyword(opts.Keyword, opts.FullText, []string{"users.username", "users.bio"}, []int{3, 1})
+
+	var total int64
+	if err := r.buildUserSearchQuery(ctx, match).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return []*UserSearchHit{}, 0, nil
+	}
+
+	var hits []*UserSearchHit
+	err := r.buildUserSearchQuery(ctx, match).
+		Select("users.*, "+match.score+" AS score", match.scoreArgs...).
+		Joins("LEFT JOIN user_profiles ON user_profiles.user_id = users.id").
+		Order("score DESC").
+		Order("user_profiles.follower_count DESC").
+		Order("users.id DESC").
+		Limit(opts.Limit).
+		Offset(opts.Offset).
+		Scan(&hits).Error
+	return hits, total, err
+}
+
+// buildUserSearchQuery builds the filtered query of a user search
+func (r *searchRepository) buildUserSearchQuery(ctx context.Context, match *keywordMatch) *gorm.DB {
+	query := r.db.WithContext(ctx).Table("users").Where("users.status = ?", "active")
+
+	if match.condition != "" {
+		query = query.Where(match.condition, match.conditionArgs...)
+	}
+
+	return query
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchKeyword(t *testing.T) {
+	columns, weights := []string{"title", "summary"}, []int{3, 1}
+
+	t.Run("No keyword matches everything", func(t *testing.T) {
+		m := matchKeyword("  ", true, columns, weights)
+		assert.Empty(t, m.condition)
+		assert.Equal(t, "0", m.score)
+	})
+
+	t.Run("FULLTEXT over all the columns", func(t *testing.T) {
+		m := matchKeyword("golang tips", true, columns, weights)
+		assert.Equal(t, "MATCH(title, summary) AGAINST (? IN NATURAL LANGUAGE MODE)", m.condition)
+		assert.Equal(t, m.condition, m.score)
+		assert.Equal(t, []interface{}{"golang tips"}, m.conditionArgs)
+	})
+
+	t.Run("LIKE for words too short to be indexed", func(t *testing.T) {
+		m := matchKeyword("go", true, columns, weights)
+		assert.Equal(t, "(title LIKE ? OR summary LIKE ?)", m.condition)
+		assert.Equal(t, "(CASE WHEN title LIKE ? THEN 3 ELSE 0 END + CASE WHEN summary LIKE ? THEN 1 ELSE 0 END)", m.score)
+		assert.Equal(t, []interface{}{"%go%", "%go%"}, m.scoreArgs)
+	})
+
+	t.Run("LIKE escapes wildcards", func(t *testing.T) {
+		m := matchKeyword(`100%_off\`, false, columns, weights)
+		assert.Equal(t, `%100\%\_off\\%`, m.conditionArgs[0])
+	})
+}
+
+func TestIsMissingFullTextIndex(t *testing.T) {
+	assert.True(t, IsMissingFullTextIndex(fmt.Errorf("search: %w", &mysql.MySQLError{Number: 1191})))
+	assert.False(t, IsMissingFullTextIndex(&mysql.MySQLError{Number: 1064}))
+	assert.False(t, IsMissingFullTextIndex(nil))
+}
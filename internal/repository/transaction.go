@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// txKey is the context key under which the current transaction is stored
+type txKey struct{}
+
+// Transactor runs a unit of work in one database transaction. Repositories that
+// read their connection through dbFor join the transaction carried by ctx, so
+// writes made by several repositories commit or roll back together.
+type Transactor interface {
+	// WithinTransaction calls fn with a context carrying a new transaction, committing when
+	// fn returns nil and rolling back otherwise. When ctx already carries a transaction,
+	// fn joins it instead of starting a nested one.
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// transactor implements Transactor interface
+type transactor struct {
+	db *gorm.DB
+}
+
+// NewTransactor creates a new transactor
+func NewTransactor(db *gorm.DB) Transactor {
+	return &transactor{db: db}
+}
+
+// WithinTransaction runs fn in a transaction
+func (t *transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+	return t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// dbFor returns the transaction carried by ctx, or db bound to ctx when there is none
+func dbFor(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/kobayashirei/airy/internal/models"
 	"gorm.io/gorm"
@@ -31,6 +32,8 @@ type UserRepository interface {
 	List(ctx context.Context, opts UserListOptions) ([]*models.User, error)
 	Count(ctx context.Context, opts UserListOptions) (int64, error)
 	CountByLastLogin(ctx context.Context, date string) (int64, error)
+	ListByID(ctx context.Context, status string, afterID int64, limit int) ([]*models.User, error)
+	ListUpdatedSince(ctx context.Context, since time.Time, afterID int64, limit int) ([]*models.User, error)
 }
 
 // userRepository implements UserRepository interface
@@ -179,3 +182,26 @@ func (r *userRepository) CountByLastLogin(ctx context.Context, date string) (int
 		Count(&count).Error
 	return count, err
 }
+
+// ListByID lists up to limit users with the status and IDs above afterID, by ID
+func (r *userRepository) ListByID(ctx context.Context, status string, afterID int64, limit int) ([]*models.User, error) {
+	var users []*models.User
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND id > ?", status, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
+
+// ListUpdatedSince lists up to limit users of any status updated since the given time
+// with IDs above afterID, by ID
+func (r *userRepository) ListUpdatedSince(ctx context.Context, since time.Time, afterID int64, limit int) ([]*models.User, error) {
+	var users []*models.User
+	err := r.db.WithContext(ctx).
+		Where("updated_at >= ? AND id > ?", since, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
@@ -32,13 +32,13 @@ func NewVoteRepository(db *gorm.DB) VoteRepository {
 
 // Create creates a new vote
 func (r *voteRepository) Create(ctx context.Context, vote *models.Vote) error {
-	return r.db.WithContext(ctx).Create(vote).Error
+	return dbFor(ctx, r.db).Create(vote).Error
 }
 
 // FindByID finds a vote by ID
 func (r *voteRepository) FindByID(ctx context.Context, id int64) (*models.Vote, error) {
 	var vote models.Vote
-	err := r.db.WithContext(ctx).Where("id = ?", id).First(&vote).Error
+	err := dbFor(ctx, r.db).Where("id = ?", id).First(&vote).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -51,7 +51,7 @@ func (r *voteRepository) FindByID(ctx context.Context, id int64) (*models.Vote,
 // FindByUserAndEntity finds a vote by user and entity
 func (r *voteRepository) FindByUserAndEntity(ctx context.Context, userID int64, entityType string, entityID int64) (*models.Vote, error) {
 	var vote models.Vote
-	err := r.db.WithContext(ctx).
+	err := dbFor(ctx, r.db).
 		Where("user_id = ? AND entity_type = ? AND entity_id = ?", userID, entityType, entityID).
 		First(&vote).Error
 	if err != nil {
@@ -65,7 +65,7 @@ func (r *voteRepository) FindByUserAndEntity(ctx context.Context, userID int64,
 
 // Update updates a vote
 func (r *voteRepository) Update(ctx context.Context, vote *models.Vote) error {
-	return r.db.WithContext(ctx).Save(vote).Error
+	return dbFor(ctx, r.db).Save(vote).Error
 }
 
 // Upsert creates or updates a vote (for idempotency)
@@ -88,12 +88,12 @@ func (r *voteRepository) Upsert(ctx context.Context, vote *models.Vote) error {
 
 // Delete deletes a vote by ID
 func (r *voteRepository) Delete(ctx context.Context, id int64) error {
-	return r.db.WithContext(ctx).Delete(&models.Vote{}, id).Error
+	return dbFor(ctx, r.db).Delete(&models.Vote{}, id).Error
 }
 
 // DeleteByUserAndEntity deletes a vote by user and entity
 func (r *voteRepository) DeleteByUserAndEntity(ctx context.Context, userID int64, entityType string, entityID int64) error {
-	return r.db.WithContext(ctx).
+	return dbFor(ctx, r.db).
 		Where("user_id = ? AND entity_type = ? AND entity_id = ?", userID, entityType, entityID).
 		Delete(&models.Vote{}).Error
 }
@@ -101,7 +101,7 @@ func (r *voteRepository) DeleteByUserAndEntity(ctx context.Context, userID int64
 // CountByEntity counts votes for an entity by vote type
 func (r *voteRepository) CountByEntity(ctx context.Context, entityType string, entityID int64, voteType string) (int64, error) {
 	var count int64
-	query := r.db.WithContext(ctx).Model(&models.Vote{}).
+	query := dbFor(ctx, r.db).Model(&models.Vote{}).
 		Where("entity_type = ? AND entity_id = ?", entityType, entityID)
 	
 	if voteType != "" {
@@ -0,0 +1,257 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kobayashirei/airy/internal/auth"
+	"github.com/kobayashirei/airy/internal/config"
+	"github.com/kobayashirei/airy/internal/database"
+	"github.com/kobayashirei/airy/internal/middleware"
+	"github.com/kobayashirei/airy/internal/models"
+	"github.com/kobayashirei/airy/internal/repository"
+	"github.com/kobayashirei/airy/internal/service"
+)
+
+// Access describes how a route is protected
+type Access int
+
+const (
+	// AccessPublic routes need no authentication
+	AccessPublic Access = iota
+	// AccessOptional routes identify the user when a valid token is sent
+	AccessOptional
+	// AccessAuthenticated routes need a valid token
+	AccessAuthenticated
+	// AccessPermission routes need a global permission held by the token roles
+	AccessPermission
+	// AccessCirclePermission routes need a permission within the circle named by CircleParam
+	AccessCirclePermission
+)
+
+// RoutePolicy is the guard applied to a single route
+type RoutePolicy struct {
+	Access      Access
+	Permission  string
+	CircleParam string
+}
+
+// routePolicies maps "METHOD /full/path" to the guard of that route.
+// Every route registered through routeGuard.handle must be listed here.
+var routePolicies = map[string]RoutePolicy{
+	// Auth
+	"POST /api/v1/auth/register":          {Access: AccessPublic},
+	"POST /api/v1/auth/activate":          {Access: AccessPublic},
+	"POST /api/v1/auth/login":             {Access: AccessPublic},
+	"POST /api/v1/auth/login/code":        {Access: AccessPublic},
+	"POST /api/v1/auth/refresh":           {Access: AccessPublic},
+	"POST /api/v1/auth/resend-activation": {Access: AccessPublic},
+
+	// Circles
+	"GET /api/v1/circles/:id":                          {Access: AccessPublic},
+	"GET /api/v1/circles/:id/members":                  {Access: AccessPublic},
+	"POST /api/v1/circles":                             {Access: AccessPermission, Permission: models.PermCircleCreate},
+	"POST /api/v1/circles/:id/join":                    {Access: AccessAuthenticated},
+	"POST /api/v1/circles/:id/members/:userId/approve": {Access: AccessCirclePermission, Permission: models.PermCircleManageMembers, CircleParam: "id"},
+	"POST /api/v1/circles/:id/moderators":              {Access: AccessCirclePermission, Permission: models.PermCircleAssignModerator, CircleParam: "id"},
+	"GET /api/v1/circles/:id/feed":                     {Access: AccessPublic},
+
+	// Notifications
+	"GET /api/v1/notifications":              {Access: AccessAuthenticated},
+	"GET /api/v1/notifications/unread-count": {Access: AccessAuthenticated},
+	"PUT /api/v1/notifications/:id/read":     {Access: AccessAuthenticated},
+	"PUT /api/v1/notifications/read-all":     {Access: AccessAuthenticated},
+
+	// Conversations
+	"GET /api/v1/conversations":               {Access: AccessAuthenticated},
+	"POST /api/v1/conversations":              {Access: AccessAuthenticated},
+	"GET /api/v1/conversations/:id/messages":  {Access: AccessAuthenticated},
+	"POST /api/v1/conversations/:id/messages": {Access: AccessAuthenticated},
+
+	// User profiles
+	"GET /api/v1/users/:id/profile": {Access: AccessPublic},
+	"GET /api/v1/users/:id/posts":   {Access: AccessPublic},
+	"PUT /api/v1/users/profile":     {Access: AccessAuthenticated},
+
+	// Follows
+	"GET /api/v1/users/:id/followers": {Access: AccessPublic},
+	"GET /api/v1/users/:id/following": {Access: AccessPublic},
+	"GET /api/v1/users/:id/follow":    {Access: AccessAuthenticated},
+	"POST /api/v1/users/:id/follow":   {Access: AccessAuthenticated},
+	"DELETE /api/v1/users/:id/follow": {Access: AccessAuthenticated},
+
+	// Favorites
+	"GET /api/v1/posts/:id/favorite":           {Access: AccessAuthenticated},
+	"POST /api/v1/posts/:id/favorite":          {Access: AccessAuthenticated},
+	"DELETE /api/v1/posts/:id/favorite":        {Access: AccessAuthenticated},
+	"GET /api/v1/favorites":                    {Access: AccessAuthenticated},
+	"GET /api/v1/favorites/collections":        {Access: AccessAuthenticated},
+	"POST /api/v1/favorites/collections":       {Access: AccessAuthenticated},
+	"PUT /api/v1/favorites/collections/:id":    {Access: AccessAuthenticated},
+	"DELETE /api/v1/favorites/collections/:id": {Access: AccessAuthenticated},
+
+	// Admin
+	"GET /api/v1/admin/dashboard":                {Access: AccessPermission, Permission: models.PermAdminAccess},
+	"GET /api/v1/admin/users":                    {Access: AccessPermission, Permission: models.PermAdminAccess},
+	"POST /api/v1/admin/users/:id/ban":           {Access: AccessPermission, Permission: models.PermUserBan},
+	"POST /api/v1/admin/users/:id/unban":         {Access: AccessPermission, Permission: models.PermUserBan},
+	"GET /api/v1/admin/posts":                    {Access: AccessPermission, Permission: models.PermAdminAccess},
+	"POST /api/v1/admin/posts/batch-review":      {Access: AccessPermission, Permission: models.PermPostReview},
+	"GET /api/v1/admin/logs":                     {Access: AccessPermission, Permission: models.PermAdminLogRead},
+	"GET /api/v1/admin/dead-letters":             {Access: AccessPermission, Permission: models.PermAdminAccess},
+	"DELETE /api/v1/admin/dead-letters":          {Access: AccessPermission, Permission: models.PermDeadLetterManage},
+	"GET /api/v1/admin/dead-letters/:id":         {Access: AccessPermission, Permission: models.PermAdminAccess},
+	"DELETE /api/v1/admin/dead-letters/:id":      {Access: AccessPermission, Permission: models.PermDeadLetterManage},
+	"POST /api/v1/admin/dead-letters/:id/replay": {Access: AccessPermission, Permission: models.PermDeadLetterManage},
+	"GET /api/v1/admin/workers":                  {Access: AccessPermission, Permission: models.PermAdminAccess},
+	"GET /api/v1/admin/jobs":                     {Access: AccessPermission, Permission: models.PermAdminAccess},
+	"DELETE /api/v1/admin/jobs/dead/:id":         {Access: AccessPermission, Permission: models.PermDeadLetterManage},
+	"POST /api/v1/admin/jobs/dead/:id/retry":     {Access: AccessPermission, Permission: models.PermDeadLetterManage},
+	"GET /api/v1/admin/hotness":                  {Access: AccessPermission, Permission: models.PermAdminAccess},
+	"POST /api/v1/admin/hotness/preview":         {Access: AccessPermission, Permission: models.PermAdminAccess},
+	"PUT /api/v1/admin/circles/:id/hotness":      {Access: AccessPermission, Permission: models.PermHotnessManage},
+	"DELETE /api/v1/admin/circles/:id/hotness":   {Access: AccessPermission, Permission: models.PermHotnessManage},
+
+	// Posts (ownership of updates and deletes is checked by PostService)
+	"GET /api/v1/posts":                 {Access: AccessPublic},
+	"GET /api/v1/posts/:id":             {Access: AccessOptional},
+	"POST /api/v1/posts":                {Access: AccessPermission, Permission: models.PermPostCreate},
+	"PUT /api/v1/posts/:id":             {Access: AccessAuthenticated},
+	"DELETE /api/v1/posts/:id":          {Access: AccessAuthenticated},
+	"PUT /api/v1/posts/:id/schedule":    {Access: AccessAuthenticated},
+	"DELETE /api/v1/posts/:id/schedule": {Access: AccessAuthenticated},
+
+	// Comments (ownership of deletes is checked by CommentService)
+	"GET /api/v1/posts/:id/comments":  {Access: AccessOptional},
+	"POST /api/v1/posts/:id/comments": {Access: AccessPermission, Permission: models.PermCommentCreate},
+	"DELETE /api/v1/comments/:id":     {Access: AccessAuthenticated},
+
+	// Votes
+	"POST /api/v1/votes":                        {Access: AccessPermission, Permission: models.PermVoteCast},
+	"DELETE /api/v1/votes":                      {Access: AccessAuthenticated},
+	"GET /api/v1/votes/:entity_type/:entity_id": {Access: AccessAuthenticated},
+
+	// Feed
+	"GET /api/v1/feed": {Access: AccessAuthenticated},
+
+	// Search
+	"GET /api/v1/search/posts": {Access: AccessPublic},
+	"GET /api/v1/search/users": {Access: AccessPublic},
+}
+
+var (
+	guardedRoutesMu sync.Mutex
+	// guardedRoutes records every route registered through routeGuard.handle
+	guardedRoutes = make(map[string]bool)
+)
+
+// routeGuard builds the middleware chain for a route from routePolicies
+type routeGuard struct {
+	jwtService        auth.JWTService
+	permissionService service.PermissionService
+}
+
+// newRouteGuard creates a route guard backed by the JWT secret and the RBAC tables
+func newRouteGuard(cfg *config.Config) *routeGuard {
+	db := database.GetDB()
+	return &routeGuard{
+		jwtService: auth.NewJWTService(cfg.JWT.Secret, cfg.JWT.Expiration),
+		permissionService: service.NewPermissionService(
+			repository.NewPermissionRepository(db),
+			repository.NewRoleRepository(db),
+			repository.NewUserRoleRepository(db),
+		),
+	}
+}
+
+// handle registers a route with the guard declared for it in routePolicies.
+// It panics when the route has no policy so a missing entry fails at startup.
+func (g *routeGuard) handle(group *gin.RouterGroup, method, relativePath string, handler gin.HandlerFunc) {
+	key := routeKey(method, joinPath(group.BasePath(), relativePath))
+	policy, ok := routePolicies[key]
+	if !ok {
+		panic(fmt.Sprintf("router: no route policy declared for %s", key))
+	}
+
+	handlers := append(g.middlewareFor(policy), handler)
+	group.Handle(method, relativePath, handlers...)
+
+	guardedRoutesMu.Lock()
+	guardedRoutes[key] = true
+	guardedRoutesMu.Unlock()
+}
+
+// middlewareFor returns the middleware chain enforcing a policy
+func (g *routeGuard) middlewareFor(policy RoutePolicy) []gin.HandlerFunc {
+	switch policy.Access {
+	case AccessOptional:
+		return []gin.HandlerFunc{middleware.OptionalAuthMiddleware(g.jwtService)}
+	case AccessAuthenticated:
+		return []gin.HandlerFunc{middleware.AuthMiddleware(g.jwtService)}
+	case AccessPermission:
+		return []gin.HandlerFunc{
+			middleware.AuthMiddleware(g.jwtService),
+			middleware.RequirePermission(g.permissionService, policy.Permission),
+		}
+	case AccessCirclePermission:
+		return []gin.HandlerFunc{
+			middleware.AuthMiddleware(g.jwtService),
+			middleware.RequireCirclePermission(g.permissionService, policy.Permission, policy.CircleParam),
+		}
+	default:
+		return nil
+	}
+}
+
+// ValidateRouteGuards checks that every mutating route was registered through the
+// route policy table. Public mutating routes must be declared explicitly as such.
+func ValidateRouteGuards(routes gin.RoutesInfo) error {
+	guardedRoutesMu.Lock()
+	defer guardedRoutesMu.Unlock()
+
+	var unguarded []string
+	for _, route := range routes {
+		if !isMutatingMethod(route.Method) {
+			continue
+		}
+		key := routeKey(route.Method, route.Path)
+		if !guardedRoutes[key] {
+			unguarded = append(unguarded, key)
+		}
+	}
+
+	if len(unguarded) > 0 {
+		sort.Strings(unguarded)
+		return fmt.Errorf("mutating routes without a declared guard: %s", strings.Join(unguarded, ", "))
+	}
+	return nil
+}
+
+// isMutatingMethod reports whether an HTTP method changes server state
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// routeKey builds the routePolicies key for a route
+func routeKey(method, fullPath string) string {
+	return method + " " + fullPath
+}
+
+// joinPath joins a group base path and a relative route path like gin does
+func joinPath(basePath, relativePath string) string {
+	if relativePath == "" {
+		return basePath
+	}
+	return path.Join(basePath, relativePath)
+}
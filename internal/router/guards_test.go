@@ -0,0 +1,122 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kobayashirei/airy/internal/config"
+)
+
+func newTestConfig() *config.Config {
+	return &config.Config{
+		JWT: config.JWTConfig{
+			Secret:     "test-secret",
+			Expiration: time.Hour,
+		},
+		Cache: config.CacheConfig{
+			DefaultExpiration: time.Minute,
+		},
+	}
+}
+
+func setupAllRoutes(cfg *config.Config) *gin.Engine {
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		c.Set("request_id", "test-request-id")
+		c.Next()
+	})
+	v1 := engine.Group("/api/v1")
+	deps := &Dependencies{}
+
+	SetupAuthRoutes(v1, cfg)
+	SetupCircleRoutes(v1, cfg)
+	SetupNotificationRoutes(v1, cfg)
+	SetupMessageRoutes(v1, cfg)
+	SetupUserProfileRoutes(v1, cfg)
+	SetupFollowRoutes(v1, cfg, deps)
+	SetupFavoriteRoutes(v1, cfg, deps)
+	SetupAdminRoutes(v1, cfg, deps)
+	SetupPostRoutes(v1, cfg, deps)
+	SetupCommentRoutes(v1, cfg, deps)
+	SetupVoteRoutes(v1, cfg, deps)
+	SetupFeedRoutes(v1, cfg, deps)
+	SetupSearchRoutes(v1, cfg, deps)
+
+	return engine
+}
+
+func TestValidateRouteGuards_AllRoutesGuarded(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	engine := setupAllRoutes(newTestConfig())
+
+	if err := ValidateRouteGuards(engine.Routes()); err != nil {
+		t.Fatalf("Expected all mutating routes to be guarded, got: %v", err)
+	}
+}
+
+func TestValidateRouteGuards_DetectsUnguardedRoute(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	engine := gin.New()
+	engine.POST("/api/v1/unguarded", func(c *gin.Context) {})
+	engine.GET("/api/v1/readonly", func(c *gin.Context) {})
+
+	err := ValidateRouteGuards(engine.Routes())
+	if err == nil {
+		t.Fatal("Expected an error for the unguarded POST route")
+	}
+	if !strings.Contains(err.Error(), "POST /api/v1/unguarded") {
+		t.Errorf("Expected error to name the unguarded route, got: %v", err)
+	}
+	if strings.Contains(err.Error(), "/api/v1/readonly") {
+		t.Errorf("Expected GET routes to be ignored, got: %v", err)
+	}
+}
+
+func TestRouteGuard_PanicsWithoutPolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected handle to panic for a route without a policy")
+		}
+	}()
+
+	engine := gin.New()
+	guard := newRouteGuard(newTestConfig())
+	guard.handle(engine.Group("/api/v1"), http.MethodPost, "/not-declared", func(c *gin.Context) {})
+}
+
+func TestRouteGuard_RejectsAnonymousRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	engine := setupAllRoutes(newTestConfig())
+
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPost, "/api/v1/posts"},
+		{http.MethodPost, "/api/v1/admin/users/1/ban"},
+		{http.MethodPost, "/api/v1/circles/1/moderators"},
+		{http.MethodPut, "/api/v1/users/profile"},
+		{http.MethodPost, "/api/v1/users/1/follow"},
+		{http.MethodGet, "/api/v1/notifications"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s %s: expected status 401, got %d", tt.method, tt.path, w.Code)
+		}
+	}
+}
@@ -10,10 +10,138 @@ import (
 	"github.com/kobayashirei/airy/internal/config"
 	"github.com/kobayashirei/airy/internal/database"
 	"github.com/kobayashirei/airy/internal/handler"
+	appLogger "github.com/kobayashirei/airy/internal/logger"
+	"github.com/kobayashirei/airy/internal/mq"
 	"github.com/kobayashirei/airy/internal/repository"
+	"github.com/kobayashirei/airy/internal/search"
 	"github.com/kobayashirei/airy/internal/service"
+	"github.com/kobayashirei/airy/internal/taskpool"
 )
 
+// Dependencies holds the shared infrastructure built once in main.
+// Any field may be nil when the backend is disabled or unreachable;
+// the services fall back to a degraded mode in that case.
+type Dependencies struct {
+	MessageQueue mq.MessageQueue
+	// Outbox records events in the transactional outbox for the relay to publish; nil disables it
+	Outbox       mq.MessageQueue
+	TaskPool     *taskpool.Pool
+	SearchClient *search.Client
+	// Workers runs the message queue consumers; nil on API-only replicas
+	Workers *service.WorkerManager
+	// Jobs is the durable job queue; nil without Redis
+	Jobs *taskpool.JobQueue
+	// Producer names this service in the envelopes of the events it publishes
+	Producer string
+
+	// hotness is shared by everything in the process that scores posts; see hotnessStrategies
+	hotness *service.HotnessStrategies
+	// search is shared by everything in the process that searches or indexes; see searchService
+	search service.SearchService
+}
+
+// deadLetterQueue returns the message queue as a dead-letter queue, or nil when it has none
+func (d *Dependencies) deadLetterQueue() mq.DeadLetterQueue {
+	if dlq, ok := d.MessageQueue.(mq.DeadLetterQueue); ok {
+		return dlq
+	}
+	return nil
+}
+
+// eventQueue returns where services publish events that must not be lost: the outbox
+// when configured, so events commit with the change that raised them, else the queue itself
+func (d *Dependencies) eventQueue() mq.MessageQueue {
+	if d.Outbox != nil {
+		return d.envelopes(d.Outbox)
+	}
+	return d.envelopes(d.MessageQueue)
+}
+
+// envelopes returns a queue that publishes events in versioned envelopes, or nil without a queue
+func (d *Dependencies) envelopes(messageQueue mq.MessageQueue) mq.MessageQueue {
+	if messageQueue == nil {
+		return nil
+	}
+	return mq.NewEnvelopeQueue(messageQueue, mq.DefaultRegistry, d.Producer)
+}
+
+// hotnessStrategies returns the hotness strategies of the process, created on first use,
+// so a circle override changed through the admin API applies to its own workers at once
+func (d *Dependencies) hotnessStrategies(cfg *config.Config) *service.HotnessStrategies {
+	if d.hotness == nil {
+		weights := cfg.Hotness.Weights
+		defaults := service.HotnessSettings{
+			Algorithm: service.HotnessAlgorithm(cfg.Hotness.Algorithm),
+			Weights: &service.HotnessWeights{
+				Upvote:   weights.Upvote,
+				Downvote: weights.Downvote,
+				Comment:  weights.Comment,
+				Favorite: weights.Favorite,
+				View:     weights.View,
+			},
+			Gravity: cfg.Hotness.Gravity,
+		}
+		d.hotness = service.NewHotnessStrategies(defaults, repository.NewCircleHotnessRepository(database.GetDB()),
+			cfg.Hotness.OverridesRefresh, appLogger.Logger)
+	}
+	return d.hotness
+}
+
+// postScheduler returns a scheduler of post publishing, or nil without the job queue,
+// when posts cannot be scheduled
+func (d *Dependencies) postScheduler(postRepo repository.PostRepository) *service.PostScheduler {
+	if d.Jobs == nil {
+		return nil
+	}
+	return service.NewPostScheduler(d.Jobs, postRepo, appLogger.Logger)
+}
+
+// searchService returns the search service on the configured backend, built on first use
+// so the whole process shares its circuit breaker. With Elasticsearch and the fallback
+// enabled, searches fail over to MySQL while Elasticsearch is unavailable; without a
+// search client they go to MySQL from the start.
+func (d *Dependencies) searchService(cfg *config.Config) service.SearchService {
+	if d.search != nil {
+		return d.search
+	}
+
+	db := database.GetDB()
+	var mysqlBackend service.SearchBackend
+	if db != nil {
+		mysqlBackend = service.NewMySQLSearchBackend(repository.NewSearchRepository(db), repository.NewBatchRepository(db),
+			cfg.Search.MySQLFullText, appLogger.Logger)
+	}
+
+	var primary, fallback service.SearchBackend
+	if cfg.Search.Backend == config.SearchBackendMySQL {
+		primary = mysqlBackend
+	} else {
+		if d.SearchClient != nil {
+			primary = service.NewESSearchBackend(d.SearchClient, repository.NewUserRepository(db),
+				repository.NewPostRepository(db), repository.NewCircleRepository(db), appLogger.Logger)
+		}
+		if cfg.Search.Fallback {
+			fallback = mysqlBackend
+		}
+	}
+
+	d.search = service.NewSearchService(primary, fallback, service.SearchBreakerOptions{
+		Failures:      cfg.Search.BreakerFailures,
+		OpenDuration:  cfg.Search.BreakerOpen,
+		HealthTimeout: cfg.Search.HealthTimeout,
+	}, appLogger.Logger)
+	return d.search
+}
+
+// SearchStatus reports which backend serves searches, or nil when the process serves none
+func (d *Dependencies) SearchStatus() *service.SearchStatus {
+	if d.search == nil {
+		return nil
+	}
+	status := d.search.Status()
+	return &status
+}
+
 // SetupAuthRoutes sets up authentication routes
 func SetupAuthRoutes(router *gin.RouterGroup, cfg *config.Config) {
 	// Initialize dependencies
@@ -22,25 +150,28 @@ func SetupAuthRoutes(router *gin.RouterGroup, cfg *config.Config) {
 
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
+	userRoleRepo := repository.NewUserRoleRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
 
 	// Initialize services
 	emailService := service.NewEmailService()
 	authJWTService := auth.NewJWTService(cfg.JWT.Secret, cfg.JWT.Expiration)
 	jwtService := service.NewJWTService(authJWTService, 7*24*time.Hour) // 7 days for refresh token
-	userService := service.NewUserService(userRepo, cacheService, emailService, jwtService)
+	userService := service.NewUserService(userRepo, cacheService, emailService, jwtService, userRoleRepo, roleRepo)
 
 	// Initialize handlers
 	authHandler := handler.NewAuthHandler(userService)
 
-	// Auth routes
+	// Auth routes (public, see routePolicies)
+	guard := newRouteGuard(cfg)
 	authGroup := router.Group("/auth")
 	{
-		authGroup.POST("/register", authHandler.Register)
-		authGroup.POST("/activate", authHandler.Activate)
-		authGroup.POST("/login", authHandler.Login)
-		authGroup.POST("/login/code", authHandler.LoginWithCode)
-		authGroup.POST("/refresh", authHandler.RefreshToken)
-		authGroup.POST("/resend-activation", authHandler.ResendActivation)
+		guard.handle(authGroup, "POST", "/register", authHandler.Register)
+		guard.handle(authGroup, "POST", "/activate", authHandler.Activate)
+		guard.handle(authGroup, "POST", "/login", authHandler.Login)
+		guard.handle(authGroup, "POST", "/login/code", authHandler.LoginWithCode)
+		guard.handle(authGroup, "POST", "/refresh", authHandler.RefreshToken)
+		guard.handle(authGroup, "POST", "/resend-activation", authHandler.ResendActivation)
 	}
 }
 
@@ -69,18 +200,18 @@ func SetupCircleRoutes(router *gin.RouterGroup, cfg *config.Config) {
 	circleHandler := handler.NewCircleHandler(circleService)
 
 	// Circle routes
+	guard := newRouteGuard(cfg)
 	circleGroup := router.Group("/circles")
 	{
 		// Public routes
-		circleGroup.GET("/:id", circleHandler.GetCircle)
-		circleGroup.GET("/:id/members", circleHandler.GetCircleMembers)
-
-		// Protected routes (require authentication)
-		// Note: In production, these should use the auth middleware
-		circleGroup.POST("", circleHandler.CreateCircle)
-		circleGroup.POST("/:id/join", circleHandler.JoinCircle)
-		circleGroup.POST("/:id/members/:userId/approve", circleHandler.ApproveMember)
-		circleGroup.POST("/:id/moderators", circleHandler.AssignModerator)
+		guard.handle(circleGroup, "GET", "/:id", circleHandler.GetCircle)
+		guard.handle(circleGroup, "GET", "/:id/members", circleHandler.GetCircleMembers)
+
+		// Protected routes (guards declared in routePolicies)
+		guard.handle(circleGroup, "POST", "", circleHandler.CreateCircle)
+		guard.handle(circleGroup, "POST", "/:id/join", circleHandler.JoinCircle)
+		guard.handle(circleGroup, "POST", "/:id/members/:userId/approve", circleHandler.ApproveMember)
+		guard.handle(circleGroup, "POST", "/:id/moderators", circleHandler.AssignModerator)
 	}
 }
 
@@ -107,13 +238,13 @@ func SetupNotificationRoutes(router *gin.RouterGroup, cfg *config.Config) {
 	notificationHandler := handler.NewNotificationHandler(notificationService)
 
 	// Notification routes (all require authentication)
+	guard := newRouteGuard(cfg)
 	notificationGroup := router.Group("/notifications")
 	{
-		// Note: In production, these should use the auth middleware
-		notificationGroup.GET("", notificationHandler.GetNotifications)
-		notificationGroup.GET("/unread-count", notificationHandler.GetUnreadCount)
-		notificationGroup.PUT("/:id/read", notificationHandler.MarkAsRead)
-		notificationGroup.PUT("/read-all", notificationHandler.MarkAllAsRead)
+		guard.handle(notificationGroup, "GET", "", notificationHandler.GetNotifications)
+		guard.handle(notificationGroup, "GET", "/unread-count", notificationHandler.GetUnreadCount)
+		guard.handle(notificationGroup, "PUT", "/:id/read", notificationHandler.MarkAsRead)
+		guard.handle(notificationGroup, "PUT", "/read-all", notificationHandler.MarkAllAsRead)
 	}
 }
 
@@ -138,13 +269,13 @@ func SetupMessageRoutes(router *gin.RouterGroup, cfg *config.Config) {
 	messageHandler := handler.NewMessageHandler(messageService)
 
 	// Conversation routes (all require authentication)
+	guard := newRouteGuard(cfg)
 	conversationGroup := router.Group("/conversations")
 	{
-		// Note: In production, these should use the auth middleware
-		conversationGroup.GET("", messageHandler.GetConversations)
-		conversationGroup.POST("", messageHandler.CreateConversation)
-		conversationGroup.GET("/:id/messages", messageHandler.GetMessages)
-		conversationGroup.POST("/:id/messages", messageHandler.SendMessage)
+		guard.handle(conversationGroup, "GET", "", messageHandler.GetConversations)
+		guard.handle(conversationGroup, "POST", "", messageHandler.CreateConversation)
+		guard.handle(conversationGroup, "GET", "/:id/messages", messageHandler.GetMessages)
+		guard.handle(conversationGroup, "POST", "/:id/messages", messageHandler.SendMessage)
 	}
 }
 
@@ -173,28 +304,98 @@ func SetupUserProfileRoutes(router *gin.RouterGroup, cfg *config.Config) {
 	userProfileHandler := handler.NewUserProfileHandler(userProfileService)
 
 	// User profile routes
+	guard := newRouteGuard(cfg)
 	userGroup := router.Group("/users")
 	{
 		// Public routes
-		userGroup.GET("/:id/profile", userProfileHandler.GetProfile)
-		userGroup.GET("/:id/posts", userProfileHandler.GetUserPosts)
+		guard.handle(userGroup, "GET", "/:id/profile", userProfileHandler.GetProfile)
+		guard.handle(userGroup, "GET", "/:id/posts", userProfileHandler.GetUserPosts)
 
 		// Protected routes (require authentication)
-		// Note: In production, these should use the auth middleware
-		userGroup.PUT("/profile", userProfileHandler.UpdateProfile)
+		guard.handle(userGroup, "PUT", "/profile", userProfileHandler.UpdateProfile)
+	}
+}
+
+// SetupFollowRoutes sets up follow graph routes
+func SetupFollowRoutes(router *gin.RouterGroup, cfg *config.Config, deps *Dependencies) {
+	// Initialize dependencies
+	db := database.GetDB()
+	cacheService := cache.NewCacheService(cache.GetClient(), cfg.Cache.DefaultExpiration)
+
+	// Initialize repositories
+	followRepo := repository.NewFollowRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	batchRepo := repository.NewBatchRepository(db)
+
+	// Initialize services
+	followService := service.NewFollowService(followRepo, userRepo, batchRepo, repository.NewTransactor(db), cacheService,
+		deps.eventQueue(), appLogger.Logger)
+
+	// Initialize handlers
+	followHandler := handler.NewFollowHandler(followService)
+
+	// Follow routes
+	guard := newRouteGuard(cfg)
+	userGroup := router.Group("/users")
+	{
+		guard.handle(userGroup, "GET", "/:id/followers", followHandler.GetFollowers)
+		guard.handle(userGroup, "GET", "/:id/following", followHandler.GetFollowing)
+		guard.handle(userGroup, "GET", "/:id/follow", followHandler.GetFollowStatus)
+		guard.handle(userGroup, "POST", "/:id/follow", followHandler.Follow)
+		guard.handle(userGroup, "DELETE", "/:id/follow", followHandler.Unfollow)
+	}
+}
+
+// SetupFavoriteRoutes sets up favorite and favorite collection routes
+func SetupFavoriteRoutes(router *gin.RouterGroup, cfg *config.Config, deps *Dependencies) {
+	// Initialize dependencies
+	db := database.GetDB()
+
+	// Initialize repositories
+	favoriteRepo := repository.NewFavoriteRepository(db)
+	collectionRepo := repository.NewFavoriteCollectionRepository(db)
+	postRepo := repository.NewPostRepository(db)
+	batchRepo := repository.NewBatchRepository(db)
+
+	// Initialize services
+	favoriteService := service.NewFavoriteService(favoriteRepo, collectionRepo, postRepo, batchRepo, repository.NewTransactor(db),
+		deps.eventQueue(), appLogger.Logger)
+
+	// Initialize handlers
+	favoriteHandler := handler.NewFavoriteHandler(favoriteService)
+
+	// Favorite routes
+	guard := newRouteGuard(cfg)
+	postGroup := router.Group("/posts")
+	{
+		guard.handle(postGroup, "GET", "/:id/favorite", favoriteHandler.GetFavoriteStatus)
+		guard.handle(postGroup, "POST", "/:id/favorite", favoriteHandler.Favorite)
+		guard.handle(postGroup, "DELETE", "/:id/favorite", favoriteHandler.Unfavorite)
+	}
+
+	favoriteGroup := router.Group("/favorites")
+	{
+		guard.handle(favoriteGroup, "GET", "", favoriteHandler.ListFavorites)
+		guard.handle(favoriteGroup, "GET", "/collections", favoriteHandler.ListCollections)
+		guard.handle(favoriteGroup, "POST", "/collections", favoriteHandler.CreateCollection)
+		guard.handle(favoriteGroup, "PUT", "/collections/:id", favoriteHandler.UpdateCollection)
+		guard.handle(favoriteGroup, "DELETE", "/collections/:id", favoriteHandler.DeleteCollection)
 	}
 }
 
 // SetupAdminRoutes sets up admin management routes
-func SetupAdminRoutes(router *gin.RouterGroup, cfg *config.Config) {
+func SetupAdminRoutes(router *gin.RouterGroup, cfg *config.Config, deps *Dependencies) {
 	// Initialize dependencies
 	db := database.GetDB()
+	cacheService := cache.NewCacheService(cache.GetClient(), cfg.Cache.DefaultExpiration)
 
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
 	postRepo := repository.NewPostRepository(db)
 	commentRepo := repository.NewCommentRepository(db)
 	adminLogRepo := repository.NewAdminLogRepository(db)
+	circleRepo := repository.NewCircleRepository(db)
+	batchRepo := repository.NewBatchRepository(db)
 
 	// Initialize services
 	adminService := service.NewAdminService(
@@ -202,22 +403,196 @@ func SetupAdminRoutes(router *gin.RouterGroup, cfg *config.Config) {
 		postRepo,
 		commentRepo,
 		adminLogRepo,
+		repository.NewTransactor(db),
+		cacheService,
+		deps.eventQueue(),
+		deps.postScheduler(postRepo),
+		appLogger.Logger,
+	)
+
+	deadLetterService := service.NewDeadLetterService(
+		repository.NewDeadLetterRepository(db),
+		adminLogRepo,
+		deps.deadLetterQueue(),
+	)
+
+	hotnessAdminService := service.NewHotnessAdminService(
+		deps.hotnessStrategies(cfg),
+		repository.NewCircleHotnessRepository(db),
+		circleRepo,
+		postRepo,
+		batchRepo,
+		adminLogRepo,
 	)
 
 	// Initialize handlers
 	adminHandler := handler.NewAdminHandler(adminService)
+	hotnessHandler := handler.NewHotnessHandler(hotnessAdminService)
+	deadLetterHandler := handler.NewDeadLetterHandler(deadLetterService)
+	workerHandler := handler.NewWorkerHandler(deps.Workers)
+	jobHandler := handler.NewJobHandler(deps.Jobs)
 
 	// Admin routes (all require authentication and admin permissions)
+	guard := newRouteGuard(cfg)
 	adminGroup := router.Group("/admin")
 	{
-		// Note: In production, these should use the auth middleware and RBAC middleware
-		// to ensure only administrators can access these endpoints
-		adminGroup.GET("/dashboard", adminHandler.GetDashboard)
-		adminGroup.GET("/users", adminHandler.ListUsers)
-		adminGroup.POST("/users/:id/ban", adminHandler.BanUser)
-		adminGroup.POST("/users/:id/unban", adminHandler.UnbanUser)
-		adminGroup.GET("/posts", adminHandler.ListPosts)
-		adminGroup.POST("/posts/batch-review", adminHandler.BatchReviewPosts)
-		adminGroup.GET("/logs", adminHandler.ListLogs)
+		guard.handle(adminGroup, "GET", "/dashboard", adminHandler.GetDashboard)
+		guard.handle(adminGroup, "GET", "/users", adminHandler.ListUsers)
+		guard.handle(adminGroup, "POST", "/users/:id/ban", adminHandler.BanUser)
+		guard.handle(adminGroup, "POST", "/users/:id/unban", adminHandler.UnbanUser)
+		guard.handle(adminGroup, "GET", "/posts", adminHandler.ListPosts)
+		guard.handle(adminGroup, "POST", "/posts/batch-review", adminHandler.BatchReviewPosts)
+		guard.handle(adminGroup, "GET", "/logs", adminHandler.ListLogs)
+		guard.handle(adminGroup, "GET", "/dead-letters", deadLetterHandler.ListDeadLetters)
+		guard.handle(adminGroup, "DELETE", "/dead-letters", deadLetterHandler.PurgeDeadLetters)
+		guard.handle(adminGroup, "GET", "/dead-letters/:id", deadLetterHandler.GetDeadLetter)
+		guard.handle(adminGroup, "DELETE", "/dead-letters/:id", deadLetterHandler.DeleteDeadLetter)
+		guard.handle(adminGroup, "POST", "/dead-letters/:id/replay", deadLetterHandler.ReplayDeadLetter)
+		guard.handle(adminGroup, "GET", "/workers", workerHandler.ListWorkers)
+		guard.handle(adminGroup, "GET", "/jobs", jobHandler.GetJobs)
+		guard.handle(adminGroup, "DELETE", "/jobs/dead/:id", jobHandler.DeleteDeadJob)
+		guard.handle(adminGroup, "POST", "/jobs/dead/:id/retry", jobHandler.RetryDeadJob)
+		guard.handle(adminGroup, "GET", "/hotness", hotnessHandler.GetSettings)
+		guard.handle(adminGroup, "POST", "/hotness/preview", hotnessHandler.PreviewSettings)
+		guard.handle(adminGroup, "PUT", "/circles/:id/hotness", hotnessHandler.SetCircleSettings)
+		guard.handle(adminGroup, "DELETE", "/circles/:id/hotness", hotnessHandler.DeleteCircleSettings)
+	}
+}
+
+// SetupPostRoutes sets up post routes
+func SetupPostRoutes(router *gin.RouterGroup, cfg *config.Config, deps *Dependencies) {
+	// Initialize dependencies
+	db := database.GetDB()
+	cacheService := cache.NewCacheService(cache.GetClient(), cfg.Cache.DefaultExpiration)
+
+	// Initialize repositories
+	postRepo := repository.NewPostRepository(db)
+	transactor := repository.NewTransactor(db)
+
+	// Initialize services
+	postService := service.NewPostService(
+		postRepo,
+		transactor,
+		cacheService,
+		service.NewContentModerationService(),
+		deps.eventQueue(),
+		deps.TaskPool,
+		deps.postScheduler(postRepo),
+	)
+
+	// Initialize handlers
+	postHandler := handler.NewPostHandler(postService)
+
+	// Post routes
+	guard := newRouteGuard(cfg)
+	postGroup := router.Group("/posts")
+	{
+		// Public routes
+		guard.handle(postGroup, "GET", "", postHandler.ListPosts)
+		guard.handle(postGroup, "GET", "/:id", postHandler.GetPost)
+
+		// Protected routes (guards declared in routePolicies)
+		guard.handle(postGroup, "POST", "", postHandler.CreatePost)
+		guard.handle(postGroup, "PUT", "/:id", postHandler.UpdatePost)
+		guard.handle(postGroup, "DELETE", "/:id", postHandler.DeletePost)
+		guard.handle(postGroup, "PUT", "/:id/schedule", postHandler.SchedulePost)
+		guard.handle(postGroup, "DELETE", "/:id/schedule", postHandler.CancelSchedule)
+	}
+}
+
+// SetupCommentRoutes sets up comment routes
+func SetupCommentRoutes(router *gin.RouterGroup, cfg *config.Config, deps *Dependencies) {
+	// Initialize dependencies
+	db := database.GetDB()
+
+	// Initialize repositories
+	commentRepo := repository.NewCommentRepository(db)
+	postRepo := repository.NewPostRepository(db)
+	batchRepo := repository.NewBatchRepository(db)
+	transactor := repository.NewTransactor(db)
+
+	// Initialize services
+	commentService := service.NewCommentService(commentRepo, postRepo, batchRepo, transactor, deps.eventQueue())
+
+	// Initialize handlers
+	commentHandler := handler.NewCommentHandler(commentService)
+
+	// Comment routes
+	guard := newRouteGuard(cfg)
+	guard.handle(router, "GET", "/posts/:id/comments", commentHandler.GetCommentTree)
+	guard.handle(router, "POST", "/posts/:id/comments", commentHandler.CreateComment)
+	guard.handle(router, "DELETE", "/comments/:id", commentHandler.DeleteComment)
+}
+
+// SetupVoteRoutes sets up vote routes
+func SetupVoteRoutes(router *gin.RouterGroup, cfg *config.Config, deps *Dependencies) {
+	// Initialize dependencies
+	db := database.GetDB()
+
+	// Initialize repositories
+	voteRepo := repository.NewVoteRepository(db)
+	postRepo := repository.NewPostRepository(db)
+	commentRepo := repository.NewCommentRepository(db)
+	transactor := repository.NewTransactor(db)
+
+	// Initialize services
+	// Leave the publisher unset without a message queue so votes are still recorded
+	var publisher service.VoteEventPublisher
+	if eventQueue := deps.eventQueue(); eventQueue != nil {
+		publisher = mq.NewPublisher(eventQueue)
+	}
+	voteService := service.NewVoteService(voteRepo, postRepo, commentRepo, transactor, publisher)
+
+	// Initialize handlers
+	voteHandler := handler.NewVoteHandler(voteService)
+
+	// Vote routes (all require authentication)
+	guard := newRouteGuard(cfg)
+	voteGroup := router.Group("/votes")
+	{
+		guard.handle(voteGroup, "POST", "", voteHandler.Vote)
+		guard.handle(voteGroup, "DELETE", "", voteHandler.CancelVote)
+		guard.handle(voteGroup, "GET", "/:entity_type/:entity_id", voteHandler.GetVote)
+	}
+}
+
+// SetupFeedRoutes sets up feed routes
+func SetupFeedRoutes(router *gin.RouterGroup, cfg *config.Config, deps *Dependencies) {
+	// Initialize dependencies
+	db := database.GetDB()
+	cacheService := cache.NewCacheService(cache.GetClient(), cfg.Cache.DefaultExpiration)
+
+	// Initialize repositories
+	postRepo := repository.NewPostRepository(db)
+	userProfileRepo := repository.NewUserProfileRepository(db)
+	followRepo := repository.NewFollowRepository(db)
+
+	// Initialize services
+	feedService := service.NewFeedService(cache.GetClient(), postRepo, userProfileRepo, followRepo, cacheService, cfg.Feed.FanoutThreshold)
+
+	// Initialize handlers
+	feedHandler := handler.NewFeedHandler(feedService)
+
+	// Feed routes
+	guard := newRouteGuard(cfg)
+	guard.handle(router, "GET", "/feed", feedHandler.GetUserFeed)
+	guard.handle(router, "GET", "/circles/:id/feed", feedHandler.GetCircleFeed)
+}
+
+// SetupSearchRoutes sets up search routes
+func SetupSearchRoutes(router *gin.RouterGroup, cfg *config.Config, deps *Dependencies) {
+	// Initialize services
+	// Without any backend the service answers ErrSearchUnavailable
+	searchService := deps.searchService(cfg)
+
+	// Initialize handlers
+	searchHandler := handler.NewSearchHandler(searchService)
+
+	// Search routes
+	guard := newRouteGuard(cfg)
+	searchGroup := router.Group("/search")
+	{
+		guard.handle(searchGroup, "GET", "/posts", searchHandler.SearchPosts)
+		guard.handle(searchGroup, "GET", "/users", searchHandler.SearchUsers)
 	}
 }
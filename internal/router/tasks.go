@@ -0,0 +1,69 @@
+package router
+
+import (
+	"fmt"
+
+	"github.com/kobayashirei/airy/internal/cache"
+	"github.com/kobayashirei/airy/internal/config"
+	"github.com/kobayashirei/airy/internal/database"
+	appLogger "github.com/kobayashirei/airy/internal/logger"
+	"github.com/kobayashirei/airy/internal/repository"
+	"github.com/kobayashirei/airy/internal/service"
+	"github.com/kobayashirei/airy/internal/taskpool"
+)
+
+// RegisterTasks registers the common tasks with the task pool's registry, backed by
+// the real services, so they can be submitted by name with TaskPool.SubmitTask. The
+// search index task is left out without a search client.
+func RegisterTasks(cfg *config.Config, deps *Dependencies) error {
+	// Initialize dependencies
+	db := database.GetDB()
+	cacheService := cache.NewCacheService(cache.GetClient(), cfg.Cache.DefaultExpiration)
+
+	// Initialize repositories
+	userRepo := repository.NewUserRepository(db)
+	userProfileRepo := repository.NewUserProfileRepository(db)
+	userStatsRepo := repository.NewUserStatsRepository(db)
+	postRepo := repository.NewPostRepository(db)
+	commentRepo := repository.NewCommentRepository(db)
+	followRepo := repository.NewFollowRepository(db)
+	entityCountRepo := repository.NewEntityCountRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	transactor := repository.NewTransactor(db)
+	batchRepo := repository.NewBatchRepository(db)
+
+	// Initialize services; publishing scheduled posts needs no scheduler
+	hotnessService := service.NewHotnessService(postRepo, entityCountRepo, deps.hotnessStrategies(cfg))
+	var hotnessIndexer service.HotnessIndexer
+	if deps.SearchClient != nil {
+		hotnessIndexer = deps.SearchClient
+	}
+	recomputer := service.NewHotnessRecomputer(hotnessService, postRepo, batchRepo, hotnessIndexer,
+		cfg.Hotness.RecomputeWindow, cfg.Hotness.RecomputeBatchSize, appLogger.Logger)
+	postService := service.NewPostService(postRepo, transactor, cacheService, service.NewContentModerationService(), deps.eventQueue(), deps.TaskPool, nil)
+	services := taskpool.Services{
+		Notifications: service.NewTaskNotifier(service.NewNotificationService(notificationRepo, userRepo, postRepo, commentRepo)),
+		Feed:          service.NewFeedService(cache.GetClient(), postRepo, userProfileRepo, followRepo, cacheService, cfg.Feed.FanoutThreshold),
+		Hotness:       hotnessService,
+		Recompute:     recomputer,
+		Counts:        entityCountRepo,
+		Email:         service.NewEmailService(),
+		Posts:         postService,
+	}
+	if deps.SearchClient != nil {
+		services.Search = service.NewSearchConsumer(deps.searchService(cfg), postRepo, userRepo, userProfileRepo, userStatsRepo, appLogger.Logger)
+	}
+
+	return taskpool.RegisterTasks(deps.TaskPool.Registry(), services)
+}
+
+// SchedulePeriodicJobs schedules the periodic jobs with the job queue; call it before
+// the queue starts. The hotness recompute runs as one job per shard.
+func SchedulePeriodicJobs(cfg *config.Config, deps *Dependencies) {
+	if cfg.Hotness.RecomputeInterval > 0 && cfg.Hotness.RecomputeShards > 0 {
+		for shard := 0; shard < cfg.Hotness.RecomputeShards; shard++ {
+			deps.Jobs.Every(fmt.Sprintf("%s:%d", taskpool.TaskRecomputeHotness, shard), cfg.Hotness.RecomputeInterval,
+				taskpool.TaskRecomputeHotness, taskpool.RecomputeHotnessTask{Shard: shard, Shards: cfg.Hotness.RecomputeShards})
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package router
+
+import (
+	"github.com/kobayashirei/airy/internal/cache"
+	"github.com/kobayashirei/airy/internal/config"
+	"github.com/kobayashirei/airy/internal/database"
+	appLogger "github.com/kobayashirei/airy/internal/logger"
+	"github.com/kobayashirei/airy/internal/repository"
+	"github.com/kobayashirei/airy/internal/service"
+)
+
+// SetupWorkers registers every message queue consumer with a worker manager, named after
+// its consumer group. Workers listed in WORKERS_DISABLED are registered disabled, and so
+// is the search indexer without a search client. Call Start on the result to run them.
+func SetupWorkers(cfg *config.Config, deps *Dependencies) *service.WorkerManager {
+	// Initialize dependencies
+	db := database.GetDB()
+	cacheService := cache.NewCacheService(cache.GetClient(), cfg.Cache.DefaultExpiration)
+
+	// Count updates must not apply twice, so consumer groups skip redelivered events;
+	// without Redis they run without deduplication
+	var deduplicator *service.EventDeduplicator
+	if cache.GetClient() != nil {
+		deduplicator = service.NewEventDeduplicator(cacheService, cfg.MQ.DedupeClaimTTL, cfg.MQ.DedupeTTL, appLogger.Logger)
+	}
+
+	// Initialize repositories
+	userRepo := repository.NewUserRepository(db)
+	userProfileRepo := repository.NewUserProfileRepository(db)
+	userStatsRepo := repository.NewUserStatsRepository(db)
+	postRepo := repository.NewPostRepository(db)
+	commentRepo := repository.NewCommentRepository(db)
+	followRepo := repository.NewFollowRepository(db)
+	entityCountRepo := repository.NewEntityCountRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+
+	// Initialize services
+	hotnessService := service.NewHotnessService(postRepo, entityCountRepo, deps.hotnessStrategies(cfg))
+	searchService := deps.searchService(cfg)
+	feedService := service.NewFeedService(cache.GetClient(), postRepo, userProfileRepo, followRepo, cacheService, cfg.Feed.FanoutThreshold)
+
+	// Register workers
+	workers := service.NewWorkerManager(deps.MessageQueue, deduplicator, appLogger.Logger)
+	options := func(name string) service.WorkerOptions {
+		return service.WorkerOptions{
+			Enabled:     cfg.Workers.Enabled(name),
+			Concurrency: cfg.Workers.Concurrency[name],
+		}
+	}
+
+	workers.Register(service.HotnessConsumerGroup,
+		service.NewHotnessWorker(hotnessService, deps.SearchClient),
+		options(service.HotnessConsumerGroup))
+
+	searchOptions := options(service.SearchConsumerGroup)
+	searchOptions.Enabled = searchOptions.Enabled && deps.SearchClient != nil
+	workers.Register(service.SearchConsumerGroup,
+		service.NewSearchConsumer(searchService, postRepo, userRepo, userProfileRepo, userStatsRepo, appLogger.Logger),
+		searchOptions)
+
+	workers.Register(service.VoteConsumerGroup,
+		service.NewVoteConsumer(entityCountRepo, notificationRepo, postRepo, commentRepo),
+		options(service.VoteConsumerGroup))
+
+	workers.Register(service.CommentConsumerGroup,
+		service.NewCommentEventConsumer(commentRepo, postRepo, userRepo, entityCountRepo, notificationRepo),
+		options(service.CommentConsumerGroup))
+
+	workers.Register(service.FavoriteConsumerGroup,
+		service.NewFavoriteConsumer(entityCountRepo),
+		options(service.FavoriteConsumerGroup))
+
+	workers.Register(service.FeedConsumerGroup,
+		service.NewFeedConsumer(feedService, postRepo),
+		options(service.FeedConsumerGroup))
+
+	return workers
+}
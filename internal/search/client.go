@@ -223,6 +223,36 @@ type Hit struct {
 	Source map[string]interface{} `json:"_source"`
 }
 
+// Health checks that the cluster answers and is not red, in which case some
+// primary shards are unassigned and searches may fail or miss documents
+func (c *Client) Health(ctx context.Context) error {
+	req := esapi.ClusterHealthRequest{
+		FilterPath: []string{"status"},
+	}
+
+	res, err := req.Do(ctx, c.es)
+	if err != nil {
+		return fmt.Errorf("failed to check cluster health: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("cluster health returned error: %s", res.String())
+	}
+
+	var health struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&health); err != nil {
+		return fmt.Errorf("failed to decode cluster health: %w", err)
+	}
+	if health.Status == "red" {
+		return fmt.Errorf("cluster health is red")
+	}
+
+	return nil
+}
+
 // Close closes the Elasticsearch client
 func (c *Client) Close() error {
 	// Elasticsearch v8 client doesn't have a Close method
@@ -246,7 +276,7 @@ func (c *Client) UpdatePostHotnessScore(ctx context.Context, postID int64, hotne
 	}
 	
 	req := esapi.UpdateRequest{
-		Index:      "posts",
+		Index:      PostWriteAlias,
 		DocumentID: documentID,
 		Body:       strings.NewReader(string(data)),
 		Refresh:    "true",
@@ -269,3 +299,80 @@ func (c *Client) UpdatePostHotnessScore(ctx context.Context, postID int64, hotne
 	
 	return nil
 }
+
+// BulkUpdatePostHotnessScores updates the hotness score field of many posts in one
+// bulk request. Posts missing from the index are skipped.
+func (c *Client) BulkUpdatePostHotnessScores(ctx context.Context, scores map[int64]float64) error {
+	if len(scores) == 0 {
+		return nil
+	}
+
+	var body strings.Builder
+	encoder := json.NewEncoder(&body)
+	for postID, hotnessScore := range scores {
+		action := map[string]interface{}{
+			"update": map[string]interface{}{"_id": fmt.Sprintf("%d", postID)},
+		}
+		update := map[string]interface{}{
+			"doc": map[string]interface{}{"hotness_score": hotnessScore},
+		}
+		if err := encoder.Encode(action); err != nil {
+			return fmt.Errorf("failed to marshal bulk action: %w", err)
+		}
+		if err := encoder.Encode(update); err != nil {
+			return fmt.Errorf("failed to marshal bulk update: %w", err)
+		}
+	}
+
+	req := esapi.BulkRequest{
+		Index: PostWriteAlias,
+		Body:  strings.NewReader(body.String()),
+	}
+
+	res, err := req.Do(ctx, c.es)
+	if err != nil {
+		return fmt.Errorf("failed to bulk update hotness: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("failed to bulk update hotness: %s", res.String())
+	}
+
+	var result struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			ID     string `json:"_id"`
+			Status int    `json:"status"`
+			Error  struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode bulk response: %w", err)
+	}
+	if !result.Errors {
+		return nil
+	}
+
+	failed := 0
+	var firstError string
+	for _, item := range result.Items {
+		for _, op := range item {
+			// A post that is not indexed yet gets its score when it is
+			if op.Status < 300 || op.Status == 404 {
+				continue
+			}
+			if failed == 0 {
+				firstError = fmt.Sprintf("post %s: %s: %s", op.ID, op.Error.Type, op.Error.Reason)
+			}
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to update hotness of %d posts, first %s", failed, firstError)
+	}
+	return nil
+}
@@ -0,0 +1,427 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"go.uber.org/zap"
+)
+
+// IndexSpec describes a searchable collection kept in versioned physical indices
+// ("posts_v1", "posts_v2", ...) behind two aliases: searches read Alias and the
+// application writes WriteAlias. A reindex builds the next version and swaps both
+// aliases to it in one atomic request.
+type IndexSpec struct {
+	Alias      string
+	WriteAlias string
+	Mapping    string
+}
+
+var (
+	// PostIndexSpec is the posts collection
+	PostIndexSpec = IndexSpec{Alias: PostIndex, WriteAlias: PostWriteAlias, Mapping: PostIndexMapping}
+	// UserIndexSpec is the users collection
+	UserIndexSpec = IndexSpec{Alias: UserIndex, WriteAlias: UserWriteAlias, Mapping: UserIndexMapping}
+)
+
+// IndexSpecs are the searchable collections by read alias
+var IndexSpecs = map[string]IndexSpec{
+	PostIndex: PostIndexSpec,
+	UserIndex: UserIndexSpec,
+}
+
+// VersionIndex returns the name of a version's physical index
+func (s IndexSpec) VersionIndex(version int) string {
+	return s.Alias + "_v" + strconv.Itoa(version)
+}
+
+// version parses a physical index name of the collection; false for other names
+func (s IndexSpec) version(index string) (int, bool) {
+	suffix, ok := strings.CutPrefix(index, s.Alias+"_v")
+	if !ok {
+		return 0, false
+	}
+	version, err := strconv.Atoi(suffix)
+	return version, err == nil && version > 0
+}
+
+// IndexVersions describes the physical indices of a collection
+type IndexVersions struct {
+	Live    []string // The indices the read alias points at
+	Legacy  bool     // The read alias name is a concrete index from before aliases were used
+	Latest  int      // The highest existing version; 0 without any
+	Indices []string // Every versioned index, by version
+}
+
+// Versions looks up the physical indices of a collection and the ones its read alias points at
+func (c *Client) Versions(ctx context.Context, spec IndexSpec) (*IndexVersions, error) {
+	allowNoIndices, ignoreUnavailable := true, true
+	req := esapi.IndicesGetRequest{
+		Index:             []string{spec.Alias, spec.Alias + "_v*"},
+		AllowNoIndices:    &allowNoIndices,
+		IgnoreUnavailable: &ignoreUnavailable,
+		FilterPath:        []string{"*.aliases"},
+	}
+
+	res, err := req.Do(ctx, c.es)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get indices: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() && res.StatusCode != http.StatusNotFound {
+		return nil, fmt.Errorf("failed to get indices: %s", res.String())
+	}
+
+	indices := map[string]struct {
+		Aliases map[string]json.RawMessage `json:"aliases"`
+	}{}
+	if res.StatusCode != http.StatusNotFound {
+		if err := json.NewDecoder(res.Body).Decode(&indices); err != nil {
+			return nil, fmt.Errorf("failed to decode indices: %w", err)
+		}
+	}
+
+	versions := &IndexVersions{}
+	byVersion := map[string]int{}
+	for index, info := range indices {
+		if _, ok := info.Aliases[spec.Alias]; ok {
+			versions.Live = append(versions.Live, index)
+		}
+		if index == spec.Alias {
+			versions.Legacy = true
+			versions.Live = append(versions.Live, index)
+			continue
+		}
+		if version, ok := spec.version(index); ok {
+			byVersion[index] = version
+			versions.Indices = append(versions.Indices, index)
+			versions.Latest = max(versions.Latest, version)
+		}
+	}
+	sort.Slice(versions.Indices, func(i, j int) bool {
+		return byVersion[versions.Indices[i]] < byVersion[versions.Indices[j]]
+	})
+	sort.Strings(versions.Live)
+	return versions, nil
+}
+
+// EnsureIndex makes sure a collection can be read and written through its aliases.
+// Without any index it creates version 1 behind both aliases. A concrete index named
+// after the read alias, from before aliases were used, gets the write alias so it is
+// written until a reindex replaces it.
+func (c *Client) EnsureIndex(ctx context.Context, spec IndexSpec) error {
+	versions, err := c.Versions(ctx, spec)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case versions.Legacy:
+		c.log.Warn("Index predates aliases, run the reindex command to move it to a versioned index",
+			zap.String("index", spec.Alias))
+		return c.UpdateAliases(ctx, []AliasAction{
+			{Add: &AliasTarget{Index: spec.Alias, Alias: spec.WriteAlias}},
+		})
+	case len(versions.Live) > 0:
+		return nil
+	}
+
+	// Versions left without aliases by an unfinished reindex stay for it to resume
+	index := spec.VersionIndex(versions.Latest + 1)
+	if err := c.CreateIndex(ctx, index, spec.Mapping); err != nil {
+		return err
+	}
+	return c.SwapAliases(ctx, spec, index, versions)
+}
+
+// CreateVersion creates the next version's physical index of a collection, without
+// aliases and with refreshes off for bulk loading; FinishVersion turns them back on
+func (c *Client) CreateVersion(ctx context.Context, spec IndexSpec) (string, error) {
+	versions, err := c.Versions(ctx, spec)
+	if err != nil {
+		return "", err
+	}
+
+	index := spec.VersionIndex(versions.Latest + 1)
+	if err := c.CreateIndex(ctx, index, spec.Mapping); err != nil {
+		return "", err
+	}
+	if err := c.UpdateSettings(ctx, index, map[string]interface{}{"refresh_interval": "-1"}); err != nil {
+		return "", err
+	}
+	return index, nil
+}
+
+// FinishVersion turns refreshes back on for a bulk loaded index and refreshes it, so
+// every loaded document is searchable before the index goes live
+func (c *Client) FinishVersion(ctx context.Context, index string) error {
+	if err := c.UpdateSettings(ctx, index, map[string]interface{}{"refresh_interval": nil}); err != nil {
+		return err
+	}
+	return c.Refresh(ctx, index)
+}
+
+// SwapAliases points both aliases of a collection at index and away from every other
+// index in one atomic request, so searches and writes move over together. A concrete
+// index from before aliases were used is deleted in the same request, as an alias
+// cannot take its name otherwise. versions may be nil to look them up.
+func (c *Client) SwapAliases(ctx context.Context, spec IndexSpec, index string, versions *IndexVersions) error {
+	if versions == nil {
+		var err error
+		if versions, err = c.Versions(ctx, spec); err != nil {
+			return err
+		}
+	}
+
+	isWriteIndex, mustExist := true, false
+	actions := []AliasAction{
+		{Add: &AliasTarget{Index: index, Alias: spec.Alias}},
+		{Add: &AliasTarget{Index: index, Alias: spec.WriteAlias, IsWriteIndex: &isWriteIndex}},
+	}
+	for _, old := range versions.Indices {
+		if old == index {
+			continue
+		}
+		actions = append(actions,
+			AliasAction{Remove: &AliasTarget{Index: old, Alias: spec.Alias, MustExist: &mustExist}},
+			AliasAction{Remove: &AliasTarget{Index: old, Alias: spec.WriteAlias, MustExist: &mustExist}},
+		)
+	}
+	if versions.Legacy {
+		actions = append(actions, AliasAction{RemoveIndex: &AliasTarget{Index: spec.Alias}})
+	}
+
+	if err := c.UpdateAliases(ctx, actions); err != nil {
+		return err
+	}
+	c.log.Info("Swapped index aliases",
+		zap.String("alias", spec.Alias),
+		zap.String("write_alias", spec.WriteAlias),
+		zap.String("index", index),
+	)
+	return nil
+}
+
+// AliasAction is one action of an atomic alias update; exactly one field is set
+type AliasAction struct {
+	Add         *AliasTarget `json:"add,omitempty"`
+	Remove      *AliasTarget `json:"remove,omitempty"`
+	RemoveIndex *AliasTarget `json:"remove_index,omitempty"`
+}
+
+// AliasTarget names the index and alias of an alias action
+type AliasTarget struct {
+	Index        string `json:"index"`
+	Alias        string `json:"alias,omitempty"`
+	IsWriteIndex *bool  `json:"is_write_index,omitempty"`
+	MustExist    *bool  `json:"must_exist,omitempty"` // Removing a missing alias fails unless false
+}
+
+// UpdateAliases applies alias actions in one atomic request
+func (c *Client) UpdateAliases(ctx context.Context, actions []AliasAction) error {
+	data, err := json.Marshal(map[string]interface{}{"actions": actions})
+	if err != nil {
+		return fmt.Errorf("failed to marshal alias actions: %w", err)
+	}
+
+	req := esapi.IndicesUpdateAliasesRequest{
+		Body: strings.NewReader(string(data)),
+	}
+
+	res, err := req.Do(ctx, c.es)
+	if err != nil {
+		return fmt.Errorf("failed to update aliases: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("failed to update aliases: %s", res.String())
+	}
+	return nil
+}
+
+// UpdateSettings updates the dynamic settings of an index; a nil value resets a setting
+func (c *Client) UpdateSettings(ctx context.Context, index string, settings map[string]interface{}) error {
+	data, err := json.Marshal(map[string]interface{}{"index": settings})
+	if err != nil {
+		return fmt.Errorf("failed to marshal settings: %w", err)
+	}
+
+	req := esapi.IndicesPutSettingsRequest{
+		Index: []string{index},
+		Body:  strings.NewReader(string(data)),
+	}
+
+	res, err := req.Do(ctx, c.es)
+	if err != nil {
+		return fmt.Errorf("failed to update settings: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("failed to update settings: %s", res.String())
+	}
+	return nil
+}
+
+// Refresh makes every document written to an index searchable
+func (c *Client) Refresh(ctx context.Context, index string) error {
+	req := esapi.IndicesRefreshRequest{
+		Index: []string{index},
+	}
+
+	res, err := req.Do(ctx, c.es)
+	if err != nil {
+		return fmt.Errorf("failed to refresh index: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("failed to refresh index: %s", res.String())
+	}
+	return nil
+}
+
+// DeleteIndex deletes an index; a missing index is not an error
+func (c *Client) DeleteIndex(ctx context.Context, index string) error {
+	req := esapi.IndicesDeleteRequest{
+		Index: []string{index},
+	}
+
+	res, err := req.Do(ctx, c.es)
+	if err != nil {
+		return fmt.Errorf("failed to delete index: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() && res.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("failed to delete index: %s", res.String())
+	}
+
+	c.log.Info("Deleted index", zap.String("index", index))
+	return nil
+}
+
+// Get retrieves a document into out, reporting false when it does not exist
+func (c *Client) Get(ctx context.Context, indexName, documentID string, out interface{}) (bool, error) {
+	req := esapi.GetRequest{
+		Index:      indexName,
+		DocumentID: documentID,
+	}
+
+	res, err := req.Do(ctx, c.es)
+	if err != nil {
+		return false, fmt.Errorf("failed to get document: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if res.IsError() {
+		return false, fmt.Errorf("failed to get document: %s", res.String())
+	}
+
+	var doc struct {
+		Source json.RawMessage `json:"_source"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&doc); err != nil {
+		return false, fmt.Errorf("failed to decode document: %w", err)
+	}
+	if err := json.Unmarshal(doc.Source, out); err != nil {
+		return false, fmt.Errorf("failed to decode document: %w", err)
+	}
+	return true, nil
+}
+
+// BulkOperation indexes Document under ID, or deletes the document with ID when Document is nil
+type BulkOperation struct {
+	ID       string
+	Document interface{}
+}
+
+// Bulk indexes and deletes documents of an index in one bulk request. Deleting a
+// missing document is not an error.
+func (c *Client) Bulk(ctx context.Context, indexName string, operations []BulkOperation) error {
+	if len(operations) == 0 {
+		return nil
+	}
+
+	var body strings.Builder
+	encoder := json.NewEncoder(&body)
+	for _, op := range operations {
+		action := "index"
+		if op.Document == nil {
+			action = "delete"
+		}
+		if err := encoder.Encode(map[string]interface{}{
+			action: map[string]interface{}{"_id": op.ID},
+		}); err != nil {
+			return fmt.Errorf("failed to marshal bulk action: %w", err)
+		}
+		if op.Document == nil {
+			continue
+		}
+		if err := encoder.Encode(op.Document); err != nil {
+			return fmt.Errorf("failed to marshal bulk document: %w", err)
+		}
+	}
+
+	req := esapi.BulkRequest{
+		Index: indexName,
+		Body:  strings.NewReader(body.String()),
+	}
+
+	res, err := req.Do(ctx, c.es)
+	if err != nil {
+		return fmt.Errorf("failed to execute bulk request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("failed to execute bulk request: %s", res.String())
+	}
+
+	var result struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			ID     string `json:"_id"`
+			Status int    `json:"status"`
+			Error  struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode bulk response: %w", err)
+	}
+	if !result.Errors {
+		return nil
+	}
+
+	failed := 0
+	var firstError string
+	for _, item := range result.Items {
+		for action, op := range item {
+			if op.Status < 300 || (action == "delete" && op.Status == http.StatusNotFound) {
+				continue
+			}
+			if failed == 0 {
+				firstError = fmt.Sprintf("document %s: %s: %s", op.ID, op.Error.Type, op.Error.Reason)
+			}
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d bulk operations failed, first %s", failed, len(operations), firstError)
+	}
+	return nil
+}
@@ -133,8 +133,14 @@ const UserIndexMapping = `
 `
 
 const (
-	// PostIndex is the name of the posts index
+	// PostIndex is the alias searches read posts through
 	PostIndex = "posts"
-	// UserIndex is the name of the users index
+	// PostWriteAlias is the alias posts are written through
+	PostWriteAlias = "posts_write"
+	// UserIndex is the alias searches read users through
 	UserIndex = "users"
+	// UserWriteAlias is the alias users are written through
+	UserWriteAlias = "users_write"
+	// ReindexStateIndex holds the progress of each collection's reindex, so it can resume
+	ReindexStateIndex = "search_reindex"
 )
@@ -6,7 +6,12 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/kobayashirei/airy/internal/cache"
 	"github.com/kobayashirei/airy/internal/models"
+	"github.com/kobayashirei/airy/internal/mq"
 	"github.com/kobayashirei/airy/internal/repository"
 )
 
@@ -97,7 +102,7 @@ type ListLogsRequest struct {
 	EntityType string  `json:"entity_type"`
 	StartDate  *string `json:"start_date"`
 	EndDate    *string `json:"end_date"`
-	Page       int     `json:"page"`
+	Cursor     string  `json:"cursor"` // next_cursor of the previous page; empty for the first page
 	PageSize   int     `json:"page_size"`
 }
 
@@ -105,31 +110,52 @@ type ListLogsRequest struct {
 type ListLogsResponse struct {
 	Logs       []*models.AdminLog `json:"logs"`
 	Total      int64              `json:"total"`
-	Page       int                `json:"page"`
 	PageSize   int                `json:"page_size"`
-	TotalPages int                `json:"total_pages"`
+	NextCursor string             `json:"next_cursor,omitempty"`
 }
 
+// adminLogCursorSort names the admin log list ordering in pagination cursors
+const adminLogCursorSort = "admin_logs"
+
 // adminService implements AdminService interface
 type adminService struct {
 	userRepo     repository.UserRepository
 	postRepo     repository.PostRepository
 	commentRepo  repository.CommentRepository
 	adminLogRepo repository.AdminLogRepository
+	transactor   repository.Transactor
+	cacheService cache.Service
+	messageQueue mq.MessageQueue
+	scheduler    *PostScheduler
+	logger       *zap.Logger
 }
 
-// NewAdminService creates a new admin service
+// NewAdminService creates a new admin service. Without a scheduler, approving a post
+// scheduled for later fails.
 func NewAdminService(
 	userRepo repository.UserRepository,
 	postRepo repository.PostRepository,
 	commentRepo repository.CommentRepository,
 	adminLogRepo repository.AdminLogRepository,
+	transactor repository.Transactor,
+	cacheService cache.Service,
+	messageQueue mq.MessageQueue,
+	scheduler *PostScheduler,
+	logger *zap.Logger,
 ) AdminService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
 	return &adminService{
 		userRepo:     userRepo,
 		postRepo:     postRepo,
 		commentRepo:  commentRepo,
 		adminLogRepo: adminLogRepo,
+		transactor:   transactor,
+		cacheService: cacheService,
+		messageQueue: messageQueue,
+		scheduler:    scheduler,
+		logger:       logger,
 	}
 }
 
@@ -295,7 +321,7 @@ func (s *adminService) BanUser(ctx context.Context, operatorID, userID int64, re
 	}
 	if err := s.adminLogRepo.Create(ctx, log); err != nil {
 		// Log error but don't fail the operation
-		fmt.Printf("failed to create admin log: %v\n", err)
+		s.logger.Warn("failed to create admin log", zap.String("action", log.Action), zap.Error(err))
 	}
 
 	return nil
@@ -330,7 +356,7 @@ func (s *adminService) UnbanUser(ctx context.Context, operatorID, userID int64,
 		CreatedAt:  time.Now(),
 	}
 	if err := s.adminLogRepo.Create(ctx, log); err != nil {
-		fmt.Printf("failed to create admin log: %v\n", err)
+		s.logger.Warn("failed to create admin log", zap.String("action", log.Action), zap.Error(err))
 	}
 
 	return nil
@@ -388,7 +414,8 @@ func (s *adminService) ListPosts(ctx context.Context, req AdminListPostsRequest)
 	}, nil
 }
 
-// BatchReviewPosts reviews multiple posts in batch
+// BatchReviewPosts reviews multiple posts in batch. An approved post with a future
+// scheduled time is scheduled rather than published.
 func (s *adminService) BatchReviewPosts(ctx context.Context, req BatchReviewRequest) error {
 	if len(req.PostIDs) == 0 {
 		return fmt.Errorf("no post IDs provided")
@@ -407,8 +434,34 @@ func (s *adminService) BatchReviewPosts(ctx context.Context, req BatchReviewRequ
 
 	// Update each post
 	for _, postID := range req.PostIDs {
-		if err := s.postRepo.UpdateStatus(ctx, postID, newStatus); err != nil {
-			return fmt.Errorf("failed to update post %d: %w", postID, err)
+		post, err := s.postRepo.FindByID(ctx, postID)
+		if err != nil {
+			return fmt.Errorf("failed to find post %d: %w", postID, err)
+		}
+		if post == nil {
+			return fmt.Errorf("post not found: %d", postID)
+		}
+
+		// Change the status together with its event, so consumers always learn of it
+		// (e.g. to remove hidden posts from feeds)
+		err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+			if newStatus == "published" {
+				return s.approvePost(ctx, post)
+			}
+			if err := s.postRepo.UpdateStatus(ctx, postID, newStatus); err != nil {
+				return fmt.Errorf("failed to update post %d: %w", postID, err)
+			}
+			return s.publishPostUpdatedEvent(ctx, post)
+		})
+		if err != nil {
+			return err
+		}
+
+		// Drop the cached copy so feeds and readers see the new status
+		if s.cacheService != nil {
+			if err := s.cacheService.Delete(ctx, cache.PostKey(postID)); err != nil {
+				s.logger.Warn("failed to invalidate post cache", zap.Int64("post_id", postID), zap.Error(err))
+			}
 		}
 
 		// Log action for each post
@@ -427,19 +480,97 @@ func (s *adminService) BatchReviewPosts(ctx context.Context, req BatchReviewRequ
 			CreatedAt:  time.Now(),
 		}
 		if err := s.adminLogRepo.Create(ctx, log); err != nil {
-			fmt.Printf("failed to create admin log: %v\n", err)
+			s.logger.Warn("failed to create admin log", zap.String("action", log.Action), zap.Error(err))
 		}
 	}
 
 	return nil
 }
 
+// approvePost publishes an approved post with the post.published event, or schedules it
+// when its scheduled time is still ahead, so that moderation never publishes a post early
+func (s *adminService) approvePost(ctx context.Context, post *models.Post) error {
+	now := time.Now()
+	scheduled := post.ScheduledAt != nil && post.ScheduledAt.After(now)
+	if scheduled && s.scheduler == nil {
+		return fmt.Errorf("failed to schedule post %d: %w", post.ID, ErrSchedulingUnavailable)
+	}
+
+	if scheduled {
+		post.Status = "scheduled"
+	} else {
+		post.Status = "published"
+		if post.PublishedAt == nil {
+			post.PublishedAt = &now
+		}
+	}
+	post.UpdatedAt = now
+
+	if err := s.postRepo.Update(ctx, post); err != nil {
+		return fmt.Errorf("failed to update post %d: %w", post.ID, err)
+	}
+	if !scheduled {
+		// Published like any other post: pushed to feeds, indexed and notified
+		return s.publishPostPublishedEvent(ctx, post)
+	}
+	if err := s.publishPostUpdatedEvent(ctx, post); err != nil {
+		return err
+	}
+	// Scheduling is idempotent, so approving a post already scheduled keeps its job
+	return s.scheduler.Schedule(ctx, post)
+}
+
+// publishPostPublishedEvent publishes a post published event.
+// Called inside the status transaction, so with the outbox queue the event commits with the status.
+func (s *adminService) publishPostPublishedEvent(ctx context.Context, post *models.Post) error {
+	if s.messageQueue == nil {
+		return nil
+	}
+
+	event := mq.PostPublishedEvent{
+		BaseEvent: mq.BaseEvent{
+			EventID:   uuid.New().String(),
+			EventType: mq.TopicPostPublished,
+			Timestamp: time.Now(),
+		},
+		PostID:   post.ID,
+		AuthorID: post.AuthorID,
+		CircleID: post.CircleID,
+		Title:    post.Title,
+	}
+
+	if err := s.messageQueue.Publish(ctx, mq.TopicPostPublished, event); err != nil {
+		return fmt.Errorf("failed to publish post published event: %w", err)
+	}
+	return nil
+}
+
+// publishPostUpdatedEvent publishes a post updated event.
+// Called inside the status transaction, so with the outbox queue the event commits with the status.
+func (s *adminService) publishPostUpdatedEvent(ctx context.Context, post *models.Post) error {
+	if s.messageQueue == nil {
+		return nil
+	}
+
+	event := mq.PostUpdatedEvent{
+		BaseEvent: mq.BaseEvent{
+			EventID:   uuid.New().String(),
+			EventType: mq.TopicPostUpdated,
+			Timestamp: time.Now(),
+		},
+		PostID:   post.ID,
+		AuthorID: post.AuthorID,
+	}
+
+	if err := s.messageQueue.Publish(ctx, mq.TopicPostUpdated, event); err != nil {
+		return fmt.Errorf("failed to publish post updated event: %w", err)
+	}
+	return nil
+}
+
 // ListLogs retrieves a list of admin logs with filtering and pagination
 func (s *adminService) ListLogs(ctx context.Context, req ListLogsRequest) (*ListLogsResponse, error) {
 	// Set default pagination
-	if req.Page < 1 {
-		req.Page = 1
-	}
 	if req.PageSize < 1 {
 		req.PageSize = 20
 	}
@@ -447,9 +578,13 @@ func (s *adminService) ListLogs(ctx context.Context, req ListLogsRequest) (*List
 		req.PageSize = 100
 	}
 
-	offset := (req.Page - 1) * req.PageSize
+	var createdAt time.Time
+	after, err := decodeKeyset(req.Cursor, adminLogCursorSort, &createdAt)
+	if err != nil {
+		return nil, err
+	}
 
-	// Build options
+	// Build options, fetching one extra row to know whether another page exists
 	opts := repository.AdminLogListOptions{
 		OperatorID: req.OperatorID,
 		Action:     req.Action,
@@ -458,8 +593,8 @@ func (s *adminService) ListLogs(ctx context.Context, req ListLogsRequest) (*List
 		EndDate:    req.EndDate,
 		SortBy:     "created_at",
 		Order:      "DESC",
-		Limit:      req.PageSize,
-		Offset:     offset,
+		Limit:      req.PageSize + 1,
+		After:      after,
 	}
 
 	// Get logs
@@ -468,23 +603,27 @@ func (s *adminService) ListLogs(ctx context.Context, req ListLogsRequest) (*List
 		return nil, fmt.Errorf("failed to list logs: %w", err)
 	}
 
+	var nextCursor string
+	if len(logs) > req.PageSize {
+		logs = logs[:req.PageSize]
+		last := logs[req.PageSize-1]
+		nextCursor, err = encodeKeyset(adminLogCursorSort, last.ID, last.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode cursor: %w", err)
+		}
+	}
+
 	// Get total count
 	total, err := s.adminLogRepo.Count(ctx, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count logs: %w", err)
 	}
 
-	totalPages := int(total) / req.PageSize
-	if int(total)%req.PageSize > 0 {
-		totalPages++
-	}
-
 	return &ListLogsResponse{
 		Logs:       logs,
 		Total:      total,
-		Page:       req.Page,
 		PageSize:   req.PageSize,
-		TotalPages: totalPages,
+		NextCursor: nextCursor,
 	}, nil
 }
 
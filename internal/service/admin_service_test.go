@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kobayashirei/airy/internal/models"
+	"github.com/kobayashirei/airy/internal/mq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchReviewPosts_Approve(t *testing.T) {
+	ctx := context.Background()
+	scheduledAt := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	// newServices creates a post and an admin service on one post repository holding post,
+	// with moderation sending content to review
+	newServices := func(post *models.Post) (PostService, AdminService, *MockPostRepository, *fakeJobScheduler, *MockMessageQueue) {
+		mockPostRepo := new(MockPostRepository)
+		mockCache := new(MockCacheService)
+		mockModeration := new(MockContentModerationService)
+		logRepo := new(MockAdminLogRepository)
+		messageQueue := new(MockMessageQueue)
+		jobs := &fakeJobScheduler{keys: map[string]time.Time{}}
+		scheduler := NewPostScheduler(jobs, mockPostRepo, nil)
+
+		mockModeration.On("CheckContent", mock.Anything, mock.Anything).Return(&ModerationResult{Status: "review"}, nil)
+		mockPostRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			*post = *args.Get(1).(*models.Post)
+			post.ID = 7
+		}).Return(nil)
+		mockPostRepo.On("FindByID", mock.Anything, int64(7)).Return(post, nil)
+		mockPostRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+		mockCache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+		logRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+		messageQueue.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		postService := NewPostService(mockPostRepo, passthroughTransactor{}, mockCache, mockModeration, nil, nil, scheduler)
+		adminService := NewAdminService(nil, mockPostRepo, nil, logRepo, passthroughTransactor{}, mockCache, messageQueue, scheduler, nil)
+		return postService, adminService, mockPostRepo, jobs, messageQueue
+	}
+	approve := BatchReviewRequest{OperatorID: 1, PostIDs: []int64{7}, Action: "approve"}
+
+	t.Run("schedules a new post due later", func(t *testing.T) {
+		post := &models.Post{}
+		postService, adminService, _, jobs, messageQueue := newServices(post)
+
+		created, err := postService.CreatePost(ctx, CreatePostRequest{
+			Title:       "Test Post",
+			Content:     "This is an advertisement",
+			AuthorID:    1,
+			ScheduledAt: &scheduledAt,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "pending", created.Status)
+		assert.Empty(t, jobs.keys)
+
+		require.NoError(t, adminService.BatchReviewPosts(ctx, approve))
+
+		assert.Equal(t, "scheduled", post.Status)
+		assert.Nil(t, post.PublishedAt)
+		assert.Equal(t, map[string]time.Time{publishJobKey(7, scheduledAt): scheduledAt}, jobs.keys)
+		messageQueue.AssertNotCalled(t, "Publish", mock.Anything, mq.TopicPostPublished, mock.Anything)
+	})
+
+	t.Run("keeps an edited scheduled post scheduled", func(t *testing.T) {
+		post := &models.Post{ID: 7, AuthorID: 1, Status: "scheduled", ScheduledAt: &scheduledAt}
+		postService, adminService, _, jobs, _ := newServices(post)
+
+		content := "This is an advertisement"
+		updated, err := postService.UpdatePost(ctx, 7, 1, UpdatePostRequest{Content: &content})
+		require.NoError(t, err)
+		assert.Equal(t, "pending", updated.Status)
+
+		require.NoError(t, adminService.BatchReviewPosts(ctx, approve))
+
+		assert.Equal(t, "scheduled", post.Status)
+		assert.Nil(t, post.PublishedAt)
+		assert.Contains(t, jobs.keys, publishJobKey(7, scheduledAt))
+	})
+
+	t.Run("publishes a post that is due", func(t *testing.T) {
+		past := time.Now().Add(-time.Minute)
+		post := &models.Post{ID: 7, AuthorID: 1, Status: "pending", ScheduledAt: &past}
+		_, adminService, mockPostRepo, jobs, messageQueue := newServices(post)
+
+		require.NoError(t, adminService.BatchReviewPosts(ctx, approve))
+
+		assert.Equal(t, "published", post.Status)
+		assert.NotNil(t, post.PublishedAt)
+		assert.Empty(t, jobs.keys)
+		mockPostRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+		messageQueue.AssertCalled(t, "Publish", mock.Anything, mq.TopicPostPublished, mock.MatchedBy(func(e mq.PostPublishedEvent) bool {
+			return e.PostID == 7 && e.AuthorID == 1
+		}))
+		messageQueue.AssertNotCalled(t, "Publish", mock.Anything, mq.TopicPostUpdated, mock.Anything)
+	})
+
+	t.Run("fails for a post due later without a scheduler", func(t *testing.T) {
+		mockPostRepo := new(MockPostRepository)
+		mockPostRepo.On("FindByID", mock.Anything, int64(7)).
+			Return(&models.Post{ID: 7, Status: "pending", ScheduledAt: &scheduledAt}, nil)
+		adminService := NewAdminService(nil, mockPostRepo, nil, new(MockAdminLogRepository), passthroughTransactor{}, nil, nil, nil, nil)
+
+		err := adminService.BatchReviewPosts(ctx, approve)
+
+		assert.ErrorIs(t, err, ErrSchedulingUnavailable)
+		mockPostRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
+
+func TestBatchReviewPosts_Reject(t *testing.T) {
+	ctx := context.Background()
+	reject := BatchReviewRequest{OperatorID: 1, PostIDs: []int64{7}, Action: "reject"}
+
+	newService := func(publishErr error) (AdminService, *MockPostRepository, *MockCacheService, *MockMessageQueue) {
+		mockPostRepo := new(MockPostRepository)
+		mockCache := new(MockCacheService)
+		messageQueue := new(MockMessageQueue)
+		logRepo := new(MockAdminLogRepository)
+
+		mockPostRepo.On("FindByID", mock.Anything, int64(7)).Return(&models.Post{ID: 7, AuthorID: 1, Status: "published"}, nil)
+		mockPostRepo.On("UpdateStatus", mock.Anything, int64(7), "hidden").Return(nil)
+		mockCache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+		messageQueue.On("Publish", mock.Anything, mq.TopicPostUpdated, mock.MatchedBy(func(e mq.PostUpdatedEvent) bool {
+			return e.PostID == 7
+		})).Return(publishErr)
+		logRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+		adminService := NewAdminService(nil, mockPostRepo, nil, logRepo, passthroughTransactor{}, mockCache, messageQueue, nil, nil)
+		return adminService, mockPostRepo, mockCache, messageQueue
+	}
+
+	t.Run("hides the post with its updated event", func(t *testing.T) {
+		adminService, mockPostRepo, mockCache, messageQueue := newService(nil)
+
+		require.NoError(t, adminService.BatchReviewPosts(ctx, reject))
+
+		mockPostRepo.AssertCalled(t, "UpdateStatus", mock.Anything, int64(7), "hidden")
+		messageQueue.AssertExpectations(t)
+		mockCache.AssertCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("fails when the event cannot be recorded", func(t *testing.T) {
+		adminService, _, mockCache, _ := newService(errors.New("outbox unavailable"))
+
+		assert.Error(t, adminService.BatchReviewPosts(ctx, reject))
+
+		mockCache.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+}
@@ -132,6 +132,23 @@ func (s *circleService) CreateCircle(ctx context.Context, req CreateCircleReques
 		return nil, fmt.Errorf("failed to add creator as member: %w", err)
 	}
 
+	// Grant the circle-scoped moderator role so RBAC checks pass for the creator
+	moderatorRole, err := s.roleRepo.FindByName(ctx, models.RoleModerator)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find moderator role: %w", err)
+	}
+	if moderatorRole != nil {
+		userRole := &models.UserRole{
+			UserID:    req.CreatorID,
+			RoleID:    moderatorRole.ID,
+			CircleID:  &circle.ID,
+			CreatedAt: time.Now(),
+		}
+		if err := s.userRoleRepo.Create(ctx, userRole); err != nil {
+			return nil, fmt.Errorf("failed to assign moderator role: %w", err)
+		}
+	}
+
 	return circle, nil
 }
 
@@ -201,6 +201,8 @@ func TestCreateCircle(t *testing.T) {
 		circleRepo.On("FindByName", ctx, "Test Circle").Return(nil, nil)
 		circleRepo.On("Create", ctx, mock.AnythingOfType("*models.Circle")).Return(nil)
 		circleMemberRepo.On("Create", ctx, mock.AnythingOfType("*models.CircleMember")).Return(nil)
+		roleRepo.On("FindByName", ctx, "moderator").Return(&models.Role{ID: 2, Name: "moderator"}, nil)
+		userRoleRepo.On("Create", ctx, mock.AnythingOfType("*models.UserRole")).Return(nil)
 
 		// Create service
 		service := NewCircleService(circleRepo, circleMemberRepo, userRepo, userRoleRepo, roleRepo)
@@ -225,6 +227,8 @@ func TestCreateCircle(t *testing.T) {
 		circleRepo.AssertExpectations(t)
 		circleMemberRepo.AssertExpectations(t)
 		userRepo.AssertExpectations(t)
+		roleRepo.AssertExpectations(t)
+		userRoleRepo.AssertExpectations(t)
 	})
 
 	t.Run("circle name already exists", func(t *testing.T) {
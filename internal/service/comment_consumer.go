@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"time"
 
@@ -39,12 +38,7 @@ func NewCommentEventConsumer(
 
 // HandleCommentCreated handles the comment.created event
 // Implements Requirement 5.5
-func (c *CommentEventConsumer) HandleCommentCreated(ctx context.Context, message []byte) error {
-	var event mq.CommentCreatedEvent
-	if err := json.Unmarshal(message, &event); err != nil {
-		return fmt.Errorf("failed to unmarshal comment created event: %w", err)
-	}
-
+func (c *CommentEventConsumer) HandleCommentCreated(ctx context.Context, event *mq.CommentCreatedEvent) error {
 	// 1. Update post comment count
 	if err := c.updatePostCommentCount(ctx, event.PostID); err != nil {
 		fmt.Printf("failed to update post comment count: %v\n", err)
@@ -52,19 +46,19 @@ func (c *CommentEventConsumer) HandleCommentCreated(ctx context.Context, message
 	}
 
 	// 2. Generate notification for post author
-	if err := c.notifyPostAuthor(ctx, &event); err != nil {
+	if err := c.notifyPostAuthor(ctx, event); err != nil {
 		fmt.Printf("failed to notify post author: %v\n", err)
 	}
 
 	// 3. Generate notification for parent comment author (if reply)
 	if event.ParentID != nil {
-		if err := c.notifyParentCommentAuthor(ctx, &event); err != nil {
+		if err := c.notifyParentCommentAuthor(ctx, event); err != nil {
 			fmt.Printf("failed to notify parent comment author: %v\n", err)
 		}
 	}
 
 	// 4. Parse @mentions and generate notifications
-	if err := c.notifyMentionedUsers(ctx, &event); err != nil {
+	if err := c.notifyMentionedUsers(ctx, event); err != nil {
 		fmt.Printf("failed to notify mentioned users: %v\n", err)
 	}
 
@@ -72,12 +66,7 @@ func (c *CommentEventConsumer) HandleCommentCreated(ctx context.Context, message
 }
 
 // HandleCommentDeleted handles the comment.deleted event
-func (c *CommentEventConsumer) HandleCommentDeleted(ctx context.Context, message []byte) error {
-	var event mq.CommentDeletedEvent
-	if err := json.Unmarshal(message, &event); err != nil {
-		return fmt.Errorf("failed to unmarshal comment deleted event: %w", err)
-	}
-
+func (c *CommentEventConsumer) HandleCommentDeleted(ctx context.Context, event *mq.CommentDeletedEvent) error {
 	// Update post comment count (decrement)
 	if err := c.decrementPostCommentCount(ctx, event.PostID); err != nil {
 		fmt.Printf("failed to decrement post comment count: %v\n", err)
@@ -263,3 +252,20 @@ func truncateContent(content string, maxLen int) string {
 	}
 	return content[:maxLen] + "..."
 }
+
+// CommentConsumerGroup is the consumer group the comment event consumer subscribes with
+const CommentConsumerGroup = "comments"
+
+// Subscribe subscribes to comment events. The handlers update counts and send
+// notifications, so messageQueue should deduplicate redeliveries (see EventDeduplicator.Queue).
+func (c *CommentEventConsumer) Subscribe(messageQueue mq.MessageQueue) error {
+	if err := mq.SubscribeGroupEvents(messageQueue, CommentConsumerGroup, mq.TopicCommentCreated, c.HandleCommentCreated); err != nil {
+		return fmt.Errorf("failed to subscribe to comment created events: %w", err)
+	}
+
+	if err := mq.SubscribeGroupEvents(messageQueue, CommentConsumerGroup, mq.TopicCommentDeleted, c.HandleCommentDeleted); err != nil {
+		return fmt.Errorf("failed to subscribe to comment deleted events: %w", err)
+	}
+
+	return nil
+}
@@ -19,12 +19,46 @@ var (
 	ErrCommentNotFound = errors.New("comment not found")
 	// ErrInvalidParentComment is returned when parent comment is invalid
 	ErrInvalidParentComment = errors.New("invalid parent comment")
+	// ErrInvalidCommentSort is returned for an unknown comment sort mode
+	ErrInvalidCommentSort = errors.New("invalid comment sort")
+)
+
+// CommentSort is the order the comments of a thread are listed in, at every level
+type CommentSort string
+
+const (
+	// CommentSortBest ranks by the Wilson lower bound of the share of upvotes
+	CommentSortBest CommentSort = "best"
+	// CommentSortTop ranks by upvotes minus downvotes
+	CommentSortTop CommentSort = "top"
+	// CommentSortNew lists the newest comments first
+	CommentSortNew CommentSort = "new"
+	// CommentSortOld lists the oldest comments first
+	CommentSortOld CommentSort = "old"
+)
+
+// commentSortColumns maps each comment sort to the column and order it lists by
+var commentSortColumns = map[CommentSort]struct{ column, order string }{
+	CommentSortBest: {"best_score", "desc"},
+	CommentSortTop:  {"score", "desc"},
+	CommentSortNew:  {"created_at", "desc"},
+	CommentSortOld:  {"created_at", "asc"},
+}
+
+// Comment thread loading limits
+const (
+	defaultCommentPageSize = 20
+	maxCommentPageSize     = 100
+	defaultCommentDepth    = 2
+	maxCommentDepth        = 5
+	defaultCommentReplies  = 3
+	maxCommentReplies      = 20
 )
 
 // CommentService defines the interface for comment business logic
 type CommentService interface {
 	CreateComment(ctx context.Context, req CreateCommentRequest) (*models.Comment, error)
-	GetCommentTree(ctx context.Context, postID int64) ([]*CommentNode, error)
+	GetCommentTree(ctx context.Context, req CommentTreeRequest) (*CommentTreeResponse, error)
 	DeleteComment(ctx context.Context, commentID int64, userID int64) error
 }
 
@@ -36,16 +70,44 @@ type CreateCommentRequest struct {
 	AuthorID int64  `json:"-"` // Set from context, not from request body
 }
 
-// CommentNode represents a comment with its children in a tree structure
+// CommentTreeRequest represents a request for a page of a post's comment thread
+type CommentTreeRequest struct {
+	PostID   int64
+	ParentID *int64      // Lists the replies to this comment instead of the top-level comments
+	Sort     CommentSort // Defaults to best
+	Cursor   string      // The NextCursor of the previous page, or a node's RepliesCursor
+	PageSize int         // Comments listed at the top of the page; default 20, max 100
+	Depth    int         // Levels of replies loaded under each listed comment; default 2, max 5, -1 for none
+	Replies  int         // Replies loaded per comment at each level; default 3, max 20
+	ViewerID *int64      // Embeds the viewer's votes when set
+}
+
+// CommentTreeResponse represents a page of a comment thread
+type CommentTreeResponse struct {
+	PostID     int64          `json:"post_id"`
+	ParentID   *int64         `json:"parent_id,omitempty"`
+	Sort       CommentSort    `json:"sort"`
+	Comments   []*CommentNode `json:"comments"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// CommentNode represents a comment with its loaded replies in a tree structure.
+// MoreReplies tells that it has replies beyond Children, listed by requesting the
+// thread with its ID as the parent and RepliesCursor as the cursor.
 type CommentNode struct {
 	*models.Comment
-	Children []*CommentNode `json:"children"`
+	UserVote      string         `json:"user_vote,omitempty"` // "up" or "down" when the viewer voted on the comment
+	Children      []*CommentNode `json:"children"`
+	MoreReplies   bool           `json:"more_replies"`
+	RepliesCursor string         `json:"replies_cursor,omitempty"`
 }
 
 // commentService implements CommentService interface
 type commentService struct {
 	commentRepo  repository.CommentRepository
 	postRepo     repository.PostRepository
+	batchRepo    repository.BatchRepository
+	transactor   repository.Transactor
 	messageQueue mq.MessageQueue
 }
 
@@ -53,11 +115,15 @@ type commentService struct {
 func NewCommentService(
 	commentRepo repository.CommentRepository,
 	postRepo repository.PostRepository,
+	batchRepo repository.BatchRepository,
+	transactor repository.Transactor,
 	messageQueue mq.MessageQueue,
 ) CommentService {
 	return &commentService{
 		commentRepo:  commentRepo,
 		postRepo:     postRepo,
+		batchRepo:    batchRepo,
+		transactor:   transactor,
 		messageQueue: messageQueue,
 	}
 }
@@ -120,50 +186,271 @@ func (s *commentService) CreateComment(ctx context.Context, req CreateCommentReq
 		comment.RootID = 0 // Temporary, will be updated
 	}
 
-	// Create comment in database
-	if err := s.commentRepo.Create(ctx, comment); err != nil {
-		return nil, fmt.Errorf("failed to create comment: %w", err)
+	// Create the comment, fix up its path and record its event in one transaction
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Create comment in database
+		if err := s.commentRepo.Create(ctx, comment); err != nil {
+			return fmt.Errorf("failed to create comment: %w", err)
+		}
+
+		// Update root ID and path after getting the comment ID
+		if req.ParentID != nil {
+			// For replies, construct path from parent path + comment ID
+			parentComment, _ := s.commentRepo.FindByID(ctx, *req.ParentID)
+			if parentComment.Path != "" {
+				comment.Path = fmt.Sprintf("%s.%d", parentComment.Path, comment.ID)
+			} else {
+				comment.Path = fmt.Sprintf("%d.%d", parentComment.ID, comment.ID)
+			}
+		} else {
+			// For root comments, set root ID to own ID and path to own ID
+			comment.RootID = comment.ID
+			comment.Path = strconv.FormatInt(comment.ID, 10)
+		}
+
+		// Update the comment with correct root ID and path
+		if err := s.commentRepo.Update(ctx, comment); err != nil {
+			return fmt.Errorf("failed to update comment path: %w", err)
+		}
+
+		// Send comment event to message queue for async processing
+		// Implements Requirement 5.4
+		return s.publishCommentCreatedEvent(ctx, comment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// GetCommentTree retrieves a page of a post's comment thread: a keyset page of its
+// top-level comments, or of the replies to a comment, each with up to req.Depth levels
+// of its first replies. Every level is loaded in one query.
+// Implements Requirement 5.2
+func (s *commentService) GetCommentTree(ctx context.Context, req CommentTreeRequest) (*CommentTreeResponse, error) {
+	if req.Sort == "" {
+		req.Sort = CommentSortBest
+	}
+	sortColumn, ok := commentSortColumns[req.Sort]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidCommentSort, req.Sort)
+	}
+	if req.PageSize < 1 {
+		req.PageSize = defaultCommentPageSize
+	}
+	if req.PageSize > maxCommentPageSize {
+		req.PageSize = maxCommentPageSize
+	}
+	switch {
+	case req.Depth < 0:
+		req.Depth = 0
+	case req.Depth == 0:
+		req.Depth = defaultCommentDepth
+	case req.Depth > maxCommentDepth:
+		req.Depth = maxCommentDepth
+	}
+	if req.Replies < 1 {
+		req.Replies = defaultCommentReplies
+	}
+	if req.Replies > maxCommentReplies {
+		req.Replies = maxCommentReplies
 	}
 
-	// Update root ID and path after getting the comment ID
+	opts := repository.CommentListOptions{
+		PostID:   &req.PostID,
+		RootOnly: req.ParentID == nil,
+		Status:   "published",
+		SortBy:   sortColumn.column,
+		Order:    sortColumn.order,
+		Limit:    req.PageSize + 1,
+	}
 	if req.ParentID != nil {
-		// For replies, construct path from parent path + comment ID
-		parentComment, _ := s.commentRepo.FindByID(ctx, *req.ParentID)
-		if parentComment.Path != "" {
-			comment.Path = fmt.Sprintf("%s.%d", parentComment.Path, comment.ID)
-		} else {
-			comment.Path = fmt.Sprintf("%d.%d", parentComment.ID, comment.ID)
+		// Replies continue under a published comment of the same post
+		parent, err := s.commentRepo.FindByID(ctx, *req.ParentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find parent comment: %w", err)
 		}
-	} else {
-		// For root comments, set root ID to own ID and path to own ID
-		comment.RootID = comment.ID
-		comment.Path = strconv.FormatInt(comment.ID, 10)
+		if parent == nil || parent.PostID != req.PostID || parent.Status != "published" {
+			return nil, ErrCommentNotFound
+		}
+		opts.ParentID = req.ParentID
 	}
 
-	// Update the comment with correct root ID and path
-	if err := s.commentRepo.Update(ctx, comment); err != nil {
-		return nil, fmt.Errorf("failed to update comment path: %w", err)
+	cursorSort := commentCursorSort(req.Sort, req.ParentID)
+	after, err := decodeCommentCursor(req.Cursor, cursorSort, sortColumn.column)
+	if err != nil {
+		return nil, err
 	}
+	opts.After = after
 
-	// Send comment event to message queue for async processing
-	// Implements Requirement 5.4
-	s.publishCommentCreatedEvent(ctx, comment)
+	comments, err := s.commentRepo.List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list comments: %w", err)
+	}
 
-	return comment, nil
+	resp := &CommentTreeResponse{
+		PostID:   req.PostID,
+		ParentID: req.ParentID,
+		Sort:     req.Sort,
+		Comments: make([]*CommentNode, 0, len(comments)),
+	}
+	if len(comments) > req.PageSize {
+		comments = comments[:req.PageSize]
+		last := comments[req.PageSize-1]
+		resp.NextCursor, err = encodeKeyset(cursorSort, last.ID, commentSortKey(last, sortColumn.column))
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode cursor: %w", err)
+		}
+	}
+	for _, comment := range comments {
+		resp.Comments = append(resp.Comments, &CommentNode{Comment: comment, Children: []*CommentNode{}})
+	}
+
+	all, err := s.loadReplies(ctx, resp.Comments, req, sortColumn.column, sortColumn.order)
+	if err != nil {
+		return nil, err
+	}
+	if req.ViewerID != nil {
+		if err := s.embedUserVotes(ctx, *req.ViewerID, all); err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
 }
 
-// GetCommentTree retrieves all comments for a post and builds a tree structure
-// Implements Requirement 5.2
-func (s *commentService) GetCommentTree(ctx context.Context, postID int64) ([]*CommentNode, error) {
-	// Get all comments for the post, ordered by path
-	comments, err := s.commentRepo.FindByPostID(ctx, postID)
+// loadReplies loads req.Depth levels of replies under nodes, req.Replies per comment
+// and level, and marks the comments with replies left unloaded. It returns every node
+// of the tree.
+func (s *commentService) loadReplies(ctx context.Context, nodes []*CommentNode, req CommentTreeRequest, sortBy, order string) ([]*CommentNode, error) {
+	all := append([]*CommentNode(nil), nodes...)
+	level := nodes
+	for depth := 0; depth < req.Depth && len(level) > 0; depth++ {
+		byID := make(map[int64]*CommentNode, len(level))
+		ids := make([]int64, len(level))
+		for i, node := range level {
+			byID[node.ID] = node
+			ids[i] = node.ID
+		}
+
+		// One reply more than shown tells whether a comment has more
+		replies, err := s.commentRepo.ListReplies(ctx, ids, repository.ReplyListOptions{
+			SortBy:    sortBy,
+			Order:     order,
+			PerParent: req.Replies + 1,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list replies: %w", err)
+		}
+
+		var next []*CommentNode
+		for _, reply := range replies {
+			parent, ok := byID[*reply.ParentID]
+			if !ok {
+				continue
+			}
+			if len(parent.Children) == req.Replies {
+				parent.MoreReplies = true
+				continue
+			}
+			child := &CommentNode{Comment: reply, Children: []*CommentNode{}}
+			parent.Children = append(parent.Children, child)
+			next = append(next, child)
+		}
+		for _, node := range level {
+			if !node.MoreReplies {
+				continue
+			}
+			last := node.Children[len(node.Children)-1]
+			node.RepliesCursor, err = encodeKeyset(commentCursorSort(req.Sort, &node.ID), last.ID, commentSortKey(last.Comment, sortBy))
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode cursor: %w", err)
+			}
+		}
+
+		all = append(all, next...)
+		level = next
+	}
+	if len(level) == 0 {
+		return all, nil
+	}
+
+	// Below the deepest loaded level, only whether replies exist is looked up
+	ids := make([]int64, len(level))
+	for i, node := range level {
+		ids[i] = node.ID
+	}
+	replied, err := s.commentRepo.FindRepliedIDs(ctx, ids)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find comments: %w", err)
+		return nil, fmt.Errorf("failed to find replied comments: %w", err)
+	}
+	hasReplies := make(map[int64]bool, len(replied))
+	for _, id := range replied {
+		hasReplies[id] = true
+	}
+	for _, node := range level {
+		node.MoreReplies = hasReplies[node.ID]
+	}
+	return all, nil
+}
+
+// embedUserVotes sets the viewer's vote on each node
+func (s *commentService) embedUserVotes(ctx context.Context, viewerID int64, nodes []*CommentNode) error {
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(nodes))
+	for i, node := range nodes {
+		ids[i] = node.ID
+	}
+	votes, err := s.batchRepo.FindUserVotesByEntityIDs(ctx, viewerID, "comment", ids)
+	if err != nil {
+		return fmt.Errorf("failed to find votes: %w", err)
+	}
+	for _, node := range nodes {
+		if vote, ok := votes[node.ID]; ok {
+			node.UserVote = vote.VoteType
+		}
 	}
+	return nil
+}
+
+// commentCursorSort names the list a comment cursor belongs to: the top-level comments
+// or the replies to one comment, in one sort
+func commentCursorSort(sort CommentSort, parentID *int64) string {
+	if parentID == nil {
+		return "comments:" + string(sort)
+	}
+	return "comments:" + strconv.FormatInt(*parentID, 10) + ":" + string(sort)
+}
+
+// commentSortKey returns a comment's value of the sort column, as stored in cursors
+func commentSortKey(comment *models.Comment, sortBy string) interface{} {
+	switch sortBy {
+	case "best_score":
+		return comment.BestScore
+	case "score":
+		return comment.Score
+	default:
+		return comment.CreatedAt
+	}
+}
 
-	// Build tree structure
-	tree := s.buildCommentTree(comments)
-	return tree, nil
+// decodeCommentCursor decodes a comment cursor, typing the sort value after the sort column
+func decodeCommentCursor(cursor, cursorSort, sortBy string) (*repository.Keyset, error) {
+	switch sortBy {
+	case "best_score":
+		var best float64
+		return decodeKeyset(cursor, cursorSort, &best)
+	case "score":
+		var score int
+		return decodeKeyset(cursor, cursorSort, &score)
+	default:
+		var createdAt time.Time
+		return decodeKeyset(cursor, cursorSort, &createdAt)
+	}
 }
 
 // DeleteComment deletes a comment (soft delete)
@@ -183,53 +470,19 @@ func (s *commentService) DeleteComment(ctx context.Context, commentID int64, use
 		return ErrUnauthorized
 	}
 
-	// Soft delete
-	if err := s.commentRepo.Delete(ctx, commentID); err != nil {
-		return fmt.Errorf("failed to delete comment: %w", err)
-	}
-
-	// Publish delete event
-	s.publishCommentDeletedEvent(ctx, comment)
-
-	return nil
-}
-
-// buildCommentTree builds a tree structure from a flat list of comments
-func (s *commentService) buildCommentTree(comments []*models.Comment) []*CommentNode {
-	// Create a map for quick lookup
-	nodeMap := make(map[int64]*CommentNode)
-	var rootNodes []*CommentNode
-
-	// First pass: create all nodes
-	for _, comment := range comments {
-		node := &CommentNode{
-			Comment:  comment,
-			Children: []*CommentNode{},
+	// Soft delete together with the delete event
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.commentRepo.Delete(ctx, commentID); err != nil {
+			return fmt.Errorf("failed to delete comment: %w", err)
 		}
-		nodeMap[comment.ID] = node
-	}
-
-	// Second pass: build tree structure
-	for _, comment := range comments {
-		node := nodeMap[comment.ID]
-		if comment.ParentID == nil {
-			// Root comment
-			rootNodes = append(rootNodes, node)
-		} else {
-			// Child comment - add to parent's children
-			if parentNode, exists := nodeMap[*comment.ParentID]; exists {
-				parentNode.Children = append(parentNode.Children, node)
-			}
-		}
-	}
-
-	return rootNodes
+		return s.publishCommentDeletedEvent(ctx, comment)
+	})
 }
 
 // publishCommentCreatedEvent publishes a comment created event
-func (s *commentService) publishCommentCreatedEvent(ctx context.Context, comment *models.Comment) {
+func (s *commentService) publishCommentCreatedEvent(ctx context.Context, comment *models.Comment) error {
 	if s.messageQueue == nil {
-		return
+		return nil
 	}
 
 	event := mq.CommentCreatedEvent{
@@ -246,14 +499,15 @@ func (s *commentService) publishCommentCreatedEvent(ctx context.Context, comment
 	}
 
 	if err := s.messageQueue.Publish(ctx, mq.TopicCommentCreated, event); err != nil {
-		fmt.Printf("failed to publish comment created event: %v\n", err)
+		return fmt.Errorf("failed to publish comment created event: %w", err)
 	}
+	return nil
 }
 
 // publishCommentDeletedEvent publishes a comment deleted event
-func (s *commentService) publishCommentDeletedEvent(ctx context.Context, comment *models.Comment) {
+func (s *commentService) publishCommentDeletedEvent(ctx context.Context, comment *models.Comment) error {
 	if s.messageQueue == nil {
-		return
+		return nil
 	}
 
 	event := mq.CommentDeletedEvent{
@@ -268,8 +522,9 @@ func (s *commentService) publishCommentDeletedEvent(ctx context.Context, comment
 	}
 
 	if err := s.messageQueue.Publish(ctx, mq.TopicCommentDeleted, event); err != nil {
-		fmt.Printf("failed to publish comment deleted event: %v\n", err)
+		return fmt.Errorf("failed to publish comment deleted event: %w", err)
 	}
+	return nil
 }
 
 // ExtractMentions extracts @mentions from comment content
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/kobayashirei/airy/internal/models"
+	"github.com/kobayashirei/airy/internal/repository"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -15,7 +16,7 @@ func TestCreateComment_RootComment(t *testing.T) {
 	mockPostRepo := new(MockPostRepository)
 	mockMQ := new(MockMessageQueue)
 
-	service := NewCommentService(mockCommentRepo, mockPostRepo, mockMQ)
+	service := NewCommentService(mockCommentRepo, mockPostRepo, nil, passthroughTransactor{}, mockMQ)
 
 	ctx := context.Background()
 	postID := int64(1)
@@ -64,7 +65,7 @@ func TestCreateComment_ReplyComment(t *testing.T) {
 	mockPostRepo := new(MockPostRepository)
 	mockMQ := new(MockMessageQueue)
 
-	service := NewCommentService(mockCommentRepo, mockPostRepo, mockMQ)
+	service := NewCommentService(mockCommentRepo, mockPostRepo, nil, passthroughTransactor{}, mockMQ)
 
 	ctx := context.Background()
 	postID := int64(1)
@@ -125,7 +126,7 @@ func TestCreateComment_PostNotFound(t *testing.T) {
 	mockPostRepo := new(MockPostRepository)
 	mockMQ := new(MockMessageQueue)
 
-	service := NewCommentService(mockCommentRepo, mockPostRepo, mockMQ)
+	service := NewCommentService(mockCommentRepo, mockPostRepo, nil, passthroughTransactor{}, mockMQ)
 
 	ctx := context.Background()
 	postID := int64(999)
@@ -156,7 +157,7 @@ func TestCreateComment_CommentsNotAllowed(t *testing.T) {
 	mockPostRepo := new(MockPostRepository)
 	mockMQ := new(MockMessageQueue)
 
-	service := NewCommentService(mockCommentRepo, mockPostRepo, mockMQ)
+	service := NewCommentService(mockCommentRepo, mockPostRepo, nil, passthroughTransactor{}, mockMQ)
 
 	ctx := context.Background()
 	postID := int64(1)
@@ -188,58 +189,106 @@ func TestGetCommentTree(t *testing.T) {
 	// Setup
 	mockCommentRepo := new(MockCommentRepository)
 	mockPostRepo := new(MockPostRepository)
+	mockBatchRepo := new(MockBatchRepository)
 	mockMQ := new(MockMessageQueue)
 
-	service := NewCommentService(mockCommentRepo, mockPostRepo, mockMQ)
+	service := NewCommentService(mockCommentRepo, mockPostRepo, mockBatchRepo, passthroughTransactor{}, mockMQ)
 
 	ctx := context.Background()
 	postID := int64(1)
+	viewerID := int64(100)
+
+	// Three top-level comments for a page of two, and two replies to the first for one per comment
+	root1 := &models.Comment{ID: 1, PostID: postID, RootID: 1, Path: "1", Status: "published", Score: 9, BestScore: 0.8}
+	root3 := &models.Comment{ID: 3, PostID: postID, RootID: 3, Path: "3", Status: "published", Score: 5, BestScore: 0.6}
+	root4 := &models.Comment{ID: 4, PostID: postID, RootID: 4, Path: "4", Status: "published", Score: 1, BestScore: 0.2}
+	reply2 := &models.Comment{ID: 2, PostID: postID, ParentID: int64Ptr(1), Level: 1, RootID: 1, Path: "1.2", Status: "published", BestScore: 0.5}
+	reply5 := &models.Comment{ID: 5, PostID: postID, ParentID: int64Ptr(1), Level: 1, RootID: 1, Path: "1.5", Status: "published", BestScore: 0.1}
+
+	mockCommentRepo.On("List", ctx, mock.MatchedBy(func(opts repository.CommentListOptions) bool {
+		return *opts.PostID == postID && opts.RootOnly && opts.Status == "published" &&
+			opts.SortBy == "best_score" && opts.Order == "desc" && opts.Limit == 3 && opts.After == nil
+	})).Return([]*models.Comment{root1, root3, root4}, nil)
+	mockCommentRepo.On("ListReplies", ctx, []int64{1, 3}, repository.ReplyListOptions{
+		SortBy: "best_score", Order: "desc", PerParent: 2,
+	}).Return([]*models.Comment{reply2, reply5}, nil)
+	mockCommentRepo.On("FindRepliedIDs", ctx, []int64{2}).Return([]int64{2}, nil)
+	mockBatchRepo.On("FindUserVotesByEntityIDs", ctx, viewerID, "comment", []int64{1, 3, 2}).Return(map[int64]*models.Vote{
+		2: {EntityID: 2, VoteType: "up"},
+	}, nil)
 
-	// Create test comments
-	comment1 := &models.Comment{
-		ID:       1,
-		PostID:   postID,
-		ParentID: nil,
-		Level:    0,
-		RootID:   1,
-		Path:     "1",
-		Content:  "Root comment 1",
-	}
-	comment2 := &models.Comment{
-		ID:       2,
+	// Execute
+	tree, err := service.GetCommentTree(ctx, CommentTreeRequest{
 		PostID:   postID,
-		ParentID: func() *int64 { id := int64(1); return &id }(),
-		Level:    1,
-		RootID:   1,
-		Path:     "1.2",
-		Content:  "Reply to comment 1",
-	}
-	comment3 := &models.Comment{
-		ID:       3,
+		PageSize: 2,
+		Depth:    1,
+		Replies:  1,
+		ViewerID: &viewerID,
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, CommentSortBest, tree.Sort)
+	assert.NotEmpty(t, tree.NextCursor)
+	assert.Len(t, tree.Comments, 2) // One page of root comments
+	first, second := tree.Comments[0], tree.Comments[1]
+	assert.Len(t, first.Children, 1) // First root shows one of its two replies
+	assert.True(t, first.MoreReplies)
+	assert.NotEmpty(t, first.RepliesCursor)
+	assert.Equal(t, "up", first.Children[0].UserVote)
+	assert.True(t, first.Children[0].MoreReplies) // Its replies are below the loaded depth
+	assert.Empty(t, first.Children[0].RepliesCursor)
+	assert.Len(t, second.Children, 0) // Second root has no replies
+	assert.False(t, second.MoreReplies)
+	assert.Empty(t, second.UserVote)
+
+	// The replies cursor continues the first root's replies after the one shown
+	mockCommentRepo.On("FindByID", ctx, int64(1)).Return(root1, nil)
+	mockCommentRepo.On("List", ctx, mock.MatchedBy(func(opts repository.CommentListOptions) bool {
+		return opts.ParentID != nil && *opts.ParentID == 1 && !opts.RootOnly && opts.Limit == 21 &&
+			opts.After != nil && opts.After.ID == 2 && opts.After.Values[0] == 0.5
+	})).Return([]*models.Comment{reply5}, nil)
+	mockCommentRepo.On("ListReplies", ctx, []int64{5}, mock.Anything).Return([]*models.Comment{}, nil)
+
+	replies, err := service.GetCommentTree(ctx, CommentTreeRequest{
 		PostID:   postID,
-		ParentID: nil,
-		Level:    0,
-		RootID:   3,
-		Path:     "3",
-		Content:  "Root comment 2",
-	}
+		ParentID: int64Ptr(1),
+		Cursor:   first.RepliesCursor,
+		Depth:    1,
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, replies.NextCursor)
+	assert.Len(t, replies.Comments, 1)
+	assert.Equal(t, int64(5), replies.Comments[0].ID)
 
-	comments := []*models.Comment{comment1, comment2, comment3}
+	mockCommentRepo.AssertExpectations(t)
+	mockBatchRepo.AssertExpectations(t)
+}
 
-	// Mock repository
-	mockCommentRepo.On("FindByPostID", ctx, postID).Return(comments, nil)
+func TestGetCommentTree_InvalidRequest(t *testing.T) {
+	// Setup
+	mockCommentRepo := new(MockCommentRepository)
+	service := NewCommentService(mockCommentRepo, nil, nil, passthroughTransactor{}, nil)
 
-	// Execute
-	tree, err := service.GetCommentTree(ctx, postID)
+	ctx := context.Background()
+	postID := int64(1)
+	mockCommentRepo.On("FindByID", ctx, int64(7)).Return(&models.Comment{ID: 7, PostID: 2, Status: "published"}, nil)
+	mockCommentRepo.On("FindByID", ctx, int64(8)).Return(&models.Comment{ID: 8, PostID: postID, Status: "published"}, nil)
 
-	// Assert
-	assert.NoError(t, err)
-	assert.NotNil(t, tree)
-	assert.Len(t, tree, 2) // Two root comments
-	assert.Len(t, tree[0].Children, 1) // First root has one child
-	assert.Len(t, tree[1].Children, 0) // Second root has no children
+	_, err := service.GetCommentTree(ctx, CommentTreeRequest{PostID: postID, Sort: "hot"})
+	assert.ErrorIs(t, err, ErrInvalidCommentSort)
 
-	mockCommentRepo.AssertExpectations(t)
+	// Replies continue only under a comment of the same post
+	_, err = service.GetCommentTree(ctx, CommentTreeRequest{PostID: postID, ParentID: int64Ptr(7)})
+	assert.ErrorIs(t, err, ErrCommentNotFound)
+
+	// A cursor is only valid for the list and sort it was issued for
+	cursor, err := encodeKeyset(commentCursorSort(CommentSortBest, int64Ptr(9)), 10, 0.5)
+	assert.NoError(t, err)
+	_, err = service.GetCommentTree(ctx, CommentTreeRequest{PostID: postID, ParentID: int64Ptr(8), Cursor: cursor})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+	_, err = service.GetCommentTree(ctx, CommentTreeRequest{PostID: postID, Sort: CommentSortTop, Cursor: cursor})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
 }
 
 func TestDeleteComment(t *testing.T) {
@@ -248,7 +297,7 @@ func TestDeleteComment(t *testing.T) {
 	mockPostRepo := new(MockPostRepository)
 	mockMQ := new(MockMessageQueue)
 
-	service := NewCommentService(mockCommentRepo, mockPostRepo, mockMQ)
+	service := NewCommentService(mockCommentRepo, mockPostRepo, nil, passthroughTransactor{}, mockMQ)
 
 	ctx := context.Background()
 	commentID := int64(1)
@@ -282,7 +331,7 @@ func TestDeleteComment_Unauthorized(t *testing.T) {
 	mockPostRepo := new(MockPostRepository)
 	mockMQ := new(MockMessageQueue)
 
-	service := NewCommentService(mockCommentRepo, mockPostRepo, mockMQ)
+	service := NewCommentService(mockCommentRepo, mockPostRepo, nil, passthroughTransactor{}, mockMQ)
 
 	ctx := context.Background()
 	commentID := int64(1)
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kobayashirei/airy/internal/models"
+	"github.com/kobayashirei/airy/internal/mq"
+	"github.com/kobayashirei/airy/internal/repository"
+)
+
+var (
+	// ErrDeadLetterNotFound is returned when a dead letter doesn't exist
+	ErrDeadLetterNotFound = errors.New("dead letter not found")
+	// ErrDeadLetterReplayUnavailable is returned when replaying without a message queue that supports it
+	ErrDeadLetterReplayUnavailable = errors.New("dead letter replay is unavailable without a message queue")
+)
+
+// DeadLetterService defines the interface for dead letter operations
+type DeadLetterService interface {
+	// Record stores a dead letter from the message queue; it is an mq.DeadLetterHandler
+	Record(ctx context.Context, letter *mq.DeadLetter) error
+	List(ctx context.Context, req DeadLetterListRequest) (*DeadLetterListResponse, error)
+	Get(ctx context.Context, id int64) (*models.DeadLetter, error)
+	Replay(ctx context.Context, operatorID, id int64, ip string) (*models.DeadLetter, error)
+	Delete(ctx context.Context, operatorID, id int64, ip string) error
+	Purge(ctx context.Context, operatorID int64, req DeadLetterPurgeRequest, ip string) (int64, error)
+}
+
+// DeadLetterListRequest represents a request to list dead letters
+type DeadLetterListRequest struct {
+	ConsumerGroup string `json:"consumer_group"`
+	Topic         string `json:"topic"`
+	Status        string `json:"status"`
+	Cursor        string `json:"cursor"` // next_cursor of the previous page; empty for the first page
+	PageSize      int    `json:"page_size"`
+}
+
+// DeadLetterListResponse represents a page of dead letters
+type DeadLetterListResponse struct {
+	DeadLetters []*models.DeadLetter `json:"dead_letters"`
+	Total       int64                `json:"total"`
+	PageSize    int                  `json:"page_size"`
+	NextCursor  string               `json:"next_cursor,omitempty"`
+}
+
+// DeadLetterPurgeRequest selects the dead letters to purge; empty fields match everything
+type DeadLetterPurgeRequest struct {
+	ConsumerGroup string `json:"consumer_group"`
+	Topic         string `json:"topic"`
+	Status        string `json:"status"`
+}
+
+// deadLetterCursorSort names the dead letter list ordering in pagination cursors
+const deadLetterCursorSort = "dead_letters"
+
+// deadLetterService implements DeadLetterService interface
+type deadLetterService struct {
+	deadLetterRepo  repository.DeadLetterRepository
+	adminLogRepo    repository.AdminLogRepository
+	deadLetterQueue mq.DeadLetterQueue
+}
+
+// NewDeadLetterService creates a new dead letter service.
+// deadLetterQueue may be nil, in which case dead letters can be inspected but not replayed.
+func NewDeadLetterService(
+	deadLetterRepo repository.DeadLetterRepository,
+	adminLogRepo repository.AdminLogRepository,
+	deadLetterQueue mq.DeadLetterQueue,
+) DeadLetterService {
+	return &deadLetterService{
+		deadLetterRepo:  deadLetterRepo,
+		adminLogRepo:    adminLogRepo,
+		deadLetterQueue: deadLetterQueue,
+	}
+}
+
+// Record stores a dead letter
+func (s *deadLetterService) Record(ctx context.Context, letter *mq.DeadLetter) error {
+	payload := string(letter.Payload)
+	if payload == "" {
+		payload = "null"
+	}
+
+	record := &models.DeadLetter{
+		LetterID:      letter.ID,
+		Queue:         letter.Queue,
+		ConsumerGroup: letter.Group,
+		Topic:         letter.Topic,
+		Payload:       payload,
+		Attempts:      letter.Attempts,
+		LastError:     letter.LastError,
+		Status:        models.DeadLetterStatusDead,
+		FailedAt:      letter.FailedAt,
+		CreatedAt:     time.Now(),
+	}
+	if err := s.deadLetterRepo.Create(ctx, record); err != nil {
+		return fmt.Errorf("failed to record dead letter: %w", err)
+	}
+	return nil
+}
+
+// List retrieves dead letters, newest first
+func (s *deadLetterService) List(ctx context.Context, req DeadLetterListRequest) (*DeadLetterListResponse, error) {
+	// Set default pagination
+	if req.PageSize < 1 {
+		req.PageSize = 20
+	}
+	if req.PageSize > 100 {
+		req.PageSize = 100
+	}
+
+	var createdAt time.Time
+	after, err := decodeKeyset(req.Cursor, deadLetterCursorSort, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra row to know whether another page exists
+	opts := repository.DeadLetterListOptions{
+		ConsumerGroup: req.ConsumerGroup,
+		Topic:         req.Topic,
+		Status:        req.Status,
+		Limit:         req.PageSize + 1,
+		After:         after,
+	}
+
+	letters, err := s.deadLetterRepo.List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+
+	var nextCursor string
+	if len(letters) > req.PageSize {
+		letters = letters[:req.PageSize]
+		last := letters[req.PageSize-1]
+		nextCursor, err = encodeKeyset(deadLetterCursorSort, last.ID, last.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode cursor: %w", err)
+		}
+	}
+
+	total, err := s.deadLetterRepo.Count(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count dead letters: %w", err)
+	}
+
+	return &DeadLetterListResponse{
+		DeadLetters: letters,
+		Total:       total,
+		PageSize:    req.PageSize,
+		NextCursor:  nextCursor,
+	}, nil
+}
+
+// Get retrieves a dead letter by ID
+func (s *deadLetterService) Get(ctx context.Context, id int64) (*models.DeadLetter, error) {
+	letter, err := s.deadLetterRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find dead letter: %w", err)
+	}
+	if letter == nil {
+		return nil, ErrDeadLetterNotFound
+	}
+	return letter, nil
+}
+
+// Replay delivers a dead-lettered message again to the consumers it failed in
+func (s *deadLetterService) Replay(ctx context.Context, operatorID, id int64, ip string) (*models.DeadLetter, error) {
+	if s.deadLetterQueue == nil {
+		return nil, ErrDeadLetterReplayUnavailable
+	}
+
+	letter, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.deadLetterQueue.Replay(ctx, &mq.DeadLetter{
+		ID:        letter.LetterID,
+		Queue:     letter.Queue,
+		Group:     letter.ConsumerGroup,
+		Topic:     letter.Topic,
+		Attempts:  letter.Attempts,
+		LastError: letter.LastError,
+		FailedAt:  letter.FailedAt,
+		Payload:   json.RawMessage(letter.Payload),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to replay dead letter: %w", err)
+	}
+
+	now := time.Now()
+	if err := s.deadLetterRepo.MarkReplayed(ctx, id, now); err != nil {
+		return nil, fmt.Errorf("failed to mark dead letter replayed: %w", err)
+	}
+	letter.Status = models.DeadLetterStatusReplayed
+	letter.ReplayCount++
+	letter.ReplayedAt = &now
+
+	s.logAction(ctx, operatorID, "replay_dead_letter", &id, ip, map[string]interface{}{
+		"topic":          letter.Topic,
+		"consumer_group": letter.ConsumerGroup,
+	})
+	return letter, nil
+}
+
+// Delete removes a dead letter
+func (s *deadLetterService) Delete(ctx context.Context, operatorID, id int64, ip string) error {
+	if _, err := s.Get(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.deadLetterRepo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete dead letter: %w", err)
+	}
+
+	s.logAction(ctx, operatorID, "delete_dead_letter", &id, ip, nil)
+	return nil
+}
+
+// Purge removes every dead letter matching the request
+func (s *deadLetterService) Purge(ctx context.Context, operatorID int64, req DeadLetterPurgeRequest, ip string) (int64, error) {
+	deleted, err := s.deadLetterRepo.DeleteMatching(ctx, repository.DeadLetterListOptions{
+		ConsumerGroup: req.ConsumerGroup,
+		Topic:         req.Topic,
+		Status:        req.Status,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge dead letters: %w", err)
+	}
+
+	s.logAction(ctx, operatorID, "purge_dead_letters", nil, ip, map[string]interface{}{
+		"consumer_group": req.ConsumerGroup,
+		"topic":          req.Topic,
+		"status":         req.Status,
+		"deleted":        deleted,
+	})
+	return deleted, nil
+}
+
+// logAction records an administrator's action on dead letters in the admin log
+func (s *deadLetterService) logAction(ctx context.Context, operatorID int64, action string, entityID *int64, ip string, details map[string]interface{}) {
+	detailsJSON, _ := json.Marshal(details)
+	log := &models.AdminLog{
+		OperatorID: operatorID,
+		Action:     action,
+		EntityType: "dead_letter",
+		EntityID:   entityID,
+		IP:         ip,
+		Details:    string(detailsJSON),
+		CreatedAt:  time.Now(),
+	}
+	if err := s.adminLogRepo.Create(ctx, log); err != nil {
+		// Log error but don't fail the operation
+		fmt.Printf("failed to create admin log: %v\n", err)
+	}
+}
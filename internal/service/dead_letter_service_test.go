@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kobayashirei/airy/internal/models"
+	"github.com/kobayashirei/airy/internal/mq"
+	"github.com/kobayashirei/airy/internal/repository"
+)
+
+func TestDeadLetterService_Record(t *testing.T) {
+	repo := new(MockDeadLetterRepository)
+	svc := NewDeadLetterService(repo, new(MockAdminLogRepository), nil)
+
+	failedAt := time.Now()
+	repo.On("Create", mock.Anything, mock.MatchedBy(func(letter *models.DeadLetter) bool {
+		return letter.LetterID == "letter-1" &&
+			letter.Queue == "airy.search.post.published" &&
+			letter.ConsumerGroup == "search" &&
+			letter.Topic == mq.TopicPostPublished &&
+			letter.Payload == `{"post_id":1}` &&
+			letter.Attempts == 5 &&
+			letter.LastError == "index unavailable" &&
+			letter.Status == models.DeadLetterStatusDead &&
+			letter.FailedAt.Equal(failedAt)
+	})).Return(nil)
+
+	err := svc.Record(context.Background(), &mq.DeadLetter{
+		ID:        "letter-1",
+		Queue:     "airy.search.post.published",
+		Group:     "search",
+		Topic:     mq.TopicPostPublished,
+		Attempts:  5,
+		LastError: "index unavailable",
+		FailedAt:  failedAt,
+		Payload:   json.RawMessage(`{"post_id":1}`),
+	})
+
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestDeadLetterService_List(t *testing.T) {
+	repo := new(MockDeadLetterRepository)
+	svc := NewDeadLetterService(repo, new(MockAdminLogRepository), nil)
+
+	now := time.Now()
+	letters := []*models.DeadLetter{
+		{ID: 3, CreatedAt: now},
+		{ID: 2, CreatedAt: now.Add(-time.Minute)},
+		{ID: 1, CreatedAt: now.Add(-2 * time.Minute)},
+	}
+	repo.On("List", mock.Anything, mock.MatchedBy(func(opts repository.DeadLetterListOptions) bool {
+		return opts.Topic == mq.TopicPostPublished && opts.Limit == 3 && opts.After == nil
+	})).Return(letters, nil)
+	repo.On("Count", mock.Anything, mock.Anything).Return(int64(3), nil)
+
+	result, err := svc.List(context.Background(), DeadLetterListRequest{Topic: mq.TopicPostPublished, PageSize: 2})
+
+	require.NoError(t, err)
+	assert.Len(t, result.DeadLetters, 2)
+	assert.Equal(t, int64(3), result.Total)
+	assert.NotEmpty(t, result.NextCursor)
+
+	// The cursor continues after the last row of the page
+	repo.On("List", mock.Anything, mock.MatchedBy(func(opts repository.DeadLetterListOptions) bool {
+		return opts.After != nil && opts.After.ID == 2
+	})).Return(letters[2:], nil)
+
+	next, err := svc.List(context.Background(), DeadLetterListRequest{Topic: mq.TopicPostPublished, PageSize: 2, Cursor: result.NextCursor})
+
+	require.NoError(t, err)
+	assert.Len(t, next.DeadLetters, 1)
+	assert.Empty(t, next.NextCursor)
+}
+
+func TestDeadLetterService_Replay(t *testing.T) {
+	letter := &models.DeadLetter{
+		ID:            7,
+		LetterID:      "letter-7",
+		Queue:         "airy.search.post.published",
+		ConsumerGroup: "search",
+		Topic:         mq.TopicPostPublished,
+		Payload:       `{"post_id":1}`,
+		Status:        models.DeadLetterStatusDead,
+	}
+
+	t.Run("replays to the consumer group and marks the letter replayed", func(t *testing.T) {
+		repo := new(MockDeadLetterRepository)
+		logRepo := new(MockAdminLogRepository)
+		queue := new(MockDeadLetterQueue)
+		svc := NewDeadLetterService(repo, logRepo, queue)
+
+		stored := *letter
+		repo.On("FindByID", mock.Anything, int64(7)).Return(&stored, nil)
+		queue.On("Replay", mock.Anything, mock.MatchedBy(func(replayed *mq.DeadLetter) bool {
+			return replayed.Group == "search" &&
+				replayed.Queue == "airy.search.post.published" &&
+				string(replayed.Payload) == `{"post_id":1}`
+		})).Return(nil)
+		repo.On("MarkReplayed", mock.Anything, int64(7), mock.Anything).Return(nil)
+		logRepo.On("Create", mock.Anything, mock.MatchedBy(func(log *models.AdminLog) bool {
+			return log.Action == "replay_dead_letter" && log.OperatorID == 1 && *log.EntityID == 7
+		})).Return(nil)
+
+		replayed, err := svc.Replay(context.Background(), 1, 7, "127.0.0.1")
+
+		require.NoError(t, err)
+		assert.Equal(t, models.DeadLetterStatusReplayed, replayed.Status)
+		assert.Equal(t, 1, replayed.ReplayCount)
+		assert.NotNil(t, replayed.ReplayedAt)
+		repo.AssertExpectations(t)
+		queue.AssertExpectations(t)
+		logRepo.AssertExpectations(t)
+	})
+
+	t.Run("keeps the letter dead when the replay fails", func(t *testing.T) {
+		repo := new(MockDeadLetterRepository)
+		queue := new(MockDeadLetterQueue)
+		svc := NewDeadLetterService(repo, new(MockAdminLogRepository), queue)
+
+		stored := *letter
+		repo.On("FindByID", mock.Anything, int64(7)).Return(&stored, nil)
+		queue.On("Replay", mock.Anything, mock.Anything).Return(errors.New("connection closed"))
+
+		_, err := svc.Replay(context.Background(), 1, 7, "127.0.0.1")
+
+		assert.Error(t, err)
+		repo.AssertNotCalled(t, "MarkReplayed", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("returns not found for an unknown letter", func(t *testing.T) {
+		repo := new(MockDeadLetterRepository)
+		svc := NewDeadLetterService(repo, new(MockAdminLogRepository), new(MockDeadLetterQueue))
+
+		repo.On("FindByID", mock.Anything, int64(8)).Return(nil, nil)
+
+		_, err := svc.Replay(context.Background(), 1, 8, "127.0.0.1")
+
+		assert.ErrorIs(t, err, ErrDeadLetterNotFound)
+	})
+
+	t.Run("is unavailable without a dead-letter queue", func(t *testing.T) {
+		svc := NewDeadLetterService(new(MockDeadLetterRepository), new(MockAdminLogRepository), nil)
+
+		_, err := svc.Replay(context.Background(), 1, 7, "127.0.0.1")
+
+		assert.ErrorIs(t, err, ErrDeadLetterReplayUnavailable)
+	})
+}
+
+func TestDeadLetterService_Purge(t *testing.T) {
+	repo := new(MockDeadLetterRepository)
+	logRepo := new(MockAdminLogRepository)
+	svc := NewDeadLetterService(repo, logRepo, nil)
+
+	repo.On("DeleteMatching", mock.Anything, repository.DeadLetterListOptions{
+		ConsumerGroup: "search",
+		Status:        models.DeadLetterStatusReplayed,
+	}).Return(int64(4), nil)
+	logRepo.On("Create", mock.Anything, mock.MatchedBy(func(log *models.AdminLog) bool {
+		return log.Action == "purge_dead_letters" && log.EntityID == nil
+	})).Return(nil)
+
+	deleted, err := svc.Purge(context.Background(), 1, DeadLetterPurgeRequest{
+		ConsumerGroup: "search",
+		Status:        models.DeadLetterStatusReplayed,
+	}, "127.0.0.1")
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), deleted)
+	repo.AssertExpectations(t)
+	logRepo.AssertExpectations(t)
+}
@@ -10,6 +10,8 @@ type EmailService interface {
 	SendActivationEmail(ctx context.Context, email, token string) error
 	SendVerificationCode(ctx context.Context, email, code string) error
 	SendPasswordResetEmail(ctx context.Context, email, token string) error
+	// SendEmail sends an email with the given subject and body
+	SendEmail(ctx context.Context, to, subject, body string) error
 }
 
 // emailService implements EmailService interface
@@ -46,3 +48,10 @@ func (s *emailService) SendPasswordResetEmail(ctx context.Context, email, token
 	fmt.Printf("Password reset email sent to %s: %s\n", email, resetLink)
 	return nil
 }
+
+// SendEmail sends an email with the given subject and body
+func (s *emailService) SendEmail(ctx context.Context, to, subject, body string) error {
+	// In production, this would send an actual email
+	fmt.Printf("Email sent to %s: %s\n", to, subject)
+	return nil
+}
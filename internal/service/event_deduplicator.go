@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/kobayashirei/airy/internal/cache"
+	"github.com/kobayashirei/airy/internal/mq"
+)
+
+// ErrEventInProgress is returned for an event another consumer of the group is handling;
+// the message is retried later, when the claim is either processed or released
+var ErrEventInProgress = errors.New("event is being handled by another consumer")
+
+// States stored under a processed event key
+const (
+	eventStateClaimed   = "claimed"
+	eventStateProcessed = "processed"
+)
+
+// Default lifetimes of processed event keys
+const (
+	DefaultEventClaimTTL     = 5 * time.Minute
+	DefaultProcessedEventTTL = 72 * time.Hour
+)
+
+// duplicateEventsTotal counts redelivered events acknowledged without handling them again
+var duplicateEventsTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "mq_duplicate_events_total",
+		Help: "Total number of redelivered events skipped because the consumer group already handled them",
+	},
+	[]string{"group"},
+)
+
+// EventDeduplicator makes event handlers idempotent per consumer group, keyed on
+// BaseEvent.EventID. Before the handler runs, SETNX claims the event for claimTTL;
+// the claim becomes a processed marker kept for processedTTL once the handler
+// succeeds, and is released when it fails so the retry handles the event again.
+// A redelivery of a processed event is acknowledged without calling the handler.
+type EventDeduplicator struct {
+	cache        cache.Service
+	keys         *cache.KeyGenerator
+	claimTTL     time.Duration
+	processedTTL time.Duration
+	log          *zap.Logger
+}
+
+// NewEventDeduplicator creates a new event deduplicator.
+// claimTTL bounds how long a crashed consumer blocks an event, and processedTTL how long
+// after handling an event its redeliveries are recognized; zero selects the defaults.
+func NewEventDeduplicator(cacheService cache.Service, claimTTL, processedTTL time.Duration, log *zap.Logger) *EventDeduplicator {
+	if claimTTL <= 0 {
+		claimTTL = DefaultEventClaimTTL
+	}
+	if processedTTL <= 0 {
+		processedTTL = DefaultProcessedEventTTL
+	}
+	if log == nil {
+		log = zap.NewNop()
+	}
+
+	return &EventDeduplicator{
+		cache:        cacheService,
+		keys:         cache.NewKeyGenerator(),
+		claimTTL:     claimTTL,
+		processedTTL: processedTTL,
+		log:          log,
+	}
+}
+
+// Wrap returns a handler that handles each event at most once for the consumer group.
+// Messages without an event ID are passed through. A nil deduplicator returns handler
+// unchanged, so consumers run without deduplication when Redis is disabled.
+func (d *EventDeduplicator) Wrap(group string, handler mq.MessageHandler) mq.MessageHandler {
+	if d == nil {
+		return handler
+	}
+
+	return func(ctx context.Context, message []byte) error {
+		event, err := mq.DecodeEnvelope(message)
+		if err != nil || event.ID == "" {
+			// Nothing to deduplicate on
+			return handler(ctx, message)
+		}
+
+		key := d.keys.ProcessedEventKey(group, event.ID)
+		claimed, err := d.cache.SetNX(ctx, key, eventStateClaimed, d.claimTTL)
+		if err != nil {
+			// Handling an event twice beats not handling it while Redis is down
+			d.log.Warn("failed to claim event, handling it without deduplication",
+				zap.String("group", group),
+				zap.String("event_id", event.ID),
+				zap.Error(err))
+			return handler(ctx, message)
+		}
+
+		if !claimed {
+			var state string
+			if err := d.cache.Get(ctx, key, &state); err == nil && state == eventStateProcessed {
+				duplicateEventsTotal.WithLabelValues(group).Inc()
+				d.log.Debug("skipping duplicate event",
+					zap.String("group", group),
+					zap.String("event_id", event.ID),
+					zap.String("event_type", event.Topic))
+				return nil
+			}
+			return fmt.Errorf("%w: %s", ErrEventInProgress, event.ID)
+		}
+
+		if err := handler(ctx, message); err != nil {
+			if releaseErr := d.cache.Delete(ctx, key); releaseErr != nil {
+				// The claim expires after claimTTL; until then retries report the event in progress
+				d.log.Warn("failed to release event claim",
+					zap.String("group", group),
+					zap.String("event_id", event.ID),
+					zap.Error(releaseErr))
+			}
+			return err
+		}
+
+		if err := d.cache.Set(ctx, key, eventStateProcessed, d.processedTTL); err != nil {
+			// The claim expires after claimTTL, after which a redelivery is handled again
+			d.log.Warn("failed to mark event processed",
+				zap.String("group", group),
+				zap.String("event_id", event.ID),
+				zap.Error(err))
+		}
+		return nil
+	}
+}
+
+// Queue returns a message queue whose SubscribeGroup wraps every handler with Wrap.
+// Subscribe is passed through: each of its subscribers is meant to see every event.
+// A nil deduplicator returns messageQueue unchanged.
+func (d *EventDeduplicator) Queue(messageQueue mq.MessageQueue) mq.MessageQueue {
+	if d == nil || messageQueue == nil {
+		return messageQueue
+	}
+	return &deduplicatingQueue{MessageQueue: messageQueue, deduplicator: d}
+}
+
+// deduplicatingQueue deduplicates the events of consumer group subscriptions
+type deduplicatingQueue struct {
+	mq.MessageQueue
+	deduplicator *EventDeduplicator
+}
+
+// SubscribeGroup subscribes the deduplicated handler to the topic
+func (q *deduplicatingQueue) SubscribeGroup(group, topic string, handler mq.MessageHandler) error {
+	return q.MessageQueue.SubscribeGroup(group, topic, q.deduplicator.Wrap(group, handler))
+}
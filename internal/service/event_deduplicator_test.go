@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kobayashirei/airy/internal/cache"
+	"github.com/kobayashirei/airy/internal/mq"
+)
+
+func TestEventDeduplicator_Wrap(t *testing.T) {
+	event, err := json.Marshal(mq.VoteCreatedEvent{
+		BaseEvent:  mq.BaseEvent{EventID: "event-1", EventType: mq.TopicVoteCreated},
+		EntityType: "post",
+		EntityID:   1,
+	})
+	require.NoError(t, err)
+	key := cache.NewKeyGenerator().ProcessedEventKey(VoteConsumerGroup, "event-1")
+
+	t.Run("handles a new event and marks it processed", func(t *testing.T) {
+		cacheService := new(MockCacheService)
+		cacheService.On("SetNX", mock.Anything, key, eventStateClaimed, DefaultEventClaimTTL).Return(true, nil)
+		cacheService.On("Set", mock.Anything, key, eventStateProcessed, DefaultProcessedEventTTL).Return(nil)
+
+		calls := 0
+		handler := NewEventDeduplicator(cacheService, 0, 0, nil).Wrap(VoteConsumerGroup, func(ctx context.Context, message []byte) error {
+			calls++
+			return nil
+		})
+
+		require.NoError(t, handler(context.Background(), event))
+		assert.Equal(t, 1, calls)
+		cacheService.AssertExpectations(t)
+	})
+
+	t.Run("skips an event the group already processed", func(t *testing.T) {
+		cacheService := new(MockCacheService)
+		cacheService.On("SetNX", mock.Anything, key, eventStateClaimed, DefaultEventClaimTTL).Return(false, nil)
+		cacheService.On("Get", mock.Anything, key, mock.Anything).Run(func(args mock.Arguments) {
+			*args.Get(2).(*string) = eventStateProcessed
+		}).Return(nil)
+
+		handler := NewEventDeduplicator(cacheService, 0, 0, nil).Wrap(VoteConsumerGroup, func(ctx context.Context, message []byte) error {
+			t.Fatal("handler called for a duplicate event")
+			return nil
+		})
+
+		assert.NoError(t, handler(context.Background(), event))
+	})
+
+	t.Run("retries an event another consumer is handling", func(t *testing.T) {
+		cacheService := new(MockCacheService)
+		cacheService.On("SetNX", mock.Anything, key, eventStateClaimed, DefaultEventClaimTTL).Return(false, nil)
+		cacheService.On("Get", mock.Anything, key, mock.Anything).Run(func(args mock.Arguments) {
+			*args.Get(2).(*string) = eventStateClaimed
+		}).Return(nil)
+
+		handler := NewEventDeduplicator(cacheService, 0, 0, nil).Wrap(VoteConsumerGroup, func(ctx context.Context, message []byte) error {
+			t.Fatal("handler called for a claimed event")
+			return nil
+		})
+
+		assert.ErrorIs(t, handler(context.Background(), event), ErrEventInProgress)
+	})
+
+	t.Run("releases the claim when the handler fails", func(t *testing.T) {
+		cacheService := new(MockCacheService)
+		cacheService.On("SetNX", mock.Anything, key, eventStateClaimed, DefaultEventClaimTTL).Return(true, nil)
+		cacheService.On("Delete", mock.Anything, key).Return(nil)
+
+		handlerErr := errors.New("database unavailable")
+		handler := NewEventDeduplicator(cacheService, 0, 0, nil).Wrap(VoteConsumerGroup, func(ctx context.Context, message []byte) error {
+			return handlerErr
+		})
+
+		assert.ErrorIs(t, handler(context.Background(), event), handlerErr)
+		cacheService.AssertExpectations(t)
+		cacheService.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("handles the event when Redis fails", func(t *testing.T) {
+		cacheService := new(MockCacheService)
+		cacheService.On("SetNX", mock.Anything, key, eventStateClaimed, DefaultEventClaimTTL).Return(false, errors.New("connection refused"))
+
+		calls := 0
+		handler := NewEventDeduplicator(cacheService, 0, 0, nil).Wrap(VoteConsumerGroup, func(ctx context.Context, message []byte) error {
+			calls++
+			return nil
+		})
+
+		require.NoError(t, handler(context.Background(), event))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("passes through messages without an event ID", func(t *testing.T) {
+		calls := 0
+		handler := NewEventDeduplicator(new(MockCacheService), 0, 0, nil).Wrap(VoteConsumerGroup, func(ctx context.Context, message []byte) error {
+			calls++
+			return nil
+		})
+
+		require.NoError(t, handler(context.Background(), []byte(`{"post_id":1}`)))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("a nil deduplicator leaves the handler alone", func(t *testing.T) {
+		var deduplicator *EventDeduplicator
+		calls := 0
+		handler := deduplicator.Wrap(VoteConsumerGroup, func(ctx context.Context, message []byte) error {
+			calls++
+			return nil
+		})
+
+		require.NoError(t, handler(context.Background(), event))
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestEventDeduplicator_Queue(t *testing.T) {
+	messageQueue := new(MockMessageQueue)
+	cacheService := new(MockCacheService)
+	deduplicator := NewEventDeduplicator(cacheService, 0, 0, nil)
+
+	var subscribed mq.MessageHandler
+	messageQueue.On("SubscribeGroup", VoteConsumerGroup, mq.TopicVoteCreated, mock.Anything).Run(func(args mock.Arguments) {
+		subscribed = args.Get(2).(mq.MessageHandler)
+	}).Return(nil)
+
+	calls := 0
+	err := deduplicator.Queue(messageQueue).SubscribeGroup(VoteConsumerGroup, mq.TopicVoteCreated, func(ctx context.Context, message []byte) error {
+		calls++
+		return nil
+	})
+	require.NoError(t, err)
+	require.NotNil(t, subscribed)
+
+	// The subscribed handler claims the event before handling it
+	cacheService.On("SetNX", mock.Anything, "processed_event:votes:event-2", eventStateClaimed, DefaultEventClaimTTL).Return(true, nil)
+	cacheService.On("Set", mock.Anything, "processed_event:votes:event-2", eventStateProcessed, DefaultProcessedEventTTL).Return(nil)
+
+	require.NoError(t, subscribed(context.Background(), []byte(`{"event_id":"event-2"}`)))
+	assert.Equal(t, 1, calls)
+	cacheService.AssertExpectations(t)
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kobayashirei/airy/internal/models"
+	"github.com/kobayashirei/airy/internal/mq"
+	"github.com/kobayashirei/airy/internal/repository"
+)
+
+// FavoriteConsumer handles favorite events from the message queue
+type FavoriteConsumer struct {
+	entityCountRepo repository.EntityCountRepository
+}
+
+// NewFavoriteConsumer creates a new favorite consumer
+func NewFavoriteConsumer(entityCountRepo repository.EntityCountRepository) *FavoriteConsumer {
+	return &FavoriteConsumer{
+		entityCountRepo: entityCountRepo,
+	}
+}
+
+// HandlePostFavorited handles post favorited events
+func (c *FavoriteConsumer) HandlePostFavorited(ctx context.Context, event *mq.PostFavoritedEvent) error {
+	if err := c.updateFavoriteCount(ctx, event.PostID, 1); err != nil {
+		return fmt.Errorf("failed to update entity count: %w", err)
+	}
+
+	return nil
+}
+
+// HandlePostUnfavorited handles post unfavorited events
+func (c *FavoriteConsumer) HandlePostUnfavorited(ctx context.Context, event *mq.PostUnfavoritedEvent) error {
+	if err := c.updateFavoriteCount(ctx, event.PostID, -1); err != nil {
+		return fmt.Errorf("failed to update entity count: %w", err)
+	}
+
+	return nil
+}
+
+// updateFavoriteCount applies delta to a post's favorite count
+func (c *FavoriteConsumer) updateFavoriteCount(ctx context.Context, postID int64, delta int) error {
+	// Ensure entity count record exists
+	existingCount, err := c.entityCountRepo.FindByEntity(ctx, "post", postID)
+	if err != nil {
+		return fmt.Errorf("failed to find entity count: %w", err)
+	}
+
+	if existingCount == nil {
+		initialCount := &models.EntityCount{
+			EntityType: "post",
+			EntityID:   postID,
+			UpdatedAt:  time.Now(),
+		}
+		if err := c.entityCountRepo.Create(ctx, initialCount); err != nil {
+			return fmt.Errorf("failed to create entity count: %w", err)
+		}
+	}
+
+	if err := c.entityCountRepo.IncrementFavoriteCount(ctx, "post", postID, delta); err != nil {
+		return fmt.Errorf("failed to increment favorite count: %w", err)
+	}
+
+	return nil
+}
+
+// FavoriteConsumerGroup is the consumer group the favorite consumer subscribes with
+const FavoriteConsumerGroup = "favorites"
+
+// Subscribe subscribes to favorite events. Each handler applies a count delta, so
+// messageQueue should deduplicate redeliveries (see EventDeduplicator.Queue).
+func (c *FavoriteConsumer) Subscribe(messageQueue mq.MessageQueue) error {
+	if err := mq.SubscribeGroupEvents(messageQueue, FavoriteConsumerGroup, mq.TopicPostFavorited, c.HandlePostFavorited); err != nil {
+		return fmt.Errorf("failed to subscribe to post favorited events: %w", err)
+	}
+
+	if err := mq.SubscribeGroupEvents(messageQueue, FavoriteConsumerGroup, mq.TopicPostUnfavorited, c.HandlePostUnfavorited); err != nil {
+		return fmt.Errorf("failed to subscribe to post unfavorited events: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,361 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/kobayashirei/airy/internal/models"
+	"github.com/kobayashirei/airy/internal/mq"
+	"github.com/kobayashirei/airy/internal/repository"
+)
+
+var (
+	// ErrFavoriteCollectionNotFound is returned when a collection does not exist or belongs to another user
+	ErrFavoriteCollectionNotFound = errors.New("favorite collection not found")
+	// ErrFavoriteCollectionExists is returned when the user already has a collection with the same name
+	ErrFavoriteCollectionExists = errors.New("favorite collection already exists")
+	// ErrInvalidCollectionName is returned when a collection name is blank
+	ErrInvalidCollectionName = errors.New("collection name cannot be empty")
+)
+
+// favoriteCursorSort names the favorite list ordering in pagination cursors
+const favoriteCursorSort = "favorites"
+
+// FavoriteService defines the interface for favorites (bookmarks) business logic
+type FavoriteService interface {
+	// Favorite adds a published post to the user's favorites, optionally filed in one of their
+	// collections. Favoriting an already favorited post only moves it to the given collection.
+	Favorite(ctx context.Context, userID, postID int64, collectionID *int64) error
+
+	// Unfavorite removes a post from the user's favorites (no-op if not favorited)
+	Unfavorite(ctx context.Context, userID, postID int64) error
+
+	// IsFavorited reports whether the user has favorited the post
+	IsFavorited(ctx context.Context, userID, postID int64) (bool, error)
+
+	// ListFavorites returns a page of the user's favorites, newest first, optionally limited to one collection
+	ListFavorites(ctx context.Context, userID int64, collectionID *int64, cursor string, limit int) (*FavoriteListResponse, error)
+
+	// ListCollections returns all of the user's collections
+	ListCollections(ctx context.Context, userID int64) ([]*models.FavoriteCollection, error)
+
+	// CreateCollection creates a named collection for the user
+	CreateCollection(ctx context.Context, userID int64, req FavoriteCollectionRequest) (*models.FavoriteCollection, error)
+
+	// UpdateCollection renames or redescribes one of the user's collections
+	UpdateCollection(ctx context.Context, userID, collectionID int64, req FavoriteCollectionRequest) (*models.FavoriteCollection, error)
+
+	// DeleteCollection deletes one of the user's collections, keeping its favorites uncategorized
+	DeleteCollection(ctx context.Context, userID, collectionID int64) error
+}
+
+// FavoriteCollectionRequest represents a request to create or update a favorite collection
+type FavoriteCollectionRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=500"`
+}
+
+// FavoritePost is a favorited post as shown in the favorites list
+type FavoritePost struct {
+	Post         *models.Post `json:"post"`
+	CollectionID *int64       `json:"collection_id,omitempty"`
+	FavoritedAt  time.Time    `json:"favorited_at"`
+}
+
+// FavoriteListResponse represents one page of a user's favorites
+type FavoriteListResponse struct {
+	Favorites  []*FavoritePost `json:"favorites"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+// favoriteService implements FavoriteService interface
+type favoriteService struct {
+	favoriteRepo   repository.FavoriteRepository
+	collectionRepo repository.FavoriteCollectionRepository
+	postRepo       repository.PostRepository
+	batchRepo      repository.BatchRepository
+	transactor     repository.Transactor
+	messageQueue   mq.MessageQueue
+	logger         *zap.Logger
+}
+
+// NewFavoriteService creates a new favorite service
+func NewFavoriteService(
+	favoriteRepo repository.FavoriteRepository,
+	collectionRepo repository.FavoriteCollectionRepository,
+	postRepo repository.PostRepository,
+	batchRepo repository.BatchRepository,
+	transactor repository.Transactor,
+	messageQueue mq.MessageQueue,
+	logger *zap.Logger,
+) FavoriteService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &favoriteService{
+		favoriteRepo:   favoriteRepo,
+		collectionRepo: collectionRepo,
+		postRepo:       postRepo,
+		batchRepo:      batchRepo,
+		transactor:     transactor,
+		messageQueue:   messageQueue,
+		logger:         logger,
+	}
+}
+
+// Favorite adds a post to the user's favorites
+func (s *favoriteService) Favorite(ctx context.Context, userID, postID int64, collectionID *int64) error {
+	post, err := s.postRepo.FindByID(ctx, postID)
+	if err != nil {
+		return fmt.Errorf("failed to find post: %w", err)
+	}
+	if post == nil || post.Status != "published" {
+		return ErrPostNotFound
+	}
+
+	if collectionID != nil {
+		if _, err := s.findOwnCollection(ctx, userID, *collectionID); err != nil {
+			return err
+		}
+	}
+
+	// The favorite count is only kept by the favorite consumer, so the event commits with the favorite
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		created, err := s.favoriteRepo.Create(ctx, &models.Favorite{
+			UserID:       userID,
+			PostID:       postID,
+			CollectionID: collectionID,
+			CreatedAt:    time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create favorite: %w", err)
+		}
+
+		if !created {
+			// Already favorited: only the collection can change
+			if err := s.favoriteRepo.UpdateCollection(ctx, userID, postID, collectionID); err != nil {
+				return fmt.Errorf("failed to update favorite collection: %w", err)
+			}
+			return nil
+		}
+
+		return s.publishFavoriteEvent(ctx, mq.TopicPostFavorited, mq.PostFavoritedEvent{
+			BaseEvent: mq.BaseEvent{
+				EventID:   uuid.New().String(),
+				EventType: mq.TopicPostFavorited,
+				Timestamp: time.Now(),
+			},
+			PostID:   postID,
+			AuthorID: post.AuthorID,
+			UserID:   userID,
+		})
+	})
+}
+
+// Unfavorite removes a post from the user's favorites
+func (s *favoriteService) Unfavorite(ctx context.Context, userID, postID int64) error {
+	// The favorite count is only kept by the favorite consumer, so the event commits with the deletion
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		deleted, err := s.favoriteRepo.Delete(ctx, userID, postID)
+		if err != nil {
+			return fmt.Errorf("failed to delete favorite: %w", err)
+		}
+		if !deleted {
+			return nil
+		}
+
+		// The author is informational; counters only need the post ID
+		var authorID int64
+		if post, err := s.postRepo.FindByID(ctx, postID); err != nil {
+			s.logger.Warn("failed to find unfavorited post author", zap.Int64("post_id", postID), zap.Error(err))
+		} else if post != nil {
+			authorID = post.AuthorID
+		}
+
+		return s.publishFavoriteEvent(ctx, mq.TopicPostUnfavorited, mq.PostUnfavoritedEvent{
+			BaseEvent: mq.BaseEvent{
+				EventID:   uuid.New().String(),
+				EventType: mq.TopicPostUnfavorited,
+				Timestamp: time.Now(),
+			},
+			PostID:   postID,
+			AuthorID: authorID,
+			UserID:   userID,
+		})
+	})
+}
+
+// IsFavorited checks whether the user has favorited the post
+func (s *favoriteService) IsFavorited(ctx context.Context, userID, postID int64) (bool, error) {
+	favorite, err := s.favoriteRepo.FindByUserAndPost(ctx, userID, postID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check favorite: %w", err)
+	}
+	return favorite != nil, nil
+}
+
+// ListFavorites lists the user's favorites with their posts
+func (s *favoriteService) ListFavorites(ctx context.Context, userID int64, collectionID *int64, cursor string, limit int) (*FavoriteListResponse, error) {
+	var createdAt time.Time
+	after, err := decodeKeyset(cursor, favoriteCursorSort, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if collectionID != nil {
+		if _, err := s.findOwnCollection(ctx, userID, *collectionID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Fetch one extra row to know whether another page exists
+	favorites, err := s.favoriteRepo.List(ctx, repository.FavoriteListOptions{
+		UserID:       userID,
+		CollectionID: collectionID,
+		Limit:        limit + 1,
+		After:        after,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list favorites: %w", err)
+	}
+
+	resp := &FavoriteListResponse{Favorites: make([]*FavoritePost, 0, len(favorites))}
+	if len(favorites) > limit {
+		favorites = favorites[:limit]
+		last := favorites[limit-1]
+		resp.NextCursor, err = encodeKeyset(favoriteCursorSort, last.ID, last.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode cursor: %w", err)
+		}
+	}
+
+	postIDs := make([]int64, len(favorites))
+	for i, favorite := range favorites {
+		postIDs[i] = favorite.PostID
+	}
+	posts, err := s.batchRepo.FindPostsByIDs(ctx, postIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find posts: %w", err)
+	}
+
+	for _, favorite := range favorites {
+		// Posts deleted or hidden since they were favorited are left out
+		post, ok := posts[favorite.PostID]
+		if !ok || post.Status != "published" {
+			continue
+		}
+		resp.Favorites = append(resp.Favorites, &FavoritePost{
+			Post:         post,
+			CollectionID: favorite.CollectionID,
+			FavoritedAt:  favorite.CreatedAt,
+		})
+	}
+
+	return resp, nil
+}
+
+// ListCollections lists the user's collections
+func (s *favoriteService) ListCollections(ctx context.Context, userID int64) ([]*models.FavoriteCollection, error) {
+	collections, err := s.collectionRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list favorite collections: %w", err)
+	}
+	return collections, nil
+}
+
+// CreateCollection creates a collection with a name unique to the user
+func (s *favoriteService) CreateCollection(ctx context.Context, userID int64, req FavoriteCollectionRequest) (*models.FavoriteCollection, error) {
+	name := strings.TrimSpace(req.Name)
+	if err := s.ensureCollectionNameFree(ctx, userID, name, 0); err != nil {
+		return nil, err
+	}
+
+	collection := &models.FavoriteCollection{
+		UserID:      userID,
+		Name:        name,
+		Description: req.Description,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if err := s.collectionRepo.Create(ctx, collection); err != nil {
+		return nil, fmt.Errorf("failed to create favorite collection: %w", err)
+	}
+	return collection, nil
+}
+
+// UpdateCollection updates the name and description of a collection
+func (s *favoriteService) UpdateCollection(ctx context.Context, userID, collectionID int64, req FavoriteCollectionRequest) (*models.FavoriteCollection, error) {
+	collection, err := s.findOwnCollection(ctx, userID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if err := s.ensureCollectionNameFree(ctx, userID, name, collectionID); err != nil {
+		return nil, err
+	}
+
+	collection.Name = name
+	collection.Description = req.Description
+	collection.UpdatedAt = time.Now()
+	if err := s.collectionRepo.Update(ctx, collection); err != nil {
+		return nil, fmt.Errorf("failed to update favorite collection: %w", err)
+	}
+	return collection, nil
+}
+
+// DeleteCollection deletes a collection owned by the user
+func (s *favoriteService) DeleteCollection(ctx context.Context, userID, collectionID int64) error {
+	if _, err := s.findOwnCollection(ctx, userID, collectionID); err != nil {
+		return err
+	}
+	if err := s.collectionRepo.Delete(ctx, collectionID); err != nil {
+		return fmt.Errorf("failed to delete favorite collection: %w", err)
+	}
+	return nil
+}
+
+// findOwnCollection loads a collection, treating other users' collections as missing
+func (s *favoriteService) findOwnCollection(ctx context.Context, userID, collectionID int64) (*models.FavoriteCollection, error) {
+	collection, err := s.collectionRepo.FindByID(ctx, collectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find favorite collection: %w", err)
+	}
+	if collection == nil || collection.UserID != userID {
+		return nil, ErrFavoriteCollectionNotFound
+	}
+	return collection, nil
+}
+
+// ensureCollectionNameFree checks that name is valid and not used by another of the user's collections
+func (s *favoriteService) ensureCollectionNameFree(ctx context.Context, userID int64, name string, exceptID int64) error {
+	if name == "" {
+		return ErrInvalidCollectionName
+	}
+	existing, err := s.collectionRepo.FindByUserAndName(ctx, userID, name)
+	if err != nil {
+		return fmt.Errorf("failed to check favorite collection name: %w", err)
+	}
+	if existing != nil && existing.ID != exceptID {
+		return ErrFavoriteCollectionExists
+	}
+	return nil
+}
+
+// publishFavoriteEvent publishes a favorite event.
+// Called inside the favorite transaction, so with the outbox queue the event commits with the favorite.
+func (s *favoriteService) publishFavoriteEvent(ctx context.Context, topic string, event interface{}) error {
+	if s.messageQueue == nil {
+		return nil
+	}
+	if err := s.messageQueue.Publish(ctx, topic, event); err != nil {
+		return fmt.Errorf("failed to publish %s event: %w", topic, err)
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/kobayashirei/airy/internal/models"
+	"github.com/kobayashirei/airy/internal/mq"
+	"github.com/kobayashirei/airy/internal/repository"
+)
+
+func newTestFavoriteService() (*favoriteService, *MockFavoriteRepository, *MockFavoriteCollectionRepository, *MockPostRepository, *MockBatchRepository, *MockMessageQueue) {
+	favoriteRepo := new(MockFavoriteRepository)
+	collectionRepo := new(MockFavoriteCollectionRepository)
+	postRepo := new(MockPostRepository)
+	batchRepo := new(MockBatchRepository)
+	messageQueue := new(MockMessageQueue)
+	service := NewFavoriteService(favoriteRepo, collectionRepo, postRepo, batchRepo, passthroughTransactor{}, messageQueue, nil).(*favoriteService)
+	return service, favoriteRepo, collectionRepo, postRepo, batchRepo, messageQueue
+}
+
+func TestFavoriteService_Favorite(t *testing.T) {
+	ctx := context.Background()
+	collectionID := int64(7)
+
+	t.Run("creates favorite and publishes event", func(t *testing.T) {
+		service, favoriteRepo, collectionRepo, postRepo, _, messageQueue := newTestFavoriteService()
+
+		postRepo.On("FindByID", ctx, int64(10)).Return(&models.Post{ID: 10, AuthorID: 3, Status: "published"}, nil)
+		collectionRepo.On("FindByID", ctx, collectionID).Return(&models.FavoriteCollection{ID: collectionID, UserID: 1}, nil)
+		favoriteRepo.On("Create", ctx, mock.MatchedBy(func(f *models.Favorite) bool {
+			return f.UserID == 1 && f.PostID == 10 && f.CollectionID != nil && *f.CollectionID == collectionID
+		})).Return(true, nil)
+		messageQueue.On("Publish", ctx, mq.TopicPostFavorited, mock.MatchedBy(func(e mq.PostFavoritedEvent) bool {
+			return e.PostID == 10 && e.AuthorID == 3 && e.UserID == 1 && e.EventID != ""
+		})).Return(nil)
+
+		err := service.Favorite(ctx, 1, 10, &collectionID)
+		assert.NoError(t, err)
+		favoriteRepo.AssertExpectations(t)
+		messageQueue.AssertExpectations(t)
+	})
+
+	t.Run("already favorited moves it to the collection without an event", func(t *testing.T) {
+		service, favoriteRepo, collectionRepo, postRepo, _, messageQueue := newTestFavoriteService()
+
+		postRepo.On("FindByID", ctx, int64(10)).Return(&models.Post{ID: 10, Status: "published"}, nil)
+		collectionRepo.On("FindByID", ctx, collectionID).Return(&models.FavoriteCollection{ID: collectionID, UserID: 1}, nil)
+		favoriteRepo.On("Create", ctx, mock.Anything).Return(false, nil)
+		favoriteRepo.On("UpdateCollection", ctx, int64(1), int64(10), &collectionID).Return(nil)
+
+		err := service.Favorite(ctx, 1, 10, &collectionID)
+		assert.NoError(t, err)
+		favoriteRepo.AssertExpectations(t)
+		messageQueue.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("fails when the event cannot be recorded", func(t *testing.T) {
+		service, favoriteRepo, _, postRepo, _, messageQueue := newTestFavoriteService()
+
+		postRepo.On("FindByID", ctx, int64(10)).Return(&models.Post{ID: 10, AuthorID: 3, Status: "published"}, nil)
+		favoriteRepo.On("Create", ctx, mock.Anything).Return(true, nil)
+		messageQueue.On("Publish", ctx, mq.TopicPostFavorited, mock.Anything).Return(errors.New("outbox unavailable"))
+
+		err := service.Favorite(ctx, 1, 10, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("unpublished post is not found", func(t *testing.T) {
+		service, favoriteRepo, _, postRepo, _, _ := newTestFavoriteService()
+
+		postRepo.On("FindByID", ctx, int64(10)).Return(&models.Post{ID: 10, Status: "draft"}, nil)
+
+		err := service.Favorite(ctx, 1, 10, nil)
+		assert.ErrorIs(t, err, ErrPostNotFound)
+		favoriteRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("collection of another user is not found", func(t *testing.T) {
+		service, favoriteRepo, collectionRepo, postRepo, _, _ := newTestFavoriteService()
+
+		postRepo.On("FindByID", ctx, int64(10)).Return(&models.Post{ID: 10, Status: "published"}, nil)
+		collectionRepo.On("FindByID", ctx, collectionID).Return(&models.FavoriteCollection{ID: collectionID, UserID: 2}, nil)
+
+		err := service.Favorite(ctx, 1, 10, &collectionID)
+		assert.ErrorIs(t, err, ErrFavoriteCollectionNotFound)
+		favoriteRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestFavoriteService_Unfavorite(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("deletes favorite and publishes event", func(t *testing.T) {
+		service, favoriteRepo, _, postRepo, _, messageQueue := newTestFavoriteService()
+
+		favoriteRepo.On("Delete", ctx, int64(1), int64(10)).Return(true, nil)
+		postRepo.On("FindByID", ctx, int64(10)).Return(&models.Post{ID: 10, AuthorID: 3}, nil)
+		messageQueue.On("Publish", ctx, mq.TopicPostUnfavorited, mock.MatchedBy(func(e mq.PostUnfavoritedEvent) bool {
+			return e.PostID == 10 && e.UserID == 1
+		})).Return(nil)
+
+		err := service.Unfavorite(ctx, 1, 10)
+		assert.NoError(t, err)
+		messageQueue.AssertExpectations(t)
+	})
+
+	t.Run("not favorited is a no-op", func(t *testing.T) {
+		service, favoriteRepo, _, _, _, messageQueue := newTestFavoriteService()
+
+		favoriteRepo.On("Delete", ctx, int64(1), int64(10)).Return(false, nil)
+
+		err := service.Unfavorite(ctx, 1, 10)
+		assert.NoError(t, err)
+		messageQueue.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestFavoriteService_ListFavorites(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	service, favoriteRepo, _, _, batchRepo, _ := newTestFavoriteService()
+
+	favorites := []*models.Favorite{
+		{ID: 3, UserID: 1, PostID: 30, CreatedAt: now},
+		{ID: 2, UserID: 1, PostID: 20, CreatedAt: now.Add(-time.Minute)},
+		{ID: 1, UserID: 1, PostID: 10, CreatedAt: now.Add(-2 * time.Minute)},
+	}
+	favoriteRepo.On("List", ctx, repository.FavoriteListOptions{UserID: 1, Limit: 3}).Return(favorites, nil)
+	batchRepo.On("FindPostsByIDs", ctx, []int64{30, 20}).Return(map[int64]*models.Post{
+		30: {ID: 30, Status: "published"},
+		20: {ID: 20, Status: "hidden"},
+	}, nil)
+
+	resp, err := service.ListFavorites(ctx, 1, nil, "", 2)
+	assert.NoError(t, err)
+	assert.Len(t, resp.Favorites, 1)
+	assert.Equal(t, int64(30), resp.Favorites[0].Post.ID)
+	assert.NotEmpty(t, resp.NextCursor)
+
+	// The cursor continues after the last row of the page, even if its post was filtered out
+	favoriteRepo.On("List", ctx, mock.MatchedBy(func(opts repository.FavoriteListOptions) bool {
+		return opts.After != nil && opts.After.ID == 2
+	})).Return(favorites[2:], nil)
+	batchRepo.On("FindPostsByIDs", ctx, []int64{10}).Return(map[int64]*models.Post{
+		10: {ID: 10, Status: "published"},
+	}, nil)
+
+	resp, err = service.ListFavorites(ctx, 1, nil, resp.NextCursor, 2)
+	assert.NoError(t, err)
+	assert.Len(t, resp.Favorites, 1)
+	assert.Empty(t, resp.NextCursor)
+
+	_, err = service.ListFavorites(ctx, 1, nil, "bogus", 2)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestFavoriteService_CreateCollection(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("creates collection with trimmed name", func(t *testing.T) {
+		service, _, collectionRepo, _, _, _ := newTestFavoriteService()
+
+		collectionRepo.On("FindByUserAndName", ctx, int64(1), "Reading").Return(nil, nil)
+		collectionRepo.On("Create", ctx, mock.MatchedBy(func(c *models.FavoriteCollection) bool {
+			return c.UserID == 1 && c.Name == "Reading"
+		})).Return(nil)
+
+		collection, err := service.CreateCollection(ctx, 1, FavoriteCollectionRequest{Name: "  Reading "})
+		assert.NoError(t, err)
+		assert.Equal(t, "Reading", collection.Name)
+	})
+
+	t.Run("duplicate name is rejected", func(t *testing.T) {
+		service, _, collectionRepo, _, _, _ := newTestFavoriteService()
+
+		collectionRepo.On("FindByUserAndName", ctx, int64(1), "Reading").Return(&models.FavoriteCollection{ID: 5, UserID: 1}, nil)
+
+		_, err := service.CreateCollection(ctx, 1, FavoriteCollectionRequest{Name: "Reading"})
+		assert.ErrorIs(t, err, ErrFavoriteCollectionExists)
+		collectionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("blank name is rejected", func(t *testing.T) {
+		service, _, _, _, _, _ := newTestFavoriteService()
+
+		_, err := service.CreateCollection(ctx, 1, FavoriteCollectionRequest{Name: "   "})
+		assert.ErrorIs(t, err, ErrInvalidCollectionName)
+	})
+}
+
+func TestFavoriteService_DeleteCollection(t *testing.T) {
+	ctx := context.Background()
+
+	service, _, collectionRepo, _, _, _ := newTestFavoriteService()
+
+	collectionRepo.On("FindByID", ctx, int64(5)).Return(&models.FavoriteCollection{ID: 5, UserID: 2}, nil)
+
+	err := service.DeleteCollection(ctx, 1, 5)
+	assert.ErrorIs(t, err, ErrFavoriteCollectionNotFound)
+	collectionRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kobayashirei/airy/internal/mq"
+	"github.com/kobayashirei/airy/internal/repository"
+)
+
+// FeedConsumer handles timeline events from the message queue
+type FeedConsumer struct {
+	feedService FeedService
+	postRepo    repository.PostRepository
+}
+
+// NewFeedConsumer creates a new feed consumer
+func NewFeedConsumer(feedService FeedService, postRepo repository.PostRepository) *FeedConsumer {
+	return &FeedConsumer{
+		feedService: feedService,
+		postRepo:    postRepo,
+	}
+}
+
+// HandlePostPublished writes a published post to the author's outbox and followers' feeds
+func (c *FeedConsumer) HandlePostPublished(ctx context.Context, event *mq.PostPublishedEvent) error {
+	if err := c.feedService.PushToFollowerFeeds(ctx, event.PostID, event.AuthorID); err != nil {
+		return fmt.Errorf("failed to push post %d to feeds: %w", event.PostID, err)
+	}
+
+	return nil
+}
+
+// HandlePostUpdated removes a post from feeds when an update left it unpublished (e.g. hidden by moderation)
+func (c *FeedConsumer) HandlePostUpdated(ctx context.Context, event *mq.PostUpdatedEvent) error {
+	post, err := c.postRepo.FindByID(ctx, event.PostID)
+	if err != nil {
+		return fmt.Errorf("failed to get post %d: %w", event.PostID, err)
+	}
+	if post != nil && post.Status == "published" {
+		return nil
+	}
+
+	if err := c.feedService.RemoveFromFeeds(ctx, event.PostID); err != nil {
+		return fmt.Errorf("failed to remove post %d from feeds: %w", event.PostID, err)
+	}
+
+	return nil
+}
+
+// HandlePostDeleted removes a deleted post from every feed it was pushed to
+func (c *FeedConsumer) HandlePostDeleted(ctx context.Context, event *mq.PostDeletedEvent) error {
+	if err := c.feedService.RemoveFromFeeds(ctx, event.PostID); err != nil {
+		return fmt.Errorf("failed to remove post %d from feeds: %w", event.PostID, err)
+	}
+
+	return nil
+}
+
+// FeedConsumerGroup is the consumer group the feed consumer subscribes with
+const FeedConsumerGroup = "feed"
+
+// Subscribe subscribes to the post events that change feeds
+func (c *FeedConsumer) Subscribe(messageQueue mq.MessageQueue) error {
+	if err := mq.SubscribeGroupEvents(messageQueue, FeedConsumerGroup, mq.TopicPostPublished, c.HandlePostPublished); err != nil {
+		return fmt.Errorf("failed to subscribe to post published events: %w", err)
+	}
+
+	if err := mq.SubscribeGroupEvents(messageQueue, FeedConsumerGroup, mq.TopicPostUpdated, c.HandlePostUpdated); err != nil {
+		return fmt.Errorf("failed to subscribe to post updated events: %w", err)
+	}
+
+	if err := mq.SubscribeGroupEvents(messageQueue, FeedConsumerGroup, mq.TopicPostDeleted, c.HandlePostDeleted); err != nil {
+		return fmt.Errorf("failed to subscribe to post deleted events: %w", err)
+	}
+
+	return nil
+}
@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"time"
 
@@ -12,41 +14,65 @@ import (
 	"github.com/kobayashirei/airy/internal/cache"
 	appLogger "github.com/kobayashirei/airy/internal/logger"
 	"github.com/kobayashirei/airy/internal/models"
+	"github.com/kobayashirei/airy/internal/pagination"
 	"github.com/kobayashirei/airy/internal/repository"
 )
 
 const (
-	// FanoutThreshold is the follower count threshold for choosing feed distribution strategy
-	FanoutThreshold = 1000
+	// DefaultFanoutThreshold is used when no follower count threshold is configured.
+	// Authors above the threshold are pulled from their outbox at read time instead of pushed.
+	DefaultFanoutThreshold = 1000
 	
 	// FeedExpiration is the TTL for feed entries in Redis
 	FeedExpiration = 7 * 24 * time.Hour // 7 days
 	
 	// MaxFeedSize is the maximum number of posts to keep in a user's feed
 	MaxFeedSize = 1000
+
+	// MaxOutboxSize is the maximum number of posts to keep in an author's outbox
+	MaxOutboxSize = 1000
+
+	// feedTimelineCursorSort names the Redis timeline ordering in feed cursors;
+	// database-backed pages use "feed:" plus the sort column
+	feedTimelineCursorSort = "feed:timeline"
 )
 
 // FeedService defines the interface for feed operations
 type FeedService interface {
-	// PushToFollowerFeeds pushes a post to followers' feeds (fan-out write)
+	// PushToFollowerFeeds records a post in the author's outbox and pushes it to
+	// followers' feeds unless the author has more followers than the fan-out threshold
 	PushToFollowerFeeds(ctx context.Context, postID int64, authorID int64) error
 	
-	// GetUserFeed retrieves a user's personalized feed
-	GetUserFeed(ctx context.Context, userID int64, limit int, offset int, sortBy string) ([]*models.Post, error)
+	// GetUserFeed retrieves a page of a user's personalized feed; cursor is empty for the first page
+	GetUserFeed(ctx context.Context, userID int64, limit int, cursor string, sortBy string) (*FeedResponse, error)
 	
 	// GetCircleFeed retrieves posts from a specific circle
 	GetCircleFeed(ctx context.Context, circleID int64, limit int, offset int, sortBy string) ([]*models.Post, error)
 	
-	// RemoveFromFeeds removes a post from all feeds (when deleted)
+	// RemoveFromFeeds removes a post from every feed and outbox it was written to (when deleted or hidden)
 	RemoveFromFeeds(ctx context.Context, postID int64) error
 }
 
+// FeedResponse is one page of a user's feed
+type FeedResponse struct {
+	Posts      []*models.Post `json:"posts"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// timelineEntry is a post in a merged Redis timeline with its feed score
+type timelineEntry struct {
+	PostID int64
+	Score  float64
+}
+
 // feedService implements FeedService interface
 type feedService struct {
 	redisClient       *redis.Client
 	postRepo          repository.PostRepository
 	userProfileRepo   repository.UserProfileRepository
+	followRepo        repository.FollowRepository
 	cacheService      cache.Service
+	fanoutThreshold   int
 }
 
 // NewFeedService creates a new feed service
@@ -54,18 +80,43 @@ func NewFeedService(
 	redisClient *redis.Client,
 	postRepo repository.PostRepository,
 	userProfileRepo repository.UserProfileRepository,
+	followRepo repository.FollowRepository,
 	cacheService cache.Service,
+	fanoutThreshold int,
 ) FeedService {
+	if fanoutThreshold <= 0 {
+		fanoutThreshold = DefaultFanoutThreshold
+	}
 	return &feedService{
 		redisClient:     redisClient,
 		postRepo:        postRepo,
 		userProfileRepo: userProfileRepo,
+		followRepo:      followRepo,
 		cacheService:    cacheService,
+		fanoutThreshold: fanoutThreshold,
 	}
 }
 
-// PushToFollowerFeeds pushes a post to followers' feeds using fan-out write strategy
+// PushToFollowerFeeds records a post in the author's outbox and fans it out to followers' feeds.
+// Authors above the fan-out threshold are only written to their outbox; followers pull
+// from it at read time instead.
 func (s *feedService) PushToFollowerFeeds(ctx context.Context, postID int64, authorID int64) error {
+	timestamp := float64(time.Now().Unix())
+
+	// Every post goes to the author's outbox so the pull path works no matter
+	// when the author crosses the threshold
+	outboxKey := cache.GetUserOutboxKey(authorID)
+	indexKey := cache.GetPostFeedsKey(postID)
+	pipe := s.redisClient.Pipeline()
+	pipe.ZAdd(ctx, outboxKey, redis.Z{Score: timestamp, Member: postID})
+	pipe.ZRemRangeByRank(ctx, outboxKey, 0, -MaxOutboxSize-1)
+	pipe.Expire(ctx, outboxKey, FeedExpiration)
+	pipe.SAdd(ctx, indexKey, outboxKey)
+	pipe.Expire(ctx, indexKey, FeedExpiration)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to write author outbox: %w", err)
+	}
+
 	// Get author's follower count to determine strategy
 	profile, err := s.userProfileRepo.FindByUserID(ctx, authorID)
 	if err != nil {
@@ -77,30 +128,29 @@ func (s *feedService) PushToFollowerFeeds(ctx context.Context, postID int64, aut
 		followerCount = profile.FollowerCount
 	}
 	
-	// If author has too many followers, use fan-in read strategy (do nothing here)
-	if followerCount > FanoutThreshold {
+	// If author has too many followers, use fan-in read strategy (outbox only)
+	if followerCount > s.fanoutThreshold {
 		appLogger.Info("Skipping fan-out for high-follower user",
 			zap.Int64("author_id", authorID),
 			zap.Int("follower_count", followerCount),
-			zap.Int("threshold", FanoutThreshold),
+			zap.Int("threshold", s.fanoutThreshold),
 		)
 		return nil
 	}
 	
 	// Fan-out write: push post to all followers' feeds
-	// In a real implementation, we would query followers from a follows table
-	// For now, we'll use a placeholder that can be extended
 	followerIDs, err := s.getFollowerIDs(ctx, authorID)
 	if err != nil {
 		return fmt.Errorf("failed to get follower IDs: %w", err)
 	}
 	
 	// Use pipeline for efficient batch operations
-	pipe := s.redisClient.Pipeline()
-	timestamp := float64(time.Now().Unix())
+	pipe = s.redisClient.Pipeline()
+	feedKeys := make([]interface{}, 0, len(followerIDs))
 	
 	for _, followerID := range followerIDs {
 		feedKey := cache.GetUserFeedKey(followerID)
+		feedKeys = append(feedKeys, feedKey)
 		
 		// Add post to follower's feed (sorted set with timestamp as score)
 		pipe.ZAdd(ctx, feedKey, redis.Z{
@@ -115,6 +165,12 @@ func (s *feedService) PushToFollowerFeeds(ctx context.Context, postID int64, aut
 		pipe.Expire(ctx, feedKey, FeedExpiration)
 	}
 	
+	// Record which feeds hold the post so RemoveFromFeeds can find them
+	if len(feedKeys) > 0 {
+		pipe.SAdd(ctx, indexKey, feedKeys...)
+		pipe.Expire(ctx, indexKey, FeedExpiration)
+	}
+	
 	_, err = pipe.Exec(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to push to follower feeds: %w", err)
@@ -129,32 +185,40 @@ func (s *feedService) PushToFollowerFeeds(ctx context.Context, postID int64, aut
 	return nil
 }
 
-// GetUserFeed retrieves a user's personalized feed
-func (s *feedService) GetUserFeed(ctx context.Context, userID int64, limit int, offset int, sortBy string) ([]*models.Post, error) {
-	// Try to get feed from Redis first (fan-out write result)
-	feedKey := cache.GetUserFeedKey(userID)
+// GetUserFeed retrieves a user's personalized feed.
+// Time-ordered pages come from the Redis timelines, falling back to the database when
+// the first page is empty; the cursor records which source a page came from.
+func (s *feedService) GetUserFeed(ctx context.Context, userID int64, limit int, cursor string, sortBy string) (*FeedResponse, error) {
+	if sortBy != "hotness_score" {
+		sortBy = "created_at"
+	}
 	
 	var postIDs []int64
+	var nextCursor string
 	var err error
 	
-	if sortBy == "hotness_score" {
-		// For hotness sorting, we need to fetch from database
-		postIDs, err = s.getFeedFromDatabase(ctx, userID, limit, offset, sortBy)
-	} else {
-		// For time-based sorting, use Redis sorted set
-		postIDs, err = s.getFeedFromRedis(ctx, feedKey, limit, offset)
+	switch {
+	case sortBy == "hotness_score" || (cursor != "" && pagination.SortName(cursor) != feedTimelineCursorSort):
+		// Hotness sorting and continued fallback pages are served from the database
+		postIDs, nextCursor, err = s.getFeedFromDatabase(ctx, userID, limit, cursor, sortBy)
+	default:
+		// For time-based sorting, merge the pushed inbox with pulled outboxes
+		postIDs, nextCursor, err = s.getFeedFromRedis(ctx, userID, limit, cursor)
 		
-		// If Redis feed is empty or error, fall back to database (fan-in read)
-		if err != nil || len(postIDs) == 0 {
+		// If the first Redis page is empty or errors, fall back to database (fan-in read)
+		if cursor == "" && (err != nil || len(postIDs) == 0) {
 			appLogger.Info("Falling back to database for user feed",
 				zap.Int64("user_id", userID),
 				zap.Error(err),
 			)
-			postIDs, err = s.getFeedFromDatabase(ctx, userID, limit, offset, sortBy)
+			postIDs, nextCursor, err = s.getFeedFromDatabase(ctx, userID, limit, "", sortBy)
 		}
 	}
 	
 	if err != nil {
+		if errors.Is(err, ErrInvalidCursor) {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to get feed post IDs: %w", err)
 	}
 	
@@ -164,7 +228,10 @@ func (s *feedService) GetUserFeed(ctx context.Context, userID int64, limit int,
 		return nil, fmt.Errorf("failed to get post details: %w", err)
 	}
 	
-	return posts, nil
+	return &FeedResponse{
+		Posts:      posts,
+		NextCursor: nextCursor,
+	}, nil
 }
 
 // GetCircleFeed retrieves posts from a specific circle
@@ -187,61 +254,182 @@ func (s *feedService) GetCircleFeed(ctx context.Context, circleID int64, limit i
 	return posts, nil
 }
 
-// RemoveFromFeeds removes a post from all feeds
+// RemoveFromFeeds removes a post from all feeds using the post's reverse feed index
 func (s *feedService) RemoveFromFeeds(ctx context.Context, postID int64) error {
-	// In a production system, we would need to track which feeds contain this post
-	// For now, this is a placeholder that can be extended
-	appLogger.Info("Removing post from feeds", zap.Int64("post_id", postID))
+	indexKey := cache.GetPostFeedsKey(postID)
+	feedKeys, err := s.redisClient.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return fmt.Errorf("failed to get feeds containing post: %w", err)
+	}
 	
-	// This would require maintaining a reverse index or scanning feeds
-	// Implementation depends on scale and requirements
+	pipe := s.redisClient.Pipeline()
+	for _, feedKey := range feedKeys {
+		pipe.ZRem(ctx, feedKey, postID)
+	}
+	pipe.Del(ctx, indexKey)
+	
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to remove post from feeds: %w", err)
+	}
+	
+	appLogger.Info("Removed post from feeds",
+		zap.Int64("post_id", postID),
+		zap.Int("feed_count", len(feedKeys)),
+	)
 	
 	return nil
 }
 
-// getFeedFromRedis retrieves post IDs from Redis sorted set
-func (s *feedService) getFeedFromRedis(ctx context.Context, feedKey string, limit int, offset int) ([]int64, error) {
-	// Get post IDs from sorted set (most recent first)
-	start := int64(offset)
-	stop := int64(offset + limit - 1)
+// getFeedFromRedis merges the user's pushed inbox with the outboxes of followed
+// high-follower authors (hybrid push/pull), newest first. The cursor is built from
+// timeline entries, so posts dropped later at read time do not shift pages.
+func (s *feedService) getFeedFromRedis(ctx context.Context, userID int64, limit int, cursor string) ([]int64, string, error) {
+	var after *timelineEntry
+	if cursor != "" {
+		after = &timelineEntry{}
+		if err := pagination.Decode(cursor, feedTimelineCursorSort, &after.Score, &after.PostID); err != nil {
+			return nil, "", ErrInvalidCursor
+		}
+	}
 	
-	results, err := s.redisClient.ZRevRange(ctx, feedKey, start, stop).Result()
+	pullAuthorIDs, err := s.followRepo.FindPopularFollowingIDs(ctx, userID, s.fanoutThreshold)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get feed from Redis: %w", err)
+		return nil, "", fmt.Errorf("failed to get followed high-follower authors: %w", err)
 	}
-	
-	postIDs := make([]int64, 0, len(results))
-	for _, result := range results {
-		postID, err := strconv.ParseInt(result, 10, 64)
+
+	keys := make([]string, 0, len(pullAuthorIDs)+1)
+	keys = append(keys, cache.GetUserFeedKey(userID))
+	for _, authorID := range pullAuthorIDs {
+		keys = append(keys, cache.GetUserOutboxKey(authorID))
+	}
+
+	// Each source needs at most limit+1 entries past the cursor for the merged page
+	// to be exact; entries sharing the cursor's score are fetched separately because
+	// only some of them come after it
+	maxScore := "+inf"
+	if after != nil {
+		maxScore = "(" + strconv.FormatFloat(after.Score, 'f', -1, 64)
+	}
+	pipe := s.redisClient.Pipeline()
+	cmds := make([]*redis.ZSliceCmd, 0, 2*len(keys))
+	for _, key := range keys {
+		cmds = append(cmds, pipe.ZRevRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{
+			Max:   maxScore,
+			Min:   "-inf",
+			Count: int64(limit + 1),
+		}))
+		if after != nil {
+			score := strconv.FormatFloat(after.Score, 'f', -1, 64)
+			cmds = append(cmds, pipe.ZRevRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{
+				Max: score,
+				Min: score,
+			}))
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, "", fmt.Errorf("failed to get feed from Redis: %w", err)
+	}
+
+	timelines := make([][]redis.Z, 0, len(cmds))
+	for _, cmd := range cmds {
+		timelines = append(timelines, cmd.Val())
+	}
+
+	entries := mergeTimelines(timelines, after, limit+1)
+	var nextCursor string
+	if len(entries) > limit {
+		entries = entries[:limit]
+		last := entries[limit-1]
+		nextCursor, err = pagination.Encode(feedTimelineCursorSort, last.Score, last.PostID)
 		if err != nil {
-			appLogger.Warn("Invalid post ID in feed", zap.String("value", result))
+			return nil, "", fmt.Errorf("failed to encode cursor: %w", err)
+		}
+	}
+
+	postIDs := make([]int64, len(entries))
+	for i, entry := range entries {
+		postIDs[i] = entry.PostID
+	}
+	return postIDs, nextCursor, nil
+}
+
+// mergeTimelines merges sorted-set timelines by score (newest first), drops
+// duplicate posts and entries not strictly after the cursor entry, and returns
+// up to limit entries
+func mergeTimelines(timelines [][]redis.Z, after *timelineEntry, limit int) []timelineEntry {
+	scores := make(map[int64]float64)
+	for _, timeline := range timelines {
+		for _, entry := range timeline {
+			member := fmt.Sprint(entry.Member)
+			postID, err := strconv.ParseInt(member, 10, 64)
+			if err != nil {
+				appLogger.Warn("Invalid post ID in feed", zap.String("value", member))
+				continue
+			}
+			if score, ok := scores[postID]; !ok || entry.Score > score {
+				scores[postID] = entry.Score
+			}
+		}
+	}
+
+	entries := make([]timelineEntry, 0, len(scores))
+	for postID, score := range scores {
+		if after != nil && (score > after.Score || (score == after.Score && postID >= after.PostID)) {
 			continue
 		}
-		postIDs = append(postIDs, postID)
+		entries = append(entries, timelineEntry{PostID: postID, Score: score})
 	}
-	
-	return postIDs, nil
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Score != entries[j].Score {
+			return entries[i].Score > entries[j].Score
+		}
+		return entries[i].PostID > entries[j].PostID
+	})
+
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries
 }
 
-// getFeedFromDatabase retrieves feed using fan-in read strategy
-func (s *feedService) getFeedFromDatabase(ctx context.Context, userID int64, limit int, offset int, sortBy string) ([]int64, error) {
-	// In a real implementation, we would:
-	// 1. Get list of users that this user follows
-	// 2. Query posts from those users
-	// 3. Merge and sort the results
-	
-	// For now, we'll return recent published posts as a placeholder
+// getFeedFromDatabase retrieves feed using fan-in read strategy over the followed authors
+func (s *feedService) getFeedFromDatabase(ctx context.Context, userID int64, limit int, cursor string, sortBy string) ([]int64, string, error) {
+	cursorSort := "feed:" + sortBy
+	after, err := decodePostCursor(cursor, cursorSort, sortBy)
+	if err != nil {
+		return nil, "", err
+	}
+
+	authorIDs, err := s.followRepo.FindFollowingIDs(ctx, userID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get followed users: %w", err)
+	}
+	if len(authorIDs) == 0 {
+		return []int64{}, "", nil
+	}
+
 	opts := repository.PostListOptions{
-		Status: "published",
-		SortBy: sortBy,
-		Order:  "DESC",
-		Limit:  limit,
-		Offset: offset,
+		AuthorIDs: authorIDs,
+		Status:    "published",
+		SortBy:    sortBy,
+		Order:     "DESC",
+		Limit:     limit + 1,
+		After:     after,
 	}
 	
 	posts, err := s.postRepo.List(ctx, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query posts: %w", err)
+		return nil, "", fmt.Errorf("failed to query posts: %w", err)
+	}
+	
+	var nextCursor string
+	if len(posts) > limit {
+		posts = posts[:limit]
+		last := posts[limit-1]
+		nextCursor, err = encodeKeyset(cursorSort, last.ID, postSortKey[sortBy](last))
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to encode cursor: %w", err)
+		}
 	}
 	
 	postIDs := make([]int64, len(posts))
@@ -249,10 +437,10 @@ func (s *feedService) getFeedFromDatabase(ctx context.Context, userID int64, lim
 		postIDs[i] = post.ID
 	}
 	
-	return postIDs, nil
+	return postIDs, nextCursor, nil
 }
 
-// batchGetPosts retrieves multiple posts with caching
+// batchGetPosts retrieves multiple posts with caching, skipping any that are no longer published
 func (s *feedService) batchGetPosts(ctx context.Context, postIDs []int64) ([]*models.Post, error) {
 	posts := make([]*models.Post, 0, len(postIDs))
 	
@@ -264,7 +452,9 @@ func (s *feedService) batchGetPosts(ctx context.Context, postIDs []int64) ([]*mo
 		
 		if err == nil {
 			// Cache hit
-			posts = append(posts, &post)
+			if post.Status == "published" {
+				posts = append(posts, &post)
+			}
 			continue
 		}
 		
@@ -283,16 +473,18 @@ func (s *feedService) batchGetPosts(ctx context.Context, postIDs []int64) ([]*mo
 		// Store in cache
 		_ = s.cacheService.Set(ctx, cacheKey, dbPost, 1*time.Hour)
 		
+		// Drop posts deleted or hidden after they were pushed to the feed
+		if dbPost.Status != "published" {
+			continue
+		}
+		
 		posts = append(posts, dbPost)
 	}
 	
 	return posts, nil
 }
 
-// getFollowerIDs retrieves the list of follower IDs for a user
-// This is a placeholder - in a real implementation, this would query a follows table
+// getFollowerIDs retrieves the list of follower IDs for a user from the follows table
 func (s *feedService) getFollowerIDs(ctx context.Context, userID int64) ([]int64, error) {
-	// TODO: Implement actual follower query from follows table
-	// For now, return empty list
-	return []int64{}, nil
+	return s.followRepo.FindFollowerIDs(ctx, userID)
 }
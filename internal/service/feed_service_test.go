@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
@@ -80,3 +81,79 @@ func TestFeedService_GetCircleFeed(t *testing.T) {
 }
 
 
+
+// TestMergeTimelines tests merging the pushed inbox with pulled author outboxes
+func TestMergeTimelines(t *testing.T) {
+	inbox := []redis.Z{
+		{Score: 100, Member: "1"},
+		{Score: 80, Member: "3"},
+	}
+	outboxA := []redis.Z{
+		{Score: 90, Member: "2"},
+		{Score: 80, Member: "3"}, // already pushed before the author crossed the threshold
+	}
+	outboxB := []redis.Z{
+		{Score: 70, Member: "4"},
+	}
+
+	t.Run("merges newest first without duplicates", func(t *testing.T) {
+		entries := mergeTimelines([][]redis.Z{inbox, outboxA, outboxB}, nil, 10)
+		assert.Equal(t, []timelineEntry{{1, 100}, {2, 90}, {3, 80}, {4, 70}}, entries)
+	})
+
+	t.Run("applies limit after merging", func(t *testing.T) {
+		entries := mergeTimelines([][]redis.Z{inbox, outboxA, outboxB}, nil, 2)
+		assert.Equal(t, []timelineEntry{{1, 100}, {2, 90}}, entries)
+	})
+
+	t.Run("continues strictly after the cursor entry", func(t *testing.T) {
+		entries := mergeTimelines([][]redis.Z{inbox, outboxA, outboxB}, &timelineEntry{PostID: 2, Score: 90}, 10)
+		assert.Equal(t, []timelineEntry{{3, 80}, {4, 70}}, entries)
+	})
+
+	t.Run("breaks score ties by post ID", func(t *testing.T) {
+		tied := []redis.Z{
+			{Score: 50, Member: "7"},
+			{Score: 50, Member: "6"},
+			{Score: 50, Member: "5"},
+		}
+		entries := mergeTimelines([][]redis.Z{tied}, &timelineEntry{PostID: 7, Score: 50}, 10)
+		assert.Equal(t, []timelineEntry{{6, 50}, {5, 50}}, entries)
+	})
+
+	t.Run("cursor past the end returns empty page", func(t *testing.T) {
+		entries := mergeTimelines([][]redis.Z{inbox}, &timelineEntry{PostID: 3, Score: 80}, 10)
+		assert.Empty(t, entries)
+	})
+}
+
+// TestNewFeedService_FanoutThreshold tests that the configured threshold is honoured
+func TestNewFeedService_FanoutThreshold(t *testing.T) {
+	configured := NewFeedService(nil, nil, nil, nil, nil, 50).(*feedService)
+	assert.Equal(t, 50, configured.fanoutThreshold)
+
+	unset := NewFeedService(nil, nil, nil, nil, nil, 0).(*feedService)
+	assert.Equal(t, DefaultFanoutThreshold, unset.fanoutThreshold)
+}
+
+// TestFeedService_BatchGetPosts_SkipsUnpublished tests that deleted or hidden posts are dropped at read time
+func TestFeedService_BatchGetPosts_SkipsUnpublished(t *testing.T) {
+	mockPostRepo := new(MockPostRepository)
+	mockCacheService := new(MockCacheService)
+	service := &feedService{
+		postRepo:     mockPostRepo,
+		cacheService: mockCacheService,
+	}
+	ctx := context.Background()
+
+	mockCacheService.On("Get", ctx, mock.Anything, mock.Anything).Return(assert.AnError)
+	mockCacheService.On("Set", ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockPostRepo.On("FindByID", ctx, int64(1)).Return(&models.Post{ID: 1, Status: "published"}, nil)
+	mockPostRepo.On("FindByID", ctx, int64(2)).Return(&models.Post{ID: 2, Status: "hidden"}, nil)
+	mockPostRepo.On("FindByID", ctx, int64(3)).Return(&models.Post{ID: 3, Status: "deleted"}, nil)
+
+	posts, err := service.batchGetPosts(ctx, []int64{1, 2, 3})
+	assert.NoError(t, err)
+	assert.Len(t, posts, 1)
+	assert.Equal(t, int64(1), posts[0].ID)
+}
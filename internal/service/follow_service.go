@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/kobayashirei/airy/internal/cache"
+	"github.com/kobayashirei/airy/internal/models"
+	"github.com/kobayashirei/airy/internal/mq"
+	"github.com/kobayashirei/airy/internal/repository"
+)
+
+var (
+	// ErrCannotFollowSelf is returned when a user tries to follow themselves
+	ErrCannotFollowSelf = errors.New("cannot follow yourself")
+)
+
+// followCursorSort names the follow list ordering in pagination cursors
+const followCursorSort = "follows"
+
+// FollowService defines the interface for the follow graph business logic
+type FollowService interface {
+	// Follow makes followerID follow followingID (no-op if already following)
+	Follow(ctx context.Context, followerID, followingID int64) error
+
+	// Unfollow removes the relationship (no-op if not following)
+	Unfollow(ctx context.Context, followerID, followingID int64) error
+
+	// IsFollowing reports whether followerID follows followingID
+	IsFollowing(ctx context.Context, followerID, followingID int64) (bool, error)
+
+	// GetFollowers lists the users following userID
+	GetFollowers(ctx context.Context, userID int64, cursor string, limit int) (*FollowListResponse, error)
+
+	// GetFollowing lists the users followed by userID
+	GetFollowing(ctx context.Context, userID int64, cursor string, limit int) (*FollowListResponse, error)
+}
+
+// FollowUser is a user entry in a followers or following list
+type FollowUser struct {
+	UserID     int64     `json:"user_id"`
+	Username   string    `json:"username"`
+	Avatar     string    `json:"avatar"`
+	Bio        string    `json:"bio"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+// FollowListResponse represents a page of followers or followed users
+type FollowListResponse struct {
+	Users      []*FollowUser `json:"users"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// followService implements FollowService interface
+type followService struct {
+	followRepo   repository.FollowRepository
+	userRepo     repository.UserRepository
+	batchRepo    repository.BatchRepository
+	transactor   repository.Transactor
+	cacheService cache.Service
+	messageQueue mq.MessageQueue
+	logger       *zap.Logger
+}
+
+// NewFollowService creates a new follow service
+func NewFollowService(
+	followRepo repository.FollowRepository,
+	userRepo repository.UserRepository,
+	batchRepo repository.BatchRepository,
+	transactor repository.Transactor,
+	cacheService cache.Service,
+	messageQueue mq.MessageQueue,
+	logger *zap.Logger,
+) FollowService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &followService{
+		followRepo:   followRepo,
+		userRepo:     userRepo,
+		batchRepo:    batchRepo,
+		transactor:   transactor,
+		cacheService: cacheService,
+		messageQueue: messageQueue,
+		logger:       logger,
+	}
+}
+
+// Follow makes followerID follow followingID
+func (s *followService) Follow(ctx context.Context, followerID, followingID int64) error {
+	if followerID == followingID {
+		return ErrCannotFollowSelf
+	}
+
+	if err := s.ensureUserExists(ctx, followingID); err != nil {
+		return err
+	}
+
+	// Create the follow, update the counters and record its event in one transaction
+	created := false
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		created, err = s.followRepo.Create(ctx, &models.Follow{
+			FollowerID:  followerID,
+			FollowingID: followingID,
+			CreatedAt:   time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create follow: %w", err)
+		}
+		if !created {
+			return nil
+		}
+		return s.publishFollowEvent(ctx, mq.TopicUserFollowed, followerID, followingID)
+	})
+	if err != nil {
+		return err
+	}
+
+	if created {
+		s.invalidateProfiles(ctx, followerID, followingID)
+	}
+	return nil
+}
+
+// Unfollow removes the relationship between followerID and followingID
+func (s *followService) Unfollow(ctx context.Context, followerID, followingID int64) error {
+	if followerID == followingID {
+		return ErrCannotFollowSelf
+	}
+
+	// Delete the follow, update the counters and record its event in one transaction
+	deleted := false
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		deleted, err = s.followRepo.Delete(ctx, followerID, followingID)
+		if err != nil {
+			return fmt.Errorf("failed to delete follow: %w", err)
+		}
+		if !deleted {
+			return nil
+		}
+		return s.publishFollowEvent(ctx, mq.TopicUserUnfollowed, followerID, followingID)
+	})
+	if err != nil {
+		return err
+	}
+
+	if deleted {
+		s.invalidateProfiles(ctx, followerID, followingID)
+	}
+	return nil
+}
+
+// IsFollowing reports whether followerID follows followingID
+func (s *followService) IsFollowing(ctx context.Context, followerID, followingID int64) (bool, error) {
+	following, err := s.followRepo.Exists(ctx, followerID, followingID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check follow: %w", err)
+	}
+	return following, nil
+}
+
+// GetFollowers lists the users following userID, newest first
+func (s *followService) GetFollowers(ctx context.Context, userID int64, cursor string, limit int) (*FollowListResponse, error) {
+	return s.listFollows(ctx, userID, cursor, limit, s.followRepo.FindFollowers, func(f *models.Follow) int64 {
+		return f.FollowerID
+	})
+}
+
+// GetFollowing lists the users followed by userID, newest first
+func (s *followService) GetFollowing(ctx context.Context, userID int64, cursor string, limit int) (*FollowListResponse, error) {
+	return s.listFollows(ctx, userID, cursor, limit, s.followRepo.FindFollowing, func(f *models.Follow) int64 {
+		return f.FollowingID
+	})
+}
+
+// listFollows loads one page of follows and resolves the other side of each relationship
+func (s *followService) listFollows(
+	ctx context.Context,
+	userID int64,
+	cursor string,
+	limit int,
+	find func(ctx context.Context, userID int64, cursor int64, limit int) ([]*models.Follow, error),
+	otherSide func(f *models.Follow) int64,
+) (*FollowListResponse, error) {
+	var afterID int64
+	after, err := decodeKeyset(cursor, followCursorSort)
+	if err != nil {
+		return nil, err
+	}
+	if after != nil {
+		afterID = after.ID
+	}
+
+	if err := s.ensureUserExists(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra row to know whether another page exists
+	follows, err := find(ctx, userID, afterID, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list follows: %w", err)
+	}
+
+	resp := &FollowListResponse{Users: make([]*FollowUser, 0, len(follows))}
+	if len(follows) > limit {
+		follows = follows[:limit]
+		resp.NextCursor, err = encodeKeyset(followCursorSort, follows[len(follows)-1].ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode cursor: %w", err)
+		}
+	}
+
+	ids := make([]int64, len(follows))
+	for i, follow := range follows {
+		ids[i] = otherSide(follow)
+	}
+	users, err := s.batchRepo.FindUsersByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find users: %w", err)
+	}
+
+	for _, follow := range follows {
+		user, ok := users[otherSide(follow)]
+		if !ok {
+			continue
+		}
+		resp.Users = append(resp.Users, &FollowUser{
+			UserID:     user.ID,
+			Username:   user.Username,
+			Avatar:     user.Avatar,
+			Bio:        user.Bio,
+			FollowedAt: follow.CreatedAt,
+		})
+	}
+
+	return resp, nil
+}
+
+// ensureUserExists returns ErrUserNotFound when the user does not exist
+func (s *followService) ensureUserExists(ctx context.Context, userID int64) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// invalidateProfiles drops the cached profiles whose follow counters changed
+func (s *followService) invalidateProfiles(ctx context.Context, userIDs ...int64) {
+	if s.cacheService == nil {
+		return
+	}
+	for _, userID := range userIDs {
+		if err := s.cacheService.Delete(ctx, cache.UserKey(userID)); err != nil {
+			s.logger.Warn("failed to delete user cache", zap.Int64("user_id", userID), zap.Error(err))
+		}
+	}
+}
+
+// publishFollowEvent publishes a user followed or unfollowed event
+func (s *followService) publishFollowEvent(ctx context.Context, topic string, followerID, followingID int64) error {
+	if s.messageQueue == nil {
+		return nil
+	}
+
+	base := mq.BaseEvent{
+		EventID:   uuid.New().String(),
+		EventType: topic,
+		Timestamp: time.Now(),
+	}
+
+	var event interface{}
+	if topic == mq.TopicUserFollowed {
+		event = mq.UserFollowedEvent{BaseEvent: base, FollowerID: followerID, FollowingID: followingID}
+	} else {
+		event = mq.UserUnfollowedEvent{BaseEvent: base, FollowerID: followerID, FollowingID: followingID}
+	}
+
+	if err := s.messageQueue.Publish(ctx, topic, event); err != nil {
+		return fmt.Errorf("failed to publish %s event: %w", topic, err)
+	}
+	return nil
+}
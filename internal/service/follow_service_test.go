@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/kobayashirei/airy/internal/cache"
+	"github.com/kobayashirei/airy/internal/models"
+	"github.com/kobayashirei/airy/internal/mq"
+)
+
+func newTestFollowService() (*followService, *MockFollowRepository, *MockUserRepository, *MockBatchRepository, *MockCacheService, *MockMessageQueue) {
+	followRepo := new(MockFollowRepository)
+	userRepo := new(MockUserRepository)
+	batchRepo := new(MockBatchRepository)
+	cacheService := new(MockCacheService)
+	messageQueue := new(MockMessageQueue)
+	service := NewFollowService(followRepo, userRepo, batchRepo, passthroughTransactor{}, cacheService, messageQueue, nil).(*followService)
+	return service, followRepo, userRepo, batchRepo, cacheService, messageQueue
+}
+
+func TestFollowService_Follow(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("creates follow, invalidates profiles and publishes event", func(t *testing.T) {
+		service, followRepo, userRepo, _, cacheService, messageQueue := newTestFollowService()
+
+		userRepo.On("FindByID", ctx, int64(2)).Return(&models.User{ID: 2}, nil)
+		followRepo.On("Create", ctx, mock.MatchedBy(func(f *models.Follow) bool {
+			return f.FollowerID == 1 && f.FollowingID == 2
+		})).Return(true, nil)
+		cacheService.On("Delete", ctx, cache.UserKey(1)).Return(nil)
+		cacheService.On("Delete", ctx, cache.UserKey(2)).Return(nil)
+		messageQueue.On("Publish", ctx, mq.TopicUserFollowed, mock.MatchedBy(func(e mq.UserFollowedEvent) bool {
+			return e.FollowerID == 1 && e.FollowingID == 2 && e.EventID != ""
+		})).Return(nil)
+
+		err := service.Follow(ctx, 1, 2)
+		assert.NoError(t, err)
+		followRepo.AssertExpectations(t)
+		cacheService.AssertExpectations(t)
+		messageQueue.AssertExpectations(t)
+	})
+
+	t.Run("already following is a no-op", func(t *testing.T) {
+		service, followRepo, userRepo, _, cacheService, messageQueue := newTestFollowService()
+
+		userRepo.On("FindByID", ctx, int64(2)).Return(&models.User{ID: 2}, nil)
+		followRepo.On("Create", ctx, mock.Anything).Return(false, nil)
+
+		err := service.Follow(ctx, 1, 2)
+		assert.NoError(t, err)
+		cacheService.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+		messageQueue.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("fails when the event cannot be recorded", func(t *testing.T) {
+		service, followRepo, userRepo, _, cacheService, messageQueue := newTestFollowService()
+
+		userRepo.On("FindByID", ctx, int64(2)).Return(&models.User{ID: 2}, nil)
+		followRepo.On("Create", ctx, mock.Anything).Return(true, nil)
+		messageQueue.On("Publish", ctx, mq.TopicUserFollowed, mock.Anything).Return(errors.New("outbox unavailable"))
+
+		err := service.Follow(ctx, 1, 2)
+		assert.Error(t, err)
+		cacheService.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("cannot follow self", func(t *testing.T) {
+		service, followRepo, _, _, _, _ := newTestFollowService()
+
+		err := service.Follow(ctx, 1, 1)
+		assert.ErrorIs(t, err, ErrCannotFollowSelf)
+		followRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("target user not found", func(t *testing.T) {
+		service, followRepo, userRepo, _, _, _ := newTestFollowService()
+
+		userRepo.On("FindByID", ctx, int64(2)).Return(nil, nil)
+
+		err := service.Follow(ctx, 1, 2)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+		followRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestFollowService_Unfollow(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("deletes follow and publishes event", func(t *testing.T) {
+		service, followRepo, _, _, cacheService, messageQueue := newTestFollowService()
+
+		followRepo.On("Delete", ctx, int64(1), int64(2)).Return(true, nil)
+		cacheService.On("Delete", ctx, mock.Anything).Return(nil)
+		messageQueue.On("Publish", ctx, mq.TopicUserUnfollowed, mock.AnythingOfType("mq.UserUnfollowedEvent")).Return(nil)
+
+		err := service.Unfollow(ctx, 1, 2)
+		assert.NoError(t, err)
+		messageQueue.AssertExpectations(t)
+	})
+
+	t.Run("not following is a no-op", func(t *testing.T) {
+		service, followRepo, _, _, _, messageQueue := newTestFollowService()
+
+		followRepo.On("Delete", ctx, int64(1), int64(2)).Return(false, nil)
+
+		err := service.Unfollow(ctx, 1, 2)
+		assert.NoError(t, err)
+		messageQueue.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestFollowService_GetFollowers(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	t.Run("returns next cursor when more pages exist", func(t *testing.T) {
+		service, followRepo, userRepo, batchRepo, _, _ := newTestFollowService()
+
+		userRepo.On("FindByID", ctx, int64(1)).Return(&models.User{ID: 1}, nil)
+		followRepo.On("FindFollowers", ctx, int64(1), int64(0), 3).Return([]*models.Follow{
+			{ID: 30, FollowerID: 4, FollowingID: 1, CreatedAt: now},
+			{ID: 20, FollowerID: 3, FollowingID: 1, CreatedAt: now},
+			{ID: 10, FollowerID: 2, FollowingID: 1, CreatedAt: now},
+		}, nil)
+		batchRepo.On("FindUsersByIDs", ctx, []int64{4, 3}).Return(map[int64]*models.User{
+			4: {ID: 4, Username: "dave"},
+			3: {ID: 3, Username: "carol"},
+		}, nil)
+
+		resp, err := service.GetFollowers(ctx, 1, "", 2)
+		assert.NoError(t, err)
+		assert.Len(t, resp.Users, 2)
+		assert.Equal(t, "dave", resp.Users[0].Username)
+		assert.Equal(t, "carol", resp.Users[1].Username)
+		next, err := decodeKeyset(resp.NextCursor, followCursorSort)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(20), next.ID)
+	})
+
+	t.Run("last page has no cursor", func(t *testing.T) {
+		service, followRepo, userRepo, batchRepo, _, _ := newTestFollowService()
+
+		userRepo.On("FindByID", ctx, int64(1)).Return(&models.User{ID: 1}, nil)
+		followRepo.On("FindFollowers", ctx, int64(1), int64(20), 3).Return([]*models.Follow{
+			{ID: 10, FollowerID: 2, FollowingID: 1, CreatedAt: now},
+		}, nil)
+		batchRepo.On("FindUsersByIDs", ctx, []int64{2}).Return(map[int64]*models.User{
+			2: {ID: 2, Username: "bob"},
+		}, nil)
+
+		cursor, err := encodeKeyset(followCursorSort, 20)
+		assert.NoError(t, err)
+
+		resp, err := service.GetFollowers(ctx, 1, cursor, 2)
+		assert.NoError(t, err)
+		assert.Len(t, resp.Users, 1)
+		assert.Empty(t, resp.NextCursor)
+	})
+
+	t.Run("rejects invalid cursor", func(t *testing.T) {
+		service, _, _, _, _, _ := newTestFollowService()
+
+		resp, err := service.GetFollowers(ctx, 1, "20", 2)
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+		assert.Nil(t, resp)
+	})
+}
@@ -0,0 +1,327 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kobayashirei/airy/internal/models"
+	"github.com/kobayashirei/airy/internal/repository"
+)
+
+// ErrCircleHotnessNotFound is returned when a circle has no hotness settings of its own
+var ErrCircleHotnessNotFound = errors.New("circle has no hotness settings")
+
+// HotnessAdminService defines the interface for managing how post hotness is calculated
+type HotnessAdminService interface {
+	GetSettings(ctx context.Context) (*HotnessSettingsResponse, error)
+	SetCircleSettings(ctx context.Context, operatorID, circleID int64, settings HotnessSettings, ip string) (*CircleHotnessSettingsResponse, error)
+	DeleteCircleSettings(ctx context.Context, operatorID, circleID int64, ip string) error
+	// Preview ranks recent posts under candidate settings next to their current ranking
+	Preview(ctx context.Context, req HotnessPreviewRequest) (*HotnessPreviewResponse, error)
+}
+
+// HotnessSettingsResponse represents the default hotness settings and the circle overrides
+type HotnessSettingsResponse struct {
+	Defaults HotnessSettings                  `json:"defaults"`
+	Circles  []*CircleHotnessSettingsResponse `json:"circles"`
+}
+
+// CircleHotnessSettingsResponse represents the hotness override of a circle
+type CircleHotnessSettingsResponse struct {
+	CircleID  int64           `json:"circle_id"`
+	Settings  HotnessSettings `json:"settings"`  // As set; empty fields follow the defaults
+	Effective HotnessSettings `json:"effective"` // With the defaults filled in
+	UpdatedBy int64           `json:"updated_by"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// HotnessPreviewRequest represents a request to preview candidate hotness settings
+type HotnessPreviewRequest struct {
+	Settings   HotnessSettings `json:"settings"`   // Empty fields follow the current settings
+	CircleID   *int64          `json:"circle_id"`  // Ranks the posts of one circle; nil ranks all posts
+	Candidates int             `json:"candidates"` // How many of the newest published posts are ranked
+	Limit      int             `json:"limit"`      // How many of the top ranked posts are returned
+}
+
+// HotnessPreviewResponse represents recent posts ranked under candidate settings
+type HotnessPreviewResponse struct {
+	Settings   HotnessSettings       `json:"settings"` // The candidate settings, with the current ones filled in
+	Candidates int                   `json:"candidates"`
+	Moved      int                   `json:"moved"` // How many of the candidates changed rank
+	Posts      []*HotnessPreviewPost `json:"posts"`
+}
+
+// HotnessPreviewPost represents a post's rank under candidate and current settings; rank 1 is the hottest
+type HotnessPreviewPost struct {
+	PostID       int64      `json:"post_id"`
+	Title        string     `json:"title"`
+	CircleID     *int64     `json:"circle_id"`
+	PublishedAt  *time.Time `json:"published_at"`
+	Rank         int        `json:"rank"`
+	Score        float64    `json:"score"`
+	CurrentRank  int        `json:"current_rank"`
+	CurrentScore float64    `json:"current_score"`
+}
+
+// hotnessAdminService implements HotnessAdminService interface
+type hotnessAdminService struct {
+	strategies        *HotnessStrategies
+	circleHotnessRepo repository.CircleHotnessRepository
+	circleRepo        repository.CircleRepository
+	postRepo          repository.PostRepository
+	batchRepo         repository.BatchRepository
+	adminLogRepo      repository.AdminLogRepository
+}
+
+// NewHotnessAdminService creates a new hotness admin service
+func NewHotnessAdminService(
+	strategies *HotnessStrategies,
+	circleHotnessRepo repository.CircleHotnessRepository,
+	circleRepo repository.CircleRepository,
+	postRepo repository.PostRepository,
+	batchRepo repository.BatchRepository,
+	adminLogRepo repository.AdminLogRepository,
+) HotnessAdminService {
+	return &hotnessAdminService{
+		strategies:        strategies,
+		circleHotnessRepo: circleHotnessRepo,
+		circleRepo:        circleRepo,
+		postRepo:          postRepo,
+		batchRepo:         batchRepo,
+		adminLogRepo:      adminLogRepo,
+	}
+}
+
+// GetSettings retrieves the default settings and every circle override
+func (s *hotnessAdminService) GetSettings(ctx context.Context) (*HotnessSettingsResponse, error) {
+	rows, err := s.circleHotnessRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list circle hotness settings: %w", err)
+	}
+
+	circles := make([]*CircleHotnessSettingsResponse, 0, len(rows))
+	for _, row := range rows {
+		circle, err := s.toResponse(row)
+		if err != nil {
+			return nil, err
+		}
+		circles = append(circles, circle)
+	}
+
+	return &HotnessSettingsResponse{
+		Defaults: s.strategies.Defaults(),
+		Circles:  circles,
+	}, nil
+}
+
+// SetCircleSettings overrides the hotness settings of a circle. Its posts are rescored
+// as they get votes and comments, and by the periodic recompute.
+func (s *hotnessAdminService) SetCircleSettings(ctx context.Context, operatorID, circleID int64, settings HotnessSettings, ip string) (*CircleHotnessSettingsResponse, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	circle, err := s.circleRepo.FindByID(ctx, circleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find circle: %w", err)
+	}
+	if circle == nil {
+		return nil, ErrCircleNotFound
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal hotness settings: %w", err)
+	}
+	row := &models.CircleHotnessSettings{
+		CircleID:  circleID,
+		Settings:  string(data),
+		UpdatedBy: operatorID,
+		UpdatedAt: time.Now(),
+	}
+	if err := s.circleHotnessRepo.Upsert(ctx, row); err != nil {
+		return nil, fmt.Errorf("failed to store circle hotness settings: %w", err)
+	}
+	s.strategies.Invalidate()
+
+	s.logAction(ctx, operatorID, "set_circle_hotness", circleID, ip, map[string]interface{}{
+		"settings": settings,
+	})
+	return s.toResponse(row)
+}
+
+// DeleteCircleSettings removes the override of a circle, returning it to the defaults
+func (s *hotnessAdminService) DeleteCircleSettings(ctx context.Context, operatorID, circleID int64, ip string) error {
+	row, err := s.circleHotnessRepo.FindByCircleID(ctx, circleID)
+	if err != nil {
+		return fmt.Errorf("failed to find circle hotness settings: %w", err)
+	}
+	if row == nil {
+		return ErrCircleHotnessNotFound
+	}
+
+	if err := s.circleHotnessRepo.Delete(ctx, circleID); err != nil {
+		return fmt.Errorf("failed to delete circle hotness settings: %w", err)
+	}
+	s.strategies.Invalidate()
+
+	s.logAction(ctx, operatorID, "delete_circle_hotness", circleID, ip, nil)
+	return nil
+}
+
+// Preview scores the newest published posts under the candidate settings and under
+// their current ones, each post under its circle's, and ranks them both ways. Changing
+// the defaults leaves circle overrides in place, so a preview of the defaults scores
+// posts in circles with their own settings under those. Nothing is stored.
+func (s *hotnessAdminService) Preview(ctx context.Context, req HotnessPreviewRequest) (*HotnessPreviewResponse, error) {
+	if err := req.Settings.Validate(); err != nil {
+		return nil, err
+	}
+	if req.Candidates <= 0 {
+		req.Candidates = 200
+	}
+	if req.Candidates > 1000 {
+		req.Candidates = 1000
+	}
+	if req.Limit <= 0 {
+		req.Limit = 50
+	}
+	if req.Limit > req.Candidates {
+		req.Limit = req.Candidates
+	}
+
+	// The candidate is compared with the settings it would replace
+	_, current, err := s.strategies.For(ctx, req.CircleID)
+	if err != nil {
+		return nil, err
+	}
+	settings := req.Settings.Inherit(current)
+	candidate, err := NewHotnessStrategy(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := s.postRepo.List(ctx, repository.PostListOptions{
+		CircleID: req.CircleID,
+		Status:   "published",
+		SortBy:   "created_at",
+		Order:    "desc",
+		Limit:    req.Candidates,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list posts: %w", err)
+	}
+
+	ids := make([]int64, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+	counts := map[int64]*models.EntityCount{}
+	if len(ids) > 0 {
+		counts, err = s.batchRepo.FindEntityCountsByIDs(ctx, "post", ids)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find entity counts: %w", err)
+		}
+	}
+
+	now := time.Now()
+	previews := make([]*HotnessPreviewPost, len(posts))
+	for i, post := range posts {
+		count, ok := counts[post.ID]
+		if !ok {
+			count = &models.EntityCount{EntityType: "post", EntityID: post.ID}
+		}
+		strategy, _, err := s.strategies.For(ctx, post.CircleID)
+		if err != nil {
+			return nil, err
+		}
+		currentScore := strategy.Score(post, count, now)
+		score := currentScore
+		if req.CircleID != nil {
+			score = candidate.Score(post, count, now)
+		} else if overridden, err := s.strategies.HasOverride(ctx, post.CircleID); err != nil {
+			return nil, err
+		} else if !overridden {
+			score = candidate.Score(post, count, now)
+		}
+		previews[i] = &HotnessPreviewPost{
+			PostID:       post.ID,
+			Title:        post.Title,
+			CircleID:     post.CircleID,
+			PublishedAt:  post.PublishedAt,
+			Score:        score,
+			CurrentScore: currentScore,
+		}
+	}
+
+	// Rank by the current scores first, then by the candidate scores; ties go to the newer post
+	rankPreviews(previews, func(p *HotnessPreviewPost) float64 { return p.CurrentScore }, func(p *HotnessPreviewPost, r int) { p.CurrentRank = r })
+	rankPreviews(previews, func(p *HotnessPreviewPost) float64 { return p.Score }, func(p *HotnessPreviewPost, r int) { p.Rank = r })
+
+	moved := 0
+	for _, preview := range previews {
+		if preview.Rank != preview.CurrentRank {
+			moved++
+		}
+	}
+	if len(previews) > req.Limit {
+		previews = previews[:req.Limit]
+	}
+
+	return &HotnessPreviewResponse{
+		Settings:   settings,
+		Candidates: len(posts),
+		Moved:      moved,
+		Posts:      previews,
+	}, nil
+}
+
+// rankPreviews sorts previews by descending score, then descending post ID, and sets their 1-based ranks
+func rankPreviews(previews []*HotnessPreviewPost, score func(*HotnessPreviewPost) float64, setRank func(*HotnessPreviewPost, int)) {
+	sort.Slice(previews, func(i, j int) bool {
+		if a, b := score(previews[i]), score(previews[j]); a != b {
+			return a > b
+		}
+		return previews[i].PostID > previews[j].PostID
+	})
+	for i, preview := range previews {
+		setRank(preview, i+1)
+	}
+}
+
+// toResponse converts stored circle settings to a response, with the effective settings filled in
+func (s *hotnessAdminService) toResponse(row *models.CircleHotnessSettings) (*CircleHotnessSettingsResponse, error) {
+	var settings HotnessSettings
+	if err := json.Unmarshal([]byte(row.Settings), &settings); err != nil {
+		return nil, fmt.Errorf("failed to decode hotness settings of circle %d: %w", row.CircleID, err)
+	}
+	return &CircleHotnessSettingsResponse{
+		CircleID:  row.CircleID,
+		Settings:  settings,
+		Effective: settings.Inherit(s.strategies.Defaults()),
+		UpdatedBy: row.UpdatedBy,
+		UpdatedAt: row.UpdatedAt,
+	}, nil
+}
+
+// logAction records an administrator's change of circle hotness settings in the admin log
+func (s *hotnessAdminService) logAction(ctx context.Context, operatorID int64, action string, circleID int64, ip string, details map[string]interface{}) {
+	detailsJSON, _ := json.Marshal(details)
+	log := &models.AdminLog{
+		OperatorID: operatorID,
+		Action:     action,
+		EntityType: "circle",
+		EntityID:   &circleID,
+		IP:         ip,
+		Details:    string(detailsJSON),
+		CreatedAt:  time.Now(),
+	}
+	if err := s.adminLogRepo.Create(ctx, log); err != nil {
+		// Log error but don't fail the operation
+		fmt.Printf("failed to create admin log: %v\n", err)
+	}
+}
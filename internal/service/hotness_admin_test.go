@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kobayashirei/airy/internal/models"
+	"github.com/kobayashirei/airy/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHotnessAdminService_Preview(t *testing.T) {
+	ctx := context.Background()
+	postRepo := new(MockPostRepository)
+	batchRepo := new(MockBatchRepository)
+	strategies := NewHotnessStrategies(HotnessSettings{Algorithm: AlgorithmReddit}, nil, 0, nil)
+	svc := NewHotnessAdminService(strategies, nil, nil, postRepo, batchRepo, nil)
+
+	now := time.Now()
+	old, newer, newest := now.Add(-48*time.Hour), now.Add(-2*time.Hour), now.Add(-time.Hour)
+	posts := []*models.Post{
+		{ID: 2, Title: "Newest", PublishedAt: &newest},
+		{ID: 3, Title: "Newer", PublishedAt: &newer},
+		{ID: 1, Title: "Old", PublishedAt: &old},
+	}
+	postRepo.On("List", mock.Anything, mock.MatchedBy(func(opts repository.PostListOptions) bool {
+		return opts.Status == "published" && opts.SortBy == "created_at" && opts.Limit == 200
+	})).Return(posts, nil)
+	batchRepo.On("FindEntityCountsByIDs", mock.Anything, "post", []int64{2, 3, 1}).Return(map[int64]*models.EntityCount{
+		1: {UpvoteCount: 100},
+		2: {UpvoteCount: 5, DownvoteCount: 4},
+		3: {UpvoteCount: 50, DownvoteCount: 45},
+	}, nil)
+
+	// Reddit favors the new posts; best ranks the old, well-liked post first
+	result, err := svc.Preview(ctx, HotnessPreviewRequest{
+		Settings: HotnessSettings{Algorithm: AlgorithmBest},
+		Limit:    2,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, AlgorithmBest, result.Settings.Algorithm)
+	assert.Equal(t, DefaultHotnessWeights, *result.Settings.Weights)
+	assert.Equal(t, 3, result.Candidates)
+	assert.Equal(t, 3, result.Moved)
+	require.Len(t, result.Posts, 2)
+	assert.Equal(t, int64(1), result.Posts[0].PostID)
+	assert.Equal(t, 1, result.Posts[0].Rank)
+	assert.Equal(t, 3, result.Posts[0].CurrentRank)
+	assert.Equal(t, int64(3), result.Posts[1].PostID)
+	assert.Equal(t, 2, result.Posts[1].Rank)
+	assert.Equal(t, 1, result.Posts[1].CurrentRank)
+}
+
+func TestHotnessAdminService_Preview_KeepsCircleOverrides(t *testing.T) {
+	ctx := context.Background()
+	postRepo := new(MockPostRepository)
+	batchRepo := new(MockBatchRepository)
+	repo := &fakeCircleHotnessRepository{settings: map[int64]*models.CircleHotnessSettings{
+		9: {CircleID: 9, Settings: `{"algorithm":"hackernews"}`},
+	}}
+	strategies := NewHotnessStrategies(HotnessSettings{Algorithm: AlgorithmReddit}, repo, time.Hour, nil)
+	svc := NewHotnessAdminService(strategies, repo, nil, postRepo, batchRepo, nil)
+
+	now := time.Now()
+	old, newest := now.Add(-48*time.Hour), now.Add(-time.Hour)
+	posts := []*models.Post{
+		{ID: 2, Title: "Newest", PublishedAt: &newest},
+		{ID: 1, Title: "Old", CircleID: int64Ptr(9), PublishedAt: &old},
+	}
+	postRepo.On("List", mock.Anything, mock.Anything).Return(posts, nil)
+	batchRepo.On("FindEntityCountsByIDs", mock.Anything, "post", []int64{2, 1}).Return(map[int64]*models.EntityCount{
+		1: {UpvoteCount: 100},
+		2: {UpvoteCount: 5, DownvoteCount: 4},
+	}, nil)
+
+	// Previewing the defaults leaves circle 9 on its own settings
+	result, err := svc.Preview(ctx, HotnessPreviewRequest{Settings: HotnessSettings{Algorithm: AlgorithmBest}})
+	require.NoError(t, err)
+	require.Len(t, result.Posts, 2)
+	for _, post := range result.Posts {
+		if post.PostID == 1 {
+			assert.Equal(t, post.CurrentScore, post.Score)
+		} else {
+			assert.NotEqual(t, post.CurrentScore, post.Score)
+		}
+	}
+
+	// Previewing circle 9 itself scores its posts under the candidate
+	postRepo.ExpectedCalls = nil
+	postRepo.On("List", mock.Anything, mock.Anything).Return(posts[1:], nil)
+	batchRepo.On("FindEntityCountsByIDs", mock.Anything, "post", []int64{1}).Return(map[int64]*models.EntityCount{
+		1: {UpvoteCount: 100},
+	}, nil)
+	result, err = svc.Preview(ctx, HotnessPreviewRequest{CircleID: int64Ptr(9), Settings: HotnessSettings{Algorithm: AlgorithmBest}})
+	require.NoError(t, err)
+	require.Len(t, result.Posts, 1)
+	assert.NotEqual(t, result.Posts[0].CurrentScore, result.Posts[0].Score)
+}
+
+func TestHotnessAdminService_Preview_InvalidSettings(t *testing.T) {
+	strategies := NewHotnessStrategies(HotnessSettings{}, nil, 0, nil)
+	svc := NewHotnessAdminService(strategies, nil, nil, nil, nil, nil)
+
+	_, err := svc.Preview(context.Background(), HotnessPreviewRequest{
+		Settings: HotnessSettings{Algorithm: "rising"},
+	})
+
+	assert.ErrorIs(t, err, ErrInvalidHotnessSettings)
+}
+
+func TestHotnessAdminService_SetCircleSettings(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeCircleHotnessRepository{settings: map[int64]*models.CircleHotnessSettings{}}
+	circleRepo := new(MockCircleRepository)
+	logRepo := new(MockAdminLogRepository)
+	strategies := NewHotnessStrategies(HotnessSettings{Algorithm: AlgorithmHackerNews}, repo, time.Hour, nil)
+	svc := NewHotnessAdminService(strategies, repo, circleRepo, nil, nil, logRepo)
+
+	circleRepo.On("FindByID", mock.Anything, int64(5)).Return(&models.Circle{ID: 5}, nil)
+	circleRepo.On("FindByID", mock.Anything, int64(6)).Return(nil, nil)
+	logRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	// Cache the overrides before the change, which must invalidate them
+	_, settings, err := strategies.For(ctx, int64Ptr(5))
+	require.NoError(t, err)
+	assert.Equal(t, AlgorithmHackerNews, settings.Algorithm)
+
+	weights := HotnessWeights{Upvote: 1, Downvote: 1, Comment: 0.5}
+	result, err := svc.SetCircleSettings(ctx, 1, 5, HotnessSettings{Algorithm: AlgorithmControversial, Weights: &weights}, "127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, AlgorithmControversial, result.Effective.Algorithm)
+	assert.Equal(t, defaultGravity, result.Effective.Gravity)
+
+	var stored HotnessSettings
+	require.NoError(t, json.Unmarshal([]byte(repo.settings[5].Settings), &stored))
+	assert.Zero(t, stored.Gravity, "Unset fields are stored unset to follow the defaults")
+
+	strategy, _, err := strategies.For(ctx, int64Ptr(5))
+	require.NoError(t, err)
+	assert.Equal(t, controversialStrategy{weights: weights}, strategy)
+
+	_, err = svc.SetCircleSettings(ctx, 1, 5, HotnessSettings{Gravity: -1}, "127.0.0.1")
+	assert.ErrorIs(t, err, ErrInvalidHotnessSettings)
+	_, err = svc.SetCircleSettings(ctx, 1, 6, HotnessSettings{}, "127.0.0.1")
+	assert.ErrorIs(t, err, ErrCircleNotFound)
+
+	// Deleting returns the circle to the defaults
+	require.NoError(t, svc.DeleteCircleSettings(ctx, 1, 5, "127.0.0.1"))
+	assert.ErrorIs(t, svc.DeleteCircleSettings(ctx, 1, 5, "127.0.0.1"), ErrCircleHotnessNotFound)
+	_, settings, err = strategies.For(ctx, int64Ptr(5))
+	require.NoError(t, err)
+	assert.Equal(t, AlgorithmHackerNews, settings.Algorithm)
+	logRepo.AssertNumberOfCalls(t, "Create", 2)
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kobayashirei/airy/internal/models"
+	"github.com/kobayashirei/airy/internal/repository"
+)
+
+// HotnessIndexer writes hotness scores to the search index
+type HotnessIndexer interface {
+	BulkUpdatePostHotnessScores(ctx context.Context, scores map[int64]float64) error
+}
+
+// HotnessRecomputer recomputes the hotness scores of recently published posts. Scores
+// that depend on a post's age, like the Hacker News algorithm's, go stale between the
+// votes and comments that recompute them, so they are recomputed periodically, in
+// shards of the posts split by ID that run as separate jobs.
+type HotnessRecomputer struct {
+	hotness   HotnessService
+	postRepo  repository.PostRepository
+	batchRepo repository.BatchRepository
+	indexer   HotnessIndexer // nil without search
+	window    time.Duration
+	batchSize int
+	logger    *zap.Logger
+}
+
+// NewHotnessRecomputer creates a recomputer of the hotness of the posts published within window
+func NewHotnessRecomputer(
+	hotness HotnessService,
+	postRepo repository.PostRepository,
+	batchRepo repository.BatchRepository,
+	indexer HotnessIndexer,
+	window time.Duration,
+	batchSize int,
+	logger *zap.Logger,
+) *HotnessRecomputer {
+	if window <= 0 {
+		window = 72 * time.Hour
+	}
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &HotnessRecomputer{
+		hotness:   hotness,
+		postRepo:  postRepo,
+		batchRepo: batchRepo,
+		indexer:   indexer,
+		window:    window,
+		batchSize: batchSize,
+		logger:    logger,
+	}
+}
+
+// RecomputeHotness recomputes the hotness scores of the recently published posts of
+// one shard, a batch at a time, and returns the number of posts
+func (r *HotnessRecomputer) RecomputeHotness(ctx context.Context, shard, shards int) (int, error) {
+	since := time.Now().Add(-r.window)
+
+	var afterID int64
+	total := 0
+	for {
+		posts, err := r.postRepo.ListPublishedSince(ctx, since, shard, shards, afterID, r.batchSize)
+		if err != nil {
+			return total, fmt.Errorf("failed to list posts: %w", err)
+		}
+		if len(posts) == 0 {
+			break
+		}
+
+		if err := r.recomputeBatch(ctx, posts); err != nil {
+			return total, err
+		}
+		total += len(posts)
+
+		if len(posts) < r.batchSize {
+			break
+		}
+		afterID = posts[len(posts)-1].ID
+	}
+
+	r.logger.Debug("Recomputed hotness", zap.Int("shard", shard), zap.Int("shards", shards), zap.Int("posts", total))
+	return total, nil
+}
+
+// recomputeBatch recomputes and stores the scores of a batch of posts
+func (r *HotnessRecomputer) recomputeBatch(ctx context.Context, posts []*models.Post) error {
+	ids := make([]int64, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+	counts, err := r.batchRepo.FindEntityCountsByIDs(ctx, "post", ids)
+	if err != nil {
+		return fmt.Errorf("failed to find entity counts: %w", err)
+	}
+
+	scores := make(map[int64]float64, len(posts))
+	for _, post := range posts {
+		count, ok := counts[post.ID]
+		if !ok {
+			count = &models.EntityCount{EntityType: "post", EntityID: post.ID}
+		}
+		score, err := r.hotness.CalculateHotness(ctx, post, count)
+		if err != nil {
+			return fmt.Errorf("failed to calculate hotness: %w", err)
+		}
+		scores[post.ID] = score
+	}
+
+	if err := r.postRepo.UpdateHotnessScores(ctx, scores); err != nil {
+		return fmt.Errorf("failed to update hotness scores: %w", err)
+	}
+
+	// The search index is eventually consistent; the next run syncs it again
+	if r.indexer != nil {
+		if err := r.indexer.BulkUpdatePostHotnessScores(ctx, scores); err != nil {
+			r.logger.Warn("Failed to sync hotness scores to the search index", zap.Error(err))
+		}
+	}
+	return nil
+}
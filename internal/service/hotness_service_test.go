@@ -7,13 +7,17 @@ import (
 
 	"github.com/kobayashirei/airy/internal/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
+// newTestHotnessService creates a hotness service using algorithm for every post
+func newTestHotnessService(algorithm HotnessAlgorithm) HotnessService {
+	return NewHotnessService(nil, nil, NewHotnessStrategies(HotnessSettings{Algorithm: algorithm}, nil, 0, nil))
+}
+
 func TestCalculateRedditHotness(t *testing.T) {
-	// Create a hotness service with Reddit algorithm
-	service := &hotnessService{
-		algorithm: AlgorithmReddit,
-	}
+	// Create a Reddit strategy with the default weights
+	strategy := redditStrategy{weights: DefaultHotnessWeights}
 
 	// Test case 1: New post with positive score
 	now := time.Now()
@@ -30,7 +34,7 @@ func TestCalculateRedditHotness(t *testing.T) {
 		DownvoteCount: 2,
 	}
 
-	score := service.calculateRedditHotness(post, counts)
+	score := strategy.Score(post, counts, now)
 	assert.Greater(t, score, 0.0, "Hotness score should be positive for upvoted post")
 
 	// Test case 2: Post with negative score
@@ -41,7 +45,7 @@ func TestCalculateRedditHotness(t *testing.T) {
 		DownvoteCount: 10,
 	}
 
-	score2 := service.calculateRedditHotness(post, counts2)
+	score2 := strategy.Score(post, counts2, now)
 	assert.Less(t, score2, score, "Post with negative score should have lower hotness")
 
 	// Test case 3: Old post should have lower hotness than new post with same score
@@ -53,15 +57,13 @@ func TestCalculateRedditHotness(t *testing.T) {
 		PublishedAt: &oldTime,
 	}
 
-	oldScore := service.calculateRedditHotness(oldPost, counts)
+	oldScore := strategy.Score(oldPost, counts, now)
 	assert.Less(t, oldScore, score, "Older post should have lower hotness than newer post")
 }
 
 func TestCalculateHackerNewsHotness(t *testing.T) {
-	// Create a hotness service with Hacker News algorithm
-	service := &hotnessService{
-		algorithm: AlgorithmHackerNews,
-	}
+	// Create a Hacker News strategy with the default weights and gravity
+	strategy := hackerNewsStrategy{weights: DefaultHotnessWeights, gravity: defaultGravity}
 
 	// Test case 1: New post with positive score
 	now := time.Now()
@@ -78,7 +80,7 @@ func TestCalculateHackerNewsHotness(t *testing.T) {
 		DownvoteCount: 2,
 	}
 
-	score := service.calculateHackerNewsHotness(post, counts)
+	score := strategy.Score(post, counts, now)
 	assert.Greater(t, score, 0.0, "Hotness score should be positive for upvoted post")
 
 	// Test case 2: Post with more upvotes should have higher hotness
@@ -89,7 +91,7 @@ func TestCalculateHackerNewsHotness(t *testing.T) {
 		DownvoteCount: 2,
 	}
 
-	score2 := service.calculateHackerNewsHotness(post, counts2)
+	score2 := strategy.Score(post, counts2, now)
 	assert.Greater(t, score2, score, "Post with more upvotes should have higher hotness")
 
 	// Test case 3: Old post should have lower hotness than new post with same score
@@ -101,7 +103,7 @@ func TestCalculateHackerNewsHotness(t *testing.T) {
 		PublishedAt: &oldTime,
 	}
 
-	oldScore := service.calculateHackerNewsHotness(oldPost, counts)
+	oldScore := strategy.Score(oldPost, counts, now)
 	assert.Less(t, oldScore, score, "Older post should have lower hotness than newer post")
 }
 
@@ -122,17 +124,13 @@ func TestCalculateHotness_AlgorithmSelection(t *testing.T) {
 	}
 
 	// Test Reddit algorithm
-	redditService := &hotnessService{
-		algorithm: AlgorithmReddit,
-	}
+	redditService := newTestHotnessService(AlgorithmReddit)
 	redditScore, err := redditService.CalculateHotness(ctx, post, counts)
 	assert.NoError(t, err)
 	assert.Greater(t, redditScore, 0.0)
 
 	// Test Hacker News algorithm
-	hnService := &hotnessService{
-		algorithm: AlgorithmHackerNews,
-	}
+	hnService := newTestHotnessService(AlgorithmHackerNews)
 	hnScore, err := hnService.CalculateHotness(ctx, post, counts)
 	assert.NoError(t, err)
 	assert.Greater(t, hnScore, 0.0)
@@ -157,18 +155,14 @@ func TestCalculateHotness_InvalidAlgorithm(t *testing.T) {
 	}
 
 	// Test with invalid algorithm
-	service := &hotnessService{
-		algorithm: "invalid",
-	}
+	service := newTestHotnessService("invalid")
 	_, err := service.CalculateHotness(ctx, post, counts)
 	assert.Error(t, err, "Should return error for invalid algorithm")
 }
 
 func TestCalculateHotness_NilPost(t *testing.T) {
 	ctx := context.Background()
-	service := &hotnessService{
-		algorithm: AlgorithmReddit,
-	}
+	service := newTestHotnessService(AlgorithmReddit)
 	counts := &models.EntityCount{
 		EntityType:  "post",
 		EntityID:    1,
@@ -196,9 +190,7 @@ func TestCalculateHotness_ZeroScore(t *testing.T) {
 	}
 
 	// Test Reddit algorithm with zero score
-	redditService := &hotnessService{
-		algorithm: AlgorithmReddit,
-	}
+	redditService := newTestHotnessService(AlgorithmReddit)
 	score, err := redditService.CalculateHotness(ctx, post, counts)
 	assert.NoError(t, err)
 	// Reddit algorithm should still produce a score based on time
@@ -206,11 +198,199 @@ func TestCalculateHotness_ZeroScore(t *testing.T) {
 	_ = score
 
 	// Test Hacker News algorithm with zero score
-	hnService := &hotnessService{
-		algorithm: AlgorithmHackerNews,
-	}
+	hnService := newTestHotnessService(AlgorithmHackerNews)
 	hnScore, err := hnService.CalculateHotness(ctx, post, counts)
 	assert.NoError(t, err)
 	// HN algorithm should produce a non-negative score
 	assert.GreaterOrEqual(t, hnScore, 0.0)
 }
+
+func TestCalculateHotness_FavoritesRaiseScore(t *testing.T) {
+	now := time.Now().Add(-2 * time.Hour)
+	post := &models.Post{
+		ID:          1,
+		Title:       "Test Post",
+		CreatedAt:   now,
+		PublishedAt: &now,
+	}
+	plain := &models.EntityCount{
+		EntityType:  "post",
+		EntityID:    1,
+		UpvoteCount: 10,
+	}
+	favorited := &models.EntityCount{
+		EntityType:    "post",
+		EntityID:      1,
+		UpvoteCount:   10,
+		FavoriteCount: 5,
+	}
+
+	reddit := redditStrategy{weights: DefaultHotnessWeights}
+	hackerNews := hackerNewsStrategy{weights: DefaultHotnessWeights, gravity: defaultGravity}
+	assert.Greater(t, reddit.Score(post, favorited, time.Now()), reddit.Score(post, plain, time.Now()))
+	assert.Greater(t, hackerNews.Score(post, favorited, time.Now()), hackerNews.Score(post, plain, time.Now()))
+}
+
+func TestBestStrategy(t *testing.T) {
+	strategy := bestStrategy{weights: DefaultHotnessWeights}
+	post := &models.Post{ID: 1}
+	now := time.Now()
+
+	few := strategy.Score(post, &models.EntityCount{UpvoteCount: 4, DownvoteCount: 1}, now)
+	many := strategy.Score(post, &models.EntityCount{UpvoteCount: 400, DownvoteCount: 100}, now)
+	worse := strategy.Score(post, &models.EntityCount{UpvoteCount: 300, DownvoteCount: 200}, now)
+
+	// The same share of votes is more confidently good with more votes
+	assert.Greater(t, many, few)
+	assert.Greater(t, many, worse)
+	assert.Less(t, many, 0.8, "The lower bound is below the observed share")
+	assert.Equal(t, 0.0, strategy.Score(post, &models.EntityCount{}, now))
+}
+
+func TestControversialStrategy(t *testing.T) {
+	strategy := controversialStrategy{weights: DefaultHotnessWeights}
+	post := &models.Post{ID: 1}
+	now := time.Now()
+
+	even := strategy.Score(post, &models.EntityCount{UpvoteCount: 50, DownvoteCount: 50}, now)
+	lopsided := strategy.Score(post, &models.EntityCount{UpvoteCount: 90, DownvoteCount: 10}, now)
+	small := strategy.Score(post, &models.EntityCount{UpvoteCount: 5, DownvoteCount: 5}, now)
+
+	assert.Equal(t, 100.0, even)
+	assert.Greater(t, even, lopsided)
+	assert.Greater(t, even, small)
+	assert.Equal(t, 0.0, strategy.Score(post, &models.EntityCount{UpvoteCount: 50}, now))
+}
+
+func TestHotnessWeights(t *testing.T) {
+	post := &models.Post{ID: 1, ViewCount: 1000}
+	counts := &models.EntityCount{UpvoteCount: 10, DownvoteCount: 2, CommentCount: 5, FavoriteCount: 1}
+
+	// The default weights ignore comments and views
+	up, down := DefaultHotnessWeights.votes(post, counts)
+	assert.Equal(t, 12.0, up)
+	assert.Equal(t, 2.0, down)
+
+	up, down = HotnessWeights{Upvote: 1, Downvote: 2, Comment: 0.5, Favorite: 3, View: 0.01}.votes(post, counts)
+	assert.InDelta(t, 25.5, up, 1e-9)
+	assert.Equal(t, 4.0, down)
+}
+
+func TestHotnessSettings_Validate(t *testing.T) {
+	assert.NoError(t, HotnessSettings{}.Validate())
+	assert.NoError(t, HotnessSettings{Algorithm: AlgorithmBest, Weights: &HotnessWeights{Upvote: 1}}.Validate())
+	assert.ErrorIs(t, HotnessSettings{Algorithm: "top"}.Validate(), ErrInvalidHotnessSettings)
+	assert.ErrorIs(t, HotnessSettings{Weights: &HotnessWeights{View: -1}}.Validate(), ErrInvalidHotnessSettings)
+	assert.ErrorIs(t, HotnessSettings{Gravity: -1}.Validate(), ErrInvalidHotnessSettings)
+}
+
+// fakeCircleHotnessRepository serves circle hotness settings from memory
+type fakeCircleHotnessRepository struct {
+	settings map[int64]*models.CircleHotnessSettings
+	lists    int
+}
+
+func (f *fakeCircleHotnessRepository) FindByCircleID(ctx context.Context, circleID int64) (*models.CircleHotnessSettings, error) {
+	return f.settings[circleID], nil
+}
+
+func (f *fakeCircleHotnessRepository) List(ctx context.Context) ([]*models.CircleHotnessSettings, error) {
+	f.lists++
+	var rows []*models.CircleHotnessSettings
+	for _, row := range f.settings {
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (f *fakeCircleHotnessRepository) Upsert(ctx context.Context, settings *models.CircleHotnessSettings) error {
+	f.settings[settings.CircleID] = settings
+	return nil
+}
+
+func (f *fakeCircleHotnessRepository) Delete(ctx context.Context, circleID int64) error {
+	delete(f.settings, circleID)
+	return nil
+}
+
+func TestHotnessStrategies_CircleOverride(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeCircleHotnessRepository{settings: map[int64]*models.CircleHotnessSettings{
+		5: {CircleID: 5, Settings: `{"algorithm":"best"}`},
+		6: {CircleID: 6, Settings: `{"gravity":1.2}`},
+		7: {CircleID: 7, Settings: `{"algorithm":"top"}`},
+	}}
+	strategies := NewHotnessStrategies(HotnessSettings{Algorithm: AlgorithmHackerNews}, repo, time.Hour, nil)
+
+	_, settings, err := strategies.For(ctx, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, AlgorithmHackerNews, settings.Algorithm)
+	assert.Equal(t, defaultGravity, settings.Gravity)
+
+	strategy, settings, err := strategies.For(ctx, int64Ptr(5))
+	assert.NoError(t, err)
+	assert.IsType(t, bestStrategy{}, strategy)
+	assert.Equal(t, DefaultHotnessWeights, *settings.Weights)
+
+	// Unset fields are inherited from the defaults
+	strategy, settings, err = strategies.For(ctx, int64Ptr(6))
+	assert.NoError(t, err)
+	assert.Equal(t, hackerNewsStrategy{weights: DefaultHotnessWeights, gravity: 1.2}, strategy)
+	assert.Equal(t, AlgorithmHackerNews, settings.Algorithm)
+
+	// Invalid and missing overrides fall back to the defaults
+	for _, circleID := range []int64{7, 8} {
+		_, settings, err = strategies.For(ctx, int64Ptr(circleID))
+		assert.NoError(t, err)
+		assert.Equal(t, defaultGravity, settings.Gravity)
+	}
+
+	// Overrides are cached until invalidated
+	assert.Equal(t, 1, repo.lists)
+	strategies.Invalidate()
+	_, _, err = strategies.For(ctx, int64Ptr(5))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, repo.lists)
+}
+
+// fakeHotnessIndexer records the scores synced to the search index
+type fakeHotnessIndexer struct {
+	scores map[int64]float64
+}
+
+func (f *fakeHotnessIndexer) BulkUpdatePostHotnessScores(ctx context.Context, scores map[int64]float64) error {
+	for id, score := range scores {
+		f.scores[id] = score
+	}
+	return nil
+}
+
+func TestHotnessRecomputer_RecomputeHotness(t *testing.T) {
+	mockPostRepo := new(MockPostRepository)
+	mockBatchRepo := new(MockBatchRepository)
+	indexer := &fakeHotnessIndexer{scores: map[int64]float64{}}
+	hotness := NewHotnessService(mockPostRepo, nil, NewHotnessStrategies(HotnessSettings{Algorithm: AlgorithmHackerNews}, nil, 0, nil))
+	recomputer := NewHotnessRecomputer(hotness, mockPostRepo, mockBatchRepo, indexer, 72*time.Hour, 2, nil)
+
+	publishedAt := time.Now().Add(-10 * time.Hour)
+	first := []*models.Post{{ID: 3, PublishedAt: &publishedAt}, {ID: 7, PublishedAt: &publishedAt}}
+	second := []*models.Post{{ID: 11, PublishedAt: &publishedAt}}
+
+	// Posts are read a batch at a time, continuing after the last ID
+	mockPostRepo.On("ListPublishedSince", mock.Anything, mock.Anything, 3, 4, int64(0), 2).Return(first, nil)
+	mockPostRepo.On("ListPublishedSince", mock.Anything, mock.Anything, 3, 4, int64(7), 2).Return(second, nil)
+	mockBatchRepo.On("FindEntityCountsByIDs", mock.Anything, "post", []int64{3, 7}).Return(map[int64]*models.EntityCount{
+		3: {EntityType: "post", EntityID: 3, UpvoteCount: 10},
+	}, nil)
+	mockBatchRepo.On("FindEntityCountsByIDs", mock.Anything, "post", []int64{11}).Return(map[int64]*models.EntityCount{}, nil)
+	mockPostRepo.On("UpdateHotnessScores", mock.Anything, mock.Anything).Return(nil)
+
+	total, err := recomputer.RecomputeHotness(context.Background(), 3, 4)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total)
+	mockPostRepo.AssertNumberOfCalls(t, "UpdateHotnessScores", 2)
+	assert.Len(t, indexer.scores, 3)
+	assert.Greater(t, indexer.scores[3], indexer.scores[7])
+	assert.Equal(t, 0.0, indexer.scores[11])
+}
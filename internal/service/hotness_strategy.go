@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kobayashirei/airy/internal/models"
+	"github.com/kobayashirei/airy/internal/repository"
+)
+
+// ErrInvalidHotnessSettings is returned for hotness settings with an unknown algorithm or negative values
+var ErrInvalidHotnessSettings = errors.New("invalid hotness settings")
+
+// defaultGravity is how quickly posts fall with age under the Hacker News algorithm (HN's own value)
+const defaultGravity = 1.8
+
+// wilsonZ is the z-score of the confidence of the Wilson lower bound, 95%
+const wilsonZ = 1.96
+
+// HotnessWeights are how much each signal of a post counts for in its hotness, in upvotes.
+// Downvotes count against the post; every other signal counts for it.
+type HotnessWeights struct {
+	Upvote   float64 `json:"upvote"`
+	Downvote float64 `json:"downvote"`
+	Comment  float64 `json:"comment"`
+	Favorite float64 `json:"favorite"`
+	View     float64 `json:"view"`
+}
+
+// DefaultHotnessWeights count votes as themselves and a favorite as favoriteWeight upvotes
+var DefaultHotnessWeights = HotnessWeights{Upvote: 1, Downvote: 1, Favorite: favoriteWeight}
+
+// votes returns the weighted votes for and against a post
+func (w HotnessWeights) votes(post *models.Post, counts *models.EntityCount) (up, down float64) {
+	up = w.Upvote*float64(counts.UpvoteCount) +
+		w.Comment*float64(counts.CommentCount) +
+		w.Favorite*float64(counts.FavoriteCount) +
+		w.View*float64(post.ViewCount)
+	down = w.Downvote * float64(counts.DownvoteCount)
+	return up, down
+}
+
+// HotnessSettings configure how post hotness is calculated. Per-circle settings leave
+// fields empty to inherit the default settings': weights as a whole, gravity on its own.
+type HotnessSettings struct {
+	Algorithm HotnessAlgorithm `json:"algorithm,omitempty"`
+	Weights   *HotnessWeights  `json:"weights,omitempty"`
+	Gravity   float64          `json:"gravity,omitempty"` // Used by "hackernews"
+}
+
+// Inherit returns the settings with their empty fields taken from base
+func (s HotnessSettings) Inherit(base HotnessSettings) HotnessSettings {
+	if s.Algorithm == "" {
+		s.Algorithm = base.Algorithm
+	}
+	if s.Weights == nil {
+		s.Weights = base.Weights
+	}
+	if s.Gravity == 0 {
+		s.Gravity = base.Gravity
+	}
+	return s
+}
+
+// Validate checks the settings name a known algorithm and have no negative values
+func (s HotnessSettings) Validate() error {
+	switch s.Algorithm {
+	case "", AlgorithmReddit, AlgorithmHackerNews, AlgorithmBest, AlgorithmControversial:
+	default:
+		return fmt.Errorf("%w: unknown algorithm %q", ErrInvalidHotnessSettings, s.Algorithm)
+	}
+	if w := s.Weights; w != nil && (w.Upvote < 0 || w.Downvote < 0 || w.Comment < 0 || w.Favorite < 0 || w.View < 0) {
+		return fmt.Errorf("%w: weights must not be negative", ErrInvalidHotnessSettings)
+	}
+	if s.Gravity < 0 {
+		return fmt.Errorf("%w: gravity must not be negative", ErrInvalidHotnessSettings)
+	}
+	return nil
+}
+
+// builtinHotnessSettings are the settings empty settings fall back to
+func builtinHotnessSettings() HotnessSettings {
+	weights := DefaultHotnessWeights
+	return HotnessSettings{Algorithm: AlgorithmReddit, Weights: &weights, Gravity: defaultGravity}
+}
+
+// HotnessStrategy scores how hot a post is; posts rank by descending score
+type HotnessStrategy interface {
+	Score(post *models.Post, counts *models.EntityCount, now time.Time) float64
+}
+
+// NewHotnessStrategy creates the strategy of the settings' algorithm. Empty settings
+// fall back to the Reddit algorithm with the default weights and gravity.
+func NewHotnessStrategy(settings HotnessSettings) (HotnessStrategy, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+	settings = settings.Inherit(builtinHotnessSettings())
+
+	weights := *settings.Weights
+	switch settings.Algorithm {
+	case AlgorithmReddit:
+		return redditStrategy{weights: weights}, nil
+	case AlgorithmHackerNews:
+		return hackerNewsStrategy{weights: weights, gravity: settings.Gravity}, nil
+	case AlgorithmBest:
+		return bestStrategy{weights: weights}, nil
+	default:
+		return controversialStrategy{weights: weights}, nil
+	}
+}
+
+// postTime is the time a post is ranked by: its publication, or its creation before that
+func postTime(post *models.Post) time.Time {
+	if post.PublishedAt != nil {
+		return *post.PublishedAt
+	}
+	return post.CreatedAt
+}
+
+// redditStrategy implements Reddit's hot ranking algorithm
+// Formula: log10(max(|score|, 1)) + sign(score) * seconds / 45000
+// where score = weighted upvotes - weighted downvotes
+// and seconds = time since epoch
+type redditStrategy struct {
+	weights HotnessWeights
+}
+
+func (s redditStrategy) Score(post *models.Post, counts *models.EntityCount, now time.Time) float64 {
+	up, down := s.weights.votes(post, counts)
+	score := up - down
+
+	// Get the order of magnitude
+	order := math.Log10(math.Max(math.Abs(score), 1))
+
+	// Get sign of score
+	var sign float64
+	if score > 0 {
+		sign = 1
+	} else if score < 0 {
+		sign = -1
+	}
+
+	// The 45000 is approximately 12.5 hours in seconds: a post 12.5 hours newer
+	// needs a tenth of the score to rank the same
+	seconds := float64(postTime(post).Unix())
+	return order + sign*seconds/45000
+}
+
+// hackerNewsStrategy implements Hacker News' ranking algorithm
+// Formula: (score - 1) / (age + 2)^gravity
+// where score = weighted upvotes - weighted downvotes + 1
+// age = hours since post creation
+// gravity controls how quickly posts fall
+type hackerNewsStrategy struct {
+	weights HotnessWeights
+	gravity float64
+}
+
+func (s hackerNewsStrategy) Score(post *models.Post, counts *models.EntityCount, now time.Time) float64 {
+	// The +1 gives new posts a baseline; the score is at least 0
+	up, down := s.weights.votes(post, counts)
+	score := math.Max(up-down+1, 0)
+
+	// The +2 prevents division by zero and gives very new posts a boost
+	ageHours := now.Sub(postTime(post)).Hours()
+	return (score - 1) / math.Pow(ageHours+2, s.gravity)
+}
+
+// bestStrategy ranks posts by the lower bound of the Wilson score interval of the share
+// of weighted votes for them, so a post needs both a high share and enough votes to be
+// confidently good. It ignores age.
+type bestStrategy struct {
+	weights HotnessWeights
+}
+
+func (s bestStrategy) Score(post *models.Post, counts *models.EntityCount, now time.Time) float64 {
+	return wilsonLowerBound(s.weights.votes(post, counts))
+}
+
+// wilsonLowerBound is the lower bound of the Wilson score interval of the share of up
+// among up and down votes; 0 without votes
+func wilsonLowerBound(up, down float64) float64 {
+	n := up + down
+	if n <= 0 {
+		return 0
+	}
+
+	p := up / n
+	z2 := wilsonZ * wilsonZ
+	return (p + z2/(2*n) - wilsonZ*math.Sqrt((p*(1-p)+z2/(4*n))/n)) / (1 + z2/n)
+}
+
+// controversialStrategy ranks posts with many weighted votes split evenly for and
+// against them highest, following Reddit's controversial sort: magnitude^balance, where
+// balance is the smaller side over the larger one. It ignores age.
+type controversialStrategy struct {
+	weights HotnessWeights
+}
+
+func (s controversialStrategy) Score(post *models.Post, counts *models.EntityCount, now time.Time) float64 {
+	up, down := s.weights.votes(post, counts)
+	if up <= 0 || down <= 0 {
+		return 0
+	}
+
+	balance := math.Min(up, down) / math.Max(up, down)
+	return math.Pow(up+down, balance)
+}
+
+// circleHotness is the hotness override of a circle
+type circleHotness struct {
+	settings HotnessSettings
+	strategy HotnessStrategy
+}
+
+// HotnessStrategies resolves the hotness strategy of a post: its circle's override, or
+// the default. Overrides are loaded all at once and cached for refresh, so a change
+// reaches the other replicas within refresh.
+type HotnessStrategies struct {
+	defaults        HotnessSettings
+	defaultStrategy HotnessStrategy
+	defaultErr      error
+
+	repo    repository.CircleHotnessRepository // nil without overrides
+	refresh time.Duration
+	logger  *zap.Logger
+
+	mu       sync.Mutex
+	loadedAt time.Time
+	circles  map[int64]circleHotness
+}
+
+// NewHotnessStrategies creates a hotness strategy resolver. Invalid default settings
+// fail every calculation, as an unknown algorithm did before overrides existed. A nil
+// repo disables per-circle overrides.
+func NewHotnessStrategies(
+	defaults HotnessSettings,
+	repo repository.CircleHotnessRepository,
+	refresh time.Duration,
+	logger *zap.Logger,
+) *HotnessStrategies {
+	if refresh <= 0 {
+		refresh = time.Minute
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	defaults = defaults.Inherit(builtinHotnessSettings())
+	strategy, err := NewHotnessStrategy(defaults)
+	return &HotnessStrategies{
+		defaults:        defaults,
+		defaultStrategy: strategy,
+		defaultErr:      err,
+		repo:            repo,
+		refresh:         refresh,
+		logger:          logger,
+	}
+}
+
+// Defaults returns the default settings, with every field set
+func (s *HotnessStrategies) Defaults() HotnessSettings {
+	return s.defaults
+}
+
+// For returns the strategy and settings of the posts of a circle, or of posts outside circles for nil
+func (s *HotnessStrategies) For(ctx context.Context, circleID *int64) (HotnessStrategy, HotnessSettings, error) {
+	if circleID != nil && s.repo != nil {
+		circles, err := s.load(ctx)
+		if err != nil {
+			return nil, HotnessSettings{}, err
+		}
+		if override, ok := circles[*circleID]; ok {
+			return override.strategy, override.settings, nil
+		}
+	}
+	if s.defaultErr != nil {
+		return nil, HotnessSettings{}, s.defaultErr
+	}
+	return s.defaultStrategy, s.defaults, nil
+}
+
+// HasOverride reports whether a circle has hotness settings of its own
+func (s *HotnessStrategies) HasOverride(ctx context.Context, circleID *int64) (bool, error) {
+	if circleID == nil || s.repo == nil {
+		return false, nil
+	}
+	circles, err := s.load(ctx)
+	if err != nil {
+		return false, err
+	}
+	_, ok := circles[*circleID]
+	return ok, nil
+}
+
+// Invalidate drops the cached overrides so the next lookup loads them again
+func (s *HotnessStrategies) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loadedAt = time.Time{}
+}
+
+// load returns the cached overrides, reloading them once they are older than refresh.
+// When reloading fails, the stale overrides are kept for another refresh rather than
+// failing every calculation.
+func (s *HotnessStrategies) load(ctx context.Context) (map[int64]circleHotness, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.circles != nil && time.Since(s.loadedAt) < s.refresh {
+		return s.circles, nil
+	}
+
+	rows, err := s.repo.List(ctx)
+	if err != nil {
+		if s.circles != nil {
+			s.logger.Warn("Failed to reload circle hotness settings, keeping the loaded ones", zap.Error(err))
+			s.loadedAt = time.Now()
+			return s.circles, nil
+		}
+		return nil, fmt.Errorf("failed to load circle hotness settings: %w", err)
+	}
+
+	circles := make(map[int64]circleHotness, len(rows))
+	for _, row := range rows {
+		var settings HotnessSettings
+		if err := json.Unmarshal([]byte(row.Settings), &settings); err != nil {
+			s.logger.Warn("Ignoring invalid circle hotness settings", zap.Int64("circle_id", row.CircleID), zap.Error(err))
+			continue
+		}
+		settings = settings.Inherit(s.defaults)
+		strategy, err := NewHotnessStrategy(settings)
+		if err != nil {
+			s.logger.Warn("Ignoring invalid circle hotness settings", zap.Int64("circle_id", row.CircleID), zap.Error(err))
+			continue
+		}
+		circles[row.CircleID] = circleHotness{settings: settings, strategy: strategy}
+	}
+
+	s.circles = circles
+	s.loadedAt = time.Now()
+	return circles, nil
+}
@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
 	"github.com/kobayashirei/airy/internal/mq"
@@ -29,12 +28,7 @@ func NewHotnessWorker(
 
 // HandleVoteCreated handles vote created events and recalculates hotness
 // Implements Requirement 12.2
-func (w *HotnessWorker) HandleVoteCreated(ctx context.Context, message []byte) error {
-	var event mq.VoteCreatedEvent
-	if err := json.Unmarshal(message, &event); err != nil {
-		return fmt.Errorf("failed to unmarshal vote created event: %w", err)
-	}
-
+func (w *HotnessWorker) HandleVoteCreated(ctx context.Context, event *mq.VoteCreatedEvent) error {
 	// Only recalculate for post votes
 	if event.EntityType != "post" {
 		return nil
@@ -45,12 +39,7 @@ func (w *HotnessWorker) HandleVoteCreated(ctx context.Context, message []byte) e
 
 // HandleVoteUpdated handles vote updated events and recalculates hotness
 // Implements Requirement 12.2
-func (w *HotnessWorker) HandleVoteUpdated(ctx context.Context, message []byte) error {
-	var event mq.VoteUpdatedEvent
-	if err := json.Unmarshal(message, &event); err != nil {
-		return fmt.Errorf("failed to unmarshal vote updated event: %w", err)
-	}
-
+func (w *HotnessWorker) HandleVoteUpdated(ctx context.Context, event *mq.VoteUpdatedEvent) error {
 	// Only recalculate for post votes
 	if event.EntityType != "post" {
 		return nil
@@ -61,12 +50,7 @@ func (w *HotnessWorker) HandleVoteUpdated(ctx context.Context, message []byte) e
 
 // HandleVoteDeleted handles vote deleted events and recalculates hotness
 // Implements Requirement 12.2
-func (w *HotnessWorker) HandleVoteDeleted(ctx context.Context, message []byte) error {
-	var event mq.VoteDeletedEvent
-	if err := json.Unmarshal(message, &event); err != nil {
-		return fmt.Errorf("failed to unmarshal vote deleted event: %w", err)
-	}
-
+func (w *HotnessWorker) HandleVoteDeleted(ctx context.Context, event *mq.VoteDeletedEvent) error {
 	// Only recalculate for post votes
 	if event.EntityType != "post" {
 		return nil
@@ -77,23 +61,13 @@ func (w *HotnessWorker) HandleVoteDeleted(ctx context.Context, message []byte) e
 
 // HandleCommentCreated handles comment created events and recalculates hotness
 // Implements Requirement 12.2
-func (w *HotnessWorker) HandleCommentCreated(ctx context.Context, message []byte) error {
-	var event mq.CommentCreatedEvent
-	if err := json.Unmarshal(message, &event); err != nil {
-		return fmt.Errorf("failed to unmarshal comment created event: %w", err)
-	}
-
+func (w *HotnessWorker) HandleCommentCreated(ctx context.Context, event *mq.CommentCreatedEvent) error {
 	return w.recalculateAndSync(ctx, event.PostID)
 }
 
 // HandleCommentDeleted handles comment deleted events and recalculates hotness
 // Implements Requirement 12.2
-func (w *HotnessWorker) HandleCommentDeleted(ctx context.Context, message []byte) error {
-	var event mq.CommentDeletedEvent
-	if err := json.Unmarshal(message, &event); err != nil {
-		return fmt.Errorf("failed to unmarshal comment deleted event: %w", err)
-	}
-
+func (w *HotnessWorker) HandleCommentDeleted(ctx context.Context, event *mq.CommentDeletedEvent) error {
 	return w.recalculateAndSync(ctx, event.PostID)
 }
 
@@ -117,3 +91,31 @@ func (w *HotnessWorker) recalculateAndSync(ctx context.Context, postID int64) er
 
 	return nil
 }
+
+// HotnessConsumerGroup is the consumer group the hotness worker subscribes with
+const HotnessConsumerGroup = "hotness"
+
+// Subscribe subscribes the hotness worker to the vote and comment events that change a post's hotness
+func (w *HotnessWorker) Subscribe(messageQueue mq.MessageQueue) error {
+	if err := mq.SubscribeGroupEvents(messageQueue, HotnessConsumerGroup, mq.TopicVoteCreated, w.HandleVoteCreated); err != nil {
+		return fmt.Errorf("failed to subscribe to vote.created: %w", err)
+	}
+
+	if err := mq.SubscribeGroupEvents(messageQueue, HotnessConsumerGroup, mq.TopicVoteUpdated, w.HandleVoteUpdated); err != nil {
+		return fmt.Errorf("failed to subscribe to vote.updated: %w", err)
+	}
+
+	if err := mq.SubscribeGroupEvents(messageQueue, HotnessConsumerGroup, mq.TopicVoteDeleted, w.HandleVoteDeleted); err != nil {
+		return fmt.Errorf("failed to subscribe to vote.deleted: %w", err)
+	}
+
+	if err := mq.SubscribeGroupEvents(messageQueue, HotnessConsumerGroup, mq.TopicCommentCreated, w.HandleCommentCreated); err != nil {
+		return fmt.Errorf("failed to subscribe to comment.created: %w", err)
+	}
+
+	if err := mq.SubscribeGroupEvents(messageQueue, HotnessConsumerGroup, mq.TopicCommentDeleted, w.HandleCommentDeleted); err != nil {
+		return fmt.Errorf("failed to subscribe to comment.deleted: %w", err)
+	}
+
+	return nil
+}
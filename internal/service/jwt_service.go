@@ -8,9 +8,10 @@ import (
 
 // JWTService defines the interface for JWT operations in the service layer
 type JWTService interface {
-	GenerateToken(userID int64, roles []string) (string, error)
-	GenerateRefreshToken(userID int64, roles []string) (string, error)
+	GenerateToken(userID int64, roles []string, circleRoles map[int64][]string) (string, error)
+	GenerateRefreshToken(userID int64, roles []string, circleRoles map[int64][]string) (string, error)
 	ParseToken(tokenString string) (*auth.Claims, error)
+	ParseRefreshToken(tokenString string) (*auth.Claims, error)
 	RefreshToken(tokenString string) (string, error)
 }
 
@@ -29,16 +30,16 @@ func NewJWTService(authJWTService auth.JWTService, refreshTokenDuration time.Dur
 }
 
 // GenerateToken generates a new JWT token
-func (s *jwtServiceWrapper) GenerateToken(userID int64, roles []string) (string, error) {
-	return s.authJWTService.GenerateToken(userID, roles)
+func (s *jwtServiceWrapper) GenerateToken(userID int64, roles []string, circleRoles map[int64][]string) (string, error) {
+	return s.authJWTService.GenerateTokenWithCircleRoles(userID, roles, circleRoles)
 }
 
 // GenerateRefreshToken generates a refresh token with longer expiration
-func (s *jwtServiceWrapper) GenerateRefreshToken(userID int64, roles []string) (string, error) {
+func (s *jwtServiceWrapper) GenerateRefreshToken(userID int64, roles []string, circleRoles map[int64][]string) (string, error) {
 	// For refresh tokens, we can use the same generation method
 	// In a more sophisticated implementation, you might want to store refresh tokens
 	// in Redis with a longer TTL and track them separately
-	return s.authJWTService.GenerateToken(userID, roles)
+	return s.authJWTService.GenerateTokenWithCircleRoles(userID, roles, circleRoles)
 }
 
 // ParseToken parses and validates a JWT token
@@ -46,6 +47,11 @@ func (s *jwtServiceWrapper) ParseToken(tokenString string) (*auth.Claims, error)
 	return s.authJWTService.ParseToken(tokenString)
 }
 
+// ParseRefreshToken parses a token for refresh, ignoring its expiration
+func (s *jwtServiceWrapper) ParseRefreshToken(tokenString string) (*auth.Claims, error) {
+	return s.authJWTService.ParseRefreshToken(tokenString)
+}
+
 // RefreshToken refreshes an existing token
 func (s *jwtServiceWrapper) RefreshToken(tokenString string) (string, error) {
 	return s.authJWTService.RefreshToken(tokenString)
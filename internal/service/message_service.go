@@ -29,10 +29,10 @@ type MessageService interface {
 	GetOrCreateConversation(ctx context.Context, user1ID, user2ID int64) (*models.Conversation, error)
 	// SendMessage sends a message in a conversation
 	SendMessage(ctx context.Context, req SendMessageRequest) (*models.Message, error)
-	// GetConversations retrieves all conversations for a user, sorted by last message time
-	GetConversations(ctx context.Context, userID int64, page, pageSize int) (*ConversationListResponse, error)
-	// GetMessages retrieves messages in a conversation with pagination
-	GetMessages(ctx context.Context, userID, conversationID int64, page, pageSize int) (*MessageListResponse, error)
+	// GetConversations retrieves a page of a user's conversations, sorted by last message time; cursor is empty for the first page
+	GetConversations(ctx context.Context, userID int64, cursor string, pageSize int) (*ConversationListResponse, error)
+	// GetMessages retrieves a page of messages in a conversation, newest first; cursor is empty for the first page
+	GetMessages(ctx context.Context, userID, conversationID int64, cursor string, pageSize int) (*MessageListResponse, error)
 	// MarkConversationAsRead marks all messages in a conversation as read for the user
 	MarkConversationAsRead(ctx context.Context, userID, conversationID int64) error
 }
@@ -46,12 +46,11 @@ type SendMessageRequest struct {
 	Content        string `json:"content"`
 }
 
-// ConversationListResponse represents a paginated list of conversations
+// ConversationListResponse represents a cursor-paginated list of conversations
 type ConversationListResponse struct {
 	Conversations []*ConversationWithDetails `json:"conversations"`
-	Total         int64                      `json:"total"`
-	Page          int                        `json:"page"`
 	PageSize      int                        `json:"page_size"`
+	NextCursor    string                     `json:"next_cursor,omitempty"`
 }
 
 // ConversationWithDetails includes conversation and additional details
@@ -63,14 +62,19 @@ type ConversationWithDetails struct {
 	UnreadCount      int64          `json:"unread_count"`
 }
 
-// MessageListResponse represents a paginated list of messages
+// MessageListResponse represents a cursor-paginated list of messages
 type MessageListResponse struct {
-	Messages []*models.Message `json:"messages"`
-	Total    int64             `json:"total"`
-	Page     int               `json:"page"`
-	PageSize int               `json:"page_size"`
+	Messages   []*models.Message `json:"messages"`
+	PageSize   int               `json:"page_size"`
+	NextCursor string            `json:"next_cursor,omitempty"`
 }
 
+// Orderings of the conversation and message lists in pagination cursors
+const (
+	conversationCursorSort = "conversations"
+	messageCursorSort      = "messages"
+)
+
 // messageService implements MessageService interface
 type messageService struct {
 	conversationRepo repository.ConversationRepository
@@ -205,23 +209,35 @@ func (s *messageService) SendMessage(ctx context.Context, req SendMessageRequest
 }
 
 // GetConversations retrieves all conversations for a user, sorted by last message time
-func (s *messageService) GetConversations(ctx context.Context, userID int64, page, pageSize int) (*ConversationListResponse, error) {
+func (s *messageService) GetConversations(ctx context.Context, userID int64, cursor string, pageSize int) (*ConversationListResponse, error) {
 	// Validate pagination parameters
-	if page < 1 {
-		page = 1
-	}
 	if pageSize < 1 || pageSize > 100 {
 		pageSize = 20
 	}
 
-	offset := (page - 1) * pageSize
+	var lastMessageAt time.Time
+	after, err := decodeKeyset(cursor, conversationCursorSort, &lastMessageAt)
+	if err != nil {
+		return nil, err
+	}
 
-	// Get conversations (repository already orders by last_message_at DESC)
-	conversations, err := s.conversationRepo.FindByUserID(ctx, userID, pageSize, offset)
+	// Get conversations (repository already orders by last_message_at DESC),
+	// fetching one extra row to know whether another page exists
+	conversations, err := s.conversationRepo.FindByUserID(ctx, userID, pageSize+1, after)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve conversations: %w", err)
 	}
 
+	var nextCursor string
+	if len(conversations) > pageSize {
+		conversations = conversations[:pageSize]
+		last := conversations[pageSize-1]
+		nextCursor, err = encodeKeyset(conversationCursorSort, last.ID, last.LastMessageAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode cursor: %w", err)
+		}
+	}
+
 	// Enrich conversations with additional details
 	conversationsWithDetails := make([]*ConversationWithDetails, 0, len(conversations))
 	for _, conv := range conversations {
@@ -239,7 +255,7 @@ func (s *messageService) GetConversations(ctx context.Context, userID int64, pag
 		}
 
 		// Get last message
-		messages, err := s.messageRepo.FindByConversationID(ctx, conv.ID, 1, 0)
+		messages, err := s.messageRepo.FindByConversationID(ctx, conv.ID, 1, nil)
 		var lastMessage *models.Message
 		if err == nil && len(messages) > 0 {
 			lastMessage = messages[0]
@@ -261,22 +277,21 @@ func (s *messageService) GetConversations(ctx context.Context, userID int64, pag
 		})
 	}
 
-	// For total count, we would need another query or cache this value
-	total := int64(len(conversations))
-	if len(conversations) == pageSize {
-		total = int64(offset + pageSize + 1)
-	}
-
 	return &ConversationListResponse{
 		Conversations: conversationsWithDetails,
-		Total:         total,
-		Page:          page,
 		PageSize:      pageSize,
+		NextCursor:    nextCursor,
 	}, nil
 }
 
 // GetMessages retrieves messages in a conversation with pagination
-func (s *messageService) GetMessages(ctx context.Context, userID, conversationID int64, page, pageSize int) (*MessageListResponse, error) {
+func (s *messageService) GetMessages(ctx context.Context, userID, conversationID int64, cursor string, pageSize int) (*MessageListResponse, error) {
+	var createdAt time.Time
+	after, err := decodeKeyset(cursor, messageCursorSort, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+
 	// Verify conversation exists and user is part of it
 	conversation, err := s.conversationRepo.FindByID(ctx, conversationID)
 	if err != nil {
@@ -292,32 +307,31 @@ func (s *messageService) GetMessages(ctx context.Context, userID, conversationID
 	}
 
 	// Validate pagination parameters
-	if page < 1 {
-		page = 1
-	}
 	if pageSize < 1 || pageSize > 100 {
 		pageSize = 50
 	}
 
-	offset := (page - 1) * pageSize
-
-	// Get messages (repository orders by created_at DESC)
-	messages, err := s.messageRepo.FindByConversationID(ctx, conversationID, pageSize, offset)
+	// Get messages (repository orders by created_at DESC),
+	// fetching one extra row to know whether another page exists
+	messages, err := s.messageRepo.FindByConversationID(ctx, conversationID, pageSize+1, after)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve messages: %w", err)
 	}
 
-	// For total count
-	total := int64(len(messages))
-	if len(messages) == pageSize {
-		total = int64(offset + pageSize + 1)
+	var nextCursor string
+	if len(messages) > pageSize {
+		messages = messages[:pageSize]
+		last := messages[pageSize-1]
+		nextCursor, err = encodeKeyset(messageCursorSort, last.ID, last.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode cursor: %w", err)
+		}
 	}
 
 	return &MessageListResponse{
-		Messages: messages,
-		Total:    total,
-		Page:     page,
-		PageSize: pageSize,
+		Messages:   messages,
+		PageSize:   pageSize,
+		NextCursor: nextCursor,
 	}, nil
 }
 
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/kobayashirei/airy/internal/models"
 	"github.com/kobayashirei/airy/internal/mq"
@@ -49,6 +50,11 @@ func (m *MockCommentRepository) FindRootComments(ctx context.Context, postID int
 	return args.Get(0).([]*models.Comment), args.Error(1)
 }
 
+func (m *MockCommentRepository) List(ctx context.Context, opts repository.CommentListOptions) ([]*models.Comment, error) {
+	args := m.Called(ctx, opts)
+	return args.Get(0).([]*models.Comment), args.Error(1)
+}
+
 func (m *MockCommentRepository) Update(ctx context.Context, comment *models.Comment) error {
 	args := m.Called(ctx, comment)
 	return args.Error(0)
@@ -64,6 +70,21 @@ func (m *MockCommentRepository) UpdateStatus(ctx context.Context, id int64, stat
 	return args.Error(0)
 }
 
+func (m *MockCommentRepository) ListReplies(ctx context.Context, parentIDs []int64, opts repository.ReplyListOptions) ([]*models.Comment, error) {
+	args := m.Called(ctx, parentIDs, opts)
+	return args.Get(0).([]*models.Comment), args.Error(1)
+}
+
+func (m *MockCommentRepository) FindRepliedIDs(ctx context.Context, ids []int64) ([]int64, error) {
+	args := m.Called(ctx, ids)
+	return args.Get(0).([]int64), args.Error(1)
+}
+
+func (m *MockCommentRepository) UpdateScores(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
 func (m *MockCommentRepository) CountByPostID(ctx context.Context, postID int64) (int64, error) {
 	args := m.Called(ctx, postID)
 	return args.Get(0).(int64), args.Error(1)
@@ -125,6 +146,19 @@ func (m *MockPostRepository) UpdateHotnessScore(ctx context.Context, id int64, s
 	return args.Error(0)
 }
 
+func (m *MockPostRepository) UpdateHotnessScores(ctx context.Context, scores map[int64]float64) error {
+	args := m.Called(ctx, scores)
+	return args.Error(0)
+}
+
+func (m *MockPostRepository) ListPublishedSince(ctx context.Context, since time.Time, shard, shards int, afterID int64, limit int) ([]*models.Post, error) {
+	args := m.Called(ctx, since, shard, shards, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Post), args.Error(1)
+}
+
 func (m *MockPostRepository) UpdateStatus(ctx context.Context, id int64, status string) error {
 	args := m.Called(ctx, id, status)
 	return args.Error(0)
@@ -135,6 +169,42 @@ func (m *MockPostRepository) CountByDate(ctx context.Context, date string) (int6
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockPostRepository) PublishScheduled(ctx context.Context, id int64, publishedAt time.Time) (bool, error) {
+	args := m.Called(ctx, id, publishedAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPostRepository) ListByID(ctx context.Context, status string, afterID int64, limit int) ([]*models.Post, error) {
+	args := m.Called(ctx, status, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Post), args.Error(1)
+}
+
+func (m *MockPostRepository) ListUpdatedSince(ctx context.Context, since time.Time, afterID int64, limit int) ([]*models.Post, error) {
+	args := m.Called(ctx, since, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Post), args.Error(1)
+}
+
+func (m *MockPostRepository) ListScheduled(ctx context.Context, afterID int64, limit int) ([]*models.Post, error) {
+	args := m.Called(ctx, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Post), args.Error(1)
+}
+
+// passthroughTransactor is a Transactor that runs the unit of work without a database
+type passthroughTransactor struct{}
+
+func (passthroughTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
 // MockMessageQueue is a mock implementation of MessageQueue
 type MockMessageQueue struct {
 	mock.Mock
@@ -150,6 +220,11 @@ func (m *MockMessageQueue) Subscribe(topic string, handler mq.MessageHandler) er
 	return args.Error(0)
 }
 
+func (m *MockMessageQueue) SubscribeGroup(group, topic string, handler mq.MessageHandler) error {
+	args := m.Called(group, topic, handler)
+	return args.Error(0)
+}
+
 func (m *MockMessageQueue) Close() error {
 	args := m.Called()
 	return args.Error(0)
@@ -303,6 +378,22 @@ func (m *MockUserRepository) Count(ctx context.Context, opts repository.UserList
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockUserRepository) ListByID(ctx context.Context, status string, afterID int64, limit int) ([]*models.User, error) {
+	args := m.Called(ctx, status, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) ListUpdatedSince(ctx context.Context, since time.Time, afterID int64, limit int) ([]*models.User, error) {
+	args := m.Called(ctx, since, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.User), args.Error(1)
+}
+
 func (m *MockUserRepository) CountByLastLogin(ctx context.Context, date string) (int64, error) {
 	args := m.Called(ctx, date)
 	return args.Get(0).(int64), args.Error(1)
@@ -416,8 +507,8 @@ func (m *MockNotificationRepository) FindByID(ctx context.Context, id int64) (*m
 	return args.Get(0).(*models.Notification), args.Error(1)
 }
 
-func (m *MockNotificationRepository) FindByReceiverID(ctx context.Context, receiverID int64, limit, offset int) ([]*models.Notification, error) {
-	args := m.Called(ctx, receiverID, limit, offset)
+func (m *MockNotificationRepository) FindByReceiverID(ctx context.Context, receiverID int64, limit int, after *repository.Keyset) ([]*models.Notification, error) {
+	args := m.Called(ctx, receiverID, limit, after)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -486,8 +577,8 @@ func (m *MockConversationRepository) FindByUsers(ctx context.Context, user1ID, u
 	return args.Get(0).(*models.Conversation), args.Error(1)
 }
 
-func (m *MockConversationRepository) FindByUserID(ctx context.Context, userID int64, limit, offset int) ([]*models.Conversation, error) {
-	args := m.Called(ctx, userID, limit, offset)
+func (m *MockConversationRepository) FindByUserID(ctx context.Context, userID int64, limit int, after *repository.Keyset) ([]*models.Conversation, error) {
+	args := m.Called(ctx, userID, limit, after)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -530,8 +621,8 @@ func (m *MockMessageRepository) FindByID(ctx context.Context, id int64) (*models
 	return args.Get(0).(*models.Message), args.Error(1)
 }
 
-func (m *MockMessageRepository) FindByConversationID(ctx context.Context, conversationID int64, limit, offset int) ([]*models.Message, error) {
-	args := m.Called(ctx, conversationID, limit, offset)
+func (m *MockMessageRepository) FindByConversationID(ctx context.Context, conversationID int64, limit int, after *repository.Keyset) ([]*models.Message, error) {
+	args := m.Called(ctx, conversationID, limit, after)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -570,3 +661,374 @@ func (m *MockMessageRepository) CountUnreadByReceiver(ctx context.Context, recei
 	args := m.Called(ctx, receiverID)
 	return args.Get(0).(int64), args.Error(1)
 }
+
+// MockFollowRepository is a mock implementation of FollowRepository
+type MockFollowRepository struct {
+	mock.Mock
+}
+
+func (m *MockFollowRepository) Create(ctx context.Context, follow *models.Follow) (bool, error) {
+	args := m.Called(ctx, follow)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockFollowRepository) Delete(ctx context.Context, followerID, followingID int64) (bool, error) {
+	args := m.Called(ctx, followerID, followingID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockFollowRepository) Exists(ctx context.Context, followerID, followingID int64) (bool, error) {
+	args := m.Called(ctx, followerID, followingID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockFollowRepository) FindFollowers(ctx context.Context, userID int64, cursor int64, limit int) ([]*models.Follow, error) {
+	args := m.Called(ctx, userID, cursor, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Follow), args.Error(1)
+}
+
+func (m *MockFollowRepository) FindFollowing(ctx context.Context, userID int64, cursor int64, limit int) ([]*models.Follow, error) {
+	args := m.Called(ctx, userID, cursor, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Follow), args.Error(1)
+}
+
+func (m *MockFollowRepository) FindFollowerIDs(ctx context.Context, userID int64) ([]int64, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int64), args.Error(1)
+}
+
+func (m *MockFollowRepository) FindFollowingIDs(ctx context.Context, userID int64) ([]int64, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int64), args.Error(1)
+}
+
+func (m *MockFollowRepository) FindPopularFollowingIDs(ctx context.Context, userID int64, minFollowers int) ([]int64, error) {
+	args := m.Called(ctx, userID, minFollowers)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int64), args.Error(1)
+}
+
+// MockFavoriteRepository is a mock implementation of FavoriteRepository
+type MockFavoriteRepository struct {
+	mock.Mock
+}
+
+func (m *MockFavoriteRepository) Create(ctx context.Context, favorite *models.Favorite) (bool, error) {
+	args := m.Called(ctx, favorite)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockFavoriteRepository) Delete(ctx context.Context, userID, postID int64) (bool, error) {
+	args := m.Called(ctx, userID, postID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockFavoriteRepository) FindByUserAndPost(ctx context.Context, userID, postID int64) (*models.Favorite, error) {
+	args := m.Called(ctx, userID, postID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Favorite), args.Error(1)
+}
+
+func (m *MockFavoriteRepository) FindFavoritedPostIDs(ctx context.Context, userID int64, postIDs []int64) (map[int64]bool, error) {
+	args := m.Called(ctx, userID, postIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int64]bool), args.Error(1)
+}
+
+func (m *MockFavoriteRepository) List(ctx context.Context, opts repository.FavoriteListOptions) ([]*models.Favorite, error) {
+	args := m.Called(ctx, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Favorite), args.Error(1)
+}
+
+func (m *MockFavoriteRepository) UpdateCollection(ctx context.Context, userID, postID int64, collectionID *int64) error {
+	args := m.Called(ctx, userID, postID, collectionID)
+	return args.Error(0)
+}
+
+// MockFavoriteCollectionRepository is a mock implementation of FavoriteCollectionRepository
+type MockFavoriteCollectionRepository struct {
+	mock.Mock
+}
+
+func (m *MockFavoriteCollectionRepository) Create(ctx context.Context, collection *models.FavoriteCollection) error {
+	args := m.Called(ctx, collection)
+	return args.Error(0)
+}
+
+func (m *MockFavoriteCollectionRepository) FindByID(ctx context.Context, id int64) (*models.FavoriteCollection, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.FavoriteCollection), args.Error(1)
+}
+
+func (m *MockFavoriteCollectionRepository) FindByUserAndName(ctx context.Context, userID int64, name string) (*models.FavoriteCollection, error) {
+	args := m.Called(ctx, userID, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.FavoriteCollection), args.Error(1)
+}
+
+func (m *MockFavoriteCollectionRepository) FindByUserID(ctx context.Context, userID int64) ([]*models.FavoriteCollection, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.FavoriteCollection), args.Error(1)
+}
+
+func (m *MockFavoriteCollectionRepository) Update(ctx context.Context, collection *models.FavoriteCollection) error {
+	args := m.Called(ctx, collection)
+	return args.Error(0)
+}
+
+func (m *MockFavoriteCollectionRepository) Delete(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// MockOutboxRepository is a mock implementation of OutboxRepository
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockOutboxRepository) Create(ctx context.Context, event *models.OutboxEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) FindDue(ctx context.Context, now time.Time, limit int) ([]*models.OutboxEvent, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.OutboxEvent), args.Error(1)
+}
+
+func (m *MockOutboxRepository) MarkPublished(ctx context.Context, id int64, publishedAt time.Time) error {
+	args := m.Called(ctx, id, publishedAt)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) MarkAttemptFailed(ctx context.Context, id int64, status string, nextAttemptAt time.Time, lastError string) error {
+	args := m.Called(ctx, id, status, nextAttemptAt, lastError)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) PendingStats(ctx context.Context) (int64, *time.Time, error) {
+	args := m.Called(ctx)
+	if args.Get(1) == nil {
+		return args.Get(0).(int64), nil, args.Error(2)
+	}
+	return args.Get(0).(int64), args.Get(1).(*time.Time), args.Error(2)
+}
+
+func (m *MockOutboxRepository) DeletePublishedBefore(ctx context.Context, before time.Time, limit int) (int64, error) {
+	args := m.Called(ctx, before, limit)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockOutboxRepository) WithRelayLock(ctx context.Context, fn func(ctx context.Context) error) (bool, error) {
+	args := m.Called(ctx, fn)
+	if !args.Bool(0) {
+		return false, args.Error(1)
+	}
+	return true, fn(ctx)
+}
+
+// MockDeadLetterRepository is a mock implementation of DeadLetterRepository
+type MockDeadLetterRepository struct {
+	mock.Mock
+}
+
+func (m *MockDeadLetterRepository) Create(ctx context.Context, letter *models.DeadLetter) error {
+	args := m.Called(ctx, letter)
+	return args.Error(0)
+}
+
+func (m *MockDeadLetterRepository) FindByID(ctx context.Context, id int64) (*models.DeadLetter, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DeadLetter), args.Error(1)
+}
+
+func (m *MockDeadLetterRepository) List(ctx context.Context, opts repository.DeadLetterListOptions) ([]*models.DeadLetter, error) {
+	args := m.Called(ctx, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.DeadLetter), args.Error(1)
+}
+
+func (m *MockDeadLetterRepository) Count(ctx context.Context, opts repository.DeadLetterListOptions) (int64, error) {
+	args := m.Called(ctx, opts)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockDeadLetterRepository) MarkReplayed(ctx context.Context, id int64, replayedAt time.Time) error {
+	args := m.Called(ctx, id, replayedAt)
+	return args.Error(0)
+}
+
+func (m *MockDeadLetterRepository) Delete(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockDeadLetterRepository) DeleteMatching(ctx context.Context, opts repository.DeadLetterListOptions) (int64, error) {
+	args := m.Called(ctx, opts)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockAdminLogRepository is a mock implementation of AdminLogRepository
+type MockAdminLogRepository struct {
+	mock.Mock
+}
+
+func (m *MockAdminLogRepository) Create(ctx context.Context, log *models.AdminLog) error {
+	args := m.Called(ctx, log)
+	return args.Error(0)
+}
+
+func (m *MockAdminLogRepository) List(ctx context.Context, opts repository.AdminLogListOptions) ([]*models.AdminLog, error) {
+	args := m.Called(ctx, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.AdminLog), args.Error(1)
+}
+
+func (m *MockAdminLogRepository) Count(ctx context.Context, opts repository.AdminLogListOptions) (int64, error) {
+	args := m.Called(ctx, opts)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockDeadLetterQueue is a mock implementation of mq.DeadLetterQueue
+type MockDeadLetterQueue struct {
+	mock.Mock
+}
+
+func (m *MockDeadLetterQueue) SubscribeDeadLetters(handler mq.DeadLetterHandler) error {
+	args := m.Called(handler)
+	return args.Error(0)
+}
+
+func (m *MockDeadLetterQueue) Replay(ctx context.Context, letter *mq.DeadLetter) error {
+	args := m.Called(ctx, letter)
+	return args.Error(0)
+}
+
+// MockBatchRepository is a mock implementation of BatchRepository
+type MockBatchRepository struct {
+	mock.Mock
+}
+
+func (m *MockBatchRepository) FindUsersByIDs(ctx context.Context, ids []int64) (map[int64]*models.User, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int64]*models.User), args.Error(1)
+}
+
+func (m *MockBatchRepository) FindPostsByIDs(ctx context.Context, ids []int64) (map[int64]*models.Post, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int64]*models.Post), args.Error(1)
+}
+
+func (m *MockBatchRepository) FindCirclesByIDs(ctx context.Context, ids []int64) (map[int64]*models.Circle, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int64]*models.Circle), args.Error(1)
+}
+
+func (m *MockBatchRepository) FindCommentsByIDs(ctx context.Context, ids []int64) (map[int64]*models.Comment, error) {
+	args := m.Called(ctx, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int64]*models.Comment), args.Error(1)
+}
+
+func (m *MockBatchRepository) FindEntityCountsByIDs(ctx context.Context, entityType string, ids []int64) (map[int64]*models.EntityCount, error) {
+	args := m.Called(ctx, entityType, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int64]*models.EntityCount), args.Error(1)
+}
+
+func (m *MockBatchRepository) FindUserVotesByEntityIDs(ctx context.Context, userID int64, entityType string, ids []int64) (map[int64]*models.Vote, error) {
+	args := m.Called(ctx, userID, entityType, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int64]*models.Vote), args.Error(1)
+}
+
+func (m *MockBatchRepository) FindUserProfilesByIDs(ctx context.Context, userIDs []int64) (map[int64]*models.UserProfile, error) {
+	args := m.Called(ctx, userIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int64]*models.UserProfile), args.Error(1)
+}
+
+func (m *MockBatchRepository) FindUserStatsByIDs(ctx context.Context, userIDs []int64) (map[int64]*models.UserStats, error) {
+	args := m.Called(ctx, userIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int64]*models.UserStats), args.Error(1)
+}
+
+// MockSearchRepository is a mock implementation of SearchRepository
+type MockSearchRepository struct {
+	mock.Mock
+}
+
+func (m *MockSearchRepository) SearchPosts(ctx context.Context, opts repository.SearchOptions) ([]*repository.PostSearchHit, int64, error) {
+	args := m.Called(ctx, opts)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*repository.PostSearchHit), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockSearchRepository) SearchUsers(ctx context.Context, opts repository.SearchOptions) ([]*repository.UserSearchHit, int64, error) {
+	args := m.Called(ctx, opts)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*repository.UserSearchHit), args.Get(1).(int64), args.Error(2)
+}
@@ -20,7 +20,8 @@ var (
 // NotificationService defines the interface for notification business logic
 type NotificationService interface {
 	CreateNotification(ctx context.Context, req CreateNotificationRequest) (*models.Notification, error)
-	GetNotifications(ctx context.Context, userID int64, page, pageSize int) (*NotificationListResponse, error)
+	// GetNotifications lists a page of notifications, unread first; cursor is empty for the first page
+	GetNotifications(ctx context.Context, userID int64, cursor string, pageSize int) (*NotificationListResponse, error)
 	MarkAsRead(ctx context.Context, userID, notificationID int64) error
 	MarkAllAsRead(ctx context.Context, userID int64) error
 	GetUnreadCount(ctx context.Context, userID int64) (int64, error)
@@ -36,15 +37,17 @@ type CreateNotificationRequest struct {
 	Content       string `json:"content"`
 }
 
-// NotificationListResponse represents a paginated list of notifications
+// NotificationListResponse represents a cursor-paginated list of notifications
 type NotificationListResponse struct {
 	Notifications []*models.Notification `json:"notifications"`
-	Total         int64                  `json:"total"`
-	Page          int                    `json:"page"`
 	PageSize      int                    `json:"page_size"`
 	UnreadCount   int64                  `json:"unread_count"`
+	NextCursor    string                 `json:"next_cursor,omitempty"`
 }
 
+// notificationCursorSort names the notification list ordering in pagination cursors
+const notificationCursorSort = "notifications"
+
 // notificationService implements NotificationService interface
 type notificationService struct {
 	notificationRepo repository.NotificationRepository
@@ -122,44 +125,47 @@ func (s *notificationService) CreateNotification(ctx context.Context, req Create
 }
 
 // GetNotifications retrieves notifications for a user with unread notifications first
-func (s *notificationService) GetNotifications(ctx context.Context, userID int64, page, pageSize int) (*NotificationListResponse, error) {
+func (s *notificationService) GetNotifications(ctx context.Context, userID int64, cursor string, pageSize int) (*NotificationListResponse, error) {
 	// Validate pagination parameters
-	if page < 1 {
-		page = 1
-	}
 	if pageSize < 1 || pageSize > 100 {
 		pageSize = 20
 	}
 
-	offset := (page - 1) * pageSize
+	var isRead bool
+	var createdAt time.Time
+	after, err := decodeKeyset(cursor, notificationCursorSort, &isRead, &createdAt)
+	if err != nil {
+		return nil, err
+	}
 
-	// Get notifications (repository already orders by is_read ASC, created_at DESC)
-	notifications, err := s.notificationRepo.FindByReceiverID(ctx, userID, pageSize, offset)
+	// Get notifications (repository already orders by is_read ASC, created_at DESC),
+	// fetching one extra row to know whether another page exists
+	notifications, err := s.notificationRepo.FindByReceiverID(ctx, userID, pageSize+1, after)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve notifications: %w", err)
 	}
 
+	var nextCursor string
+	if len(notifications) > pageSize {
+		notifications = notifications[:pageSize]
+		last := notifications[pageSize-1]
+		nextCursor, err = encodeKeyset(notificationCursorSort, last.ID, last.IsRead, last.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode cursor: %w", err)
+		}
+	}
+
 	// Get unread count
 	unreadCount, err := s.notificationRepo.CountUnreadByReceiverID(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count unread notifications: %w", err)
 	}
 
-	// For total count, we would need another query or cache this value
-	// For now, we'll use a simple approach
-	total := int64(len(notifications))
-	if len(notifications) == pageSize {
-		// There might be more, but we don't know exactly how many
-		// In production, you'd want to do a separate count query
-		total = int64(offset + pageSize + 1)
-	}
-
 	return &NotificationListResponse{
 		Notifications: notifications,
-		Total:         total,
-		Page:          page,
 		PageSize:      pageSize,
 		UnreadCount:   unreadCount,
+		NextCursor:    nextCursor,
 	}, nil
 }
 
@@ -3,11 +3,13 @@ package service
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
 	"github.com/kobayashirei/airy/internal/models"
+	"github.com/kobayashirei/airy/internal/repository"
 )
 
 func TestCreateNotification_Success(t *testing.T) {
@@ -102,19 +104,55 @@ func TestGetNotifications_Success(t *testing.T) {
 	}
 
 	// Setup expectations
-	mockNotificationRepo.On("FindByReceiverID", mock.Anything, int64(1), 20, 0).Return(notifications, nil)
+	mockNotificationRepo.On("FindByReceiverID", mock.Anything, int64(1), 21, (*repository.Keyset)(nil)).Return(notifications, nil)
 	mockNotificationRepo.On("CountUnreadByReceiverID", mock.Anything, int64(1)).Return(int64(1), nil)
 
 	// Test
-	result, err := service.GetNotifications(context.Background(), 1, 1, 20)
+	result, err := service.GetNotifications(context.Background(), 1, "", 20)
 
 	// Assertions
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
 	assert.Equal(t, 2, len(result.Notifications))
 	assert.Equal(t, int64(1), result.UnreadCount)
-	assert.Equal(t, 1, result.Page)
 	assert.Equal(t, 20, result.PageSize)
+	assert.Empty(t, result.NextCursor)
+
+	mockNotificationRepo.AssertExpectations(t)
+}
+
+func TestGetNotifications_Cursor(t *testing.T) {
+	mockNotificationRepo := new(MockNotificationRepository)
+	service := NewNotificationService(mockNotificationRepo, new(MockUserRepository), new(MockPostRepository), new(MockCommentRepository))
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	notifications := []*models.Notification{
+		{ID: 3, ReceiverID: 1, IsRead: false, CreatedAt: createdAt.Add(time.Minute)},
+		{ID: 2, ReceiverID: 1, IsRead: false, CreatedAt: createdAt},
+		{ID: 1, ReceiverID: 1, IsRead: true, CreatedAt: createdAt},
+	}
+
+	mockNotificationRepo.On("FindByReceiverID", mock.Anything, int64(1), 3, (*repository.Keyset)(nil)).Return(notifications, nil).Once()
+	mockNotificationRepo.On("CountUnreadByReceiverID", mock.Anything, int64(1)).Return(int64(2), nil)
+
+	first, err := service.GetNotifications(context.Background(), 1, "", 2)
+	assert.NoError(t, err)
+	assert.Len(t, first.Notifications, 2)
+	assert.NotEmpty(t, first.NextCursor)
+
+	// The next page continues after the last notification of the first page
+	mockNotificationRepo.On("FindByReceiverID", mock.Anything, int64(1), 3, mock.MatchedBy(func(k *repository.Keyset) bool {
+		return k != nil && k.ID == 2 && k.Values[0] == false && k.Values[1].(time.Time).Equal(createdAt)
+	})).Return(notifications[2:], nil).Once()
+
+	second, err := service.GetNotifications(context.Background(), 1, first.NextCursor, 2)
+	assert.NoError(t, err)
+	assert.Len(t, second.Notifications, 1)
+	assert.Empty(t, second.NextCursor)
+
+	// Cursors from another list are rejected
+	_, err = service.GetNotifications(context.Background(), 1, "bogus", 2)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
 
 	mockNotificationRepo.AssertExpectations(t)
 }
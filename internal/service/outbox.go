@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/kobayashirei/airy/internal/models"
+	"github.com/kobayashirei/airy/internal/mq"
+	"github.com/kobayashirei/airy/internal/repository"
+)
+
+// ErrOutboxSubscribe is returned when subscribing through the outbox, which only records events
+var ErrOutboxSubscribe = errors.New("the outbox cannot be subscribed to; subscribe to the message queue instead")
+
+// outboxQueue implements mq.MessageQueue by recording events in the outbox table.
+// Publishing inside repository.Transactor.WithinTransaction stores the event in the
+// same transaction as the entity change; OutboxRelay later publishes it for real.
+type outboxQueue struct {
+	outboxRepo repository.OutboxRepository
+}
+
+// NewOutboxQueue creates a message queue that writes events to the transactional outbox
+func NewOutboxQueue(outboxRepo repository.OutboxRepository) mq.MessageQueue {
+	return &outboxQueue{outboxRepo: outboxRepo}
+}
+
+// Publish records the event in the outbox
+func (q *outboxQueue) Publish(ctx context.Context, topic string, message interface{}) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	// Keep the event's own ID so consumers can deduplicate redeliveries
+	eventID := ""
+	if envelope, err := mq.DecodeEnvelope(payload); err == nil {
+		eventID = envelope.ID
+	}
+	if eventID == "" {
+		eventID = uuid.New().String()
+	}
+
+	// Events without an aggregate are ordered per topic
+	event := message
+	if envelope, ok := message.(*mq.Envelope); ok && envelope.Event() != nil {
+		event = envelope.Event()
+	}
+	aggregateType, aggregateID := topic, int64(0)
+	if aggregate, ok := event.(mq.AggregateEvent); ok {
+		aggregateType, aggregateID = aggregate.AggregateKey()
+	}
+
+	now := time.Now()
+	outboxEvent := &models.OutboxEvent{
+		EventID:       eventID,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Topic:         topic,
+		Payload:       string(payload),
+		Status:        models.OutboxStatusPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+	if err := q.outboxRepo.Create(ctx, outboxEvent); err != nil {
+		return fmt.Errorf("failed to write outbox event: %w", err)
+	}
+	return nil
+}
+
+// Subscribe is not supported by the outbox
+func (q *outboxQueue) Subscribe(topic string, handler mq.MessageHandler) error {
+	return ErrOutboxSubscribe
+}
+
+// SubscribeGroup is not supported by the outbox
+func (q *outboxQueue) SubscribeGroup(group, topic string, handler mq.MessageHandler) error {
+	return ErrOutboxSubscribe
+}
+
+// Close is a no-op; the outbox shares the database connection
+func (q *outboxQueue) Close() error {
+	return nil
+}
@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+
+	"github.com/kobayashirei/airy/internal/models"
+	"github.com/kobayashirei/airy/internal/mq"
+	"github.com/kobayashirei/airy/internal/repository"
+)
+
+var (
+	// outboxPublishedTotal counts outbox events published to the message queue
+	outboxPublishedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_events_published_total",
+			Help: "Total number of outbox events published to the message queue",
+		},
+		[]string{"topic"},
+	)
+
+	// outboxPublishFailuresTotal counts failed attempts to publish an outbox event
+	outboxPublishFailuresTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_publish_failures_total",
+			Help: "Total number of failed attempts to publish an outbox event",
+		},
+		[]string{"topic"},
+	)
+
+	// outboxGivenUpTotal counts outbox events the relay stopped retrying
+	outboxGivenUpTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_events_failed_total",
+			Help: "Total number of outbox events marked failed after exhausting their attempts",
+		},
+		[]string{"topic"},
+	)
+
+	// outboxPendingEvents tracks the number of events waiting in the outbox
+	outboxPendingEvents = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "outbox_pending_events",
+			Help: "Number of outbox events waiting to be published",
+		},
+	)
+
+	// outboxOldestPendingAge tracks how long the oldest pending event has been waiting
+	outboxOldestPendingAge = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "outbox_oldest_pending_age_seconds",
+			Help: "Age in seconds of the oldest outbox event waiting to be published",
+		},
+	)
+)
+
+// OutboxRelayConfig holds outbox relay configuration
+type OutboxRelayConfig struct {
+	PollInterval time.Duration // How often the outbox is polled
+	BatchSize    int           // Events fetched per query
+	MaxAttempts  int           // Attempts before an event is marked failed; 0 retries forever
+	BaseBackoff  time.Duration // Delay before the first retry, doubled on each further attempt
+	MaxBackoff   time.Duration // Upper bound of the retry delay
+	Retention    time.Duration // How long published events are kept; 0 keeps them forever
+	Logger       *zap.Logger
+}
+
+// DefaultOutboxRelayConfig returns the default outbox relay configuration
+func DefaultOutboxRelayConfig() OutboxRelayConfig {
+	return OutboxRelayConfig{
+		PollInterval: time.Second,
+		BatchSize:    100,
+		MaxAttempts:  20,
+		BaseBackoff:  time.Second,
+		MaxBackoff:   5 * time.Minute,
+		Retention:    24 * time.Hour,
+	}
+}
+
+// outboxCleanupInterval is how often published events past the retention are deleted
+const outboxCleanupInterval = 10 * time.Minute
+
+// OutboxRelay publishes the events recorded in the transactional outbox to the message queue.
+// Delivery is at least once: an event published just before a crash is published again.
+type OutboxRelay struct {
+	outboxRepo   repository.OutboxRepository
+	messageQueue mq.MessageQueue
+	config       OutboxRelayConfig
+	logger       *zap.Logger
+
+	mu          sync.Mutex
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+	lastCleanup time.Time
+}
+
+// NewOutboxRelay creates a new outbox relay
+func NewOutboxRelay(outboxRepo repository.OutboxRepository, messageQueue mq.MessageQueue, config OutboxRelayConfig) *OutboxRelay {
+	defaults := DefaultOutboxRelayConfig()
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaults.PollInterval
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.MaxAttempts < 0 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.BaseBackoff <= 0 {
+		config.BaseBackoff = defaults.BaseBackoff
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = defaults.MaxBackoff
+	}
+
+	logger := config.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	return &OutboxRelay{
+		outboxRepo:   outboxRepo,
+		messageQueue: messageQueue,
+		config:       config,
+		logger:       logger,
+	}
+}
+
+// Start starts polling the outbox in the background
+func (r *OutboxRelay) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(r.config.PollInterval)
+		defer ticker.Stop()
+
+		r.logger.Info("Started outbox relay", zap.Duration("interval", r.config.PollInterval))
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := r.poll(ctx); err != nil && ctx.Err() == nil {
+					r.logger.Warn("Outbox relay poll failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops the relay and waits for the current poll to finish
+func (r *OutboxRelay) Stop() {
+	r.mu.Lock()
+	cancel := r.cancel
+	r.cancel = nil
+	r.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+
+	cancel()
+
+	r.wg.Wait()
+	r.logger.Info("Outbox relay stopped")
+}
+
+// poll relays the outbox while holding the relay lock, then refreshes the backlog metrics
+func (r *OutboxRelay) poll(ctx context.Context) error {
+	acquired, err := r.outboxRepo.WithRelayLock(ctx, func(ctx context.Context) error {
+		if err := r.drain(ctx); err != nil {
+			return err
+		}
+		r.cleanup(ctx)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		// Another replica owns the outbox; it reports the backlog
+		return nil
+	}
+
+	count, oldest, err := r.outboxRepo.PendingStats(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read outbox stats: %w", err)
+	}
+	outboxPendingEvents.Set(float64(count))
+	if oldest != nil {
+		outboxOldestPendingAge.Set(time.Since(*oldest).Seconds())
+	} else {
+		outboxOldestPendingAge.Set(0)
+	}
+	return nil
+}
+
+// drain relays full batches until the outbox has no more due events or a publish fails
+func (r *OutboxRelay) drain(ctx context.Context) error {
+	for ctx.Err() == nil {
+		fetched, failed, err := r.RelayOnce(ctx)
+		if err != nil {
+			return err
+		}
+		if failed > 0 || fetched < r.config.BatchSize {
+			return nil
+		}
+	}
+	return nil
+}
+
+// RelayOnce publishes one batch of due events. It returns how many events were fetched and
+// how many failed to publish. After a failure the remaining events of the same aggregate are
+// held back so they are not published ahead of the failed one.
+func (r *OutboxRelay) RelayOnce(ctx context.Context) (int, int, error) {
+	events, err := r.outboxRepo.FindDue(ctx, time.Now(), r.config.BatchSize)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to find due outbox events: %w", err)
+	}
+
+	failed := 0
+	blocked := make(map[string]bool)
+	for _, event := range events {
+		aggregate := event.AggregateType + ":" + strconv.FormatInt(event.AggregateID, 10)
+		if blocked[aggregate] {
+			continue
+		}
+
+		if err := r.messageQueue.Publish(ctx, event.Topic, json.RawMessage(event.Payload)); err != nil {
+			blocked[aggregate] = true
+			failed++
+			if err := r.recordFailure(ctx, event, err); err != nil {
+				return len(events), failed, err
+			}
+			continue
+		}
+
+		if err := r.outboxRepo.MarkPublished(ctx, event.ID, time.Now()); err != nil {
+			// The event is out but still pending, so it will be published again
+			return len(events), failed, fmt.Errorf("failed to mark outbox event %d published: %w", event.ID, err)
+		}
+		outboxPublishedTotal.WithLabelValues(event.Topic).Inc()
+	}
+
+	return len(events), failed, nil
+}
+
+// recordFailure schedules the next attempt of an event, or gives up on it
+func (r *OutboxRelay) recordFailure(ctx context.Context, event *models.OutboxEvent, publishErr error) error {
+	outboxPublishFailuresTotal.WithLabelValues(event.Topic).Inc()
+
+	attempts := event.Attempts + 1
+	status := models.OutboxStatusPending
+	if r.config.MaxAttempts > 0 && attempts >= r.config.MaxAttempts {
+		status = models.OutboxStatusFailed
+		outboxGivenUpTotal.WithLabelValues(event.Topic).Inc()
+		r.logger.Error("Giving up on outbox event",
+			zap.Int64("id", event.ID),
+			zap.String("event_id", event.EventID),
+			zap.String("topic", event.Topic),
+			zap.Int("attempts", attempts),
+			zap.Error(publishErr),
+		)
+	}
+
+	nextAttemptAt := time.Now().Add(r.backoff(attempts))
+	if err := r.outboxRepo.MarkAttemptFailed(ctx, event.ID, status, nextAttemptAt, publishErr.Error()); err != nil {
+		return fmt.Errorf("failed to record outbox attempt for event %d: %w", event.ID, err)
+	}
+	return nil
+}
+
+// backoff returns the delay before the attempt following the given number of failed attempts
+func (r *OutboxRelay) backoff(attempts int) time.Duration {
+	delay := r.config.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= r.config.MaxBackoff {
+			return r.config.MaxBackoff
+		}
+	}
+	if delay > r.config.MaxBackoff {
+		return r.config.MaxBackoff
+	}
+	return delay
+}
+
+// cleanup deletes published events past the retention, at most once per cleanup interval
+func (r *OutboxRelay) cleanup(ctx context.Context) {
+	if r.config.Retention <= 0 || time.Since(r.lastCleanup) < outboxCleanupInterval {
+		return
+	}
+	r.lastCleanup = time.Now()
+
+	deleted, err := r.outboxRepo.DeletePublishedBefore(ctx, time.Now().Add(-r.config.Retention), 10000)
+	if err != nil {
+		r.logger.Warn("Failed to clean up published outbox events", zap.Error(err))
+		return
+	}
+	if deleted > 0 {
+		r.logger.Info("Cleaned up published outbox events", zap.Int64("deleted", deleted))
+	}
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/kobayashirei/airy/internal/models"
+	"github.com/kobayashirei/airy/internal/mq"
+)
+
+func TestOutboxQueue_Publish(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("records aggregate and event ID", func(t *testing.T) {
+		outboxRepo := new(MockOutboxRepository)
+		queue := NewOutboxQueue(outboxRepo)
+
+		event := mq.VoteCreatedEvent{
+			BaseEvent:  mq.BaseEvent{EventID: "evt-1", EventType: mq.TopicVoteCreated},
+			EntityType: "comment",
+			EntityID:   42,
+			VoteType:   "up",
+		}
+		outboxRepo.On("Create", ctx, mock.MatchedBy(func(e *models.OutboxEvent) bool {
+			var payload mq.VoteCreatedEvent
+			return e.EventID == "evt-1" &&
+				e.AggregateType == "comment" && e.AggregateID == 42 &&
+				e.Topic == mq.TopicVoteCreated &&
+				e.Status == models.OutboxStatusPending &&
+				json.Unmarshal([]byte(e.Payload), &payload) == nil && payload.VoteType == "up"
+		})).Return(nil)
+
+		assert.NoError(t, queue.Publish(ctx, mq.TopicVoteCreated, event))
+		outboxRepo.AssertExpectations(t)
+	})
+
+	t.Run("enveloped events keep their ID and aggregate", func(t *testing.T) {
+		outboxRepo := new(MockOutboxRepository)
+		queue := mq.NewEnvelopeQueue(NewOutboxQueue(outboxRepo), mq.DefaultRegistry, "airy/test")
+
+		event := mq.CommentCreatedEvent{
+			BaseEvent: mq.BaseEvent{EventID: "evt-2", EventType: mq.TopicCommentCreated},
+			CommentID: 5,
+			PostID:    9,
+		}
+		outboxRepo.On("Create", ctx, mock.MatchedBy(func(e *models.OutboxEvent) bool {
+			envelope, err := mq.DecodeEnvelope([]byte(e.Payload))
+			return err == nil && e.EventID == "evt-2" &&
+				e.AggregateType == "comment" && e.AggregateID == 5 &&
+				envelope.Version == 1 && envelope.Producer == "airy/test"
+		})).Return(nil)
+
+		assert.NoError(t, queue.Publish(ctx, mq.TopicCommentCreated, event))
+		outboxRepo.AssertExpectations(t)
+	})
+
+	t.Run("events without aggregate are ordered per topic", func(t *testing.T) {
+		outboxRepo := new(MockOutboxRepository)
+		queue := NewOutboxQueue(outboxRepo)
+
+		outboxRepo.On("Create", ctx, mock.MatchedBy(func(e *models.OutboxEvent) bool {
+			return e.AggregateType == mq.TopicUserRegistered && e.AggregateID == 0 && e.EventID != ""
+		})).Return(nil)
+
+		assert.NoError(t, queue.Publish(ctx, mq.TopicUserRegistered, map[string]int{"user_id": 1}))
+		outboxRepo.AssertExpectations(t)
+	})
+
+	t.Run("write failure is returned so the transaction rolls back", func(t *testing.T) {
+		outboxRepo := new(MockOutboxRepository)
+		queue := NewOutboxQueue(outboxRepo)
+
+		outboxRepo.On("Create", ctx, mock.Anything).Return(errors.New("db down"))
+
+		assert.Error(t, queue.Publish(ctx, mq.TopicPostPublished, mq.PostPublishedEvent{PostID: 1}))
+	})
+}
+
+func TestOutboxRelay_RelayOnce(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("failure holds back later events of the same aggregate", func(t *testing.T) {
+		outboxRepo := new(MockOutboxRepository)
+		messageQueue := new(MockMessageQueue)
+		relay := NewOutboxRelay(outboxRepo, messageQueue, OutboxRelayConfig{BatchSize: 10})
+
+		events := []*models.OutboxEvent{
+			{ID: 1, AggregateType: "post", AggregateID: 1, Topic: mq.TopicPostPublished, Payload: `{"post_id":1}`},
+			{ID: 2, AggregateType: "post", AggregateID: 2, Topic: mq.TopicPostPublished, Payload: `{"post_id":2}`},
+			{ID: 3, AggregateType: "post", AggregateID: 1, Topic: mq.TopicPostUpdated, Payload: `{"post_id":1}`},
+		}
+		outboxRepo.On("FindDue", ctx, mock.Anything, 10).Return(events, nil)
+		messageQueue.On("Publish", ctx, mq.TopicPostPublished, json.RawMessage(`{"post_id":1}`)).Return(errors.New("broker down"))
+		messageQueue.On("Publish", ctx, mq.TopicPostPublished, json.RawMessage(`{"post_id":2}`)).Return(nil)
+		outboxRepo.On("MarkAttemptFailed", ctx, int64(1), models.OutboxStatusPending, mock.Anything, "broker down").Return(nil)
+		outboxRepo.On("MarkPublished", ctx, int64(2), mock.Anything).Return(nil)
+
+		fetched, failed, err := relay.RelayOnce(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, fetched)
+		assert.Equal(t, 1, failed)
+		messageQueue.AssertNotCalled(t, "Publish", ctx, mq.TopicPostUpdated, mock.Anything)
+		outboxRepo.AssertExpectations(t)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		outboxRepo := new(MockOutboxRepository)
+		messageQueue := new(MockMessageQueue)
+		relay := NewOutboxRelay(outboxRepo, messageQueue, OutboxRelayConfig{BatchSize: 10, MaxAttempts: 3})
+
+		events := []*models.OutboxEvent{
+			{ID: 1, AggregateType: "post", AggregateID: 1, Topic: mq.TopicPostDeleted, Payload: `{}`, Attempts: 2},
+		}
+		outboxRepo.On("FindDue", ctx, mock.Anything, 10).Return(events, nil)
+		messageQueue.On("Publish", ctx, mq.TopicPostDeleted, mock.Anything).Return(errors.New("broker down"))
+		outboxRepo.On("MarkAttemptFailed", ctx, int64(1), models.OutboxStatusFailed, mock.Anything, "broker down").Return(nil)
+
+		_, failed, err := relay.RelayOnce(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, failed)
+		outboxRepo.AssertExpectations(t)
+	})
+}
+
+func TestOutboxRelay_Backoff(t *testing.T) {
+	relay := NewOutboxRelay(nil, nil, OutboxRelayConfig{BaseBackoff: time.Second, MaxBackoff: 10 * time.Second})
+
+	assert.Equal(t, time.Second, relay.backoff(1))
+	assert.Equal(t, 2*time.Second, relay.backoff(2))
+	assert.Equal(t, 8*time.Second, relay.backoff(4))
+	assert.Equal(t, 10*time.Second, relay.backoff(5))
+	assert.Equal(t, 10*time.Second, relay.backoff(50))
+}
+
+func TestOutboxRelay_PollSkipsWithoutLock(t *testing.T) {
+	ctx := context.Background()
+	outboxRepo := new(MockOutboxRepository)
+	relay := NewOutboxRelay(outboxRepo, new(MockMessageQueue), OutboxRelayConfig{})
+
+	outboxRepo.On("WithRelayLock", ctx, mock.Anything).Return(false, nil)
+
+	assert.NoError(t, relay.poll(ctx))
+	outboxRepo.AssertNotCalled(t, "FindDue", mock.Anything, mock.Anything, mock.Anything)
+	outboxRepo.AssertNotCalled(t, "PendingStats", mock.Anything)
+}
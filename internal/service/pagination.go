@@ -0,0 +1,35 @@
+package service
+
+import (
+	"reflect"
+
+	"github.com/kobayashirei/airy/internal/pagination"
+	"github.com/kobayashirei/airy/internal/repository"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor is malformed, tampered with or issued for another list
+var ErrInvalidCursor = pagination.ErrInvalidCursor
+
+// decodeKeyset decodes a cursor issued by encodeKeyset for the same sort into a repository keyset.
+// values must be pointers to the sort key types; an empty cursor means the first page and yields nil.
+func decodeKeyset(cursor, sort string, values ...interface{}) (*repository.Keyset, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	var id int64
+	if err := pagination.Decode(cursor, sort, append(values, &id)...); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	keyset := &repository.Keyset{ID: id, Values: make([]interface{}, len(values))}
+	for i, value := range values {
+		keyset.Values[i] = reflect.ValueOf(value).Elem().Interface()
+	}
+	return keyset, nil
+}
+
+// encodeKeyset builds the cursor for the page following a row with the given sort key values and ID
+func encodeKeyset(sort string, id int64, values ...interface{}) (string, error) {
+	return pagination.Encode(sort, append(values, id)...)
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kobayashirei/airy/internal/models"
+	"github.com/kobayashirei/airy/internal/repository"
+	"github.com/kobayashirei/airy/internal/taskpool"
+)
+
+// JobScheduler schedules unique jobs, implemented by taskpool.JobQueue
+type JobScheduler interface {
+	EnqueueUnique(ctx context.Context, uniqueKey, name string, args interface{}, runAt time.Time) (string, bool, error)
+	CancelUnique(ctx context.Context, uniqueKey string) error
+}
+
+// PostScheduler schedules the publishing of scheduled posts as jobs of the job queue,
+// which survive restarts and run once across replicas. Each schedule of a post is its
+// own unique job, so rescheduling never waits on the job of the previous schedule,
+// which finds the post scheduled for later and leaves it alone.
+type PostScheduler struct {
+	jobs     JobScheduler
+	postRepo repository.PostRepository
+	logger   *zap.Logger
+}
+
+// NewPostScheduler creates a post scheduler
+func NewPostScheduler(jobs JobScheduler, postRepo repository.PostRepository, logger *zap.Logger) *PostScheduler {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &PostScheduler{
+		jobs:     jobs,
+		postRepo: postRepo,
+		logger:   logger,
+	}
+}
+
+// Schedule enqueues the job publishing a scheduled post at its scheduled time. It is
+// idempotent: a post whose job is already enqueued keeps it.
+func (s *PostScheduler) Schedule(ctx context.Context, post *models.Post) error {
+	if post.ScheduledAt == nil {
+		return fmt.Errorf("post %d has no scheduled time", post.ID)
+	}
+	_, _, err := s.jobs.EnqueueUnique(ctx, publishJobKey(post.ID, *post.ScheduledAt),
+		taskpool.TaskPublishPost, taskpool.PublishPostTask{PostID: post.ID}, *post.ScheduledAt)
+	if err != nil {
+		return fmt.Errorf("failed to schedule post %d: %w", post.ID, err)
+	}
+	return nil
+}
+
+// Unschedule cancels the job publishing a post at scheduledAt. A job that already
+// started, or is gone, is not an error: it finds the post changed and does nothing.
+func (s *PostScheduler) Unschedule(ctx context.Context, postID int64, scheduledAt time.Time) {
+	err := s.jobs.CancelUnique(ctx, publishJobKey(postID, scheduledAt))
+	if err != nil && !errors.Is(err, taskpool.ErrJobNotFound) {
+		s.logger.Warn("failed to cancel scheduled post job, it runs and finds the post changed",
+			zap.Int64("post_id", postID), zap.Error(err))
+	}
+}
+
+// Resync enqueues the jobs of all scheduled posts that have none, such as posts whose
+// jobs were lost with Redis data. It returns the number of scheduled posts.
+func (s *PostScheduler) Resync(ctx context.Context) (int, error) {
+	const batchSize = 500
+
+	var afterID int64
+	total := 0
+	for {
+		posts, err := s.postRepo.ListScheduled(ctx, afterID, batchSize)
+		if err != nil {
+			return total, fmt.Errorf("failed to list scheduled posts: %w", err)
+		}
+		for _, post := range posts {
+			if err := s.Schedule(ctx, post); err != nil {
+				return total, err
+			}
+			total++
+		}
+		if len(posts) < batchSize {
+			return total, nil
+		}
+		afterID = posts[len(posts)-1].ID
+	}
+}
+
+// publishJobKey is the unique key of the job publishing a post at scheduledAt
+func publishJobKey(postID int64, scheduledAt time.Time) string {
+	return "post:publish:" + strconv.FormatInt(postID, 10) + ":" + strconv.FormatInt(scheduledAt.Unix(), 10)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,6 +23,12 @@ var (
 	ErrPostNotFound = errors.New("post not found")
 	// ErrUnauthorized is returned when user is not authorized
 	ErrUnauthorized = errors.New("unauthorized")
+	// ErrInvalidSchedule is returned when a post is scheduled for a time that has passed
+	ErrInvalidSchedule = errors.New("scheduled time must be in the future")
+	// ErrPostNotSchedulable is returned when a post cannot be scheduled or unscheduled in its status
+	ErrPostNotSchedulable = errors.New("post cannot be scheduled in its status")
+	// ErrSchedulingUnavailable is returned when posts are scheduled without a job queue
+	ErrSchedulingUnavailable = errors.New("scheduled publishing is not available")
 )
 
 // PostService defines the interface for post business logic
@@ -31,6 +38,9 @@ type PostService interface {
 	UpdatePost(ctx context.Context, postID int64, userID int64, req UpdatePostRequest) (*models.Post, error)
 	DeletePost(ctx context.Context, postID int64, userID int64) error
 	ListPosts(ctx context.Context, opts ListPostsRequest) (*ListPostsResponse, error)
+	SchedulePost(ctx context.Context, postID int64, userID int64, scheduledAt time.Time) (*models.Post, error)
+	CancelSchedule(ctx context.Context, postID int64, userID int64) (*models.Post, error)
+	PublishScheduledPost(ctx context.Context, postID int64) error
 }
 
 // CreatePostRequest represents a request to create a post
@@ -59,6 +69,11 @@ type UpdatePostRequest struct {
 	AllowComment *bool   `json:"allow_comment"`
 }
 
+// SchedulePostRequest represents a request to schedule or reschedule a post
+type SchedulePostRequest struct {
+	ScheduledAt time.Time `json:"scheduled_at" binding:"required"`
+}
+
 // ListPostsRequest represents a request to list posts
 type ListPostsRequest struct {
 	AuthorID *int64  `form:"author_id"`
@@ -66,46 +81,52 @@ type ListPostsRequest struct {
 	Status   string  `form:"status"`
 	SortBy   string  `form:"sort_by"` // "created_at", "hotness_score", "view_count"
 	Order    string  `form:"order"`   // "asc", "desc"
-	Page     int     `form:"page" binding:"min=1"`
-	PageSize int     `form:"page_size" binding:"min=1,max=100"`
+	Cursor   string  `form:"cursor"`  // next_cursor of the previous page; empty for the first page
+	PageSize int     `form:"page_size" binding:"omitempty,min=1,max=100"`
 }
 
 // ListPostsResponse represents a response for listing posts
 type ListPostsResponse struct {
 	Posts      []*models.Post `json:"posts"`
 	Total      int64          `json:"total"`
-	Page       int            `json:"page"`
 	PageSize   int            `json:"page_size"`
-	TotalPages int            `json:"total_pages"`
+	NextCursor string         `json:"next_cursor,omitempty"`
 }
 
 // postService implements PostService interface
 type postService struct {
 	postRepo       repository.PostRepository
+	transactor     repository.Transactor
 	cacheService   cache.Service
 	moderationService ContentModerationService
 	messageQueue   mq.MessageQueue
 	taskPool       *taskpool.Pool
+	scheduler      *PostScheduler
 	sanitizer      *bluemonday.Policy
 }
 
-// NewPostService creates a new post service
+// NewPostService creates a new post service. Without a scheduler, posts cannot be
+// scheduled for later.
 func NewPostService(
 	postRepo repository.PostRepository,
+	transactor repository.Transactor,
 	cacheService cache.Service,
 	moderationService ContentModerationService,
 	messageQueue mq.MessageQueue,
 	taskPool *taskpool.Pool,
+	scheduler *PostScheduler,
 ) PostService {
 	// Create HTML sanitizer policy
 	sanitizer := bluemonday.UGCPolicy()
 	
 	return &postService{
 		postRepo:          postRepo,
+		transactor:        transactor,
 		cacheService:      cacheService,
 		moderationService: moderationService,
 		messageQueue:      messageQueue,
 		taskPool:          taskPool,
+		scheduler:         scheduler,
 		sanitizer:         sanitizer,
 	}
 }
@@ -119,8 +140,19 @@ func (s *postService) CreatePost(ctx context.Context, req CreatePostRequest) (*m
 	// Sanitize HTML to prevent XSS
 	htmlContent = s.sanitizer.Sanitize(htmlContent)
 	
-	// Create post object
+	// A post scheduled for later is kept until its publishing job runs
 	now := time.Now()
+	scheduledAt := req.ScheduledAt
+	if scheduledAt != nil {
+		truncated := scheduledAt.Truncate(time.Second)
+		scheduledAt = &truncated
+	}
+	scheduled := scheduledAt != nil && scheduledAt.After(now)
+	if scheduled && s.scheduler == nil {
+		return nil, ErrSchedulingUnavailable
+	}
+	
+	// Create post object
 	post := &models.Post{
 		Title:           req.Title,
 		ContentMarkdown: req.Content,
@@ -132,7 +164,7 @@ func (s *postService) CreatePost(ctx context.Context, req CreatePostRequest) (*m
 		Status:          "draft", // Initial status
 		Category:        req.Category,
 		Tags:            req.Tags,
-		ScheduledAt:     req.ScheduledAt,
+		ScheduledAt:     scheduledAt,
 		AllowComment:    req.AllowComment,
 		IsAnonymous:     req.IsAnonymous,
 		ViewCount:       0,
@@ -149,6 +181,9 @@ func (s *postService) CreatePost(ctx context.Context, req CreatePostRequest) (*m
 	
 	// Map moderation result to post status
 	post.Status = MapModerationStatusToPostStatus(moderationResult.Status)
+	if post.Status == "published" && scheduled {
+		post.Status = "scheduled"
+	}
 	
 	// If status is published, set published timestamp
 	if post.Status == "published" {
@@ -156,14 +191,26 @@ func (s *postService) CreatePost(ctx context.Context, req CreatePostRequest) (*m
 		post.PublishedAt = &publishedAt
 	}
 	
-	// Save post to database
-	if err := s.postRepo.Create(ctx, post); err != nil {
-		return nil, fmt.Errorf("failed to create post: %w", err)
-	}
-	
-	// If post is published, trigger async tasks
-	if post.Status == "published" {
-		s.triggerAsyncTasks(ctx, post)
+	// Save post to database together with its event
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.postRepo.Create(ctx, post); err != nil {
+			return fmt.Errorf("failed to create post: %w", err)
+		}
+		
+		// If post is published, trigger async tasks
+		if post.Status == "published" {
+			return s.triggerAsyncTasks(ctx, post)
+		}
+		
+		// Schedule within the transaction, so that the post is not created when
+		// scheduling fails; a job that finds no post does nothing
+		if post.Status == "scheduled" {
+			return s.scheduler.Schedule(ctx, post)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	
 	return post, nil
@@ -250,7 +297,11 @@ func (s *postService) UpdatePost(ctx context.Context, postID int64, userID int64
 		if err != nil {
 			return nil, fmt.Errorf("content moderation failed: %w", err)
 		}
-		post.Status = MapModerationStatusToPostStatus(moderationResult.Status)
+		status := MapModerationStatusToPostStatus(moderationResult.Status)
+		if status != "published" || post.Status != "scheduled" {
+			// A scheduled post passing moderation stays scheduled
+			post.Status = status
+		}
 		
 		// Update published timestamp if newly published
 		if post.Status == "published" && post.PublishedAt == nil {
@@ -261,9 +312,15 @@ func (s *postService) UpdatePost(ctx context.Context, postID int64, userID int64
 	
 	post.UpdatedAt = time.Now()
 	
-	// Save to database
-	if err := s.postRepo.Update(ctx, post); err != nil {
-		return nil, fmt.Errorf("failed to update post: %w", err)
+	// Save to database together with the update event
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.postRepo.Update(ctx, post); err != nil {
+			return fmt.Errorf("failed to update post: %w", err)
+		}
+		return s.publishPostUpdatedEvent(ctx, post)
+	})
+	if err != nil {
+		return nil, err
 	}
 	
 	// Invalidate cache
@@ -272,9 +329,6 @@ func (s *postService) UpdatePost(ctx context.Context, postID int64, userID int64
 		fmt.Printf("failed to invalidate cache: %v\n", err)
 	}
 	
-	// Publish update event
-	s.publishPostUpdatedEvent(ctx, post)
-	
 	return post, nil
 }
 
@@ -294,9 +348,19 @@ func (s *postService) DeletePost(ctx context.Context, postID int64, userID int64
 		return ErrUnauthorized
 	}
 	
-	// Soft delete
-	if err := s.postRepo.Delete(ctx, postID); err != nil {
-		return fmt.Errorf("failed to delete post: %w", err)
+	// Soft delete together with the delete event
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.postRepo.Delete(ctx, postID); err != nil {
+			return fmt.Errorf("failed to delete post: %w", err)
+		}
+		return s.publishPostDeletedEvent(ctx, post)
+	})
+	if err != nil {
+		return err
+	}
+	
+	if post.Status == "scheduled" && s.scheduler != nil {
+		s.scheduler.Unschedule(ctx, postID, *post.ScheduledAt)
 	}
 	
 	// Invalidate cache
@@ -305,18 +369,12 @@ func (s *postService) DeletePost(ctx context.Context, postID int64, userID int64
 		fmt.Printf("failed to invalidate cache: %v\n", err)
 	}
 	
-	// Publish delete event
-	s.publishPostDeletedEvent(ctx, post)
-	
 	return nil
 }
 
 // ListPosts lists posts with pagination and filtering
 func (s *postService) ListPosts(ctx context.Context, req ListPostsRequest) (*ListPostsResponse, error) {
 	// Set defaults
-	if req.Page < 1 {
-		req.Page = 1
-	}
 	if req.PageSize < 1 {
 		req.PageSize = 20
 	}
@@ -324,15 +382,30 @@ func (s *postService) ListPosts(ctx context.Context, req ListPostsRequest) (*Lis
 		req.PageSize = 100
 	}
 	
-	// Build repository options
+	sortBy := req.SortBy
+	if _, ok := postSortKey[sortBy]; !ok {
+		sortBy = "created_at"
+	}
+	order := "desc"
+	if strings.EqualFold(req.Order, "asc") {
+		order = "asc"
+	}
+	cursorSort := "posts:" + sortBy + ":" + order
+	
+	after, err := decodePostCursor(req.Cursor, cursorSort, sortBy)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Build repository options, fetching one extra row to know whether another page exists
 	opts := repository.PostListOptions{
 		AuthorID: req.AuthorID,
 		CircleID: req.CircleID,
 		Status:   req.Status,
-		SortBy:   req.SortBy,
-		Order:    req.Order,
-		Limit:    req.PageSize,
-		Offset:   (req.Page - 1) * req.PageSize,
+		SortBy:   sortBy,
+		Order:    order,
+		Limit:    req.PageSize + 1,
+		After:    after,
 	}
 	
 	// Get posts
@@ -341,27 +414,177 @@ func (s *postService) ListPosts(ctx context.Context, req ListPostsRequest) (*Lis
 		return nil, fmt.Errorf("failed to list posts: %w", err)
 	}
 	
+	var nextCursor string
+	if len(posts) > req.PageSize {
+		posts = posts[:req.PageSize]
+		last := posts[req.PageSize-1]
+		nextCursor, err = encodeKeyset(cursorSort, last.ID, postSortKey[sortBy](last))
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode cursor: %w", err)
+		}
+	}
+	
 	// Get total count
 	total, err := s.postRepo.Count(ctx, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count posts: %w", err)
 	}
 	
-	// Calculate total pages
-	totalPages := int(total) / req.PageSize
-	if int(total)%req.PageSize > 0 {
-		totalPages++
-	}
-	
 	return &ListPostsResponse{
 		Posts:      posts,
 		Total:      total,
-		Page:       req.Page,
 		PageSize:   req.PageSize,
-		TotalPages: totalPages,
+		NextCursor: nextCursor,
 	}, nil
 }
 
+// SchedulePost schedules a draft or scheduled post to be published at scheduledAt
+func (s *postService) SchedulePost(ctx context.Context, postID int64, userID int64, scheduledAt time.Time) (*models.Post, error) {
+	if s.scheduler == nil {
+		return nil, ErrSchedulingUnavailable
+	}
+	scheduledAt = scheduledAt.Truncate(time.Second)
+	if !scheduledAt.After(time.Now()) {
+		return nil, ErrInvalidSchedule
+	}
+	
+	post, err := s.findOwnPost(ctx, postID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if post.Status != "draft" && post.Status != "scheduled" {
+		return nil, ErrPostNotSchedulable
+	}
+	
+	previous := post.ScheduledAt
+	wasScheduled := post.Status == "scheduled"
+	post.Status = "scheduled"
+	post.ScheduledAt = &scheduledAt
+	post.UpdatedAt = time.Now()
+	
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.postRepo.Update(ctx, post); err != nil {
+			return fmt.Errorf("failed to update post: %w", err)
+		}
+		return s.scheduler.Schedule(ctx, post)
+	})
+	if err != nil {
+		return nil, err
+	}
+	
+	// The job of the previous schedule would find the post scheduled for later
+	// anyway, cancelling it only saves the run
+	if wasScheduled && previous != nil && !previous.Equal(scheduledAt) {
+		s.scheduler.Unschedule(ctx, postID, *previous)
+	}
+	s.invalidatePost(ctx, postID)
+	
+	return post, nil
+}
+
+// CancelSchedule turns a scheduled post back into a draft
+func (s *postService) CancelSchedule(ctx context.Context, postID int64, userID int64) (*models.Post, error) {
+	post, err := s.findOwnPost(ctx, postID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if post.Status != "scheduled" {
+		return nil, ErrPostNotSchedulable
+	}
+	
+	previous := post.ScheduledAt
+	post.Status = "draft"
+	post.ScheduledAt = nil
+	post.UpdatedAt = time.Now()
+	
+	if err := s.postRepo.Update(ctx, post); err != nil {
+		return nil, fmt.Errorf("failed to update post: %w", err)
+	}
+	
+	if s.scheduler != nil && previous != nil {
+		s.scheduler.Unschedule(ctx, postID, *previous)
+	}
+	s.invalidatePost(ctx, postID)
+	
+	return post, nil
+}
+
+// PublishScheduledPost publishes a scheduled post whose time has come, with the
+// post.published event. It does nothing for a post that is no longer scheduled, is
+// scheduled for later, or was published by another replica.
+func (s *postService) PublishScheduledPost(ctx context.Context, postID int64) error {
+	published := false
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		now := time.Now()
+		ok, err := s.postRepo.PublishScheduled(ctx, postID, now)
+		if err != nil {
+			return fmt.Errorf("failed to publish post: %w", err)
+		}
+		if !ok {
+			return nil
+		}
+		
+		post, err := s.postRepo.FindByID(ctx, postID)
+		if err != nil {
+			return fmt.Errorf("failed to find post: %w", err)
+		}
+		published = true
+		return s.triggerAsyncTasks(ctx, post)
+	})
+	if err != nil {
+		return err
+	}
+	
+	if published {
+		s.invalidatePost(ctx, postID)
+	}
+	return nil
+}
+
+// findOwnPost finds a post of the user
+func (s *postService) findOwnPost(ctx context.Context, postID int64, userID int64) (*models.Post, error) {
+	post, err := s.postRepo.FindByID(ctx, postID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find post: %w", err)
+	}
+	if post == nil {
+		return nil, ErrPostNotFound
+	}
+	if post.AuthorID != userID {
+		return nil, ErrUnauthorized
+	}
+	return post, nil
+}
+
+// invalidatePost drops the cached copy of a post
+func (s *postService) invalidatePost(ctx context.Context, postID int64) {
+	if err := s.cacheService.Delete(ctx, cache.PostKey(postID)); err != nil {
+		fmt.Printf("failed to invalidate cache: %v\n", err)
+	}
+}
+
+// postSortKey maps each sortable post column to the row value stored in cursors
+var postSortKey = map[string]func(post *models.Post) interface{}{
+	"created_at":    func(post *models.Post) interface{} { return post.CreatedAt },
+	"hotness_score": func(post *models.Post) interface{} { return post.HotnessScore },
+	"view_count":    func(post *models.Post) interface{} { return post.ViewCount },
+}
+
+// decodePostCursor decodes a post list cursor, typing the sort value after the sort column
+func decodePostCursor(cursor, cursorSort, sortBy string) (*repository.Keyset, error) {
+	switch sortBy {
+	case "hotness_score":
+		var score float64
+		return decodeKeyset(cursor, cursorSort, &score)
+	case "view_count":
+		var views int
+		return decodeKeyset(cursor, cursorSort, &views)
+	default:
+		var createdAt time.Time
+		return decodeKeyset(cursor, cursorSort, &createdAt)
+	}
+}
+
 // markdownToHTML converts markdown to HTML
 func (s *postService) markdownToHTML(markdown string) string {
 	// Use blackfriday to convert markdown to HTML
@@ -380,19 +603,20 @@ func (s *postService) incrementViewCountAsync(ctx context.Context, postID int64)
 
 // triggerAsyncTasks triggers async tasks for post publication
 // Implements Requirement 4.4
-func (s *postService) triggerAsyncTasks(ctx context.Context, post *models.Post) {
+func (s *postService) triggerAsyncTasks(ctx context.Context, post *models.Post) error {
 	// Publish post published event to message queue
 	// This will trigger:
 	// - Search index update
 	// - Feed push
 	// - Notifications
-	s.publishPostPublishedEvent(ctx, post)
+	return s.publishPostPublishedEvent(ctx, post)
 }
 
-// publishPostPublishedEvent publishes a post published event
-func (s *postService) publishPostPublishedEvent(ctx context.Context, post *models.Post) {
+// publishPostPublishedEvent publishes a post published event.
+// Called inside the write transaction, so with the outbox queue the event commits with the post.
+func (s *postService) publishPostPublishedEvent(ctx context.Context, post *models.Post) error {
 	if s.messageQueue == nil {
-		return
+		return nil
 	}
 	
 	event := mq.PostPublishedEvent{
@@ -408,14 +632,15 @@ func (s *postService) publishPostPublishedEvent(ctx context.Context, post *model
 	}
 	
 	if err := s.messageQueue.Publish(ctx, mq.TopicPostPublished, event); err != nil {
-		fmt.Printf("failed to publish post published event: %v\n", err)
+		return fmt.Errorf("failed to publish post published event: %w", err)
 	}
+	return nil
 }
 
 // publishPostUpdatedEvent publishes a post updated event
-func (s *postService) publishPostUpdatedEvent(ctx context.Context, post *models.Post) {
+func (s *postService) publishPostUpdatedEvent(ctx context.Context, post *models.Post) error {
 	if s.messageQueue == nil {
-		return
+		return nil
 	}
 	
 	event := mq.PostUpdatedEvent{
@@ -429,14 +654,15 @@ func (s *postService) publishPostUpdatedEvent(ctx context.Context, post *models.
 	}
 	
 	if err := s.messageQueue.Publish(ctx, mq.TopicPostUpdated, event); err != nil {
-		fmt.Printf("failed to publish post updated event: %v\n", err)
+		return fmt.Errorf("failed to publish post updated event: %w", err)
 	}
+	return nil
 }
 
 // publishPostDeletedEvent publishes a post deleted event
-func (s *postService) publishPostDeletedEvent(ctx context.Context, post *models.Post) {
+func (s *postService) publishPostDeletedEvent(ctx context.Context, post *models.Post) error {
 	if s.messageQueue == nil {
-		return
+		return nil
 	}
 	
 	event := mq.PostDeletedEvent{
@@ -450,6 +676,7 @@ func (s *postService) publishPostDeletedEvent(ctx context.Context, post *models.
 	}
 	
 	if err := s.messageQueue.Publish(ctx, mq.TopicPostDeleted, event); err != nil {
-		fmt.Printf("failed to publish post deleted event: %v\n", err)
+		return fmt.Errorf("failed to publish post deleted event: %w", err)
 	}
+	return nil
 }
@@ -8,7 +8,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"github.com/kobayashirei/airy/internal/cache"
 	"github.com/kobayashirei/airy/internal/models"
+	"github.com/kobayashirei/airy/internal/mq"
 )
 
 // MockCacheService is a mock implementation of cache.Service
@@ -72,7 +74,7 @@ func TestCreatePost_Success(t *testing.T) {
 	mockMQ := new(MockMessageQueue)
 
 	// Create service
-	service := NewPostService(mockPostRepo, mockCache, mockModeration, mockMQ, nil)
+	service := NewPostService(mockPostRepo, passthroughTransactor{}, mockCache, mockModeration, mockMQ, nil, nil)
 
 	// Setup expectations
 	mockModeration.On("CheckContent", mock.Anything, mock.Anything).Return(&ModerationResult{
@@ -115,7 +117,7 @@ func TestCreatePost_ModerationReview(t *testing.T) {
 	mockModeration := new(MockContentModerationService)
 
 	// Create service
-	service := NewPostService(mockPostRepo, mockCache, mockModeration, nil, nil)
+	service := NewPostService(mockPostRepo, passthroughTransactor{}, mockCache, mockModeration, nil, nil, nil)
 
 	// Setup expectations - content flagged for review
 	mockModeration.On("CheckContent", mock.Anything, mock.Anything).Return(&ModerationResult{
@@ -154,7 +156,7 @@ func TestCreatePost_ModerationReject(t *testing.T) {
 	mockModeration := new(MockContentModerationService)
 
 	// Create service
-	service := NewPostService(mockPostRepo, mockCache, mockModeration, nil, nil)
+	service := NewPostService(mockPostRepo, passthroughTransactor{}, mockCache, mockModeration, nil, nil, nil)
 
 	// Setup expectations - content rejected
 	mockModeration.On("CheckContent", mock.Anything, mock.Anything).Return(&ModerationResult{
@@ -212,7 +214,7 @@ func TestMarkdownToHTML(t *testing.T) {
 	mockCache := new(MockCacheService)
 	mockModeration := new(MockContentModerationService)
 
-	service := NewPostService(mockPostRepo, mockCache, mockModeration, nil, nil).(*postService)
+	service := NewPostService(mockPostRepo, passthroughTransactor{}, mockCache, mockModeration, nil, nil, nil).(*postService)
 
 	// Test markdown conversion
 	markdown := "# Heading\n\nThis is **bold** text."
@@ -221,3 +223,160 @@ func TestMarkdownToHTML(t *testing.T) {
 	assert.Contains(t, html, "<h1>")
 	assert.Contains(t, html, "<strong>")
 }
+
+// fakeJobScheduler records the unique keys of scheduled jobs
+type fakeJobScheduler struct {
+	keys map[string]time.Time
+}
+
+func (f *fakeJobScheduler) EnqueueUnique(ctx context.Context, uniqueKey, name string, args interface{}, runAt time.Time) (string, bool, error) {
+	if _, ok := f.keys[uniqueKey]; ok {
+		return uniqueKey, false, nil
+	}
+	f.keys[uniqueKey] = runAt
+	return uniqueKey, true, nil
+}
+
+func (f *fakeJobScheduler) CancelUnique(ctx context.Context, uniqueKey string) error {
+	delete(f.keys, uniqueKey)
+	return nil
+}
+
+func TestCreatePost_Scheduled(t *testing.T) {
+	// Setup mocks
+	mockPostRepo := new(MockPostRepository)
+	mockCache := new(MockCacheService)
+	mockModeration := new(MockContentModerationService)
+	mockMQ := new(MockMessageQueue)
+	jobs := &fakeJobScheduler{keys: map[string]time.Time{}}
+
+	// Create service
+	service := NewPostService(mockPostRepo, passthroughTransactor{}, mockCache, mockModeration, mockMQ, nil, NewPostScheduler(jobs, mockPostRepo, nil))
+
+	// Setup expectations - no post.published event until the scheduled time
+	mockModeration.On("CheckContent", mock.Anything, mock.Anything).Return(&ModerationResult{Status: "pass"}, nil)
+	mockPostRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.Post).ID = 7
+	}).Return(nil)
+
+	// Test
+	scheduledAt := time.Now().Add(time.Hour)
+	post, err := service.CreatePost(context.Background(), CreatePostRequest{
+		Title:       "Test Post",
+		Content:     "This is a test post content",
+		AuthorID:    1,
+		ScheduledAt: &scheduledAt,
+	})
+
+	// Assertions
+	assert.NoError(t, err)
+	assert.Equal(t, "scheduled", post.Status)
+	assert.Nil(t, post.PublishedAt)
+	assert.Equal(t, map[string]time.Time{
+		publishJobKey(7, scheduledAt): scheduledAt.Truncate(time.Second),
+	}, jobs.keys)
+	mockMQ.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreatePost_ScheduledWithoutScheduler(t *testing.T) {
+	service := NewPostService(new(MockPostRepository), passthroughTransactor{}, new(MockCacheService), new(MockContentModerationService), nil, nil, nil)
+
+	scheduledAt := time.Now().Add(time.Hour)
+	_, err := service.CreatePost(context.Background(), CreatePostRequest{
+		Title:       "Test Post",
+		Content:     "This is a test post content",
+		AuthorID:    1,
+		ScheduledAt: &scheduledAt,
+	})
+
+	assert.ErrorIs(t, err, ErrSchedulingUnavailable)
+}
+
+func TestSchedulePost(t *testing.T) {
+	previous := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	newService := func(post *models.Post) (PostService, *MockPostRepository, *fakeJobScheduler) {
+		mockPostRepo := new(MockPostRepository)
+		mockCache := new(MockCacheService)
+		jobs := &fakeJobScheduler{keys: map[string]time.Time{publishJobKey(post.ID, previous): previous}}
+
+		mockPostRepo.On("FindByID", mock.Anything, post.ID).Return(post, nil)
+		mockPostRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+		mockCache.On("Delete", mock.Anything, mock.Anything).Return(nil)
+
+		service := NewPostService(mockPostRepo, passthroughTransactor{}, mockCache, new(MockContentModerationService), nil, nil, NewPostScheduler(jobs, mockPostRepo, nil))
+		return service, mockPostRepo, jobs
+	}
+
+	t.Run("reschedules a scheduled post", func(t *testing.T) {
+		service, _, jobs := newService(&models.Post{ID: 1, AuthorID: 1, Status: "scheduled", ScheduledAt: &previous})
+
+		next := previous.Add(time.Hour)
+		post, err := service.SchedulePost(context.Background(), 1, 1, next)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "scheduled", post.Status)
+		assert.Equal(t, map[string]time.Time{publishJobKey(1, next): next}, jobs.keys)
+	})
+
+	t.Run("cancels a schedule", func(t *testing.T) {
+		service, _, jobs := newService(&models.Post{ID: 1, AuthorID: 1, Status: "scheduled", ScheduledAt: &previous})
+
+		post, err := service.CancelSchedule(context.Background(), 1, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "draft", post.Status)
+		assert.Nil(t, post.ScheduledAt)
+		assert.Empty(t, jobs.keys)
+	})
+
+	t.Run("rejects past times", func(t *testing.T) {
+		service, _, _ := newService(&models.Post{ID: 1, AuthorID: 1, Status: "draft"})
+
+		_, err := service.SchedulePost(context.Background(), 1, 1, time.Now().Add(-time.Minute))
+		assert.ErrorIs(t, err, ErrInvalidSchedule)
+	})
+
+	t.Run("rejects published posts", func(t *testing.T) {
+		service, _, _ := newService(&models.Post{ID: 1, AuthorID: 1, Status: "published"})
+
+		_, err := service.SchedulePost(context.Background(), 1, 1, time.Now().Add(time.Hour))
+		assert.ErrorIs(t, err, ErrPostNotSchedulable)
+	})
+
+	t.Run("rejects other authors", func(t *testing.T) {
+		service, _, _ := newService(&models.Post{ID: 1, AuthorID: 2, Status: "draft"})
+
+		_, err := service.SchedulePost(context.Background(), 1, 1, time.Now().Add(time.Hour))
+		assert.ErrorIs(t, err, ErrUnauthorized)
+	})
+}
+
+func TestPublishScheduledPost(t *testing.T) {
+	t.Run("publishes a due post with its event", func(t *testing.T) {
+		mockPostRepo := new(MockPostRepository)
+		mockCache := new(MockCacheService)
+		mockMQ := new(MockMessageQueue)
+		service := NewPostService(mockPostRepo, passthroughTransactor{}, mockCache, new(MockContentModerationService), mockMQ, nil, nil)
+
+		mockPostRepo.On("PublishScheduled", mock.Anything, int64(1), mock.Anything).Return(true, nil)
+		mockPostRepo.On("FindByID", mock.Anything, int64(1)).Return(&models.Post{ID: 1, AuthorID: 1, Status: "published"}, nil)
+		mockMQ.On("Publish", mock.Anything, mq.TopicPostPublished, mock.Anything).Return(nil)
+		mockCache.On("Delete", mock.Anything, cache.PostKey(1)).Return(nil)
+
+		assert.NoError(t, service.PublishScheduledPost(context.Background(), 1))
+		mockMQ.AssertExpectations(t)
+		mockCache.AssertExpectations(t)
+	})
+
+	t.Run("leaves posts that are not due alone", func(t *testing.T) {
+		mockPostRepo := new(MockPostRepository)
+		mockMQ := new(MockMessageQueue)
+		service := NewPostService(mockPostRepo, passthroughTransactor{}, new(MockCacheService), new(MockContentModerationService), mockMQ, nil, nil)
+
+		mockPostRepo.On("PublishScheduled", mock.Anything, int64(1), mock.Anything).Return(false, nil)
+
+		assert.NoError(t, service.PublishScheduledPost(context.Background(), 1))
+		mockMQ.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
 	"github.com/kobayashirei/airy/internal/mq"
@@ -40,12 +39,7 @@ func NewSearchConsumer(
 }
 
 // HandlePostPublished handles post published events
-func (c *SearchConsumer) HandlePostPublished(ctx context.Context, message []byte) error {
-	var event mq.PostPublishedEvent
-	if err := json.Unmarshal(message, &event); err != nil {
-		return fmt.Errorf("failed to unmarshal post published event: %w", err)
-	}
-
+func (c *SearchConsumer) HandlePostPublished(ctx context.Context, event *mq.PostPublishedEvent) error {
 	c.log.Info("Processing post published event", zap.Int64("post_id", event.PostID))
 
 	// Get post from database
@@ -63,13 +57,21 @@ func (c *SearchConsumer) HandlePostPublished(ctx context.Context, message []byte
 	return nil
 }
 
-// HandlePostUpdated handles post updated events
-func (c *SearchConsumer) HandlePostUpdated(ctx context.Context, message []byte) error {
-	var event mq.PostUpdatedEvent
-	if err := json.Unmarshal(message, &event); err != nil {
-		return fmt.Errorf("failed to unmarshal post updated event: %w", err)
+// ReindexPost writes a post's current state from the database to the search index
+func (c *SearchConsumer) ReindexPost(ctx context.Context, postID int64) error {
+	post, err := c.postRepo.FindByID(ctx, postID)
+	if err != nil {
+		return fmt.Errorf("failed to get post %d: %w", postID, err)
 	}
 
+	if err := c.searchService.IndexPost(ctx, post); err != nil {
+		return fmt.Errorf("failed to index post %d: %w", postID, err)
+	}
+	return nil
+}
+
+// HandlePostUpdated handles post updated events
+func (c *SearchConsumer) HandlePostUpdated(ctx context.Context, event *mq.PostUpdatedEvent) error {
 	c.log.Info("Processing post updated event", zap.Int64("post_id", event.PostID))
 
 	// Get post from database
@@ -88,12 +90,7 @@ func (c *SearchConsumer) HandlePostUpdated(ctx context.Context, message []byte)
 }
 
 // HandlePostDeleted handles post deleted events
-func (c *SearchConsumer) HandlePostDeleted(ctx context.Context, message []byte) error {
-	var event mq.PostDeletedEvent
-	if err := json.Unmarshal(message, &event); err != nil {
-		return fmt.Errorf("failed to unmarshal post deleted event: %w", err)
-	}
-
+func (c *SearchConsumer) HandlePostDeleted(ctx context.Context, event *mq.PostDeletedEvent) error {
 	c.log.Info("Processing post deleted event", zap.Int64("post_id", event.PostID))
 
 	// Delete post from Elasticsearch
@@ -106,12 +103,7 @@ func (c *SearchConsumer) HandlePostDeleted(ctx context.Context, message []byte)
 }
 
 // HandleUserRegistered handles user registered events
-func (c *SearchConsumer) HandleUserRegistered(ctx context.Context, message []byte) error {
-	var event mq.UserRegisteredEvent
-	if err := json.Unmarshal(message, &event); err != nil {
-		return fmt.Errorf("failed to unmarshal user registered event: %w", err)
-	}
-
+func (c *SearchConsumer) HandleUserRegistered(ctx context.Context, event *mq.UserRegisteredEvent) error {
 	c.log.Info("Processing user registered event", zap.Int64("user_id", event.UserID))
 
 	// Get user from database
@@ -143,23 +135,26 @@ func (c *SearchConsumer) HandleUserRegistered(ctx context.Context, message []byt
 	return nil
 }
 
+// SearchConsumerGroup is the consumer group the search indexer subscribes with
+const SearchConsumerGroup = "search"
+
 // Subscribe subscribes to all search-related events
 func (c *SearchConsumer) Subscribe(messageQueue mq.MessageQueue) error {
 	// Subscribe to post events
-	if err := messageQueue.Subscribe(mq.TopicPostPublished, c.HandlePostPublished); err != nil {
+	if err := mq.SubscribeGroupEvents(messageQueue, SearchConsumerGroup, mq.TopicPostPublished, c.HandlePostPublished); err != nil {
 		return fmt.Errorf("failed to subscribe to post published events: %w", err)
 	}
 
-	if err := messageQueue.Subscribe(mq.TopicPostUpdated, c.HandlePostUpdated); err != nil {
+	if err := mq.SubscribeGroupEvents(messageQueue, SearchConsumerGroup, mq.TopicPostUpdated, c.HandlePostUpdated); err != nil {
 		return fmt.Errorf("failed to subscribe to post updated events: %w", err)
 	}
 
-	if err := messageQueue.Subscribe(mq.TopicPostDeleted, c.HandlePostDeleted); err != nil {
+	if err := mq.SubscribeGroupEvents(messageQueue, SearchConsumerGroup, mq.TopicPostDeleted, c.HandlePostDeleted); err != nil {
 		return fmt.Errorf("failed to subscribe to post deleted events: %w", err)
 	}
 
 	// Subscribe to user events
-	if err := messageQueue.Subscribe(mq.TopicUserRegistered, c.HandleUserRegistered); err != nil {
+	if err := mq.SubscribeGroupEvents(messageQueue, SearchConsumerGroup, mq.TopicUserRegistered, c.HandleUserRegistered); err != nil {
 		return fmt.Errorf("failed to subscribe to user registered events: %w", err)
 	}
 
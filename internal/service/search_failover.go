@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kobayashirei/airy/internal/models"
+)
+
+// Circuit breaker states
+const (
+	// SearchBreakerClosed sends searches to the primary backend
+	SearchBreakerClosed = "closed"
+	// SearchBreakerOpen sends searches to the fallback backend until the primary is health checked again
+	SearchBreakerOpen = "open"
+)
+
+// SearchBreakerOptions configure when searches fail over from the primary backend
+type SearchBreakerOptions struct {
+	Failures      int           // Consecutive failed searches that open the breaker; default 3
+	OpenDuration  time.Duration // How long the breaker stays open before the primary is health checked; default 30s
+	HealthTimeout time.Duration // How long a health check may take; default 2s
+}
+
+// SearchStatus reports which backend serves searches
+type SearchStatus struct {
+	Backend  string     `json:"backend"`            // The backend searches go to now
+	Primary  string     `json:"primary,omitempty"`  // The configured backend
+	Fallback string     `json:"fallback,omitempty"` // Where searches go while the primary is unavailable
+	Breaker  string     `json:"breaker,omitempty"`  // Empty without a fallback
+	OpenedAt *time.Time `json:"opened_at,omitempty"`
+}
+
+// searchService sends searches to the primary backend and fails over to the fallback
+// while the primary is unavailable. A circuit breaker opens when the primary fails a
+// health check after a failed search, or fails several searches in a row, and sends
+// searches straight to the fallback. Once open long enough, the next search health
+// checks the primary and closes the breaker if it recovered.
+//
+// Indexing always goes to the primary: the MySQL fallback needs none, and indexing
+// that fails is retried by the search consumer.
+type searchService struct {
+	primary  SearchBackend
+	fallback SearchBackend
+	opts     SearchBreakerOptions
+	log      *zap.Logger
+	now      func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	openedAt *time.Time
+	retryAt  time.Time
+	probing  bool
+}
+
+// NewSearchService creates a search service on the primary backend, failing over to
+// the fallback when one is given. Without a primary, the fallback serves every search.
+func NewSearchService(primary, fallback SearchBackend, opts SearchBreakerOptions, log *zap.Logger) SearchService {
+	if opts.Failures <= 0 {
+		opts.Failures = 3
+	}
+	if opts.OpenDuration <= 0 {
+		opts.OpenDuration = 30 * time.Second
+	}
+	if opts.HealthTimeout <= 0 {
+		opts.HealthTimeout = 2 * time.Second
+	}
+	if log == nil {
+		log = zap.NewNop()
+	}
+	if primary == nil {
+		primary, fallback = fallback, nil
+	}
+	return &searchService{
+		primary:  primary,
+		fallback: fallback,
+		opts:     opts,
+		log:      log,
+		now:      time.Now,
+	}
+}
+
+// SearchPosts searches posts on the primary backend, or the fallback while it is unavailable
+func (s *searchService) SearchPosts(ctx context.Context, query SearchQuery) (*SearchResult, error) {
+	return s.search(ctx, func(backend SearchBackend) (*SearchResult, error) {
+		return backend.SearchPosts(ctx, query)
+	})
+}
+
+// SearchUsers searches users on the primary backend, or the fallback while it is unavailable
+func (s *searchService) SearchUsers(ctx context.Context, query SearchQuery) (*SearchResult, error) {
+	return s.search(ctx, func(backend SearchBackend) (*SearchResult, error) {
+		return backend.SearchUsers(ctx, query)
+	})
+}
+
+// search runs a search on the backend the breaker allows, failing over when the primary fails
+func (s *searchService) search(ctx context.Context, run func(SearchBackend) (*SearchResult, error)) (*SearchResult, error) {
+	if s.primary == nil {
+		return nil, ErrSearchUnavailable
+	}
+	if s.fallback == nil {
+		return run(s.primary)
+	}
+
+	if !s.allowPrimary(ctx) {
+		return run(s.fallback)
+	}
+
+	result, err := run(s.primary)
+	if err == nil {
+		s.recordSuccess()
+		return result, nil
+	}
+	if ctx.Err() != nil {
+		// The caller gave up; that says nothing about the primary
+		return nil, err
+	}
+
+	s.log.Warn("Search failed, failing over",
+		zap.String("backend", s.primary.Name()),
+		zap.String("fallback", s.fallback.Name()),
+		zap.Error(err),
+	)
+	s.recordFailure(ctx)
+	return run(s.fallback)
+}
+
+// allowPrimary reports whether a search may go to the primary. While the breaker is
+// open it may not, except once it has been open long enough: then one search health
+// checks the primary, closing the breaker if it passes and keeping it open otherwise.
+func (s *searchService) allowPrimary(ctx context.Context) bool {
+	s.mu.Lock()
+	if s.openedAt == nil {
+		s.mu.Unlock()
+		return true
+	}
+	if s.probing || s.now().Before(s.retryAt) {
+		s.mu.Unlock()
+		return false
+	}
+	s.probing = true
+	s.mu.Unlock()
+
+	err := s.checkHealth(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.probing = false
+	if err != nil {
+		s.retryAt = s.now().Add(s.opts.OpenDuration)
+		s.log.Warn("Search backend still unhealthy", zap.String("backend", s.primary.Name()), zap.Error(err))
+		return false
+	}
+	s.log.Info("Search backend recovered, closing the circuit breaker",
+		zap.String("backend", s.primary.Name()),
+		zap.Duration("open_for", s.now().Sub(*s.openedAt)),
+	)
+	s.openedAt = nil
+	s.failures = 0
+	return true
+}
+
+// recordSuccess resets the count of consecutive failures
+func (s *searchService) recordSuccess() {
+	s.mu.Lock()
+	s.failures = 0
+	s.mu.Unlock()
+}
+
+// recordFailure counts a failed search and opens the breaker once the primary fails
+// its health check, or has failed too many searches in a row
+func (s *searchService) recordFailure(ctx context.Context) {
+	healthErr := s.checkHealth(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.openedAt != nil {
+		return
+	}
+	s.failures++
+	if healthErr == nil && s.failures < s.opts.Failures {
+		return
+	}
+
+	now := s.now()
+	s.openedAt = &now
+	s.retryAt = now.Add(s.opts.OpenDuration)
+	s.log.Error("Opening the search circuit breaker",
+		zap.String("backend", s.primary.Name()),
+		zap.String("fallback", s.fallback.Name()),
+		zap.Int("failures", s.failures),
+		zap.NamedError("health", healthErr),
+		zap.Duration("retry_in", s.opts.OpenDuration),
+	)
+}
+
+// checkHealth health checks the primary within the health timeout
+func (s *searchService) checkHealth(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, s.opts.HealthTimeout)
+	defer cancel()
+	return s.primary.Health(ctx)
+}
+
+// Status reports which backend serves searches and the state of the breaker
+func (s *searchService) Status() SearchStatus {
+	if s.primary == nil {
+		return SearchStatus{}
+	}
+	status := SearchStatus{Backend: s.primary.Name(), Primary: s.primary.Name()}
+	if s.fallback == nil {
+		return status
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status.Fallback = s.fallback.Name()
+	status.Breaker = SearchBreakerClosed
+	if s.openedAt != nil {
+		openedAt := *s.openedAt
+		status.Backend = s.fallback.Name()
+		status.Breaker = SearchBreakerOpen
+		status.OpenedAt = &openedAt
+	}
+	return status
+}
+
+// InitializeIndices initializes the indices of the primary backend
+func (s *searchService) InitializeIndices(ctx context.Context) error {
+	if s.primary == nil {
+		return ErrSearchUnavailable
+	}
+	return s.primary.InitializeIndices(ctx)
+}
+
+// IndexPost indexes a post in the primary backend
+func (s *searchService) IndexPost(ctx context.Context, post *models.Post) error {
+	if s.primary == nil {
+		return ErrSearchUnavailable
+	}
+	return s.primary.IndexPost(ctx, post)
+}
+
+// UpdatePost updates a post in the primary backend
+func (s *searchService) UpdatePost(ctx context.Context, postID int64, post *models.Post) error {
+	if s.primary == nil {
+		return ErrSearchUnavailable
+	}
+	return s.primary.UpdatePost(ctx, postID, post)
+}
+
+// DeletePost deletes a post from the primary backend
+func (s *searchService) DeletePost(ctx context.Context, postID int64) error {
+	if s.primary == nil {
+		return ErrSearchUnavailable
+	}
+	return s.primary.DeletePost(ctx, postID)
+}
+
+// IndexUser indexes a user in the primary backend
+func (s *searchService) IndexUser(ctx context.Context, user *models.User, profile *models.UserProfile, stats *models.UserStats) error {
+	if s.primary == nil {
+		return ErrSearchUnavailable
+	}
+	return s.primary.IndexUser(ctx, user, profile, stats)
+}
+
+// UpdateUser updates a user in the primary backend
+func (s *searchService) UpdateUser(ctx context.Context, userID int64, user *models.User, profile *models.UserProfile, stats *models.UserStats) error {
+	if s.primary == nil {
+		return ErrSearchUnavailable
+	}
+	return s.primary.UpdateUser(ctx, userID, user, profile, stats)
+}
+
+// DeleteUser deletes a user from the primary backend
+func (s *searchService) DeleteUser(ctx context.Context, userID int64) error {
+	if s.primary == nil {
+		return ErrSearchUnavailable
+	}
+	return s.primary.DeleteUser(ctx, userID)
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kobayashirei/airy/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSearchBackend answers every search with one result naming the backend
+type fakeSearchBackend struct {
+	name      string
+	searchErr error
+	healthErr error
+	searches  int
+	checks    int
+	indexed   int
+}
+
+func (b *fakeSearchBackend) Name() string { return b.name }
+
+func (b *fakeSearchBackend) Health(ctx context.Context) error {
+	b.checks++
+	return b.healthErr
+}
+
+func (b *fakeSearchBackend) search() (*SearchResult, error) {
+	b.searches++
+	if b.searchErr != nil {
+		return nil, b.searchErr
+	}
+	return &SearchResult{Total: 1, Results: []SearchResultItem{{Data: map[string]interface{}{"backend": b.name}}}}, nil
+}
+
+func (b *fakeSearchBackend) SearchPosts(ctx context.Context, query SearchQuery) (*SearchResult, error) {
+	return b.search()
+}
+
+func (b *fakeSearchBackend) SearchUsers(ctx context.Context, query SearchQuery) (*SearchResult, error) {
+	return b.search()
+}
+
+func (b *fakeSearchBackend) IndexPost(ctx context.Context, post *models.Post) error {
+	b.indexed++
+	return nil
+}
+
+func (b *fakeSearchBackend) UpdatePost(ctx context.Context, postID int64, post *models.Post) error {
+	return nil
+}
+
+func (b *fakeSearchBackend) DeletePost(ctx context.Context, postID int64) error { return nil }
+
+func (b *fakeSearchBackend) IndexUser(ctx context.Context, user *models.User, profile *models.UserProfile, stats *models.UserStats) error {
+	return nil
+}
+
+func (b *fakeSearchBackend) UpdateUser(ctx context.Context, userID int64, user *models.User, profile *models.UserProfile, stats *models.UserStats) error {
+	return nil
+}
+
+func (b *fakeSearchBackend) DeleteUser(ctx context.Context, userID int64) error { return nil }
+
+func (b *fakeSearchBackend) InitializeIndices(ctx context.Context) error { return nil }
+
+// servedBy returns a function that returns which backend answered a search
+func servedBy(t *testing.T) func(*SearchResult, error) string {
+	return func(result *SearchResult, err error) string {
+		require.NoError(t, err)
+		require.Len(t, result.Results, 1)
+		return result.Results[0].Data["backend"].(string)
+	}
+}
+
+func TestSearchService_Failover(t *testing.T) {
+	ctx := context.Background()
+	served := servedBy(t)
+	primary := &fakeSearchBackend{name: "elasticsearch"}
+	fallback := &fakeSearchBackend{name: "mysql"}
+	svc := NewSearchService(primary, fallback, SearchBreakerOptions{Failures: 2, OpenDuration: time.Minute}, nil).(*searchService)
+	now := time.Now()
+	svc.now = func() time.Time { return now }
+
+	assert.Equal(t, "elasticsearch", served(svc.SearchPosts(ctx, SearchQuery{})))
+	assert.Equal(t, SearchStatus{Backend: "elasticsearch", Primary: "elasticsearch", Fallback: "mysql", Breaker: SearchBreakerClosed}, svc.Status())
+
+	// A failed search on a healthy cluster fails over but keeps the breaker closed
+	primary.searchErr = errors.New("query timed out")
+	assert.Equal(t, "mysql", served(svc.SearchPosts(ctx, SearchQuery{})))
+	assert.Equal(t, SearchBreakerClosed, svc.Status().Breaker)
+
+	// A success resets the count, so it takes two failures in a row to open
+	primary.searchErr = nil
+	served(svc.SearchUsers(ctx, SearchQuery{}))
+	primary.searchErr = errors.New("query timed out")
+	served(svc.SearchPosts(ctx, SearchQuery{}))
+	assert.Equal(t, SearchBreakerClosed, svc.Status().Breaker)
+	served(svc.SearchPosts(ctx, SearchQuery{}))
+	assert.Equal(t, SearchBreakerOpen, svc.Status().Breaker)
+	assert.Equal(t, "mysql", svc.Status().Backend)
+
+	// While open, searches skip the primary
+	primary.searchErr = nil
+	searches := primary.searches
+	assert.Equal(t, "mysql", served(svc.SearchPosts(ctx, SearchQuery{})))
+	assert.Equal(t, searches, primary.searches)
+
+	// Once open long enough, a failed health check keeps it open for another while
+	primary.healthErr = errors.New("cluster health is red")
+	now = now.Add(time.Minute)
+	checks := primary.checks
+	assert.Equal(t, "mysql", served(svc.SearchPosts(ctx, SearchQuery{})))
+	assert.Equal(t, checks+1, primary.checks)
+	assert.Equal(t, "mysql", served(svc.SearchPosts(ctx, SearchQuery{})))
+	assert.Equal(t, checks+1, primary.checks)
+
+	// A passing health check closes it
+	primary.healthErr = nil
+	now = now.Add(time.Minute)
+	assert.Equal(t, "elasticsearch", served(svc.SearchPosts(ctx, SearchQuery{})))
+	assert.Equal(t, SearchBreakerClosed, svc.Status().Breaker)
+	assert.Nil(t, svc.Status().OpenedAt)
+
+	// Indexing goes to the primary only
+	require.NoError(t, svc.IndexPost(ctx, &models.Post{ID: 1}))
+	assert.Equal(t, 1, primary.indexed)
+	assert.Zero(t, fallback.indexed)
+}
+
+func TestSearchService_UnhealthyOpensAtOnce(t *testing.T) {
+	ctx := context.Background()
+	served := servedBy(t)
+	primary := &fakeSearchBackend{name: "elasticsearch", searchErr: errors.New("connection refused"), healthErr: errors.New("connection refused")}
+	fallback := &fakeSearchBackend{name: "mysql"}
+	svc := NewSearchService(primary, fallback, SearchBreakerOptions{Failures: 5}, nil)
+
+	assert.Equal(t, "mysql", served(svc.SearchPosts(ctx, SearchQuery{})))
+
+	status := svc.Status()
+	assert.Equal(t, SearchBreakerOpen, status.Breaker)
+	assert.NotNil(t, status.OpenedAt)
+}
+
+func TestSearchService_SingleBackend(t *testing.T) {
+	ctx := context.Background()
+	served := servedBy(t)
+
+	// Without Elasticsearch the fallback serves every search
+	fallback := &fakeSearchBackend{name: "mysql"}
+	svc := NewSearchService(nil, fallback, SearchBreakerOptions{}, nil)
+	assert.Equal(t, "mysql", served(svc.SearchPosts(ctx, SearchQuery{})))
+	assert.Equal(t, SearchStatus{Backend: "mysql", Primary: "mysql"}, svc.Status())
+
+	// Without a fallback errors are returned
+	primary := &fakeSearchBackend{name: "elasticsearch", searchErr: errors.New("connection refused")}
+	svc = NewSearchService(primary, nil, SearchBreakerOptions{}, nil)
+	_, err := svc.SearchPosts(ctx, SearchQuery{})
+	assert.EqualError(t, err, "connection refused")
+
+	// Without any backend search is unavailable
+	svc = NewSearchService(nil, nil, SearchBreakerOptions{}, nil)
+	_, err = svc.SearchUsers(ctx, SearchQuery{})
+	assert.ErrorIs(t, err, ErrSearchUnavailable)
+	assert.ErrorIs(t, svc.IndexPost(ctx, &models.Post{}), ErrSearchUnavailable)
+}
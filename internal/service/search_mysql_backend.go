@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+
+	"github.com/kobayashirei/airy/internal/config"
+	"github.com/kobayashirei/airy/internal/models"
+	"github.com/kobayashirei/airy/internal/repository"
+)
+
+// mysqlSearchBackend searches the posts and users tables directly. The tables are
+// their own index, so indexing is a no-op. Results carry the same documents as
+// Elasticsearch's, so callers cannot tell the backends apart.
+type mysqlSearchBackend struct {
+	searchRepo repository.SearchRepository
+	batchRepo  repository.BatchRepository
+	fullText   atomic.Bool // Turned off when the FULLTEXT indexes turn out to be missing
+	log        *zap.Logger
+}
+
+// NewMySQLSearchBackend creates a search backend on MySQL. With fullText, keywords
+// are matched with the FULLTEXT indexes of migration 000015 while they exist;
+// otherwise only with LIKE.
+func NewMySQLSearchBackend(
+	searchRepo repository.SearchRepository,
+	batchRepo repository.BatchRepository,
+	fullText bool,
+	log *zap.Logger,
+) SearchBackend {
+	if log == nil {
+		log = zap.NewNop()
+	}
+	backend := &mysqlSearchBackend{
+		searchRepo: searchRepo,
+		batchRepo:  batchRepo,
+		log:        log,
+	}
+	backend.fullText.Store(fullText)
+	return backend
+}
+
+// Name names the backend
+func (s *mysqlSearchBackend) Name() string {
+	return config.SearchBackendMySQL
+}
+
+// Health is always healthy; searches run on the primary database, which the server
+// cannot serve without anyway
+func (s *mysqlSearchBackend) Health(ctx context.Context) error {
+	return nil
+}
+
+// InitializeIndices has no indices to create
+func (s *mysqlSearchBackend) InitializeIndices(ctx context.Context) error {
+	return nil
+}
+
+// IndexPost is a no-op; posts are searched where they are stored
+func (s *mysqlSearchBackend) IndexPost(ctx context.Context, post *models.Post) error {
+	return nil
+}
+
+// UpdatePost is a no-op; posts are searched where they are stored
+func (s *mysqlSearchBackend) UpdatePost(ctx context.Context, postID int64, post *models.Post) error {
+	return nil
+}
+
+// DeletePost is a no-op; posts are searched where they are stored
+func (s *mysqlSearchBackend) DeletePost(ctx context.Context, postID int64) error {
+	return nil
+}
+
+// IndexUser is a no-op; users are searched where they are stored
+func (s *mysqlSearchBackend) IndexUser(ctx context.Context, user *models.User, profile *models.UserProfile, stats *models.UserStats) error {
+	return nil
+}
+
+// UpdateUser is a no-op; users are searched where they are stored
+func (s *mysqlSearchBackend) UpdateUser(ctx context.Context, userID int64, user *models.User, profile *models.UserProfile, stats *models.UserStats) error {
+	return nil
+}
+
+// DeleteUser is a no-op; users are searched where they are stored
+func (s *mysqlSearchBackend) DeleteUser(ctx context.Context, userID int64) error {
+	return nil
+}
+
+// SearchPosts searches published posts with the same filters and sorts as Elasticsearch
+func (s *mysqlSearchBackend) SearchPosts(ctx context.Context, query SearchQuery) (*SearchResult, error) {
+	query.normalize()
+
+	opts := repository.SearchOptions{
+		Keyword:  query.Keyword,
+		CircleID: query.CircleID,
+		Tags:     query.Tags,
+		SortBy:   query.SortBy,
+		FullText: s.fullText.Load(),
+		Limit:    query.PageSize,
+		Offset:   (query.Page - 1) * query.PageSize,
+	}
+	hits, total, err := s.searchRepo.SearchPosts(ctx, opts)
+	if s.retryWithLike(err, &opts) {
+		hits, total, err = s.searchRepo.SearchPosts(ctx, opts)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to search posts: %w", err)
+	}
+
+	authorIDs := make([]int64, 0, len(hits))
+	var circleIDs []int64
+	for _, hit := range hits {
+		authorIDs = append(authorIDs, hit.AuthorID)
+		if hit.CircleID != nil {
+			circleIDs = append(circleIDs, *hit.CircleID)
+		}
+	}
+	authors, err := s.batchRepo.FindUsersByIDs(ctx, authorIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find authors: %w", err)
+	}
+	circles, err := s.batchRepo.FindCirclesByIDs(ctx, circleIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find circles: %w", err)
+	}
+
+	results := make([]SearchResultItem, 0, len(hits))
+	for _, hit := range hits {
+		var authorUsername, circleName string
+		if author, ok := authors[hit.AuthorID]; ok {
+			authorUsername = author.Username
+		}
+		if hit.CircleID != nil {
+			if circle, ok := circles[*hit.CircleID]; ok {
+				circleName = circle.Name
+			}
+		}
+		data, err := sourceDocument(postDocument(&hit.Post, authorUsername, circleName, s.log))
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, SearchResultItem{ID: hit.ID, Score: hit.Score, Data: data})
+	}
+
+	return &SearchResult{
+		Total:   total,
+		Results: results,
+	}, nil
+}
+
+// SearchUsers searches active users, by relevance and then follower count as Elasticsearch does
+func (s *mysqlSearchBackend) SearchUsers(ctx context.Context, query SearchQuery) (*SearchResult, error) {
+	query.normalize()
+
+	opts := repository.SearchOptions{
+		Keyword:  query.Keyword,
+		FullText: s.fullText.Load(),
+		Limit:    query.PageSize,
+		Offset:   (query.Page - 1) * query.PageSize,
+	}
+	hits, total, err := s.searchRepo.SearchUsers(ctx, opts)
+	if s.retryWithLike(err, &opts) {
+		hits, total, err = s.searchRepo.SearchUsers(ctx, opts)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to search users: %w", err)
+	}
+
+	ids := make([]int64, len(hits))
+	for i, hit := range hits {
+		ids[i] = hit.ID
+	}
+	profiles, err := s.batchRepo.FindUserProfilesByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user profiles: %w", err)
+	}
+	stats, err := s.batchRepo.FindUserStatsByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user stats: %w", err)
+	}
+
+	results := make([]SearchResultItem, 0, len(hits))
+	for _, hit := range hits {
+		data, err := sourceDocument(userDocument(&hit.User, profiles[hit.ID], stats[hit.ID]))
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, SearchResultItem{ID: hit.ID, Score: hit.Score, Data: data})
+	}
+
+	return &SearchResult{
+		Total:   total,
+		Results: results,
+	}, nil
+}
+
+// retryWithLike reports whether a search failed for want of the FULLTEXT indexes, and
+// then switches it and every later search to LIKE
+func (s *mysqlSearchBackend) retryWithLike(err error, opts *repository.SearchOptions) bool {
+	if !opts.FullText || !repository.IsMissingFullTextIndex(err) {
+		return false
+	}
+	if s.fullText.Swap(false) {
+		s.log.Warn("FULLTEXT search indexes are missing, matching keywords with LIKE; apply migration 000015 to use them")
+	}
+	opts.FullText = false
+	return true
+}
+
+// sourceDocument round-trips a document through JSON, so its values have the types
+// of an Elasticsearch hit's source: numbers as float64, times as strings
+func sourceDocument(doc map[string]interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode search document: %w", err)
+	}
+	var source map[string]interface{}
+	if err := json.Unmarshal(data, &source); err != nil {
+		return nil, fmt.Errorf("failed to decode search document: %w", err)
+	}
+	return source, nil
+}
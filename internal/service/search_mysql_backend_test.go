@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/kobayashirei/airy/internal/models"
+	"github.com/kobayashirei/airy/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLSearchBackend_SearchPosts(t *testing.T) {
+	ctx := context.Background()
+	searchRepo := new(MockSearchRepository)
+	batchRepo := new(MockBatchRepository)
+	backend := NewMySQLSearchBackend(searchRepo, batchRepo, true, nil)
+
+	circleID := int64(4)
+	publishedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	hits := []*repository.PostSearchHit{{
+		Post: models.Post{
+			ID: 9, AuthorID: 7, CircleID: &circleID, Title: "Go tips", Status: "published",
+			Tags: `["go","tips"]`, ViewCount: 12, HotnessScore: 1.5, PublishedAt: &publishedAt,
+		},
+		Score: 2.5,
+	}}
+	searchRepo.On("SearchPosts", mock.Anything, repository.SearchOptions{
+		Keyword: "go", CircleID: &circleID, Tags: []string{"go"}, SortBy: "hotness", FullText: true, Limit: 10, Offset: 10,
+	}).Return(hits, int64(11), nil)
+	batchRepo.On("FindUsersByIDs", mock.Anything, []int64{7}).Return(map[int64]*models.User{7: {ID: 7, Username: "alice"}}, nil)
+	batchRepo.On("FindCirclesByIDs", mock.Anything, []int64{4}).Return(map[int64]*models.Circle{4: {ID: 4, Name: "Gophers"}}, nil)
+
+	result, err := backend.SearchPosts(ctx, SearchQuery{
+		Keyword: "go", CircleID: &circleID, Tags: []string{"go"}, SortBy: "hotness", Page: 2, PageSize: 10,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), result.Total)
+	require.Len(t, result.Results, 1)
+	item := result.Results[0]
+	assert.Equal(t, int64(9), item.ID)
+	assert.Equal(t, 2.5, item.Score)
+	// Documents read like Elasticsearch sources
+	assert.Equal(t, "alice", item.Data["author_username"])
+	assert.Equal(t, "Gophers", item.Data["circle_name"])
+	assert.Equal(t, float64(4), item.Data["circle_id"])
+	assert.Equal(t, float64(12), item.Data["view_count"])
+	assert.Equal(t, []interface{}{"go", "tips"}, item.Data["tags"])
+	assert.Equal(t, "2026-10-01T12:00:00Z", item.Data["published_at"])
+}
+
+func TestMySQLSearchBackend_FallsBackToLike(t *testing.T) {
+	ctx := context.Background()
+	searchRepo := new(MockSearchRepository)
+	batchRepo := new(MockBatchRepository)
+	backend := NewMySQLSearchBackend(searchRepo, batchRepo, true, nil)
+
+	noIndex := fmt.Errorf("query failed: %w", &mysql.MySQLError{Number: 1191, Message: "Can't find FULLTEXT index matching the column list"})
+	searchRepo.On("SearchUsers", mock.Anything, mock.MatchedBy(func(opts repository.SearchOptions) bool { return opts.FullText })).
+		Return(nil, int64(0), noIndex).Once()
+	searchRepo.On("SearchUsers", mock.Anything, mock.MatchedBy(func(opts repository.SearchOptions) bool {
+		return !opts.FullText && opts.Keyword == "alice" && opts.Limit == 20 && opts.Offset == 0
+	})).Return([]*repository.UserSearchHit{{User: models.User{ID: 7, Username: "alice"}, Score: 3}}, int64(1), nil)
+	batchRepo.On("FindUserProfilesByIDs", mock.Anything, []int64{7}).Return(map[int64]*models.UserProfile{7: {UserID: 7, FollowerCount: 5}}, nil)
+	batchRepo.On("FindUserStatsByIDs", mock.Anything, []int64{7}).Return(map[int64]*models.UserStats{}, nil)
+
+	for i := 0; i < 2; i++ {
+		result, err := backend.SearchUsers(ctx, SearchQuery{Keyword: "alice"})
+
+		require.NoError(t, err)
+		require.Len(t, result.Results, 1)
+		assert.Equal(t, "alice", result.Results[0].Data["username"])
+		assert.Equal(t, float64(5), result.Results[0].Data["follower_count"])
+	}
+	// Later searches go straight to LIKE
+	searchRepo.AssertNumberOfCalls(t, "SearchUsers", 3)
+}
@@ -0,0 +1,490 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kobayashirei/airy/internal/models"
+	"github.com/kobayashirei/airy/internal/repository"
+	"github.com/kobayashirei/airy/internal/search"
+)
+
+// ErrUnknownSearchIndex is returned when reindexing a collection that does not exist
+var ErrUnknownSearchIndex = errors.New("unknown search index")
+
+// defaultReindexBatchSize is how many rows are read and bulk indexed at a time
+const defaultReindexBatchSize = 500
+
+// reindexCatchUpMargin widens the catch-up windows for clock skew between the
+// application servers and for transactions that committed late
+const reindexCatchUpMargin = time.Minute
+
+// Reindex phases, in order
+const (
+	// ReindexPhaseCopying copies every searchable row into the new index by ID
+	ReindexPhaseCopying = "copying"
+	// ReindexPhaseCatchingUp applies the rows changed while copying, then swaps the aliases
+	ReindexPhaseCatchingUp = "catching_up"
+	// ReindexPhaseSwapped applies the rows changed between catching up and the swap
+	ReindexPhaseSwapped = "swapped"
+	// ReindexPhaseDone marks a finished reindex
+	ReindexPhaseDone = "done"
+)
+
+// ReindexClient is the part of the search client a reindex uses
+type ReindexClient interface {
+	Versions(ctx context.Context, spec search.IndexSpec) (*search.IndexVersions, error)
+	CreateVersion(ctx context.Context, spec search.IndexSpec) (string, error)
+	FinishVersion(ctx context.Context, index string) error
+	SwapAliases(ctx context.Context, spec search.IndexSpec, index string, versions *search.IndexVersions) error
+	DeleteIndex(ctx context.Context, index string) error
+	IndexExists(ctx context.Context, indexName string) (bool, error)
+	Bulk(ctx context.Context, indexName string, operations []search.BulkOperation) error
+	Get(ctx context.Context, indexName, documentID string, out interface{}) (bool, error)
+	Index(ctx context.Context, indexName, documentID string, document interface{}) error
+}
+
+// ReindexState is the progress of a collection's reindex. It is saved in the search
+// cluster after every batch, so an interrupted reindex resumes where it stopped.
+type ReindexState struct {
+	Alias      string     `json:"alias"`
+	Index      string     `json:"index"` // The physical index being built
+	Phase      string     `json:"phase"`
+	LastID     int64      `json:"last_id"` // The last row copied
+	Copied     int64      `json:"copied"`
+	Total      int64      `json:"total"` // Searchable rows when the reindex started
+	StartedAt  time.Time  `json:"started_at"`
+	CaughtUpAt *time.Time `json:"caught_up_at,omitempty"` // When catching up before the swap began
+	UpdatedAt  time.Time  `json:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// ReindexOptions configure a reindex
+type ReindexOptions struct {
+	BatchSize int  // Rows per bulk request; default 500
+	Restart   bool // Discards an unfinished reindex that has not swapped yet, and its index
+	DeleteOld bool // Deletes the replaced indices after the swap instead of keeping them for a rollback
+	// Progress is called after every batch; nil logs the progress
+	Progress func(state ReindexState, rate float64, eta time.Duration)
+}
+
+// reindexSource reads one collection's rows from MySQL as bulk operations
+type reindexSource interface {
+	// count counts the searchable rows
+	count(ctx context.Context) (int64, error)
+	// searchable indexes up to limit searchable rows with IDs above afterID, by ID,
+	// and returns the last row's ID
+	searchable(ctx context.Context, afterID int64, limit int) ([]search.BulkOperation, int64, error)
+	// updatedSince indexes the searchable ones of up to limit rows updated since the
+	// given time with IDs above afterID, by ID, deletes the others, and returns the last row's ID
+	updatedSince(ctx context.Context, since time.Time, afterID int64, limit int) ([]search.BulkOperation, int64, error)
+}
+
+// SearchReindexer rebuilds search collections from MySQL without downtime: it bulk
+// loads a new versioned index while searches and writes keep using the live one,
+// catches up on the rows changed meanwhile, and swaps both aliases atomically.
+type SearchReindexer struct {
+	client  ReindexClient
+	sources map[string]reindexSource
+	log     *zap.Logger
+}
+
+// NewSearchReindexer creates a reindexer of the posts and users collections
+func NewSearchReindexer(
+	client ReindexClient,
+	postRepo repository.PostRepository,
+	userRepo repository.UserRepository,
+	batchRepo repository.BatchRepository,
+	log *zap.Logger,
+) *SearchReindexer {
+	if log == nil {
+		log = zap.NewNop()
+	}
+	return &SearchReindexer{
+		client: client,
+		sources: map[string]reindexSource{
+			search.PostIndex: &postReindexSource{postRepo: postRepo, batchRepo: batchRepo, log: log},
+			search.UserIndex: &userReindexSource{userRepo: userRepo, batchRepo: batchRepo},
+		},
+		log: log,
+	}
+}
+
+// Status returns the state of the last reindex of a collection, nil if it was never reindexed
+func (r *SearchReindexer) Status(ctx context.Context, alias string) (*ReindexState, error) {
+	var state ReindexState
+	found, err := r.client.Get(ctx, search.ReindexStateIndex, alias, &state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load reindex state: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+// Reindex rebuilds a collection, named by its read alias, into a new index and makes
+// it live. An unfinished reindex of the collection is resumed unless opts.Restart.
+func (r *SearchReindexer) Reindex(ctx context.Context, alias string, opts ReindexOptions) (*ReindexState, error) {
+	spec, ok := search.IndexSpecs[alias]
+	source := r.sources[alias]
+	if !ok || source == nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownSearchIndex, alias)
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultReindexBatchSize
+	}
+
+	state, err := r.resume(ctx, alias, opts.Restart)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		if state, err = r.start(ctx, spec, source); err != nil {
+			return nil, err
+		}
+	}
+
+	if state.Phase == ReindexPhaseCopying {
+		if err := r.copyRows(ctx, source, state, opts); err != nil {
+			return state, err
+		}
+		now := time.Now()
+		state.Phase = ReindexPhaseCatchingUp
+		state.CaughtUpAt = &now
+		if err := r.save(ctx, state); err != nil {
+			return state, err
+		}
+	}
+
+	if state.Phase == ReindexPhaseCatchingUp {
+		// The live index kept taking writes while copying; replay the rows they changed
+		if err := r.catchUp(ctx, source, state.Index, state.StartedAt.Add(-reindexCatchUpMargin), opts.BatchSize); err != nil {
+			return state, err
+		}
+		if err := r.client.FinishVersion(ctx, state.Index); err != nil {
+			return state, err
+		}
+		if err := r.client.SwapAliases(ctx, spec, state.Index, nil); err != nil {
+			return state, err
+		}
+		state.Phase = ReindexPhaseSwapped
+		if err := r.save(ctx, state); err != nil {
+			return state, err
+		}
+	}
+
+	if state.Phase == ReindexPhaseSwapped {
+		if opts.DeleteOld {
+			if err := r.deleteOld(ctx, spec, state.Index); err != nil {
+				return state, err
+			}
+		}
+		// Writes went to the old index until the swap; replay the rows changed since catching up
+		if err := r.catchUp(ctx, source, state.Index, state.CaughtUpAt.Add(-reindexCatchUpMargin), opts.BatchSize); err != nil {
+			return state, err
+		}
+		now := time.Now()
+		state.Phase = ReindexPhaseDone
+		state.FinishedAt = &now
+		if err := r.save(ctx, state); err != nil {
+			return state, err
+		}
+	}
+
+	r.log.Info("Reindex finished",
+		zap.String("alias", alias),
+		zap.String("index", state.Index),
+		zap.Int64("copied", state.Copied),
+		zap.Duration("took", state.FinishedAt.Sub(state.StartedAt)),
+	)
+	return state, nil
+}
+
+// resume loads the unfinished reindex of a collection, if any. With restart, one that
+// has not swapped yet is discarded together with its index.
+func (r *SearchReindexer) resume(ctx context.Context, alias string, restart bool) (*ReindexState, error) {
+	state, err := r.Status(ctx, alias)
+	if err != nil || state == nil || state.Phase == ReindexPhaseDone {
+		return nil, err
+	}
+
+	if restart && state.Phase != ReindexPhaseSwapped {
+		r.log.Info("Discarding unfinished reindex", zap.String("alias", alias), zap.String("index", state.Index))
+		return nil, r.client.DeleteIndex(ctx, state.Index)
+	}
+
+	exists, err := r.client.IndexExists(ctx, state.Index)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		r.log.Warn("Index of unfinished reindex is gone, starting over", zap.String("alias", alias), zap.String("index", state.Index))
+		return nil, nil
+	}
+
+	r.log.Info("Resuming reindex",
+		zap.String("alias", alias),
+		zap.String("index", state.Index),
+		zap.String("phase", state.Phase),
+		zap.Int64("last_id", state.LastID),
+	)
+	return state, nil
+}
+
+// start creates the next version's index and records the new reindex
+func (r *SearchReindexer) start(ctx context.Context, spec search.IndexSpec, source reindexSource) (*ReindexState, error) {
+	total, err := source.count(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count rows: %w", err)
+	}
+
+	// Rows changed from here on are caught up after copying
+	startedAt := time.Now()
+	index, err := r.client.CreateVersion(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &ReindexState{
+		Alias:     spec.Alias,
+		Index:     index,
+		Phase:     ReindexPhaseCopying,
+		Total:     total,
+		StartedAt: startedAt,
+	}
+	r.log.Info("Reindex started", zap.String("alias", spec.Alias), zap.String("index", index), zap.Int64("total", total))
+	return state, r.save(ctx, state)
+}
+
+// copyRows bulk indexes the searchable rows after state.LastID into the new index,
+// saving the state and reporting progress after every batch
+func (r *SearchReindexer) copyRows(ctx context.Context, source reindexSource, state *ReindexState, opts ReindexOptions) error {
+	runStart, runCopied := time.Now(), int64(0)
+	for {
+		operations, lastID, err := source.searchable(ctx, state.LastID, opts.BatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to read rows after %d: %w", state.LastID, err)
+		}
+		if len(operations) == 0 {
+			return nil
+		}
+		if err := r.client.Bulk(ctx, state.Index, operations); err != nil {
+			return err
+		}
+
+		state.LastID = lastID
+		state.Copied += int64(len(operations))
+		if err := r.save(ctx, state); err != nil {
+			return err
+		}
+
+		runCopied += int64(len(operations))
+		rate := float64(runCopied) / time.Since(runStart).Seconds()
+		var eta time.Duration
+		if remaining := state.Total - state.Copied; remaining > 0 && rate > 0 {
+			eta = time.Duration(float64(remaining) / rate * float64(time.Second))
+		}
+		r.report(*state, rate, eta, opts.Progress)
+	}
+}
+
+// report passes progress to the callback, or logs it
+func (r *SearchReindexer) report(state ReindexState, rate float64, eta time.Duration, progress func(ReindexState, float64, time.Duration)) {
+	if progress != nil {
+		progress(state, rate, eta)
+		return
+	}
+
+	var percent float64
+	if state.Total > 0 {
+		percent = min(100, float64(state.Copied)*100/float64(state.Total))
+	}
+	r.log.Info("Reindex progress",
+		zap.String("alias", state.Alias),
+		zap.Int64("copied", state.Copied),
+		zap.Int64("total", state.Total),
+		zap.String("percent", fmt.Sprintf("%.1f", percent)),
+		zap.String("rate", fmt.Sprintf("%.0f/s", rate)),
+		zap.Duration("eta", eta.Round(time.Second)),
+	)
+}
+
+// catchUp applies the rows updated since the given time to an index
+func (r *SearchReindexer) catchUp(ctx context.Context, source reindexSource, index string, since time.Time, batchSize int) error {
+	var afterID, applied int64
+	for {
+		operations, lastID, err := source.updatedSince(ctx, since, afterID, batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to read rows updated since %s: %w", since.Format(time.RFC3339), err)
+		}
+		if len(operations) == 0 {
+			break
+		}
+		if err := r.client.Bulk(ctx, index, operations); err != nil {
+			return err
+		}
+		afterID = lastID
+		applied += int64(len(operations))
+	}
+
+	r.log.Info("Reindex caught up", zap.String("index", index), zap.Time("since", since), zap.Int64("rows", applied))
+	return nil
+}
+
+// deleteOld deletes the versions of a collection other than the live index
+func (r *SearchReindexer) deleteOld(ctx context.Context, spec search.IndexSpec, live string) error {
+	versions, err := r.client.Versions(ctx, spec)
+	if err != nil {
+		return err
+	}
+	for _, index := range versions.Indices {
+		if index == live {
+			continue
+		}
+		if err := r.client.DeleteIndex(ctx, index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// save stores the state of a reindex
+func (r *SearchReindexer) save(ctx context.Context, state *ReindexState) error {
+	state.UpdatedAt = time.Now()
+	if err := r.client.Index(ctx, search.ReindexStateIndex, state.Alias, state); err != nil {
+		return fmt.Errorf("failed to save reindex state: %w", err)
+	}
+	return nil
+}
+
+// postReindexSource reads posts; published posts are searchable
+type postReindexSource struct {
+	postRepo  repository.PostRepository
+	batchRepo repository.BatchRepository
+	log       *zap.Logger
+}
+
+func (s *postReindexSource) count(ctx context.Context) (int64, error) {
+	return s.postRepo.Count(ctx, repository.PostListOptions{Status: "published"})
+}
+
+func (s *postReindexSource) searchable(ctx context.Context, afterID int64, limit int) ([]search.BulkOperation, int64, error) {
+	posts, err := s.postRepo.ListByID(ctx, "published", afterID, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.operations(ctx, posts)
+}
+
+func (s *postReindexSource) updatedSince(ctx context.Context, since time.Time, afterID int64, limit int) ([]search.BulkOperation, int64, error) {
+	posts, err := s.postRepo.ListUpdatedSince(ctx, since, afterID, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.operations(ctx, posts)
+}
+
+// operations builds the documents of the posts with their authors and circles loaded in batches
+func (s *postReindexSource) operations(ctx context.Context, posts []*models.Post) ([]search.BulkOperation, int64, error) {
+	if len(posts) == 0 {
+		return nil, 0, nil
+	}
+
+	authorIDs := make([]int64, 0, len(posts))
+	var circleIDs []int64
+	for _, post := range posts {
+		authorIDs = append(authorIDs, post.AuthorID)
+		if post.CircleID != nil {
+			circleIDs = append(circleIDs, *post.CircleID)
+		}
+	}
+	authors, err := s.batchRepo.FindUsersByIDs(ctx, authorIDs)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find authors: %w", err)
+	}
+	circles, err := s.batchRepo.FindCirclesByIDs(ctx, circleIDs)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find circles: %w", err)
+	}
+
+	operations := make([]search.BulkOperation, len(posts))
+	for i, post := range posts {
+		operations[i].ID = strconv.FormatInt(post.ID, 10)
+		if post.Status != "published" {
+			continue
+		}
+		var authorUsername, circleName string
+		if author, ok := authors[post.AuthorID]; ok {
+			authorUsername = author.Username
+		}
+		if post.CircleID != nil {
+			if circle, ok := circles[*post.CircleID]; ok {
+				circleName = circle.Name
+			}
+		}
+		operations[i].Document = postDocument(post, authorUsername, circleName, s.log)
+	}
+	return operations, posts[len(posts)-1].ID, nil
+}
+
+// userReindexSource reads users; active users are searchable
+type userReindexSource struct {
+	userRepo  repository.UserRepository
+	batchRepo repository.BatchRepository
+}
+
+func (s *userReindexSource) count(ctx context.Context) (int64, error) {
+	return s.userRepo.Count(ctx, repository.UserListOptions{Status: "active"})
+}
+
+func (s *userReindexSource) searchable(ctx context.Context, afterID int64, limit int) ([]search.BulkOperation, int64, error) {
+	users, err := s.userRepo.ListByID(ctx, "active", afterID, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.operations(ctx, users)
+}
+
+func (s *userReindexSource) updatedSince(ctx context.Context, since time.Time, afterID int64, limit int) ([]search.BulkOperation, int64, error) {
+	users, err := s.userRepo.ListUpdatedSince(ctx, since, afterID, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.operations(ctx, users)
+}
+
+// operations builds the documents of the users with their profiles and stats loaded in batches
+func (s *userReindexSource) operations(ctx context.Context, users []*models.User) ([]search.BulkOperation, int64, error) {
+	if len(users) == 0 {
+		return nil, 0, nil
+	}
+
+	ids := make([]int64, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+	profiles, err := s.batchRepo.FindUserProfilesByIDs(ctx, ids)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find user profiles: %w", err)
+	}
+	stats, err := s.batchRepo.FindUserStatsByIDs(ctx, ids)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find user stats: %w", err)
+	}
+
+	operations := make([]search.BulkOperation, len(users))
+	for i, user := range users {
+		operations[i].ID = strconv.FormatInt(user.ID, 10)
+		if user.Status != "active" {
+			continue
+		}
+		operations[i].Document = userDocument(user, profiles[user.ID], stats[user.ID])
+	}
+	return operations, users[len(users)-1].ID, nil
+}
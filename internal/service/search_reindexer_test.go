@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kobayashirei/airy/internal/models"
+	"github.com/kobayashirei/airy/internal/repository"
+	"github.com/kobayashirei/airy/internal/search"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeReindexClient keeps indices, aliases and documents in memory
+type fakeReindexClient struct {
+	docs     map[string]map[string]json.RawMessage
+	aliases  map[string]string // Alias to index
+	latest   int
+	failBulk int // Fails the nth bulk request
+	bulks    int
+}
+
+func newFakeReindexClient() *fakeReindexClient {
+	return &fakeReindexClient{
+		docs:    map[string]map[string]json.RawMessage{search.ReindexStateIndex: {}},
+		aliases: map[string]string{},
+	}
+}
+
+func (c *fakeReindexClient) Versions(ctx context.Context, spec search.IndexSpec) (*search.IndexVersions, error) {
+	versions := &search.IndexVersions{Latest: c.latest}
+	for v := 1; v <= c.latest; v++ {
+		index := spec.VersionIndex(v)
+		if _, ok := c.docs[index]; ok {
+			versions.Indices = append(versions.Indices, index)
+		}
+	}
+	if live, ok := c.aliases[spec.Alias]; ok {
+		versions.Live = []string{live}
+	}
+	return versions, nil
+}
+
+func (c *fakeReindexClient) CreateVersion(ctx context.Context, spec search.IndexSpec) (string, error) {
+	c.latest++
+	index := spec.VersionIndex(c.latest)
+	c.docs[index] = map[string]json.RawMessage{}
+	return index, nil
+}
+
+func (c *fakeReindexClient) FinishVersion(ctx context.Context, index string) error {
+	return nil
+}
+
+func (c *fakeReindexClient) SwapAliases(ctx context.Context, spec search.IndexSpec, index string, versions *search.IndexVersions) error {
+	c.aliases[spec.Alias] = index
+	c.aliases[spec.WriteAlias] = index
+	return nil
+}
+
+func (c *fakeReindexClient) DeleteIndex(ctx context.Context, index string) error {
+	delete(c.docs, index)
+	return nil
+}
+
+func (c *fakeReindexClient) IndexExists(ctx context.Context, index string) (bool, error) {
+	_, ok := c.docs[index]
+	return ok, nil
+}
+
+func (c *fakeReindexClient) Bulk(ctx context.Context, index string, operations []search.BulkOperation) error {
+	c.bulks++
+	if c.bulks == c.failBulk {
+		return errors.New("cluster unavailable")
+	}
+	for _, op := range operations {
+		if op.Document == nil {
+			delete(c.docs[index], op.ID)
+			continue
+		}
+		if err := c.Index(ctx, index, op.ID, op.Document); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *fakeReindexClient) Get(ctx context.Context, index, id string, out interface{}) (bool, error) {
+	data, ok := c.docs[index][id]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, out)
+}
+
+func (c *fakeReindexClient) Index(ctx context.Context, index, id string, document interface{}) error {
+	data, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+	c.docs[index][id] = data
+	return nil
+}
+
+func TestSearchReindexer_ResumesAndSwaps(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeReindexClient()
+	postRepo := new(MockPostRepository)
+	batchRepo := new(MockBatchRepository)
+	reindexer := NewSearchReindexer(client, postRepo, nil, batchRepo, nil)
+
+	// The live index is version 1
+	client.latest = 1
+	client.docs["posts_v1"] = map[string]json.RawMessage{"9": json.RawMessage(`{}`)}
+	client.aliases["posts"], client.aliases["posts_write"] = "posts_v1", "posts_v1"
+
+	posts := []*models.Post{
+		{ID: 1, AuthorID: 7, Title: "One", Status: "published"},
+		{ID: 2, AuthorID: 7, Title: "Two", Status: "published"},
+		{ID: 3, AuthorID: 7, Title: "Three", Status: "published"},
+	}
+	postRepo.On("Count", mock.Anything, repository.PostListOptions{Status: "published"}).Return(int64(3), nil)
+	postRepo.On("ListByID", mock.Anything, "published", int64(0), 2).Return(posts[:2], nil)
+	postRepo.On("ListByID", mock.Anything, "published", int64(2), 2).Return(posts[2:], nil)
+	postRepo.On("ListByID", mock.Anything, "published", int64(3), 2).Return([]*models.Post{}, nil)
+	// Post 2 was unpublished while copying
+	postRepo.On("ListUpdatedSince", mock.Anything, mock.Anything, int64(0), 2).Return([]*models.Post{{ID: 2, AuthorID: 7, Status: "hidden"}}, nil)
+	postRepo.On("ListUpdatedSince", mock.Anything, mock.Anything, int64(2), 2).Return([]*models.Post{}, nil)
+	batchRepo.On("FindUsersByIDs", mock.Anything, mock.Anything).Return(map[int64]*models.User{7: {ID: 7, Username: "alice"}}, nil)
+	batchRepo.On("FindCirclesByIDs", mock.Anything, mock.Anything).Return(map[int64]*models.Circle{}, nil)
+
+	// The second batch fails; the first is checkpointed
+	client.failBulk = 2
+	var reported []int64
+	opts := ReindexOptions{
+		BatchSize: 2,
+		DeleteOld: true,
+		Progress:  func(state ReindexState, rate float64, eta time.Duration) { reported = append(reported, state.Copied) },
+	}
+	_, err := reindexer.Reindex(ctx, search.PostIndex, opts)
+	require.Error(t, err)
+
+	state, err := reindexer.Status(ctx, search.PostIndex)
+	require.NoError(t, err)
+	assert.Equal(t, "posts_v2", state.Index)
+	assert.Equal(t, ReindexPhaseCopying, state.Phase)
+	assert.Equal(t, int64(2), state.LastID)
+	assert.Equal(t, "posts_v1", client.aliases["posts"], "Searches stay on the live index")
+
+	// Running again resumes after post 2 and swaps
+	state, err = reindexer.Reindex(ctx, search.PostIndex, opts)
+	require.NoError(t, err)
+	assert.Equal(t, ReindexPhaseDone, state.Phase)
+	assert.Equal(t, int64(3), state.Copied)
+	assert.Equal(t, int64(3), state.Total)
+	assert.Equal(t, []int64{2, 3}, reported)
+	assert.Equal(t, "posts_v2", client.aliases["posts"])
+	assert.Equal(t, "posts_v2", client.aliases["posts_write"])
+	assert.NotContains(t, client.docs, "posts_v1")
+
+	docs := client.docs["posts_v2"]
+	assert.Len(t, docs, 2)
+	assert.Contains(t, docs, "1")
+	assert.Contains(t, docs, "3")
+	assert.Contains(t, string(docs["1"]), `"author_username":"alice"`)
+	postRepo.AssertNotCalled(t, "ListByID", mock.Anything, "published", int64(0), 3)
+}
+
+func TestSearchReindexer_Restart(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeReindexClient()
+	postRepo := new(MockPostRepository)
+	reindexer := NewSearchReindexer(client, postRepo, nil, new(MockBatchRepository), nil)
+
+	client.latest = 1
+	client.docs["posts_v1"] = map[string]json.RawMessage{}
+	require.NoError(t, client.Index(ctx, search.ReindexStateIndex, search.PostIndex, ReindexState{
+		Alias: search.PostIndex, Index: "posts_v1", Phase: ReindexPhaseCopying, LastID: 10,
+	}))
+
+	postRepo.On("Count", mock.Anything, mock.Anything).Return(int64(0), nil)
+	postRepo.On("ListByID", mock.Anything, "published", int64(0), defaultReindexBatchSize).Return([]*models.Post{}, nil)
+	postRepo.On("ListUpdatedSince", mock.Anything, mock.Anything, int64(0), defaultReindexBatchSize).Return([]*models.Post{}, nil)
+
+	state, err := reindexer.Reindex(ctx, search.PostIndex, ReindexOptions{Restart: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, "posts_v2", state.Index)
+	assert.Zero(t, state.LastID)
+	assert.NotContains(t, client.docs, "posts_v1", "The unfinished index is discarded")
+
+	_, err = reindexer.Reindex(ctx, "comments", ReindexOptions{})
+	assert.ErrorIs(t, err, ErrUnknownSearchIndex)
+}
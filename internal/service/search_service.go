@@ -3,15 +3,20 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 
+	"github.com/kobayashirei/airy/internal/config"
 	"github.com/kobayashirei/airy/internal/models"
 	"github.com/kobayashirei/airy/internal/repository"
 	"github.com/kobayashirei/airy/internal/search"
 	"go.uber.org/zap"
 )
 
+// ErrSearchUnavailable is returned when no search backend is configured
+var ErrSearchUnavailable = errors.New("search backend unavailable")
+
 // SearchService defines the interface for search operations
 type SearchService interface {
 	// Post search operations
@@ -28,6 +33,29 @@ type SearchService interface {
 
 	// Initialize indices
 	InitializeIndices(ctx context.Context) error
+
+	// Status reports which backend serves searches
+	Status() SearchStatus
+}
+
+// SearchBackend is a store searches run on and indexing keeps in sync
+type SearchBackend interface {
+	// Name names the backend in logs and statuses
+	Name() string
+	// Health checks that the backend can serve searches
+	Health(ctx context.Context) error
+
+	IndexPost(ctx context.Context, post *models.Post) error
+	UpdatePost(ctx context.Context, postID int64, post *models.Post) error
+	DeletePost(ctx context.Context, postID int64) error
+	SearchPosts(ctx context.Context, query SearchQuery) (*SearchResult, error)
+
+	IndexUser(ctx context.Context, user *models.User, profile *models.UserProfile, stats *models.UserStats) error
+	UpdateUser(ctx context.Context, userID int64, user *models.User, profile *models.UserProfile, stats *models.UserStats) error
+	DeleteUser(ctx context.Context, userID int64) error
+	SearchUsers(ctx context.Context, query SearchQuery) (*SearchResult, error)
+
+	InitializeIndices(ctx context.Context) error
 }
 
 // SearchQuery represents a search query
@@ -40,6 +68,22 @@ type SearchQuery struct {
 	PageSize int
 }
 
+// normalize applies the default page, page size and sort, and caps the page size
+func (q *SearchQuery) normalize() {
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.PageSize < 1 {
+		q.PageSize = 20
+	}
+	if q.PageSize > 100 {
+		q.PageSize = 100
+	}
+	if q.SortBy == "" {
+		q.SortBy = "relevance"
+	}
+}
+
 // SearchResult represents search results
 type SearchResult struct {
 	Total   int64
@@ -53,7 +97,8 @@ type SearchResultItem struct {
 	Data  map[string]interface{}
 }
 
-type searchService struct {
+// esSearchBackend searches Elasticsearch
+type esSearchBackend struct {
 	esClient   *search.Client
 	userRepo   repository.UserRepository
 	postRepo   repository.PostRepository
@@ -61,15 +106,15 @@ type searchService struct {
 	log        *zap.Logger
 }
 
-// NewSearchService creates a new search service
-func NewSearchService(
+// NewESSearchBackend creates a search backend on Elasticsearch
+func NewESSearchBackend(
 	esClient *search.Client,
 	userRepo repository.UserRepository,
 	postRepo repository.PostRepository,
 	circleRepo repository.CircleRepository,
 	log *zap.Logger,
-) SearchService {
-	return &searchService{
+) SearchBackend {
+	return &esSearchBackend{
 		esClient:   esClient,
 		userRepo:   userRepo,
 		postRepo:   postRepo,
@@ -78,15 +123,33 @@ func NewSearchService(
 	}
 }
 
-// InitializeIndices creates the necessary Elasticsearch indices
-func (s *searchService) InitializeIndices(ctx context.Context) error {
+// Name names the backend
+func (s *esSearchBackend) Name() string {
+	return config.SearchBackendElasticsearch
+}
+
+// Health checks the health of the cluster
+func (s *esSearchBackend) Health(ctx context.Context) error {
+	if s.esClient == nil {
+		return ErrSearchUnavailable
+	}
+	return s.esClient.Health(ctx)
+}
+
+// InitializeIndices makes sure the posts and users indices exist behind their read and
+// write aliases. Mapping changes go live through the reindex command, not here.
+func (s *esSearchBackend) InitializeIndices(ctx context.Context) error {
+	if s.esClient == nil {
+		return ErrSearchUnavailable
+	}
+
 	// Create posts index
-	if err := s.esClient.CreateIndex(ctx, search.PostIndex, search.PostIndexMapping); err != nil {
+	if err := s.esClient.EnsureIndex(ctx, search.PostIndexSpec); err != nil {
 		return fmt.Errorf("failed to create posts index: %w", err)
 	}
 
 	// Create users index
-	if err := s.esClient.CreateIndex(ctx, search.UserIndex, search.UserIndexMapping); err != nil {
+	if err := s.esClient.EnsureIndex(ctx, search.UserIndexSpec); err != nil {
 		return fmt.Errorf("failed to create users index: %w", err)
 	}
 
@@ -95,7 +158,11 @@ func (s *searchService) InitializeIndices(ctx context.Context) error {
 }
 
 // IndexPost indexes a post in Elasticsearch
-func (s *searchService) IndexPost(ctx context.Context, post *models.Post) error {
+func (s *esSearchBackend) IndexPost(ctx context.Context, post *models.Post) error {
+	if s.esClient == nil {
+		return ErrSearchUnavailable
+	}
+
 	// Get author information
 	author, err := s.userRepo.FindByID(ctx, post.AuthorID)
 	if err != nil {
@@ -111,43 +178,11 @@ func (s *searchService) IndexPost(ctx context.Context, post *models.Post) error
 		}
 	}
 
-	// Parse tags
-	var tags []string
-	if post.Tags != "" {
-		if err := json.Unmarshal([]byte(post.Tags), &tags); err != nil {
-			s.log.Warn(fmt.Sprintf("Failed to parse tags for post %d: %v", post.ID, err))
-			tags = []string{}
-		}
-	}
-
 	// Create document
-	doc := map[string]interface{}{
-		"id":              post.ID,
-		"title":           post.Title,
-		"content":         post.ContentMarkdown,
-		"summary":         post.Summary,
-		"author_id":       post.AuthorID,
-		"author_username": author.Username,
-		"status":          post.Status,
-		"category":        post.Category,
-		"tags":            tags,
-		"view_count":      post.ViewCount,
-		"hotness_score":   post.HotnessScore,
-		"created_at":      post.CreatedAt,
-		"updated_at":      post.UpdatedAt,
-	}
-
-	if post.CircleID != nil {
-		doc["circle_id"] = *post.CircleID
-		doc["circle_name"] = circleName
-	}
-
-	if post.PublishedAt != nil {
-		doc["published_at"] = *post.PublishedAt
-	}
+	doc := postDocument(post, author.Username, circleName, s.log)
 
 	// Index the document
-	if err := s.esClient.Index(ctx, search.PostIndex, strconv.FormatInt(post.ID, 10), doc); err != nil {
+	if err := s.esClient.Index(ctx, search.PostWriteAlias, strconv.FormatInt(post.ID, 10), doc); err != nil {
 		return fmt.Errorf("failed to index post: %w", err)
 	}
 
@@ -156,7 +191,11 @@ func (s *searchService) IndexPost(ctx context.Context, post *models.Post) error
 }
 
 // UpdatePost updates a post in Elasticsearch
-func (s *searchService) UpdatePost(ctx context.Context, postID int64, post *models.Post) error {
+func (s *esSearchBackend) UpdatePost(ctx context.Context, postID int64, post *models.Post) error {
+	if s.esClient == nil {
+		return ErrSearchUnavailable
+	}
+
 	// Get author information
 	author, err := s.userRepo.FindByID(ctx, post.AuthorID)
 	if err != nil {
@@ -172,15 +211,6 @@ func (s *searchService) UpdatePost(ctx context.Context, postID int64, post *mode
 		}
 	}
 
-	// Parse tags
-	var tags []string
-	if post.Tags != "" {
-		if err := json.Unmarshal([]byte(post.Tags), &tags); err != nil {
-			s.log.Warn(fmt.Sprintf("Failed to parse tags for post %d: %v", post.ID, err))
-			tags = []string{}
-		}
-	}
-
 	// Create document
 	doc := map[string]interface{}{
 		"title":           post.Title,
@@ -189,7 +219,7 @@ func (s *searchService) UpdatePost(ctx context.Context, postID int64, post *mode
 		"author_username": author.Username,
 		"status":          post.Status,
 		"category":        post.Category,
-		"tags":            tags,
+		"tags":            postTags(post, s.log),
 		"view_count":      post.ViewCount,
 		"hotness_score":   post.HotnessScore,
 		"updated_at":      post.UpdatedAt,
@@ -204,7 +234,7 @@ func (s *searchService) UpdatePost(ctx context.Context, postID int64, post *mode
 	}
 
 	// Update the document
-	if err := s.esClient.Update(ctx, search.PostIndex, strconv.FormatInt(postID, 10), doc); err != nil {
+	if err := s.esClient.Update(ctx, search.PostWriteAlias, strconv.FormatInt(postID, 10), doc); err != nil {
 		return fmt.Errorf("failed to update post: %w", err)
 	}
 
@@ -213,8 +243,12 @@ func (s *searchService) UpdatePost(ctx context.Context, postID int64, post *mode
 }
 
 // DeletePost deletes a post from Elasticsearch
-func (s *searchService) DeletePost(ctx context.Context, postID int64) error {
-	if err := s.esClient.Delete(ctx, search.PostIndex, strconv.FormatInt(postID, 10)); err != nil {
+func (s *esSearchBackend) DeletePost(ctx context.Context, postID int64) error {
+	if s.esClient == nil {
+		return ErrSearchUnavailable
+	}
+
+	if err := s.esClient.Delete(ctx, search.PostWriteAlias, strconv.FormatInt(postID, 10)); err != nil {
 		return fmt.Errorf("failed to delete post: %w", err)
 	}
 
@@ -223,7 +257,11 @@ func (s *searchService) DeletePost(ctx context.Context, postID int64) error {
 }
 
 // SearchPosts searches for posts
-func (s *searchService) SearchPosts(ctx context.Context, query SearchQuery) (*SearchResult, error) {
+func (s *esSearchBackend) SearchPosts(ctx context.Context, query SearchQuery) (*SearchResult, error) {
+	if s.esClient == nil {
+		return nil, ErrSearchUnavailable
+	}
+
 	// Build Elasticsearch query
 	esQuery := s.buildPostSearchQuery(query)
 
@@ -250,21 +288,52 @@ func (s *searchService) SearchPosts(ctx context.Context, query SearchQuery) (*Se
 	}, nil
 }
 
-// buildPostSearchQuery builds an Elasticsearch query for post search
-func (s *searchService) buildPostSearchQuery(query SearchQuery) map[string]interface{} {
-	// Set defaults
-	if query.Page < 1 {
-		query.Page = 1
+// postDocument builds the search document of a post
+func postDocument(post *models.Post, authorUsername, circleName string, log *zap.Logger) map[string]interface{} {
+	doc := map[string]interface{}{
+		"id":              post.ID,
+		"title":           post.Title,
+		"content":         post.ContentMarkdown,
+		"summary":         post.Summary,
+		"author_id":       post.AuthorID,
+		"author_username": authorUsername,
+		"status":          post.Status,
+		"category":        post.Category,
+		"tags":            postTags(post, log),
+		"view_count":      post.ViewCount,
+		"hotness_score":   post.HotnessScore,
+		"created_at":      post.CreatedAt,
+		"updated_at":      post.UpdatedAt,
 	}
-	if query.PageSize < 1 {
-		query.PageSize = 20
+
+	if post.CircleID != nil {
+		doc["circle_id"] = *post.CircleID
+		doc["circle_name"] = circleName
 	}
-	if query.PageSize > 100 {
-		query.PageSize = 100
+
+	if post.PublishedAt != nil {
+		doc["published_at"] = *post.PublishedAt
 	}
-	if query.SortBy == "" {
-		query.SortBy = "relevance"
+
+	return doc
+}
+
+// postTags parses the JSON tags of a post; malformed tags are logged and dropped
+func postTags(post *models.Post, log *zap.Logger) []string {
+	var tags []string
+	if post.Tags != "" {
+		if err := json.Unmarshal([]byte(post.Tags), &tags); err != nil {
+			log.Warn(fmt.Sprintf("Failed to parse tags for post %d: %v", post.ID, err))
+			tags = []string{}
+		}
 	}
+	return tags
+}
+
+// buildPostSearchQuery builds an Elasticsearch query for post search
+func (s *esSearchBackend) buildPostSearchQuery(query SearchQuery) map[string]interface{} {
+	// Set defaults
+	query.normalize()
 
 	// Build must clauses
 	mustClauses := []map[string]interface{}{
@@ -337,26 +406,14 @@ func (s *searchService) buildPostSearchQuery(query SearchQuery) map[string]inter
 }
 
 // IndexUser indexes a user in Elasticsearch
-func (s *searchService) IndexUser(ctx context.Context, user *models.User, profile *models.UserProfile, stats *models.UserStats) error {
-	doc := map[string]interface{}{
-		"id":         user.ID,
-		"username":   user.Username,
-		"email":      user.Email,
-		"bio":        user.Bio,
-		"status":     user.Status,
-		"created_at": user.CreatedAt,
-	}
-
-	if profile != nil {
-		doc["follower_count"] = profile.FollowerCount
-		doc["following_count"] = profile.FollowingCount
+func (s *esSearchBackend) IndexUser(ctx context.Context, user *models.User, profile *models.UserProfile, stats *models.UserStats) error {
+	if s.esClient == nil {
+		return ErrSearchUnavailable
 	}
 
-	if stats != nil {
-		doc["post_count"] = stats.PostCount
-	}
+	doc := userDocument(user, profile, stats)
 
-	if err := s.esClient.Index(ctx, search.UserIndex, strconv.FormatInt(user.ID, 10), doc); err != nil {
+	if err := s.esClient.Index(ctx, search.UserWriteAlias, strconv.FormatInt(user.ID, 10), doc); err != nil {
 		return fmt.Errorf("failed to index user: %w", err)
 	}
 
@@ -365,7 +422,11 @@ func (s *searchService) IndexUser(ctx context.Context, user *models.User, profil
 }
 
 // UpdateUser updates a user in Elasticsearch
-func (s *searchService) UpdateUser(ctx context.Context, userID int64, user *models.User, profile *models.UserProfile, stats *models.UserStats) error {
+func (s *esSearchBackend) UpdateUser(ctx context.Context, userID int64, user *models.User, profile *models.UserProfile, stats *models.UserStats) error {
+	if s.esClient == nil {
+		return ErrSearchUnavailable
+	}
+
 	doc := map[string]interface{}{
 		"username": user.Username,
 		"bio":      user.Bio,
@@ -381,7 +442,7 @@ func (s *searchService) UpdateUser(ctx context.Context, userID int64, user *mode
 		doc["post_count"] = stats.PostCount
 	}
 
-	if err := s.esClient.Update(ctx, search.UserIndex, strconv.FormatInt(userID, 10), doc); err != nil {
+	if err := s.esClient.Update(ctx, search.UserWriteAlias, strconv.FormatInt(userID, 10), doc); err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
 	}
 
@@ -390,8 +451,12 @@ func (s *searchService) UpdateUser(ctx context.Context, userID int64, user *mode
 }
 
 // DeleteUser deletes a user from Elasticsearch
-func (s *searchService) DeleteUser(ctx context.Context, userID int64) error {
-	if err := s.esClient.Delete(ctx, search.UserIndex, strconv.FormatInt(userID, 10)); err != nil {
+func (s *esSearchBackend) DeleteUser(ctx context.Context, userID int64) error {
+	if s.esClient == nil {
+		return ErrSearchUnavailable
+	}
+
+	if err := s.esClient.Delete(ctx, search.UserWriteAlias, strconv.FormatInt(userID, 10)); err != nil {
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
 
@@ -400,7 +465,11 @@ func (s *searchService) DeleteUser(ctx context.Context, userID int64) error {
 }
 
 // SearchUsers searches for users
-func (s *searchService) SearchUsers(ctx context.Context, query SearchQuery) (*SearchResult, error) {
+func (s *esSearchBackend) SearchUsers(ctx context.Context, query SearchQuery) (*SearchResult, error) {
+	if s.esClient == nil {
+		return nil, ErrSearchUnavailable
+	}
+
 	// Build Elasticsearch query
 	esQuery := s.buildUserSearchQuery(query)
 
@@ -427,19 +496,34 @@ func (s *searchService) SearchUsers(ctx context.Context, query SearchQuery) (*Se
 	}, nil
 }
 
-// buildUserSearchQuery builds an Elasticsearch query for user search
-func (s *searchService) buildUserSearchQuery(query SearchQuery) map[string]interface{} {
-	// Set defaults
-	if query.Page < 1 {
-		query.Page = 1
+// userDocument builds the search document of a user; profile and stats may be nil
+func userDocument(user *models.User, profile *models.UserProfile, stats *models.UserStats) map[string]interface{} {
+	doc := map[string]interface{}{
+		"id":         user.ID,
+		"username":   user.Username,
+		"email":      user.Email,
+		"bio":        user.Bio,
+		"status":     user.Status,
+		"created_at": user.CreatedAt,
 	}
-	if query.PageSize < 1 {
-		query.PageSize = 20
+
+	if profile != nil {
+		doc["follower_count"] = profile.FollowerCount
+		doc["following_count"] = profile.FollowingCount
 	}
-	if query.PageSize > 100 {
-		query.PageSize = 100
+
+	if stats != nil {
+		doc["post_count"] = stats.PostCount
 	}
 
+	return doc
+}
+
+// buildUserSearchQuery builds an Elasticsearch query for user search
+func (s *esSearchBackend) buildUserSearchQuery(query SearchQuery) map[string]interface{} {
+	// Set defaults
+	query.normalize()
+
 	// Build must clauses
 	mustClauses := []map[string]interface{}{
 		{
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+
+	"github.com/kobayashirei/airy/internal/taskpool"
+)
+
+// taskNotifier delivers the notifications of taskpool.SendNotificationTask
+type taskNotifier struct {
+	notifications NotificationService
+}
+
+// NewTaskNotifier adapts the notification service to the task pool's notifier
+func NewTaskNotifier(notifications NotificationService) taskpool.Notifier {
+	return &taskNotifier{notifications: notifications}
+}
+
+// Notify creates the notification for the user
+func (n *taskNotifier) Notify(ctx context.Context, userID int64, notification taskpool.Notification) error {
+	_, err := n.notifications.CreateNotification(ctx, CreateNotificationRequest{
+		ReceiverID:    userID,
+		TriggerUserID: notification.TriggerUserID,
+		Type:          notification.Type,
+		EntityType:    notification.EntityType,
+		EntityID:      notification.EntityID,
+		Content:       notification.Content,
+	})
+	return err
+}
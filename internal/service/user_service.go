@@ -95,6 +95,8 @@ type userService struct {
 	cacheService cache.Service
 	emailService EmailService
 	jwtService   JWTService
+	userRoleRepo repository.UserRoleRepository
+	roleRepo     repository.RoleRepository
 }
 
 // NewUserService creates a new user service
@@ -103,12 +105,16 @@ func NewUserService(
 	cacheService cache.Service,
 	emailService EmailService,
 	jwtService JWTService,
+	userRoleRepo repository.UserRoleRepository,
+	roleRepo repository.RoleRepository,
 ) UserService {
 	return &userService{
 		userRepo:     userRepo,
 		cacheService: cacheService,
 		emailService: emailService,
 		jwtService:   jwtService,
+		userRoleRepo: userRoleRepo,
+		roleRepo:     roleRepo,
 	}
 }
 
@@ -274,15 +280,20 @@ func (s *userService) Login(ctx context.Context, req LoginRequest) (*LoginRespon
 		return nil, errors.New("user account is not active")
 	}
 
+	// Resolve roles from user_roles
+	roles, circleRoles, err := s.resolveRoles(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Generate JWT token
-	roles := []string{"user"} // Default role, can be extended to fetch from user_roles table
-	token, err := s.jwtService.GenerateToken(user.ID, roles)
+	token, err := s.jwtService.GenerateToken(user.ID, roles, circleRoles)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate token: %w", err)
 	}
 
 	// Generate refresh token (longer expiration)
-	refreshToken, err := s.jwtService.GenerateRefreshToken(user.ID, roles)
+	refreshToken, err := s.jwtService.GenerateRefreshToken(user.ID, roles, circleRoles)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
 	}
@@ -343,15 +354,20 @@ func (s *userService) LoginWithCode(ctx context.Context, req LoginWithCodeReques
 		return nil, errors.New("user account is not active")
 	}
 
+	// Resolve roles from user_roles
+	roles, circleRoles, err := s.resolveRoles(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Generate JWT token
-	roles := []string{"user"}
-	token, err := s.jwtService.GenerateToken(user.ID, roles)
+	token, err := s.jwtService.GenerateToken(user.ID, roles, circleRoles)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate token: %w", err)
 	}
 
 	// Generate refresh token
-	refreshToken, err := s.jwtService.GenerateRefreshToken(user.ID, roles)
+	refreshToken, err := s.jwtService.GenerateRefreshToken(user.ID, roles, circleRoles)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
 	}
@@ -377,8 +393,31 @@ func (s *userService) LoginWithCode(ctx context.Context, req LoginWithCodeReques
 }
 
 // RefreshToken refreshes an access token
+// Roles are resolved again so role changes take effect on the next refresh
 func (s *userService) RefreshToken(ctx context.Context, token string) (*RefreshTokenResponse, error) {
-	newToken, err := s.jwtService.RefreshToken(token)
+	claims, err := s.jwtService.ParseRefreshToken(token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+
+	// Make sure the account still exists and is allowed to sign in
+	user, err := s.userRepo.FindByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if user.Status != "active" {
+		return nil, errors.New("user account is not active")
+	}
+
+	roles, circleRoles, err := s.resolveRoles(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	newToken, err := s.jwtService.GenerateToken(user.ID, roles, circleRoles)
 	if err != nil {
 		return nil, fmt.Errorf("failed to refresh token: %w", err)
 	}
@@ -388,6 +427,50 @@ func (s *userService) RefreshToken(ctx context.Context, token string) (*RefreshT
 	}, nil
 }
 
+// resolveRoles loads the user's global and circle-scoped role names from user_roles.
+// Every active account holds the baseline "user" role.
+func (s *userService) resolveRoles(ctx context.Context, userID int64) ([]string, map[int64][]string, error) {
+	roles := []string{models.RoleUser}
+	var circleRoles map[int64][]string
+
+	userRoles, err := s.userRoleRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find user roles: %w", err)
+	}
+
+	roleNames := make(map[int64]string)
+	for _, ur := range userRoles {
+		name, ok := roleNames[ur.RoleID]
+		if !ok {
+			role, err := s.roleRepo.FindByID(ctx, ur.RoleID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to find role %d: %w", ur.RoleID, err)
+			}
+			if role != nil {
+				name = role.Name
+			}
+			roleNames[ur.RoleID] = name
+		}
+		if name == "" {
+			continue
+		}
+
+		if ur.CircleID == nil {
+			if name != models.RoleUser {
+				roles = append(roles, name)
+			}
+			continue
+		}
+
+		if circleRoles == nil {
+			circleRoles = make(map[int64][]string)
+		}
+		circleRoles[*ur.CircleID] = append(circleRoles[*ur.CircleID], name)
+	}
+
+	return roles, circleRoles, nil
+}
+
 // ResendActivation regenerates and resends activation token to user's email
 func (s *userService) ResendActivation(ctx context.Context, identifier string) error {
     var user *models.User
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/kobayashirei/airy/internal/auth"
+	"github.com/kobayashirei/airy/internal/models"
+)
+
+func newTestUserService(userRepo *MockUserRepository, userRoleRepo *MockUserRoleRepository, roleRepo *MockRoleRepository) (UserService, JWTService) {
+	jwtService := NewJWTService(auth.NewJWTService("test-secret", time.Hour), 24*time.Hour)
+	return NewUserService(userRepo, nil, nil, jwtService, userRoleRepo, roleRepo), jwtService
+}
+
+func TestUserService_Login_LoadsRolesFromUserRoles(t *testing.T) {
+	ctx := context.Background()
+	userRepo := new(MockUserRepository)
+	userRoleRepo := new(MockUserRoleRepository)
+	roleRepo := new(MockRoleRepository)
+	service, jwtService := newTestUserService(userRepo, userRoleRepo, roleRepo)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	user := &models.User{ID: 1, Username: "admin", Email: "admin@example.com", PasswordHash: string(hash), Status: "active"}
+	circleID := int64(5)
+
+	userRepo.On("FindByEmail", ctx, "admin@example.com").Return(user, nil)
+	userRepo.On("UpdateLoginInfo", ctx, int64(1), "127.0.0.1").Return(nil)
+	userRoleRepo.On("FindByUserID", ctx, int64(1)).Return([]*models.UserRole{
+		{UserID: 1, RoleID: 1},
+		{UserID: 1, RoleID: 2, CircleID: &circleID},
+	}, nil)
+	roleRepo.On("FindByID", ctx, int64(1)).Return(&models.Role{ID: 1, Name: models.RoleSuperAdmin}, nil)
+	roleRepo.On("FindByID", ctx, int64(2)).Return(&models.Role{ID: 2, Name: models.RoleModerator}, nil)
+
+	resp, err := service.Login(ctx, LoginRequest{Identifier: "admin@example.com", Password: "password", ClientIP: "127.0.0.1"})
+	assert.NoError(t, err)
+
+	claims, err := jwtService.ParseToken(resp.Token)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{models.RoleUser, models.RoleSuperAdmin}, claims.Roles)
+	assert.Equal(t, []string{models.RoleModerator}, claims.CircleRoles[circleID])
+}
+
+func TestUserService_RefreshToken_PicksUpRoleChanges(t *testing.T) {
+	ctx := context.Background()
+	userRepo := new(MockUserRepository)
+	userRoleRepo := new(MockUserRoleRepository)
+	roleRepo := new(MockRoleRepository)
+	service, jwtService := newTestUserService(userRepo, userRoleRepo, roleRepo)
+
+	// The old token was issued before the user was granted super_admin
+	oldToken, err := jwtService.GenerateToken(1, []string{models.RoleUser}, nil)
+	assert.NoError(t, err)
+
+	userRepo.On("FindByID", ctx, int64(1)).Return(&models.User{ID: 1, Status: "active"}, nil)
+	userRoleRepo.On("FindByUserID", ctx, int64(1)).Return([]*models.UserRole{{UserID: 1, RoleID: 1}}, nil)
+	roleRepo.On("FindByID", ctx, int64(1)).Return(&models.Role{ID: 1, Name: models.RoleSuperAdmin}, nil)
+
+	resp, err := service.RefreshToken(ctx, oldToken)
+	assert.NoError(t, err)
+
+	claims, err := jwtService.ParseToken(resp.Token)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{models.RoleUser, models.RoleSuperAdmin}, claims.Roles)
+}
+
+func TestUserService_RefreshToken_RejectsInactiveUser(t *testing.T) {
+	ctx := context.Background()
+	userRepo := new(MockUserRepository)
+	userRoleRepo := new(MockUserRoleRepository)
+	roleRepo := new(MockRoleRepository)
+	service, jwtService := newTestUserService(userRepo, userRoleRepo, roleRepo)
+
+	oldToken, err := jwtService.GenerateToken(1, []string{models.RoleUser}, nil)
+	assert.NoError(t, err)
+
+	userRepo.On("FindByID", ctx, int64(1)).Return(&models.User{ID: 1, Status: "banned"}, nil)
+
+	resp, err := service.RefreshToken(ctx, oldToken)
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	userRoleRepo.AssertNotCalled(t, "FindByUserID", mock.Anything, mock.Anything)
+}
@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"time"
 
@@ -35,16 +34,14 @@ func NewVoteConsumer(
 }
 
 // HandleVoteCreated handles vote created events
-func (c *VoteConsumer) HandleVoteCreated(ctx context.Context, message []byte) error {
-	var event mq.VoteCreatedEvent
-	if err := json.Unmarshal(message, &event); err != nil {
-		return fmt.Errorf("failed to unmarshal vote created event: %w", err)
-	}
-
+func (c *VoteConsumer) HandleVoteCreated(ctx context.Context, event *mq.VoteCreatedEvent) error {
 	// Update entity count
 	if err := c.updateEntityCountForVote(ctx, event.EntityType, event.EntityID, event.VoteType, 1); err != nil {
 		return fmt.Errorf("failed to update entity count: %w", err)
 	}
+	if err := c.refreshCommentScores(ctx, event.EntityType, event.EntityID); err != nil {
+		return err
+	}
 
 	// Generate notification (exclude self-votes)
 	if err := c.generateVoteNotification(ctx, event.UserID, event.EntityType, event.EntityID, event.VoteType); err != nil {
@@ -56,12 +53,7 @@ func (c *VoteConsumer) HandleVoteCreated(ctx context.Context, message []byte) er
 }
 
 // HandleVoteUpdated handles vote updated events
-func (c *VoteConsumer) HandleVoteUpdated(ctx context.Context, message []byte) error {
-	var event mq.VoteUpdatedEvent
-	if err := json.Unmarshal(message, &event); err != nil {
-		return fmt.Errorf("failed to unmarshal vote updated event: %w", err)
-	}
-
+func (c *VoteConsumer) HandleVoteUpdated(ctx context.Context, event *mq.VoteUpdatedEvent) error {
 	// Decrement old vote type count
 	if err := c.updateEntityCountForVote(ctx, event.EntityType, event.EntityID, event.OldVoteType, -1); err != nil {
 		return fmt.Errorf("failed to decrement old vote count: %w", err)
@@ -71,6 +63,9 @@ func (c *VoteConsumer) HandleVoteUpdated(ctx context.Context, message []byte) er
 	if err := c.updateEntityCountForVote(ctx, event.EntityType, event.EntityID, event.NewVoteType, 1); err != nil {
 		return fmt.Errorf("failed to increment new vote count: %w", err)
 	}
+	if err := c.refreshCommentScores(ctx, event.EntityType, event.EntityID); err != nil {
+		return err
+	}
 
 	// Note: We don't generate a new notification for vote updates
 	// The original notification still stands
@@ -79,16 +74,14 @@ func (c *VoteConsumer) HandleVoteUpdated(ctx context.Context, message []byte) er
 }
 
 // HandleVoteDeleted handles vote deleted events
-func (c *VoteConsumer) HandleVoteDeleted(ctx context.Context, message []byte) error {
-	var event mq.VoteDeletedEvent
-	if err := json.Unmarshal(message, &event); err != nil {
-		return fmt.Errorf("failed to unmarshal vote deleted event: %w", err)
-	}
-
+func (c *VoteConsumer) HandleVoteDeleted(ctx context.Context, event *mq.VoteDeletedEvent) error {
 	// Decrement vote count
 	if err := c.updateEntityCountForVote(ctx, event.EntityType, event.EntityID, event.VoteType, -1); err != nil {
 		return fmt.Errorf("failed to update entity count: %w", err)
 	}
+	if err := c.refreshCommentScores(ctx, event.EntityType, event.EntityID); err != nil {
+		return err
+	}
 
 	// Note: We don't delete the notification when a vote is cancelled
 	// The notification remains as historical record
@@ -134,6 +127,19 @@ func (c *VoteConsumer) updateEntityCountForVote(ctx context.Context, entityType
 	return nil
 }
 
+// refreshCommentScores recomputes the vote scores comment threads are sorted by from the
+// comment's vote counts; other entities are left alone
+func (c *VoteConsumer) refreshCommentScores(ctx context.Context, entityType string, entityID int64) error {
+	if entityType != "comment" {
+		return nil
+	}
+
+	if err := c.commentRepo.UpdateScores(ctx, entityID); err != nil {
+		return fmt.Errorf("failed to update comment scores: %w", err)
+	}
+	return nil
+}
+
 // generateVoteNotification generates a notification for a vote
 func (c *VoteConsumer) generateVoteNotification(ctx context.Context, voterID int64, entityType string, entityID int64, voteType string) error {
 	// Get the content author ID
@@ -203,3 +209,24 @@ func (c *VoteConsumer) generateVoteNotification(ctx context.Context, voterID int
 
 	return nil
 }
+
+// VoteConsumerGroup is the consumer group the vote consumer subscribes with
+const VoteConsumerGroup = "votes"
+
+// Subscribe subscribes to vote events. Each handler applies count deltas, so
+// messageQueue should deduplicate redeliveries (see EventDeduplicator.Queue).
+func (c *VoteConsumer) Subscribe(messageQueue mq.MessageQueue) error {
+	if err := mq.SubscribeGroupEvents(messageQueue, VoteConsumerGroup, mq.TopicVoteCreated, c.HandleVoteCreated); err != nil {
+		return fmt.Errorf("failed to subscribe to vote created events: %w", err)
+	}
+
+	if err := mq.SubscribeGroupEvents(messageQueue, VoteConsumerGroup, mq.TopicVoteUpdated, c.HandleVoteUpdated); err != nil {
+		return fmt.Errorf("failed to subscribe to vote updated events: %w", err)
+	}
+
+	if err := mq.SubscribeGroupEvents(messageQueue, VoteConsumerGroup, mq.TopicVoteDeleted, c.HandleVoteDeleted); err != nil {
+		return fmt.Errorf("failed to subscribe to vote deleted events: %w", err)
+	}
+
+	return nil
+}
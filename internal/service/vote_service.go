@@ -20,12 +20,20 @@ type VoteService interface {
 	GetVote(ctx context.Context, userID int64, entityType string, entityID int64) (*models.Vote, error)
 }
 
+// VoteEventPublisher publishes vote lifecycle events; *mq.Publisher satisfies it
+type VoteEventPublisher interface {
+	PublishVoteCreated(ctx context.Context, voteID, userID int64, entityType string, entityID int64, voteType string) error
+	PublishVoteUpdated(ctx context.Context, voteID, userID int64, entityType string, entityID int64, oldVoteType, newVoteType string) error
+	PublishVoteDeleted(ctx context.Context, voteID, userID int64, entityType string, entityID int64, voteType string) error
+}
+
 // voteService implements VoteService interface
 type voteService struct {
 	voteRepo repository.VoteRepository
 	postRepo repository.PostRepository
 	commentRepo repository.CommentRepository
-	publisher *mq.Publisher
+	transactor repository.Transactor
+	publisher VoteEventPublisher
 }
 
 // NewVoteService creates a new vote service
@@ -33,12 +41,14 @@ func NewVoteService(
 	voteRepo repository.VoteRepository,
 	postRepo repository.PostRepository,
 	commentRepo repository.CommentRepository,
-	publisher *mq.Publisher,
+	transactor repository.Transactor,
+	publisher VoteEventPublisher,
 ) VoteService {
 	return &voteService{
 		voteRepo: voteRepo,
 		postRepo: postRepo,
 		commentRepo: commentRepo,
+		transactor: transactor,
 		publisher: publisher,
 	}
 }
@@ -86,28 +96,31 @@ func (s *voteService) Vote(ctx context.Context, userID int64, entityType string,
 	}
 
 	var vote *models.Vote
-	var eventType string
-	var oldVoteType string
-
-	if existingVote != nil {
-		// Vote exists - update it (idempotent)
-		if existingVote.VoteType == voteType {
-			// Same vote type - return existing vote (idempotent)
-			return existingVote, nil
-		}
 
-		// Different vote type - update
-		oldVoteType = existingVote.VoteType
-		existingVote.VoteType = voteType
-		existingVote.UpdatedAt = time.Now()
+	if existingVote != nil && existingVote.VoteType == voteType {
+		// Same vote type - return existing vote (idempotent)
+		return existingVote, nil
+	}
 
-		if err := s.voteRepo.Update(ctx, existingVote); err != nil {
-			return nil, fmt.Errorf("failed to update vote: %w", err)
+	// Save the vote and record its event in one transaction, so counters never miss a vote
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if existingVote != nil {
+			// Different vote type - update
+			oldVoteType := existingVote.VoteType
+			existingVote.VoteType = voteType
+			existingVote.UpdatedAt = time.Now()
+
+			if err := s.voteRepo.Update(ctx, existingVote); err != nil {
+				return fmt.Errorf("failed to update vote: %w", err)
+			}
+
+			vote = existingVote
+			if err := s.publishVoteEvent(ctx, vote, mq.TopicVoteUpdated, oldVoteType, authorID); err != nil {
+				return fmt.Errorf("failed to publish vote event: %w", err)
+			}
+			return nil
 		}
 
-		vote = existingVote
-		eventType = mq.TopicVoteUpdated
-	} else {
 		// Create new vote
 		vote = &models.Vote{
 			UserID:     userID,
@@ -119,17 +132,16 @@ func (s *voteService) Vote(ctx context.Context, userID int64, entityType string,
 		}
 
 		if err := s.voteRepo.Create(ctx, vote); err != nil {
-			return nil, fmt.Errorf("failed to create vote: %w", err)
+			return fmt.Errorf("failed to create vote: %w", err)
 		}
 
-		eventType = mq.TopicVoteCreated
-	}
-
-	// Publish vote event to message queue for async processing
-	if err := s.publishVoteEvent(ctx, vote, eventType, oldVoteType, authorID); err != nil {
-		// Log error but don't fail the request
-		// The vote is already saved, event publishing is best-effort
-		fmt.Printf("failed to publish vote event: %v\n", err)
+		if err := s.publishVoteEvent(ctx, vote, mq.TopicVoteCreated, "", authorID); err != nil {
+			return fmt.Errorf("failed to publish vote event: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return vote, nil
@@ -173,18 +185,16 @@ func (s *voteService) CancelVote(ctx context.Context, userID int64, entityType s
 		}
 	}
 
-	// Delete the vote
-	if err := s.voteRepo.DeleteByUserAndEntity(ctx, userID, entityType, entityID); err != nil {
-		return fmt.Errorf("failed to delete vote: %w", err)
-	}
-
-	// Publish vote deleted event
-	if err := s.publishVoteDeletedEvent(ctx, existingVote, authorID); err != nil {
-		// Log error but don't fail the request
-		fmt.Printf("failed to publish vote deleted event: %v\n", err)
-	}
-
-	return nil
+	// Delete the vote together with its deleted event
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.voteRepo.DeleteByUserAndEntity(ctx, userID, entityType, entityID); err != nil {
+			return fmt.Errorf("failed to delete vote: %w", err)
+		}
+		if err := s.publishVoteDeletedEvent(ctx, existingVote, authorID); err != nil {
+			return fmt.Errorf("failed to publish vote deleted event: %w", err)
+		}
+		return nil
+	})
 }
 
 // GetVote retrieves a user's vote for an entity
@@ -198,6 +208,10 @@ func (s *voteService) GetVote(ctx context.Context, userID int64, entityType stri
 
 // publishVoteEvent publishes a vote event to the message queue
 func (s *voteService) publishVoteEvent(ctx context.Context, vote *models.Vote, eventType string, oldVoteType string, authorID int64) error {
+	if s.publisher == nil {
+		return nil
+	}
+
 	switch eventType {
 	case mq.TopicVoteCreated:
 		return s.publisher.PublishVoteCreated(ctx, vote.ID, vote.UserID, vote.EntityType, vote.EntityID, vote.VoteType)
@@ -210,5 +224,8 @@ func (s *voteService) publishVoteEvent(ctx context.Context, vote *models.Vote, e
 
 // publishVoteDeletedEvent publishes a vote deleted event
 func (s *voteService) publishVoteDeletedEvent(ctx context.Context, vote *models.Vote, authorID int64) error {
+	if s.publisher == nil {
+		return nil
+	}
 	return s.publisher.PublishVoteDeleted(ctx, vote.ID, vote.UserID, vote.EntityType, vote.EntityID, vote.VoteType)
 }
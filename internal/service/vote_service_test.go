@@ -17,7 +17,7 @@ func TestVoteService_Vote_CreateNewVote(t *testing.T) {
 	mockCommentRepo := new(MockCommentRepository)
 	mockPublisher := new(MockPublisher)
 
-	service := NewVoteService(mockVoteRepo, mockPostRepo, mockCommentRepo, mockPublisher)
+	service := NewVoteService(mockVoteRepo, mockPostRepo, mockCommentRepo, passthroughTransactor{}, mockPublisher)
 
 	userID := int64(1)
 	postID := int64(100)
@@ -57,7 +57,7 @@ func TestVoteService_Vote_Idempotent(t *testing.T) {
 	mockCommentRepo := new(MockCommentRepository)
 	mockPublisher := new(MockPublisher)
 
-	service := NewVoteService(mockVoteRepo, mockPostRepo, mockCommentRepo, mockPublisher)
+	service := NewVoteService(mockVoteRepo, mockPostRepo, mockCommentRepo, passthroughTransactor{}, mockPublisher)
 
 	userID := int64(1)
 	postID := int64(100)
@@ -100,7 +100,7 @@ func TestVoteService_CancelVote_NoVoteExists(t *testing.T) {
 	mockCommentRepo := new(MockCommentRepository)
 	mockPublisher := new(MockPublisher)
 
-	service := NewVoteService(mockVoteRepo, mockPostRepo, mockCommentRepo, mockPublisher)
+	service := NewVoteService(mockVoteRepo, mockPostRepo, mockCommentRepo, passthroughTransactor{}, mockPublisher)
 
 	mockVoteRepo.On("FindByUserAndEntity", ctx, int64(1), "post", int64(100)).Return(nil, nil)
 
@@ -1,76 +1,383 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
 
-	"github.com/kobayashirei/airy/internal/mq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"go.uber.org/zap"
+
+	"github.com/kobayashirei/airy/internal/mq"
 )
 
-// WorkerManager manages all background workers
+// Worker states reported by WorkerManager.Status
+const (
+	WorkerStateDisabled = "disabled" // Turned off in the configuration; never started
+	WorkerStatePending  = "pending"  // Registered, waiting for Start
+	WorkerStateRunning  = "running"
+	WorkerStateFailed   = "failed" // Subscribing failed; see LastError
+	WorkerStateStopping = "stopping"
+	WorkerStateStopped  = "stopped"
+)
+
+// workerMessagesTotal counts the messages each worker handled, by result
+var workerMessagesTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "worker_messages_total",
+		Help: "Total number of messages handled by background workers",
+	},
+	[]string{"worker", "result"},
+)
+
+// EventSubscriber is a consumer that subscribes its handlers to the message queue
+type EventSubscriber interface {
+	Subscribe(messageQueue mq.MessageQueue) error
+}
+
+// WorkerOptions configures a registered worker
+type WorkerOptions struct {
+	Enabled     bool
+	Concurrency int // Consumers per consumer group queue; 0 uses the message queue's default
+}
+
+// WorkerStatus is a snapshot of a worker
+type WorkerStatus struct {
+	Name          string               `json:"name"`
+	State         string               `json:"state"`
+	Concurrency   int                  `json:"concurrency,omitempty"`
+	Subscriptions []WorkerSubscription `json:"subscriptions"`
+	InFlight      int64                `json:"in_flight"`
+	Processed     int64                `json:"processed"`
+	Failed        int64                `json:"failed"`
+	LastError     string               `json:"last_error,omitempty"`
+	LastErrorAt   *time.Time           `json:"last_error_at,omitempty"`
+	LastHandledAt *time.Time           `json:"last_handled_at,omitempty"`
+}
+
+// WorkerSubscription is a topic a worker consumes
+type WorkerSubscription struct {
+	Group string `json:"group,omitempty"`
+	Topic string `json:"topic"`
+	Lag   *int   `json:"lag,omitempty"` // Messages waiting for the group; absent when the queue cannot tell
+}
+
+// WorkerManager runs the registered background workers on the message queue. Each worker
+// subscribes through a queue that applies its concurrency, deduplicates its consumer group
+// events and tracks the messages it handles, so Status can report on it and Stop can wait
+// for its in-flight messages.
 type WorkerManager struct {
-	messageQueue   mq.MessageQueue
-	hotnessWorker  *HotnessWorker
-	logger         *zap.Logger
+	messageQueue mq.MessageQueue
+	deduplicator *EventDeduplicator
+	logger       *zap.Logger
+
+	mu      sync.RWMutex
+	workers []*worker
 }
 
-// NewWorkerManager creates a new worker manager
+// NewWorkerManager creates a new worker manager. A nil deduplicator runs the workers
+// without deduplication.
 func NewWorkerManager(
 	messageQueue mq.MessageQueue,
-	hotnessWorker *HotnessWorker,
+	deduplicator *EventDeduplicator,
 	logger *zap.Logger,
 ) *WorkerManager {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
 	return &WorkerManager{
-		messageQueue:  messageQueue,
-		hotnessWorker: hotnessWorker,
-		logger:        logger,
+		messageQueue: messageQueue,
+		deduplicator: deduplicator,
+		logger:       logger,
+	}
+}
+
+// Register adds a worker; call it before Start. A disabled worker is only reported.
+func (wm *WorkerManager) Register(name string, subscriber EventSubscriber, options WorkerOptions) {
+	w := &worker{
+		name:       name,
+		subscriber: subscriber,
+		options:    options,
+		state:      WorkerStatePending,
+	}
+	if !options.Enabled {
+		w.state = WorkerStateDisabled
 	}
+
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+	wm.workers = append(wm.workers, w)
 }
 
-// Start starts all workers by subscribing to message queue topics
+// Start subscribes every enabled worker. A worker that fails to subscribe is marked
+// failed and the others still start; the failures are returned together.
 func (wm *WorkerManager) Start() error {
 	if wm.messageQueue == nil {
 		wm.logger.Warn("Message queue not configured, workers will not start")
 		return nil
 	}
 
-	// Subscribe hotness worker to vote events
-	if wm.hotnessWorker != nil {
-		if err := wm.messageQueue.Subscribe(mq.TopicVoteCreated, wm.hotnessWorker.HandleVoteCreated); err != nil {
-			return fmt.Errorf("failed to subscribe to vote.created: %w", err)
+	var errs []error
+	for _, w := range wm.snapshot() {
+		if w.getState() != WorkerStatePending {
+			continue
+		}
+
+		if err := w.subscriber.Subscribe(&workerQueue{MessageQueue: wm.messageQueue, manager: wm, worker: w}); err != nil {
+			w.fail(err)
+			wm.logger.Error("Failed to start worker", zap.String("worker", w.name), zap.Error(err))
+			errs = append(errs, fmt.Errorf("failed to start worker %s: %w", w.name, err))
+			continue
+		}
+
+		w.setState(WorkerStateRunning)
+		wm.logger.Info("Started worker",
+			zap.String("worker", w.name),
+			zap.Int("subscriptions", len(w.getSubscriptions())),
+			zap.Int("concurrency", w.options.Concurrency))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Stop stops the workers' consumers and waits until the messages they already received
+// are handled, or until ctx is done. Unhandled messages stay in their queues for the
+// next replica to pick up.
+func (wm *WorkerManager) Stop(ctx context.Context) error {
+	var stopping []*worker
+	var groups []string
+	for _, w := range wm.snapshot() {
+		state := w.getState()
+		if state != WorkerStateRunning && state != WorkerStateFailed {
+			continue
+		}
+		w.setState(WorkerStateStopping)
+		stopping = append(stopping, w)
+		for _, sub := range w.getSubscriptions() {
+			if sub.Group != "" {
+				groups = append(groups, sub.Group)
+			}
+		}
+	}
+
+	var err error
+	if drainer, ok := wm.messageQueue.(mq.Drainer); ok && len(groups) > 0 {
+		err = drainer.Drain(ctx, groups...)
+	}
+	if err == nil {
+		// Queues that cannot drain keep delivering; wait for the handlers running now
+		err = waitIdle(ctx, stopping)
+	}
+
+	for _, w := range stopping {
+		w.setState(WorkerStateStopped)
+	}
+
+	if err != nil {
+		wm.logger.Warn("Workers stopped before handling every in-flight message", zap.Error(err))
+		return err
+	}
+	wm.logger.Info("All workers stopped")
+	return nil
+}
+
+// Status reports every registered worker, with the lag of its consumer groups when
+// the message queue reports it. A nil manager reports no workers.
+func (wm *WorkerManager) Status() []WorkerStatus {
+	if wm == nil {
+		return []WorkerStatus{}
+	}
+
+	lagReporter, _ := wm.messageQueue.(mq.LagReporter)
+
+	workers := wm.snapshot()
+	statuses := make([]WorkerStatus, 0, len(workers))
+	for _, w := range workers {
+		status := w.status()
+		if lagReporter != nil && (status.State == WorkerStateRunning || status.State == WorkerStateFailed) {
+			for i, sub := range status.Subscriptions {
+				if sub.Group == "" {
+					continue
+				}
+				if lag, err := lagReporter.Lag(sub.Group, sub.Topic); err == nil {
+					status.Subscriptions[i].Lag = &lag
+				}
+			}
 		}
-		wm.logger.Info("Subscribed hotness worker to vote.created")
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
 
-		if err := wm.messageQueue.Subscribe(mq.TopicVoteUpdated, wm.hotnessWorker.HandleVoteUpdated); err != nil {
-			return fmt.Errorf("failed to subscribe to vote.updated: %w", err)
+// snapshot returns the registered workers
+func (wm *WorkerManager) snapshot() []*worker {
+	wm.mu.RLock()
+	defer wm.mu.RUnlock()
+	return append([]*worker(nil), wm.workers...)
+}
+
+// waitIdle waits until none of the workers is handling a message
+func waitIdle(ctx context.Context, workers []*worker) error {
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		var inFlight int64
+		for _, w := range workers {
+			inFlight += w.inFlight.Load()
+		}
+		if inFlight == 0 {
+			return nil
 		}
-		wm.logger.Info("Subscribed hotness worker to vote.updated")
 
-		if err := wm.messageQueue.Subscribe(mq.TopicVoteDeleted, wm.hotnessWorker.HandleVoteDeleted); err != nil {
-			return fmt.Errorf("failed to subscribe to vote.deleted: %w", err)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %d messages in flight", mq.ErrDrainTimeout, inFlight)
+		case <-ticker.C:
 		}
-		wm.logger.Info("Subscribed hotness worker to vote.deleted")
+	}
+}
+
+// worker is a registered worker and what it handled so far
+type worker struct {
+	name       string
+	subscriber EventSubscriber
+	options    WorkerOptions
+
+	inFlight  atomic.Int64
+	processed atomic.Int64
+	failed    atomic.Int64
+
+	mu            sync.Mutex
+	state         string
+	subscriptions []WorkerSubscription
+	lastError     string
+	lastErrorAt   time.Time
+	lastHandledAt time.Time
+}
+
+// track wraps a handler to count the messages the worker handles and remember its last error
+func (w *worker) track(handler mq.MessageHandler) mq.MessageHandler {
+	return func(ctx context.Context, message []byte) error {
+		w.inFlight.Add(1)
+		defer w.inFlight.Add(-1)
 
-		// Subscribe to comment events
-		if err := wm.messageQueue.Subscribe(mq.TopicCommentCreated, wm.hotnessWorker.HandleCommentCreated); err != nil {
-			return fmt.Errorf("failed to subscribe to comment.created: %w", err)
+		err := handler(ctx, message)
+
+		w.mu.Lock()
+		w.lastHandledAt = time.Now()
+		if err != nil {
+			w.lastError = err.Error()
+			w.lastErrorAt = w.lastHandledAt
 		}
-		wm.logger.Info("Subscribed hotness worker to comment.created")
+		w.mu.Unlock()
 
-		if err := wm.messageQueue.Subscribe(mq.TopicCommentDeleted, wm.hotnessWorker.HandleCommentDeleted); err != nil {
-			return fmt.Errorf("failed to subscribe to comment.deleted: %w", err)
+		if err != nil {
+			w.failed.Add(1)
+			workerMessagesTotal.WithLabelValues(w.name, "failed").Inc()
+			return err
 		}
-		wm.logger.Info("Subscribed hotness worker to comment.deleted")
+		w.processed.Add(1)
+		workerMessagesTotal.WithLabelValues(w.name, "processed").Inc()
+		return nil
+	}
+}
+
+// fail marks the worker failed with err
+func (w *worker) fail(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.state = WorkerStateFailed
+	w.lastError = err.Error()
+	w.lastErrorAt = time.Now()
+}
+
+func (w *worker) getState() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.state
+}
+
+func (w *worker) setState(state string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.state = state
+}
+
+func (w *worker) addSubscription(group, topic string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscriptions = append(w.subscriptions, WorkerSubscription{Group: group, Topic: topic})
+}
+
+func (w *worker) getSubscriptions() []WorkerSubscription {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]WorkerSubscription(nil), w.subscriptions...)
+}
+
+// status returns the worker's status without lag
+func (w *worker) status() WorkerStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	status := WorkerStatus{
+		Name:          w.name,
+		State:         w.state,
+		Concurrency:   w.options.Concurrency,
+		Subscriptions: append([]WorkerSubscription{}, w.subscriptions...),
+		InFlight:      w.inFlight.Load(),
+		Processed:     w.processed.Load(),
+		Failed:        w.failed.Load(),
+		LastError:     w.lastError,
 	}
+	if !w.lastErrorAt.IsZero() {
+		at := w.lastErrorAt
+		status.LastErrorAt = &at
+	}
+	if !w.lastHandledAt.IsZero() {
+		at := w.lastHandledAt
+		status.LastHandledAt = &at
+	}
+	return status
+}
 
-	wm.logger.Info("All workers started successfully")
+// workerQueue is the message queue a worker subscribes through. It records the worker's
+// subscriptions, applies its concurrency and wraps its handlers with deduplication and tracking.
+type workerQueue struct {
+	mq.MessageQueue
+	manager *WorkerManager
+	worker  *worker
+}
+
+// Subscribe subscribes the tracked handler to every message on the topic
+func (q *workerQueue) Subscribe(topic string, handler mq.MessageHandler) error {
+	if err := q.MessageQueue.Subscribe(topic, q.worker.track(handler)); err != nil {
+		return err
+	}
+	q.worker.addSubscription("", topic)
 	return nil
 }
 
-// Stop stops all workers
-func (wm *WorkerManager) Stop() error {
-	// Currently, RabbitMQ subscriptions are closed when the connection is closed
-	// No additional cleanup needed here
-	wm.logger.Info("All workers stopped")
+// SubscribeGroup subscribes the deduplicated, tracked handler with the worker's concurrency
+func (q *workerQueue) SubscribeGroup(group, topic string, handler mq.MessageHandler) error {
+	handler = q.worker.track(q.manager.deduplicator.Wrap(group, handler))
+
+	var err error
+	if subscriber, ok := q.MessageQueue.(mq.ConcurrentGroupSubscriber); ok && q.worker.options.Concurrency > 0 {
+		err = subscriber.SubscribeGroupConcurrency(group, topic, q.worker.options.Concurrency, handler)
+	} else {
+		err = q.MessageQueue.SubscribeGroup(group, topic, handler)
+	}
+	if err != nil {
+		return err
+	}
+
+	q.worker.addSubscription(group, topic)
 	return nil
 }
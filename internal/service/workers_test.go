@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kobayashirei/airy/internal/mq"
+)
+
+// subscriberFunc adapts a function to EventSubscriber
+type subscriberFunc func(messageQueue mq.MessageQueue) error
+
+func (f subscriberFunc) Subscribe(messageQueue mq.MessageQueue) error {
+	return f(messageQueue)
+}
+
+func TestWorkerManager(t *testing.T) {
+	t.Run("runs enabled workers and reports their state", func(t *testing.T) {
+		queue := mq.NewMemoryQueue(mq.MemoryConfig{Retry: mq.RetryPolicy{MaxAttempts: 1}})
+		defer queue.Close()
+
+		var handled atomic.Int32
+		manager := NewWorkerManager(queue, nil, nil)
+		manager.Register("counter", subscriberFunc(func(messageQueue mq.MessageQueue) error {
+			return messageQueue.SubscribeGroup("counter", "test.worker", func(ctx context.Context, message []byte) error {
+				if handled.Add(1) == 2 {
+					return errors.New("count unavailable")
+				}
+				return nil
+			})
+		}), WorkerOptions{Enabled: true, Concurrency: 2})
+		manager.Register("off", subscriberFunc(func(messageQueue mq.MessageQueue) error {
+			t.Fatal("disabled worker subscribed")
+			return nil
+		}), WorkerOptions{Enabled: false})
+
+		require.NoError(t, manager.Start())
+		require.NoError(t, queue.Publish(context.Background(), "test.worker", 1))
+		require.NoError(t, queue.Publish(context.Background(), "test.worker", 2))
+		require.Eventually(t, func() bool {
+			status := manager.Status()[0]
+			return status.Processed+status.Failed == 2
+		}, time.Second, 10*time.Millisecond)
+
+		statuses := manager.Status()
+		require.Len(t, statuses, 2)
+
+		counter := statuses[0]
+		assert.Equal(t, "counter", counter.Name)
+		assert.Equal(t, WorkerStateRunning, counter.State)
+		assert.Equal(t, 2, counter.Concurrency)
+		assert.Equal(t, int64(1), counter.Processed)
+		assert.Equal(t, int64(1), counter.Failed)
+		assert.Equal(t, "count unavailable", counter.LastError)
+		assert.NotNil(t, counter.LastErrorAt)
+		require.Len(t, counter.Subscriptions, 1)
+		assert.Equal(t, "counter", counter.Subscriptions[0].Group)
+		require.NotNil(t, counter.Subscriptions[0].Lag)
+		assert.Equal(t, 0, *counter.Subscriptions[0].Lag)
+
+		assert.Equal(t, WorkerStateDisabled, statuses[1].State)
+
+		// The worker's concurrency replaces the queue's default
+		for _, consumer := range queue.Health().Consumers {
+			assert.Equal(t, 2, consumer.Expected)
+		}
+	})
+
+	t.Run("keeps starting workers after one fails", func(t *testing.T) {
+		messageQueue := new(MockMessageQueue)
+		messageQueue.On("SubscribeGroup", "broken", "test.worker", mock.Anything).Return(errors.New("queue declare refused"))
+		messageQueue.On("SubscribeGroup", "healthy", "test.worker", mock.Anything).Return(nil)
+
+		manager := NewWorkerManager(messageQueue, nil, nil)
+		for _, group := range []string{"broken", "healthy"} {
+			group := group
+			manager.Register(group, subscriberFunc(func(messageQueue mq.MessageQueue) error {
+				return messageQueue.SubscribeGroup(group, "test.worker", func(ctx context.Context, message []byte) error { return nil })
+			}), WorkerOptions{Enabled: true})
+		}
+
+		err := manager.Start()
+		assert.ErrorContains(t, err, "failed to start worker broken")
+
+		statuses := manager.Status()
+		assert.Equal(t, WorkerStateFailed, statuses[0].State)
+		assert.Equal(t, "queue declare refused", statuses[0].LastError)
+		assert.Equal(t, WorkerStateRunning, statuses[1].State)
+	})
+
+	t.Run("stop waits for in-flight messages", func(t *testing.T) {
+		queue := mq.NewMemoryQueue(mq.MemoryConfig{})
+		defer queue.Close()
+
+		started := make(chan struct{})
+		var finished atomic.Bool
+		manager := NewWorkerManager(queue, nil, nil)
+		manager.Register("slow", subscriberFunc(func(messageQueue mq.MessageQueue) error {
+			return messageQueue.SubscribeGroup("slow", "test.worker", func(ctx context.Context, message []byte) error {
+				close(started)
+				time.Sleep(50 * time.Millisecond)
+				finished.Store(true)
+				return nil
+			})
+		}), WorkerOptions{Enabled: true})
+		require.NoError(t, manager.Start())
+
+		require.NoError(t, queue.Publish(context.Background(), "test.worker", 1))
+		<-started
+
+		require.NoError(t, manager.Stop(context.Background()))
+		assert.True(t, finished.Load())
+		assert.Equal(t, WorkerStateStopped, manager.Status()[0].State)
+	})
+
+	t.Run("stop gives up when the context ends", func(t *testing.T) {
+		queue := mq.NewMemoryQueue(mq.MemoryConfig{})
+
+		started := make(chan struct{})
+		release := make(chan struct{})
+		manager := NewWorkerManager(queue, nil, nil)
+		manager.Register("stuck", subscriberFunc(func(messageQueue mq.MessageQueue) error {
+			return messageQueue.SubscribeGroup("stuck", "test.worker", func(ctx context.Context, message []byte) error {
+				close(started)
+				<-release
+				return nil
+			})
+		}), WorkerOptions{Enabled: true})
+		require.NoError(t, manager.Start())
+
+		require.NoError(t, queue.Publish(context.Background(), "test.worker", 1))
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, manager.Stop(ctx), mq.ErrDrainTimeout)
+
+		close(release)
+		assert.NoError(t, queue.Close())
+	})
+
+	t.Run("a nil manager reports no workers", func(t *testing.T) {
+		var manager *WorkerManager
+		assert.Empty(t, manager.Status())
+	})
+}
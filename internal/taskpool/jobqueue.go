@@ -0,0 +1,738 @@
+package taskpool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// Job results counted by jobqueue_jobs_total
+const (
+	jobResultSucceeded = "succeeded"
+	jobResultRetried   = "retried"
+	jobResultDead      = "dead"
+)
+
+var (
+	// ErrJobNotFound is returned for a job that is not in the state the operation expects
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobQueueStopTimeout is returned by Stop when running jobs outlive its context
+	ErrJobQueueStopTimeout = errors.New("timeout waiting for running jobs")
+)
+
+var (
+	jobsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "jobqueue_jobs_total",
+			Help: "Total number of job attempts by task and result",
+		},
+		[]string{"task", "result"},
+	)
+
+	jobsInState = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "jobqueue_jobs",
+			Help: "Number of jobs in the job queue by state, as last seen by this replica",
+		},
+		[]string{"state"},
+	)
+)
+
+// Job is a task stored in the job queue with its arguments
+type Job struct {
+	ID         string          `json:"id"`
+	Name       string          `json:"name"` // The task name in the registry
+	Args       json.RawMessage `json:"args"`
+	UniqueKey  string          `json:"unique_key,omitempty"`
+	Attempts   int             `json:"attempts"`
+	RunAt      time.Time       `json:"run_at"`
+	EnqueuedAt time.Time       `json:"enqueued_at"`
+	LastError  string          `json:"last_error,omitempty"`
+	FailedAt   *time.Time      `json:"failed_at,omitempty"` // When the job was moved to the dead set
+}
+
+// JobQueueStats counts the jobs in each state
+type JobQueueStats struct {
+	Scheduled int64 `json:"scheduled"` // Waiting for their run time, including retries
+	Due       int64 `json:"due"`       // Scheduled jobs whose run time has passed
+	Running   int64 `json:"running"`
+	Dead      int64 `json:"dead"`
+}
+
+// JobQueueConfig holds configuration for the job queue
+type JobQueueConfig struct {
+	// Prefix of the Redis keys, wrapped in braces so that every key of the queue
+	// hashes to the same Redis Cluster slot (default "jobs")
+	Prefix string
+	// Concurrency is the number of jobs this replica runs at once (default 4)
+	Concurrency int
+	// PollInterval is how often due jobs are claimed (default 1s)
+	PollInterval time.Duration
+	// VisibilityTimeout is how long a claimed job stays invisible to other replicas
+	// without a heartbeat before it is run again (default 5m)
+	VisibilityTimeout time.Duration
+	// Retry is how failed jobs are retried, unless their task type has its own policy
+	Retry RetryPolicy
+	// MaxDead bounds the dead set; the oldest dead jobs are dropped beyond it (default 10000)
+	MaxDead int
+	// Logger for logging job execution
+	Logger *zap.Logger
+}
+
+// JobQueue is a durable queue of tasks in Redis. Jobs are kept in sorted sets by the
+// time they are due, so they survive restarts and can be scheduled for later. Every
+// replica may work the queue: claiming moves a job to the running set atomically,
+// and a job whose replica dies is run again once its visibility timeout passes.
+// Jobs that keep failing end in a bounded dead set.
+//
+// Jobs are tasks from the registry, built from their JSON arguments when they run,
+// so that their service dependencies are those of the replica running them.
+type JobQueue struct {
+	client   redis.UniversalClient
+	registry *Registry
+	config   JobQueueConfig
+	logger   *zap.Logger
+
+	scheduledKey string
+	runningKey   string
+	deadKey      string
+
+	periodicMu sync.Mutex
+	periodic   []periodicJob
+
+	slots    chan struct{} // One per running job
+	running  sync.WaitGroup
+	ctx      context.Context // Cancelled when Stop gives up on running jobs
+	cancel   context.CancelFunc
+	done     chan struct{}
+	stopOnce sync.Once
+	poller   sync.WaitGroup
+}
+
+// NewJobQueue creates a job queue that builds its jobs with registry
+func NewJobQueue(client redis.UniversalClient, registry *Registry, config JobQueueConfig) *JobQueue {
+	if config.Prefix == "" {
+		config.Prefix = "jobs"
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 4
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+	if config.VisibilityTimeout <= 0 {
+		config.VisibilityTimeout = 5 * time.Minute
+	}
+	if config.Retry.MaxAttempts <= 0 {
+		config.Retry = RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: 10 * time.Minute}
+	}
+	if config.MaxDead <= 0 {
+		config.MaxDead = 10000
+	}
+	if config.Logger == nil {
+		config.Logger = zap.NewNop()
+	}
+
+	prefix := "{" + config.Prefix + "}"
+	ctx, cancel := context.WithCancel(context.Background())
+	return &JobQueue{
+		client:       client,
+		registry:     registry,
+		config:       config,
+		logger:       config.Logger,
+		scheduledKey: prefix + ":scheduled",
+		runningKey:   prefix + ":running",
+		deadKey:      prefix + ":dead",
+		slots:        make(chan struct{}, config.Concurrency),
+		ctx:          ctx,
+		cancel:       cancel,
+		done:         make(chan struct{}),
+	}
+}
+
+// periodicJob is a job enqueued every interval
+type periodicJob struct {
+	key      string
+	interval time.Duration
+	name     string
+	args     interface{}
+}
+
+// keyPrefix is the prefix of the job and unique keys built by the scripts
+func (q *JobQueue) keyPrefix() string {
+	return "{" + q.config.Prefix + "}"
+}
+
+// releaseUniqueLua defines release_unique(prefix, id), which frees the unique key
+// of a job that is done with, if the key still points to the job
+const releaseUniqueLua = `
+local function release_unique(prefix, id)
+	local unique = redis.call('HGET', prefix .. ':job:' .. id, 'unique_key')
+	if unique and unique ~= '' then
+		local unique_key = prefix .. ':unique:' .. unique
+		if redis.call('GET', unique_key) == id then
+			redis.call('DEL', unique_key)
+		end
+	end
+end
+`
+
+// enqueueScript adds a job unless its unique key is taken.
+// KEYS: scheduled. ARGV: prefix, id, run_at, name, args, unique_key, enqueued_at.
+// Returns {1, id} for a new job, {0, existing id} for a taken unique key.
+var enqueueScript = redis.NewScript(`
+local prefix, id, unique = ARGV[1], ARGV[2], ARGV[6]
+if unique ~= '' then
+	local unique_key = prefix .. ':unique:' .. unique
+	local existing = redis.call('GET', unique_key)
+	if existing then
+		return {0, existing}
+	end
+	redis.call('SET', unique_key, id)
+end
+redis.call('HSET', prefix .. ':job:' .. id,
+	'name', ARGV[4], 'args', ARGV[5], 'unique_key', unique,
+	'attempts', 0, 'run_at', ARGV[3], 'enqueued_at', ARGV[7])
+redis.call('ZADD', KEYS[1], ARGV[3], id)
+return {1, id}
+`)
+
+// claimScript requeues the claimed jobs whose visibility timeout passed, then claims
+// up to limit due jobs, counting an attempt for each.
+// KEYS: scheduled, running. ARGV: prefix, now, deadline, limit.
+// Returns id, name, args, attempts, unique_key, run_at, enqueued_at, last_error for each job.
+var claimScript = redis.NewScript(`
+local prefix, now = ARGV[1], ARGV[2]
+local expired = redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', now)
+for _, id in ipairs(expired) do
+	redis.call('ZREM', KEYS[2], id)
+	redis.call('ZADD', KEYS[1], now, id)
+end
+
+local jobs = {}
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', now, 'LIMIT', 0, ARGV[4])
+for _, id in ipairs(ids) do
+	redis.call('ZREM', KEYS[1], id)
+	local job_key = prefix .. ':job:' .. id
+	if redis.call('EXISTS', job_key) == 1 then
+		local attempts = redis.call('HINCRBY', job_key, 'attempts', 1)
+		redis.call('ZADD', KEYS[2], ARGV[3], id)
+		local fields = redis.call('HMGET', job_key, 'name', 'args', 'unique_key', 'run_at', 'enqueued_at', 'last_error')
+		table.insert(jobs, id)
+		table.insert(jobs, fields[1] or '')
+		table.insert(jobs, fields[2] or '')
+		table.insert(jobs, tostring(attempts))
+		table.insert(jobs, fields[3] or '')
+		table.insert(jobs, fields[4] or '0')
+		table.insert(jobs, fields[5] or '0')
+		table.insert(jobs, fields[6] or '')
+	end
+end
+return jobs
+`)
+
+// completeScript deletes a job that succeeded, wherever it is.
+// KEYS: scheduled, running. ARGV: prefix, id.
+var completeScript = redis.NewScript(releaseUniqueLua + `
+local prefix, id = ARGV[1], ARGV[2]
+redis.call('ZREM', KEYS[2], id)
+redis.call('ZREM', KEYS[1], id)
+release_unique(prefix, id)
+redis.call('DEL', prefix .. ':job:' .. id)
+return 1
+`)
+
+// retryScript schedules a failed job again, if this replica still holds its claim.
+// KEYS: scheduled, running. ARGV: prefix, id, run_at, error.
+var retryScript = redis.NewScript(`
+local prefix, id = ARGV[1], ARGV[2]
+if redis.call('ZREM', KEYS[2], id) == 0 then
+	return 0
+end
+redis.call('HSET', prefix .. ':job:' .. id, 'last_error', ARGV[4], 'run_at', ARGV[3])
+redis.call('ZADD', KEYS[1], ARGV[3], id)
+return 1
+`)
+
+// killScript moves a failed job to the dead set, if this replica still holds its
+// claim, and drops the oldest dead jobs beyond max_dead.
+// KEYS: running, dead. ARGV: prefix, id, now, error, max_dead.
+var killScript = redis.NewScript(releaseUniqueLua + `
+local prefix, id = ARGV[1], ARGV[2]
+if redis.call('ZREM', KEYS[1], id) == 0 then
+	return 0
+end
+release_unique(prefix, id)
+redis.call('HSET', prefix .. ':job:' .. id, 'last_error', ARGV[4], 'failed_at', ARGV[3])
+redis.call('ZADD', KEYS[2], ARGV[3], id)
+
+local excess = redis.call('ZCARD', KEYS[2]) - tonumber(ARGV[5])
+if excess > 0 then
+	local oldest = redis.call('ZRANGE', KEYS[2], 0, excess - 1)
+	for _, old in ipairs(oldest) do
+		redis.call('DEL', prefix .. ':job:' .. old)
+	end
+	redis.call('ZREMRANGEBYRANK', KEYS[2], 0, excess - 1)
+end
+return 1
+`)
+
+// cancelScript deletes a job that has not started.
+// KEYS: scheduled. ARGV: prefix, id.
+var cancelScript = redis.NewScript(releaseUniqueLua + `
+local prefix, id = ARGV[1], ARGV[2]
+if redis.call('ZREM', KEYS[1], id) == 0 then
+	return 0
+end
+release_unique(prefix, id)
+redis.call('DEL', prefix .. ':job:' .. id)
+return 1
+`)
+
+// retryDeadScript schedules a dead job to run now with fresh attempts. Its unique key
+// is taken again unless another job holds it.
+// KEYS: scheduled, dead. ARGV: prefix, id, now.
+var retryDeadScript = redis.NewScript(`
+local prefix, id = ARGV[1], ARGV[2]
+if redis.call('ZREM', KEYS[2], id) == 0 then
+	return 0
+end
+local job_key = prefix .. ':job:' .. id
+local unique = redis.call('HGET', job_key, 'unique_key')
+if unique and unique ~= '' then
+	redis.call('SET', prefix .. ':unique:' .. unique, id, 'NX')
+end
+redis.call('HSET', job_key, 'attempts', 0, 'run_at', ARGV[3])
+redis.call('HDEL', job_key, 'failed_at')
+redis.call('ZADD', KEYS[1], ARGV[3], id)
+return 1
+`)
+
+// advancePeriodScript moves a periodic job on to the current period, unless a replica
+// already did.
+// KEYS: period. ARGV: period, ttl in milliseconds.
+// Returns 1 when this replica moved it on and is to enqueue the job.
+var advancePeriodScript = redis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if current and tonumber(current) >= tonumber(ARGV[1]) then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+return 1
+`)
+
+// deleteDeadScript deletes a dead job.
+// KEYS: dead. ARGV: prefix, id.
+var deleteDeadScript = redis.NewScript(`
+if redis.call('ZREM', KEYS[1], ARGV[2]) == 0 then
+	return 0
+end
+redis.call('DEL', ARGV[1] .. ':job:' .. ARGV[2])
+return 1
+`)
+
+// Enqueue adds a job that runs as soon as a replica is free
+func (q *JobQueue) Enqueue(ctx context.Context, name string, args interface{}) (string, error) {
+	id, _, err := q.enqueue(ctx, name, args, time.Now(), "")
+	return id, err
+}
+
+// EnqueueAt adds a job that runs at runAt
+func (q *JobQueue) EnqueueAt(ctx context.Context, name string, args interface{}, runAt time.Time) (string, error) {
+	id, _, err := q.enqueue(ctx, name, args, runAt, "")
+	return id, err
+}
+
+// EnqueueUnique adds a job that runs at runAt, unless a job with the same unique key is
+// waiting or running. It returns the ID of the new or the existing job, and whether the
+// job was added. The key is freed when its job succeeds, dies or is cancelled.
+func (q *JobQueue) EnqueueUnique(ctx context.Context, uniqueKey, name string, args interface{}, runAt time.Time) (string, bool, error) {
+	if uniqueKey == "" {
+		return "", false, errors.New("unique key is empty")
+	}
+	return q.enqueue(ctx, name, args, runAt, uniqueKey)
+}
+
+func (q *JobQueue) enqueue(ctx context.Context, name string, args interface{}, runAt time.Time, uniqueKey string) (string, bool, error) {
+	raw, ok := args.(json.RawMessage)
+	if !ok {
+		encoded, err := json.Marshal(args)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to marshal arguments of job %s: %w", name, err)
+		}
+		raw = encoded
+	}
+
+	// Reject jobs this replica could not run before they are stored
+	if _, err := q.registry.New(name, raw); err != nil {
+		return "", false, err
+	}
+
+	result, err := enqueueScript.Run(ctx, q.client, []string{q.scheduledKey},
+		q.keyPrefix(), uuid.New().String(), runAt.UnixMilli(), name, string(raw), uniqueKey, time.Now().UnixMilli(),
+	).Slice()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to enqueue job %s: %w", name, err)
+	}
+	added, _ := result[0].(int64)
+	id, _ := result[1].(string)
+	return id, added == 1, nil
+}
+
+// Cancel deletes a job that has not started yet
+func (q *JobQueue) Cancel(ctx context.Context, id string) error {
+	removed, err := cancelScript.Run(ctx, q.client, []string{q.scheduledKey}, q.keyPrefix(), id).Int()
+	if err != nil {
+		return fmt.Errorf("failed to cancel job %s: %w", id, err)
+	}
+	if removed == 0 {
+		return fmt.Errorf("%w: %s is not scheduled", ErrJobNotFound, id)
+	}
+	return nil
+}
+
+// CancelUnique deletes the job holding a unique key, if it has not started yet
+func (q *JobQueue) CancelUnique(ctx context.Context, uniqueKey string) error {
+	id, err := q.client.Get(ctx, q.keyPrefix()+":unique:"+uniqueKey).Result()
+	if errors.Is(err, redis.Nil) {
+		return fmt.Errorf("%w: no job holds %s", ErrJobNotFound, uniqueKey)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find job %s: %w", uniqueKey, err)
+	}
+	return q.Cancel(ctx, id)
+}
+
+// Stats counts the jobs in each state
+func (q *JobQueue) Stats(ctx context.Context) (*JobQueueStats, error) {
+	pipe := q.client.Pipeline()
+	scheduled := pipe.ZCard(ctx, q.scheduledKey)
+	due := pipe.ZCount(ctx, q.scheduledKey, "-inf", strconv.FormatInt(time.Now().UnixMilli(), 10))
+	running := pipe.ZCard(ctx, q.runningKey)
+	dead := pipe.ZCard(ctx, q.deadKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to count jobs: %w", err)
+	}
+	return &JobQueueStats{
+		Scheduled: scheduled.Val(),
+		Due:       due.Val(),
+		Running:   running.Val(),
+		Dead:      dead.Val(),
+	}, nil
+}
+
+// Dead lists up to limit dead jobs, most recently failed first
+func (q *JobQueue) Dead(ctx context.Context, limit int) ([]Job, error) {
+	ids, err := q.client.ZRevRange(ctx, q.deadKey, 0, int64(limit)-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead jobs: %w", err)
+	}
+
+	pipe := q.client.Pipeline()
+	fields := make([]*redis.MapStringStringCmd, len(ids))
+	for i, id := range ids {
+		fields[i] = pipe.HGetAll(ctx, q.keyPrefix()+":job:"+id)
+	}
+	if len(ids) > 0 {
+		if _, err := pipe.Exec(ctx); err != nil {
+			return nil, fmt.Errorf("failed to load dead jobs: %w", err)
+		}
+	}
+
+	jobs := make([]Job, 0, len(ids))
+	for i, id := range ids {
+		if values := fields[i].Val(); len(values) > 0 {
+			jobs = append(jobs, jobFromHash(id, values))
+		}
+	}
+	return jobs, nil
+}
+
+// RetryDead schedules a dead job to run now with fresh attempts
+func (q *JobQueue) RetryDead(ctx context.Context, id string) error {
+	moved, err := retryDeadScript.Run(ctx, q.client, []string{q.scheduledKey, q.deadKey},
+		q.keyPrefix(), id, time.Now().UnixMilli()).Int()
+	if err != nil {
+		return fmt.Errorf("failed to retry job %s: %w", id, err)
+	}
+	if moved == 0 {
+		return fmt.Errorf("%w: %s is not dead", ErrJobNotFound, id)
+	}
+	return nil
+}
+
+// DeleteDead deletes a dead job
+func (q *JobQueue) DeleteDead(ctx context.Context, id string) error {
+	deleted, err := deleteDeadScript.Run(ctx, q.client, []string{q.deadKey}, q.keyPrefix(), id).Int()
+	if err != nil {
+		return fmt.Errorf("failed to delete job %s: %w", id, err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("%w: %s is not dead", ErrJobNotFound, id)
+	}
+	return nil
+}
+
+// Every enqueues a job once every interval, on whichever replica working the queue
+// gets to it first. key identifies the periodic job across replicas and is the job's
+// unique key, so a run that outlasts the interval is not joined by the next one.
+// Call it before Start.
+func (q *JobQueue) Every(key string, interval time.Duration, name string, args interface{}) {
+	if interval < time.Millisecond {
+		panic("taskpool: interval of JobQueue.Every must be at least a millisecond")
+	}
+
+	q.periodicMu.Lock()
+	defer q.periodicMu.Unlock()
+	q.periodic = append(q.periodic, periodicJob{key: key, interval: interval, name: name, args: args})
+}
+
+// enqueuePeriodic enqueues the periodic jobs whose period began since they were last enqueued
+func (q *JobQueue) enqueuePeriodic() {
+	q.periodicMu.Lock()
+	periodic := q.periodic
+	q.periodicMu.Unlock()
+
+	now := time.Now()
+	for _, job := range periodic {
+		period := now.UnixMilli() / job.interval.Milliseconds()
+		advanced, err := advancePeriodScript.Run(q.ctx, q.client, []string{q.keyPrefix() + ":periodic:" + job.key},
+			period, (2 * job.interval).Milliseconds()).Int()
+		if err != nil {
+			q.logger.Warn("failed to check periodic job", zap.String("key", job.key), zap.Error(err))
+			continue
+		}
+		if advanced == 0 {
+			continue
+		}
+		if _, _, err := q.enqueue(q.ctx, job.name, job.args, now, "periodic:"+job.key); err != nil {
+			q.logger.Warn("failed to enqueue periodic job", zap.String("key", job.key), zap.Error(err))
+		}
+	}
+}
+
+// Start starts claiming and running due jobs
+func (q *JobQueue) Start() {
+	q.poller.Add(1)
+	go q.poll()
+}
+
+// Stop stops claiming jobs and waits for the running ones. When ctx ends first, the
+// running jobs are cancelled; their claims expire and other replicas run them again.
+func (q *JobQueue) Stop(ctx context.Context) error {
+	q.stopOnce.Do(func() { close(q.done) })
+	q.poller.Wait()
+
+	idle := make(chan struct{})
+	go func() {
+		q.running.Wait()
+		close(idle)
+	}()
+
+	select {
+	case <-idle:
+		q.cancel()
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		return ErrJobQueueStopTimeout
+	}
+}
+
+// poll claims due jobs for the free slots every poll interval
+func (q *JobQueue) poll() {
+	defer q.poller.Done()
+
+	ticker := time.NewTicker(q.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		q.enqueuePeriodic()
+		q.claimAndRun()
+		select {
+		case <-q.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// claimAndRun claims as many due jobs as there are free slots and runs them
+func (q *JobQueue) claimAndRun() {
+	if stats, err := q.Stats(q.ctx); err == nil {
+		jobsInState.WithLabelValues("scheduled").Set(float64(stats.Scheduled))
+		jobsInState.WithLabelValues("running").Set(float64(stats.Running))
+		jobsInState.WithLabelValues("dead").Set(float64(stats.Dead))
+	}
+
+	free := cap(q.slots) - len(q.slots)
+	if free == 0 {
+		return
+	}
+
+	jobs, err := q.claim(q.ctx, free)
+	if err != nil {
+		q.logger.Warn("failed to claim jobs", zap.Error(err))
+		return
+	}
+	for _, job := range jobs {
+		q.slots <- struct{}{}
+		q.running.Add(1)
+		go q.run(job)
+	}
+}
+
+// claim moves up to limit due jobs to the running set
+func (q *JobQueue) claim(ctx context.Context, limit int) ([]Job, error) {
+	now := time.Now()
+	values, err := claimScript.Run(ctx, q.client, []string{q.scheduledKey, q.runningKey},
+		q.keyPrefix(), now.UnixMilli(), now.Add(q.config.VisibilityTimeout).UnixMilli(), limit,
+	).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	const fields = 8
+	jobs := make([]Job, 0, len(values)/fields)
+	for i := 0; i+fields <= len(values); i += fields {
+		attempts, _ := strconv.Atoi(values[i+3])
+		jobs = append(jobs, Job{
+			ID:         values[i],
+			Name:       values[i+1],
+			Args:       json.RawMessage(values[i+2]),
+			Attempts:   attempts,
+			UniqueKey:  values[i+4],
+			RunAt:      parseMillis(values[i+5]),
+			EnqueuedAt: parseMillis(values[i+6]),
+			LastError:  values[i+7],
+		})
+	}
+	return jobs, nil
+}
+
+// run executes a claimed job and completes, retries or kills it
+func (q *JobQueue) run(job Job) {
+	defer func() {
+		<-q.slots
+		q.running.Done()
+	}()
+
+	log := q.logger.With(zap.String("job_id", job.ID), zap.String("task", job.Name), zap.Int("attempt", job.Attempts))
+	ctx := context.Background() // Settling a job must outlive a cancelled run
+
+	task, err := q.registry.New(job.Name, job.Args)
+	if err != nil {
+		q.kill(ctx, job, err, log)
+		return
+	}
+
+	retry := q.config.Retry
+	if retrying, ok := task.(RetryingTask); ok && retrying.RetryPolicy().MaxAttempts > 0 {
+		retry = retrying.RetryPolicy()
+	}
+	if job.Attempts > retry.MaxAttempts {
+		// Attempts are counted when claimed, so replicas that died running the job count too
+		q.kill(ctx, job, fmt.Errorf("gave up after %d attempts; last error: %s", retry.MaxAttempts, job.LastError), log)
+		return
+	}
+
+	err = q.execute(job, task)
+	switch {
+	case err == nil:
+		if err := completeScript.Run(ctx, q.client, []string{q.scheduledKey, q.runningKey}, q.keyPrefix(), job.ID).Err(); err != nil {
+			// The claim expires and the job runs again, so tasks must be idempotent
+			log.Warn("failed to complete job", zap.Error(err))
+		}
+		jobsTotal.WithLabelValues(job.Name, jobResultSucceeded).Inc()
+	case IsPermanent(err) || job.Attempts >= retry.MaxAttempts:
+		q.kill(ctx, job, err, log)
+	default:
+		runAt := time.Now().Add(retry.Delay(job.Attempts))
+		if err := retryScript.Run(ctx, q.client, []string{q.scheduledKey, q.runningKey},
+			q.keyPrefix(), job.ID, runAt.UnixMilli(), err.Error()).Err(); err != nil {
+			log.Warn("failed to schedule job retry", zap.Error(err))
+		}
+		jobsTotal.WithLabelValues(job.Name, jobResultRetried).Inc()
+		log.Warn("job failed, retrying", zap.Error(err), zap.Time("retry_at", runAt))
+	}
+}
+
+// execute runs a job's task, extending its claim while it runs
+func (q *JobQueue) execute(job Job, task Task) (err error) {
+	ctx, cancel := context.WithCancel(q.ctx)
+	defer cancel()
+
+	go q.heartbeat(ctx, job.ID)
+
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("job panicked: %v", p)
+		}
+	}()
+	return task.Execute(ctx)
+}
+
+// heartbeat pushes back the visibility deadline of a running job until ctx ends
+func (q *JobQueue) heartbeat(ctx context.Context, id string) {
+	ticker := time.NewTicker(q.config.VisibilityTimeout / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deadline := time.Now().Add(q.config.VisibilityTimeout).UnixMilli()
+			// XX: a job whose claim expired belongs to whoever claims it next
+			if err := q.client.ZAddXX(ctx, q.runningKey, redis.Z{Score: float64(deadline), Member: id}).Err(); err != nil && ctx.Err() == nil {
+				q.logger.Warn("failed to extend job claim", zap.String("job_id", id), zap.Error(err))
+			}
+		}
+	}
+}
+
+// kill moves a job to the dead set
+func (q *JobQueue) kill(ctx context.Context, job Job, cause error, log *zap.Logger) {
+	if err := killScript.Run(ctx, q.client, []string{q.runningKey, q.deadKey},
+		q.keyPrefix(), job.ID, time.Now().UnixMilli(), cause.Error(), q.config.MaxDead).Err(); err != nil {
+		log.Warn("failed to move job to the dead set", zap.Error(err))
+	}
+	jobsTotal.WithLabelValues(job.Name, jobResultDead).Inc()
+	log.Error("job failed permanently, moved to the dead set", zap.Error(cause))
+}
+
+// jobFromHash builds a job from its Redis hash
+func jobFromHash(id string, values map[string]string) Job {
+	attempts, _ := strconv.Atoi(values["attempts"])
+	job := Job{
+		ID:         id,
+		Name:       values["name"],
+		Args:       json.RawMessage(values["args"]),
+		UniqueKey:  values["unique_key"],
+		Attempts:   attempts,
+		RunAt:      parseMillis(values["run_at"]),
+		EnqueuedAt: parseMillis(values["enqueued_at"]),
+		LastError:  values["last_error"],
+	}
+	if failedAt, ok := values["failed_at"]; ok {
+		t := parseMillis(failedAt)
+		job.FailedAt = &t
+	}
+	return job
+}
+
+// parseMillis parses a Unix time in milliseconds
+func parseMillis(value string) time.Time {
+	millis, _ := strconv.ParseInt(value, 10, 64)
+	return time.UnixMilli(millis)
+}
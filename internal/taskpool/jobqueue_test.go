@@ -0,0 +1,218 @@
+package taskpool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTestRedis creates a test Redis client
+func setupTestRedis(t *testing.T) *redis.Client {
+	client := redis.NewClient(&redis.Options{
+		Addr: "localhost:6379",
+		DB:   15, // Use a separate DB for testing
+	})
+
+	ctx := context.Background()
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skip("Redis not available for testing")
+	}
+
+	// Clean up test DB
+	client.FlushDB(ctx)
+
+	return client
+}
+
+// countingTask counts its runs and fails while failing is positive
+type countingTask struct {
+	runs    *int32
+	failing *int32
+	fatal   bool
+}
+
+func (t *countingTask) Execute(ctx context.Context) error {
+	atomic.AddInt32(t.runs, 1)
+	if atomic.AddInt32(t.failing, -1) >= 0 {
+		if t.fatal {
+			return Permanent(errors.New("fatal failure"))
+		}
+		return errors.New("transient failure")
+	}
+	return nil
+}
+
+func newTestJobQueue(t *testing.T, client *redis.Client, task *countingTask) *JobQueue {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register("test.count", func(json.RawMessage) (Task, error) {
+		return task, nil
+	}))
+
+	return NewJobQueue(client, registry, JobQueueConfig{
+		Prefix:       "testjobs",
+		PollInterval: 20 * time.Millisecond,
+		Retry:        RetryPolicy{MaxAttempts: 3, BaseDelay: 10 * time.Millisecond, MaxDelay: 20 * time.Millisecond},
+	})
+}
+
+func TestJobQueue_RunsDueJobs(t *testing.T) {
+	client := setupTestRedis(t)
+	defer client.Close()
+
+	var runs, failing int32
+	jobs := newTestJobQueue(t, client, &countingTask{runs: &runs, failing: &failing})
+	ctx := context.Background()
+
+	_, err := jobs.Enqueue(ctx, "test.count", nil)
+	require.NoError(t, err)
+	_, err = jobs.EnqueueAt(ctx, "test.count", nil, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	jobs.Start()
+	defer jobs.Stop(ctx)
+
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&runs) == 1 }, 2*time.Second, 10*time.Millisecond)
+
+	stats, err := jobs.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Scheduled)
+	assert.Equal(t, int64(0), stats.Due)
+}
+
+func TestJobQueue_EnqueueUnique(t *testing.T) {
+	client := setupTestRedis(t)
+	defer client.Close()
+
+	var runs, failing int32
+	jobs := newTestJobQueue(t, client, &countingTask{runs: &runs, failing: &failing})
+	ctx := context.Background()
+	runAt := time.Now().Add(time.Hour)
+
+	id, added, err := jobs.EnqueueUnique(ctx, "post:1", "test.count", nil, runAt)
+	require.NoError(t, err)
+	assert.True(t, added)
+
+	existing, added, err := jobs.EnqueueUnique(ctx, "post:1", "test.count", nil, runAt)
+	require.NoError(t, err)
+	assert.False(t, added)
+	assert.Equal(t, id, existing)
+
+	require.NoError(t, jobs.CancelUnique(ctx, "post:1"))
+	assert.ErrorIs(t, jobs.Cancel(ctx, id), ErrJobNotFound)
+
+	// The key is free again once its job is cancelled
+	_, added, err = jobs.EnqueueUnique(ctx, "post:1", "test.count", nil, runAt)
+	require.NoError(t, err)
+	assert.True(t, added)
+}
+
+func TestJobQueue_RetriesThenDies(t *testing.T) {
+	client := setupTestRedis(t)
+	defer client.Close()
+
+	var runs int32
+	failing := int32(10)
+	jobs := newTestJobQueue(t, client, &countingTask{runs: &runs, failing: &failing})
+	ctx := context.Background()
+
+	id, err := jobs.Enqueue(ctx, "test.count", nil)
+	require.NoError(t, err)
+
+	jobs.Start()
+	assert.Eventually(t, func() bool {
+		stats, err := jobs.Stats(ctx)
+		return err == nil && stats.Dead == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	require.NoError(t, jobs.Stop(ctx))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&runs))
+
+	dead, err := jobs.Dead(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, dead, 1)
+	assert.Equal(t, id, dead[0].ID)
+	assert.Equal(t, "transient failure", dead[0].LastError)
+	assert.NotNil(t, dead[0].FailedAt)
+
+	// Retrying runs the job again with fresh attempts
+	atomic.StoreInt32(&failing, 0)
+	require.NoError(t, jobs.RetryDead(ctx, id))
+	assert.ErrorIs(t, jobs.RetryDead(ctx, id), ErrJobNotFound)
+
+	jobs = newTestJobQueue(t, client, &countingTask{runs: &runs, failing: &failing})
+	jobs.Start()
+	defer jobs.Stop(ctx)
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&runs) == 4 }, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestJobQueue_PermanentFailure(t *testing.T) {
+	client := setupTestRedis(t)
+	defer client.Close()
+
+	var runs int32
+	failing := int32(1)
+	jobs := newTestJobQueue(t, client, &countingTask{runs: &runs, failing: &failing, fatal: true})
+	ctx := context.Background()
+
+	id, err := jobs.Enqueue(ctx, "test.count", nil)
+	require.NoError(t, err)
+
+	jobs.Start()
+	assert.Eventually(t, func() bool {
+		stats, err := jobs.Stats(ctx)
+		return err == nil && stats.Dead == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	require.NoError(t, jobs.Stop(ctx))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&runs))
+
+	require.NoError(t, jobs.DeleteDead(ctx, id))
+	assert.ErrorIs(t, jobs.DeleteDead(ctx, id), ErrJobNotFound)
+}
+
+func TestJobQueue_UnknownTaskDies(t *testing.T) {
+	client := setupTestRedis(t)
+	defer client.Close()
+
+	var runs, failing int32
+	jobs := newTestJobQueue(t, client, &countingTask{runs: &runs, failing: &failing})
+	ctx := context.Background()
+
+	_, err := jobs.Enqueue(ctx, "test.unknown", nil)
+	require.NoError(t, err)
+
+	jobs.Start()
+	defer jobs.Stop(ctx)
+	assert.Eventually(t, func() bool {
+		stats, err := jobs.Stats(ctx)
+		return err == nil && stats.Dead == 1
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestJobQueue_Every(t *testing.T) {
+	client := setupTestRedis(t)
+	defer client.Close()
+
+	var runs, failing int32
+	task := &countingTask{runs: &runs, failing: &failing}
+	ctx := context.Background()
+
+	// Two replicas enqueue the periodic job once per period between them
+	first := newTestJobQueue(t, client, task)
+	second := newTestJobQueue(t, client, task)
+	first.Every("test.count:0", 200*time.Millisecond, "test.count", nil)
+	second.Every("test.count:0", 200*time.Millisecond, "test.count", nil)
+
+	first.Start()
+	second.Start()
+	time.Sleep(time.Second)
+	require.NoError(t, first.Stop(ctx))
+	require.NoError(t, second.Stop(ctx))
+
+	assert.InDelta(t, 5, atomic.LoadInt32(&runs), 1)
+}
@@ -7,9 +7,51 @@ import (
 	"time"
 
 	"github.com/panjf2000/ants/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"go.uber.org/zap"
 )
 
+// Task results counted by taskpool_tasks_total
+const (
+	resultSucceeded = "succeeded"
+	resultFailed    = "failed"
+)
+
+var (
+	tasksQueued = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "taskpool_tasks_queued",
+			Help: "Number of submitted tasks waiting for a goroutine or a retry",
+		},
+		[]string{"task"},
+	)
+
+	tasksRunning = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "taskpool_tasks_running",
+			Help: "Number of tasks being executed",
+		},
+		[]string{"task"},
+	)
+
+	tasksTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "taskpool_tasks_total",
+			Help: "Total number of finished tasks by result, after retries",
+		},
+		[]string{"task", "result"},
+	)
+
+	taskRetriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "taskpool_task_retries_total",
+			Help: "Total number of task attempts that failed and were retried",
+		},
+		[]string{"task"},
+	)
+)
+
 // Task represents an asynchronous task that can be executed
 type Task interface {
 	Execute(ctx context.Context) error
@@ -25,11 +67,13 @@ func (f TaskFunc) Execute(ctx context.Context) error {
 
 // Pool wraps ants pool and provides task submission with error handling
 type Pool struct {
-	pool   *ants.Pool
-	logger *zap.Logger
-	wg     sync.WaitGroup
-	ctx    context.Context
-	cancel context.CancelFunc
+	pool     *ants.Pool
+	logger   *zap.Logger
+	retry    RetryPolicy
+	registry *Registry
+	wg       sync.WaitGroup
+	ctx      context.Context
+	cancel   context.CancelFunc
 }
 
 // Config holds configuration for the task pool
@@ -48,6 +92,10 @@ type Config struct {
 	PanicHandler func(interface{})
 	// Logger for logging task execution
 	Logger *zap.Logger
+	// Retry is how failed tasks are retried, unless their type has its own policy
+	Retry RetryPolicy
+	// Registry builds the tasks submitted by name with SubmitTask
+	Registry *Registry
 }
 
 // DefaultConfig returns a default configuration for the task pool
@@ -59,6 +107,7 @@ func DefaultConfig() *Config {
 		MaxBlockingTasks: 0,
 		Nonblocking:      false,
 		Logger:           zap.NewNop(),
+		Retry:            DefaultRetryPolicy(),
 	}
 }
 
@@ -68,8 +117,6 @@ func NewPool(config *Config) (*Pool, error) {
 		config = DefaultConfig()
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-
 	options := []ants.Option{
 		ants.WithExpiryDuration(config.ExpiryDuration),
 		ants.WithPreAlloc(config.PreAlloc),
@@ -101,11 +148,23 @@ func NewPool(config *Config) (*Pool, error) {
 		return nil, fmt.Errorf("failed to create ants pool: %w", err)
 	}
 
+	retry := config.Retry
+	if retry.MaxAttempts <= 0 {
+		retry.MaxAttempts = 1
+	}
+	registry := config.Registry
+	if registry == nil {
+		registry = NewRegistry()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
 	return &Pool{
-		pool:   pool,
-		logger: config.Logger,
-		ctx:    ctx,
-		cancel: cancel,
+		pool:     pool,
+		logger:   config.Logger,
+		retry:    retry,
+		registry: registry,
+		ctx:      ctx,
+		cancel:   cancel,
 	}, nil
 }
 
@@ -116,31 +175,104 @@ func (p *Pool) Submit(task Task) error {
 		return fmt.Errorf("task pool is closed")
 	}
 
+	name := taskName(task)
 	p.wg.Add(1)
-	
+	tasksQueued.WithLabelValues(name).Inc()
+
 	err := p.pool.Submit(func() {
 		defer p.wg.Done()
-		
-		// Create a context with timeout for the task
-		ctx, cancel := context.WithTimeout(p.ctx, 5*time.Minute)
-		defer cancel()
+		p.run(name, task)
+	})
+
+	if err != nil {
+		tasksQueued.WithLabelValues(name).Dec()
+		p.wg.Done() // Decrement if submission failed
+		return fmt.Errorf("failed to submit task: %w", err)
+	}
+
+	return nil
+}
+
+// SubmitTask builds the task registered under name from its arguments and submits it
+func (p *Pool) SubmitTask(name string, args interface{}) error {
+	task, err := p.registry.New(name, args)
+	if err != nil {
+		return err
+	}
+	return p.Submit(task)
+}
+
+// Registry returns the registry SubmitTask builds tasks with
+func (p *Pool) Registry() *Registry {
+	return p.registry
+}
+
+// run executes a queued task, retrying it with its retry policy until it succeeds,
+// fails permanently or the pool is released
+func (p *Pool) run(name string, task Task) {
+	retry := p.retry
+	if retrying, ok := task.(RetryingTask); ok && retrying.RetryPolicy().MaxAttempts > 0 {
+		retry = retrying.RetryPolicy()
+	}
 
-		// Execute the task and handle errors
-		if err := task.Execute(ctx); err != nil {
+	for attempt := 1; ; attempt++ {
+		tasksQueued.WithLabelValues(name).Dec()
+		err := p.execute(name, task)
+
+		if err == nil {
+			tasksTotal.WithLabelValues(name, resultSucceeded).Inc()
+			return
+		}
+
+		if attempt >= retry.MaxAttempts || IsPermanent(err) || p.ctx.Err() != nil {
+			tasksTotal.WithLabelValues(name, resultFailed).Inc()
 			if p.logger != nil {
 				p.logger.Error("task execution failed",
 					zap.Error(err),
-					zap.String("task_type", fmt.Sprintf("%T", task)))
+					zap.String("task_type", name),
+					zap.Int("attempts", attempt))
 			}
+			return
 		}
-	})
 
-	if err != nil {
-		p.wg.Done() // Decrement if submission failed
-		return fmt.Errorf("failed to submit task: %w", err)
+		// Wait for the retry in the queue; the goroutine stays with the task
+		taskRetriesTotal.WithLabelValues(name).Inc()
+		tasksQueued.WithLabelValues(name).Inc()
+		timer := time.NewTimer(retry.Delay(attempt))
+		select {
+		case <-timer.C:
+		case <-p.ctx.Done():
+			timer.Stop()
+			tasksQueued.WithLabelValues(name).Dec()
+			tasksTotal.WithLabelValues(name, resultFailed).Inc()
+			if p.logger != nil {
+				p.logger.Warn("task abandoned before its retry, pool released",
+					zap.Error(err),
+					zap.String("task_type", name),
+					zap.Int("attempts", attempt))
+			}
+			return
+		}
 	}
+}
 
-	return nil
+// execute runs one attempt of a task
+func (p *Pool) execute(name string, task Task) error {
+	tasksRunning.WithLabelValues(name).Inc()
+	defer tasksRunning.WithLabelValues(name).Dec() // Also when the task panics
+
+	// Create a context with timeout for the task
+	ctx, cancel := context.WithTimeout(p.ctx, 5*time.Minute)
+	defer cancel()
+	return task.Execute(ctx)
+}
+
+// taskName returns the type of a task for its metrics and logs
+func taskName(task Task) string {
+	if named, ok := task.(NamedTask); ok {
+		return named.TaskName()
+	}
+	return fmt.Sprintf("%T", task)
 }
 
 // SubmitFunc submits a function as a task to the pool
@@ -291,3 +291,65 @@ func TestPool_ContextCancellation(t *testing.T) {
 		assert.True(t, ctxCancelled.Load())
 	})
 }
+
+func TestPool_Retry(t *testing.T) {
+	newPool := func(t *testing.T) *Pool {
+		config := DefaultConfig()
+		config.Retry = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+		pool, err := NewPool(config)
+		require.NoError(t, err)
+		t.Cleanup(pool.Release)
+		return pool
+	}
+
+	t.Run("retries a failed task until it succeeds", func(t *testing.T) {
+		pool := newPool(t)
+
+		var attempts atomic.Int32
+		require.NoError(t, pool.SubmitFunc(func(ctx context.Context) error {
+			if attempts.Add(1) < 3 {
+				return errors.New("temporarily unavailable")
+			}
+			return nil
+		}))
+
+		pool.Wait()
+		assert.Equal(t, int32(3), attempts.Load())
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		pool := newPool(t)
+
+		var attempts atomic.Int32
+		require.NoError(t, pool.SubmitFunc(func(ctx context.Context) error {
+			attempts.Add(1)
+			return errors.New("down")
+		}))
+
+		pool.Wait()
+		assert.Equal(t, int32(3), attempts.Load())
+	})
+
+	t.Run("does not retry permanent errors", func(t *testing.T) {
+		pool := newPool(t)
+
+		var attempts atomic.Int32
+		require.NoError(t, pool.SubmitFunc(func(ctx context.Context) error {
+			attempts.Add(1)
+			return Permanent(errors.New("post deleted"))
+		}))
+
+		pool.Wait()
+		assert.Equal(t, int32(1), attempts.Load())
+	})
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	assert.Equal(t, 100*time.Millisecond, policy.Delay(1))
+	assert.Equal(t, 200*time.Millisecond, policy.Delay(2))
+	assert.Equal(t, 800*time.Millisecond, policy.Delay(4))
+	assert.Equal(t, time.Second, policy.Delay(5))
+	assert.Equal(t, time.Second, policy.Delay(50))
+}
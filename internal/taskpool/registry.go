@@ -0,0 +1,139 @@
+package taskpool
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrUnknownTask is returned when a task name was never registered
+var ErrUnknownTask = errors.New("unknown task")
+
+// Factory builds a task from its JSON encoded arguments
+type Factory func(args json.RawMessage) (Task, error)
+
+// NamedTask is a task that reports its type, used to label its metrics and logs
+type NamedTask interface {
+	Task
+	TaskName() string
+}
+
+// RetryingTask is a task with its own retry policy instead of the pool's
+type RetryingTask interface {
+	Task
+	RetryPolicy() RetryPolicy
+}
+
+// registration is a registered task type
+type registration struct {
+	factory Factory
+	retry   *RetryPolicy // nil uses the pool's policy
+}
+
+// Registry maps task names to the factories that build them, so that a task can be
+// submitted by name with plain arguments from anywhere, without its dependencies
+type Registry struct {
+	mu    sync.RWMutex
+	tasks map[string]registration
+}
+
+// NewRegistry creates an empty task registry
+func NewRegistry() *Registry {
+	return &Registry{tasks: make(map[string]registration)}
+}
+
+// Register registers a task type under name
+func (r *Registry) Register(name string, factory Factory) error {
+	return r.register(name, registration{factory: factory})
+}
+
+// RegisterWithRetry registers a task type under name, retried with its own policy
+func (r *Registry) RegisterWithRetry(name string, retry RetryPolicy, factory Factory) error {
+	return r.register(name, registration{factory: factory, retry: &retry})
+}
+
+func (r *Registry) register(name string, task registration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tasks[name]; ok {
+		return fmt.Errorf("task %s is already registered", name)
+	}
+	r.tasks[name] = task
+	return nil
+}
+
+// New builds the task registered under name from its arguments, a struct or
+// json.RawMessage. The task reports name as its type.
+func (r *Registry) New(name string, args interface{}) (Task, error) {
+	r.mu.RLock()
+	task, ok := r.tasks[name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTask, name)
+	}
+
+	raw, ok := args.(json.RawMessage)
+	if !ok {
+		encoded, err := json.Marshal(args)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal arguments of task %s: %w", name, err)
+		}
+		raw = encoded
+	}
+
+	built, err := task.factory(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build task %s: %w", name, err)
+	}
+	return &registeredTask{Task: built, name: name, retry: task.retry}, nil
+}
+
+// Names returns the registered task names in order
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.tasks))
+	for name := range r.tasks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// registeredTask is a task built by a registry
+type registeredTask struct {
+	Task
+	name  string
+	retry *RetryPolicy
+}
+
+// TaskName returns the name the task is registered under
+func (t *registeredTask) TaskName() string {
+	return t.name
+}
+
+// RetryPolicy returns the task's retry policy, or the zero policy to use the pool's
+func (t *registeredTask) RetryPolicy() RetryPolicy {
+	if t.retry == nil {
+		return RetryPolicy{}
+	}
+	return *t.retry
+}
+
+// FactoryFor returns a factory that decodes the arguments into a copy of the task
+// built by newTask, which sets its dependencies
+func FactoryFor[T Task](newTask func() T) Factory {
+	return func(args json.RawMessage) (Task, error) {
+		task := newTask()
+		if len(args) > 0 {
+			if err := json.Unmarshal(args, task); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal arguments: %w", err)
+			}
+		}
+		return task, nil
+	}
+}
@@ -0,0 +1,59 @@
+package taskpool
+
+import (
+	"errors"
+	"time"
+)
+
+// RetryPolicy bounds how often and how fast a failed task is retried
+type RetryPolicy struct {
+	MaxAttempts int           // Attempts, including the first; 1 disables retries
+	BaseDelay   time.Duration // Delay before the first retry, doubled on each further retry
+	MaxDelay    time.Duration // Upper bound of the retry delay
+}
+
+// DefaultRetryPolicy returns the default retry policy
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+	}
+}
+
+// Delay returns how long to wait before retrying after the given number of failed attempts
+func (p RetryPolicy) Delay(failed int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < failed; i++ {
+		delay *= 2
+		if delay >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	if delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return delay
+}
+
+// permanentError marks an error that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not worth retrying, e.g. the task's entity no longer exists
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
@@ -2,80 +2,309 @@ package taskpool
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 )
 
-// Common task implementations for the Airy system
+// Common task implementations for the Airy system. Each task holds its arguments, which
+// are JSON encoded when it is submitted by name, and the service doing the work, which
+// the factory registered by RegisterTasks sets.
+
+// Names of the tasks registered by RegisterTasks
+const (
+	TaskUpdateSearchIndex  = "search.update_index"
+	TaskSendNotification   = "notification.send"
+	TaskUpdateFeed         = "feed.update"
+	TaskUpdateHotnessScore = "hotness.update_score"
+	TaskUpdateCount        = "count.update"
+	TaskSendEmail          = "email.send"
+	TaskPublishPost        = "post.publish"
+	TaskRecomputeHotness   = "hotness.recompute"
+)
+
+// Count types of UpdateCountTask
+const (
+	CountTypeUpvote   = "upvote"
+	CountTypeDownvote = "downvote"
+	CountTypeComment  = "comment"
+	CountTypeFavorite = "favorite"
+)
+
+// ErrNoService is returned by a task built without the service it needs
+var ErrNoService = errors.New("task has no service")
+
+// PostIndexer writes a post's current state from the database to the search index
+type PostIndexer interface {
+	ReindexPost(ctx context.Context, postID int64) error
+}
+
+// Notification is a notification for a user, as SendNotificationTask delivers it
+type Notification struct {
+	Type          string `json:"type"`        // comment, vote, mention, system
+	EntityType    string `json:"entity_type"` // post, comment
+	EntityID      *int64 `json:"entity_id,omitempty"`
+	TriggerUserID *int64 `json:"trigger_user_id,omitempty"`
+	Content       string `json:"content"`
+}
+
+// Notifier delivers notifications to users
+type Notifier interface {
+	Notify(ctx context.Context, userID int64, notification Notification) error
+}
+
+// FeedUpdater pushes a new post to the feeds of its author's followers
+type FeedUpdater interface {
+	PushToFollowerFeeds(ctx context.Context, postID int64, authorID int64) error
+}
+
+// HotnessUpdater recalculates and stores a post's hotness score
+type HotnessUpdater interface {
+	RecalculatePostHotness(ctx context.Context, postID int64) (float64, error)
+}
+
+// HotnessRecomputer recomputes the hotness scores of the recently published posts of
+// one of shards shards, returning the number of posts
+type HotnessRecomputer interface {
+	RecomputeHotness(ctx context.Context, shard, shards int) (int, error)
+}
+
+// CountUpdater applies deltas to the aggregated counts of entities
+type CountUpdater interface {
+	IncrementUpvoteCount(ctx context.Context, entityType string, entityID int64, delta int) error
+	IncrementDownvoteCount(ctx context.Context, entityType string, entityID int64, delta int) error
+	IncrementCommentCount(ctx context.Context, entityType string, entityID int64, delta int) error
+	IncrementFavoriteCount(ctx context.Context, entityType string, entityID int64, delta int) error
+}
+
+// Mailer sends emails
+type Mailer interface {
+	SendEmail(ctx context.Context, to, subject, body string) error
+}
+
+// PostPublisher publishes scheduled posts whose time has come
+type PostPublisher interface {
+	PublishScheduledPost(ctx context.Context, postID int64) error
+}
+
+// Services are the services the common tasks run with. RegisterTasks skips the tasks
+// whose service is nil.
+type Services struct {
+	Search        PostIndexer
+	Notifications Notifier
+	Feed          FeedUpdater
+	Hotness       HotnessUpdater
+	Recompute     HotnessRecomputer
+	Counts        CountUpdater
+	Email         Mailer
+	Posts         PostPublisher
+}
+
+// RegisterTasks registers the common tasks whose service is set
+func RegisterTasks(registry *Registry, services Services) error {
+	var errs []error
+	if services.Search != nil {
+		errs = append(errs, registry.Register(TaskUpdateSearchIndex, FactoryFor(func() *UpdateSearchIndexTask {
+			return &UpdateSearchIndexTask{Indexer: services.Search}
+		})))
+	}
+	if services.Notifications != nil {
+		errs = append(errs, registry.Register(TaskSendNotification, FactoryFor(func() *SendNotificationTask {
+			return &SendNotificationTask{Notifier: services.Notifications}
+		})))
+	}
+	if services.Feed != nil {
+		errs = append(errs, registry.Register(TaskUpdateFeed, FactoryFor(func() *UpdateFeedTask {
+			return &UpdateFeedTask{Feed: services.Feed}
+		})))
+	}
+	if services.Hotness != nil {
+		errs = append(errs, registry.Register(TaskUpdateHotnessScore, FactoryFor(func() *UpdateHotnessScoreTask {
+			return &UpdateHotnessScoreTask{Hotness: services.Hotness}
+		})))
+	}
+	if services.Recompute != nil {
+		// The recompute runs periodically, so the next run retries
+		errs = append(errs, registry.RegisterWithRetry(TaskRecomputeHotness, RetryPolicy{MaxAttempts: 1}, FactoryFor(func() *RecomputeHotnessTask {
+			return &RecomputeHotnessTask{Recompute: services.Recompute}
+		})))
+	}
+	if services.Counts != nil {
+		// A count delta applied twice stays wrong, so it is not retried after an
+		// attempt that may have been applied
+		errs = append(errs, registry.RegisterWithRetry(TaskUpdateCount, RetryPolicy{MaxAttempts: 1}, FactoryFor(func() *UpdateCountTask {
+			return &UpdateCountTask{Counts: services.Counts}
+		})))
+	}
+	if services.Email != nil {
+		// Mail servers throttle, so emails back off longer
+		errs = append(errs, registry.RegisterWithRetry(TaskSendEmail, RetryPolicy{
+			MaxAttempts: 5,
+			BaseDelay:   time.Second,
+			MaxDelay:    30 * time.Second,
+		}, FactoryFor(func() *SendEmailTask {
+			return &SendEmailTask{Mailer: services.Email}
+		})))
+	}
+	if services.Posts != nil {
+		errs = append(errs, registry.Register(TaskPublishPost, FactoryFor(func() *PublishPostTask {
+			return &PublishPostTask{Posts: services.Posts}
+		})))
+	}
+	return errors.Join(errs...)
+}
 
 // UpdateSearchIndexTask updates the search index for a post
 type UpdateSearchIndexTask struct {
-	PostID int64
-	// SearchService would be injected here
+	PostID  int64       `json:"post_id"`
+	Indexer PostIndexer `json:"-"`
 }
 
+// TaskName implements NamedTask
+func (t *UpdateSearchIndexTask) TaskName() string { return TaskUpdateSearchIndex }
+
 func (t *UpdateSearchIndexTask) Execute(ctx context.Context) error {
-	// Implementation will be added when search service is implemented
-	return fmt.Errorf("search service not yet implemented")
+	if t.Indexer == nil {
+		return Permanent(fmt.Errorf("%w: search", ErrNoService))
+	}
+	return t.Indexer.ReindexPost(ctx, t.PostID)
 }
 
 // SendNotificationTask sends a notification to a user
 type SendNotificationTask struct {
-	UserID       int64
-	Notification interface{} // Will be replaced with actual Notification type
-	// NotificationService would be injected here
+	UserID       int64        `json:"user_id"`
+	Notification Notification `json:"notification"`
+	Notifier     Notifier     `json:"-"`
 }
 
+// TaskName implements NamedTask
+func (t *SendNotificationTask) TaskName() string { return TaskSendNotification }
+
 func (t *SendNotificationTask) Execute(ctx context.Context) error {
-	// Implementation will be added when notification service is implemented
-	return fmt.Errorf("notification service not yet implemented")
+	if t.Notifier == nil {
+		return Permanent(fmt.Errorf("%w: notification", ErrNoService))
+	}
+	return t.Notifier.Notify(ctx, t.UserID, t.Notification)
 }
 
 // UpdateFeedTask updates user feeds after a post is published
 type UpdateFeedTask struct {
-	PostID   int64
-	AuthorID int64
-	// FeedService would be injected here
+	PostID   int64       `json:"post_id"`
+	AuthorID int64       `json:"author_id"`
+	Feed     FeedUpdater `json:"-"`
 }
 
+// TaskName implements NamedTask
+func (t *UpdateFeedTask) TaskName() string { return TaskUpdateFeed }
+
 func (t *UpdateFeedTask) Execute(ctx context.Context) error {
-	// Implementation will be added when feed service is implemented
-	return fmt.Errorf("feed service not yet implemented")
+	if t.Feed == nil {
+		return Permanent(fmt.Errorf("%w: feed", ErrNoService))
+	}
+	return t.Feed.PushToFollowerFeeds(ctx, t.PostID, t.AuthorID)
 }
 
 // UpdateHotnessScoreTask recalculates hotness score for a post
 type UpdateHotnessScoreTask struct {
-	PostID int64
-	// HotnessService would be injected here
+	PostID  int64          `json:"post_id"`
+	Hotness HotnessUpdater `json:"-"`
 }
 
+// TaskName implements NamedTask
+func (t *UpdateHotnessScoreTask) TaskName() string { return TaskUpdateHotnessScore }
+
 func (t *UpdateHotnessScoreTask) Execute(ctx context.Context) error {
-	// Implementation will be added when hotness service is implemented
-	return fmt.Errorf("hotness service not yet implemented")
+	if t.Hotness == nil {
+		return Permanent(fmt.Errorf("%w: hotness", ErrNoService))
+	}
+	_, err := t.Hotness.RecalculatePostHotness(ctx, t.PostID)
+	return err
+}
+
+// RecomputeHotnessTask recomputes the hotness scores of a shard of the recently
+// published posts, whose scores decay with age without new votes or comments
+type RecomputeHotnessTask struct {
+	Shard     int               `json:"shard"`
+	Shards    int               `json:"shards"`
+	Recompute HotnessRecomputer `json:"-"`
+}
+
+// TaskName implements NamedTask
+func (t *RecomputeHotnessTask) TaskName() string { return TaskRecomputeHotness }
+
+func (t *RecomputeHotnessTask) Execute(ctx context.Context) error {
+	if t.Recompute == nil {
+		return Permanent(fmt.Errorf("%w: hotness recompute", ErrNoService))
+	}
+	if t.Shards <= 0 || t.Shard < 0 || t.Shard >= t.Shards {
+		return Permanent(fmt.Errorf("invalid shard %d of %d", t.Shard, t.Shards))
+	}
+	_, err := t.Recompute.RecomputeHotness(ctx, t.Shard, t.Shards)
+	return err
 }
 
 // UpdateCountTask updates aggregated counts for entities
 type UpdateCountTask struct {
-	EntityType string
-	EntityID   int64
-	CountType  string // "upvote", "downvote", "comment", "favorite"
-	Delta      int
-	// CountService would be injected here
+	EntityType string       `json:"entity_type"`
+	EntityID   int64        `json:"entity_id"`
+	CountType  string       `json:"count_type"` // "upvote", "downvote", "comment", "favorite"
+	Delta      int          `json:"delta"`
+	Counts     CountUpdater `json:"-"`
 }
 
+// TaskName implements NamedTask
+func (t *UpdateCountTask) TaskName() string { return TaskUpdateCount }
+
 func (t *UpdateCountTask) Execute(ctx context.Context) error {
-	// Implementation will be added when count service is implemented
-	return fmt.Errorf("count service not yet implemented")
+	if t.Counts == nil {
+		return Permanent(fmt.Errorf("%w: count", ErrNoService))
+	}
+
+	switch t.CountType {
+	case CountTypeUpvote:
+		return t.Counts.IncrementUpvoteCount(ctx, t.EntityType, t.EntityID, t.Delta)
+	case CountTypeDownvote:
+		return t.Counts.IncrementDownvoteCount(ctx, t.EntityType, t.EntityID, t.Delta)
+	case CountTypeComment:
+		return t.Counts.IncrementCommentCount(ctx, t.EntityType, t.EntityID, t.Delta)
+	case CountTypeFavorite:
+		return t.Counts.IncrementFavoriteCount(ctx, t.EntityType, t.EntityID, t.Delta)
+	default:
+		return Permanent(fmt.Errorf("unknown count type %q", t.CountType))
+	}
 }
 
 // SendEmailTask sends an email
 type SendEmailTask struct {
-	To      string
-	Subject string
-	Body    string
-	// EmailService would be injected here
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+	Mailer  Mailer `json:"-"`
 }
 
+// TaskName implements NamedTask
+func (t *SendEmailTask) TaskName() string { return TaskSendEmail }
+
 func (t *SendEmailTask) Execute(ctx context.Context) error {
-	// Implementation will be added when email service is implemented
-	return fmt.Errorf("email service not yet implemented")
+	if t.Mailer == nil {
+		return Permanent(fmt.Errorf("%w: email", ErrNoService))
+	}
+	return t.Mailer.SendEmail(ctx, t.To, t.Subject, t.Body)
+}
+
+// PublishPostTask publishes a scheduled post. Its job is enqueued for the post's
+// scheduled time; a post rescheduled since, or no longer scheduled, is left alone.
+type PublishPostTask struct {
+	PostID int64         `json:"post_id"`
+	Posts  PostPublisher `json:"-"`
+}
+
+// TaskName implements NamedTask
+func (t *PublishPostTask) TaskName() string { return TaskPublishPost }
+
+func (t *PublishPostTask) Execute(ctx context.Context) error {
+	if t.Posts == nil {
+		return Permanent(fmt.Errorf("%w: posts", ErrNoService))
+	}
+	return t.Posts.PublishScheduledPost(ctx, t.PostID)
 }
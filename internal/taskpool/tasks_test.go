@@ -0,0 +1,157 @@
+package taskpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServices records the calls of the tasks
+type fakeServices struct {
+	mu      sync.Mutex
+	calls   []string
+	failing atomic.Int32 // Calls left that fail
+}
+
+func (f *fakeServices) record(call string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, call)
+	if f.failing.Add(-1) >= 0 {
+		return errors.New("service unavailable")
+	}
+	return nil
+}
+
+// reset forgets the recorded calls and fails the next failing calls
+func (f *fakeServices) reset(failing int32) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = nil
+	f.failing.Store(failing)
+}
+
+func (f *fakeServices) Calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.calls...)
+}
+
+func (f *fakeServices) ReindexPost(ctx context.Context, postID int64) error {
+	return f.record("reindex")
+}
+
+func (f *fakeServices) Notify(ctx context.Context, userID int64, notification Notification) error {
+	return f.record("notify " + notification.Type)
+}
+
+func (f *fakeServices) PushToFollowerFeeds(ctx context.Context, postID int64, authorID int64) error {
+	return f.record("feed")
+}
+
+func (f *fakeServices) RecalculatePostHotness(ctx context.Context, postID int64) (float64, error) {
+	return 0, f.record("hotness")
+}
+
+func (f *fakeServices) IncrementUpvoteCount(ctx context.Context, entityType string, entityID int64, delta int) error {
+	return f.record("upvote")
+}
+
+func (f *fakeServices) IncrementDownvoteCount(ctx context.Context, entityType string, entityID int64, delta int) error {
+	return f.record("downvote")
+}
+
+func (f *fakeServices) IncrementCommentCount(ctx context.Context, entityType string, entityID int64, delta int) error {
+	return f.record("comment")
+}
+
+func (f *fakeServices) IncrementFavoriteCount(ctx context.Context, entityType string, entityID int64, delta int) error {
+	return f.record("favorite")
+}
+
+func (f *fakeServices) RecomputeHotness(ctx context.Context, shard, shards int) (int, error) {
+	return 0, f.record("recompute")
+}
+
+func (f *fakeServices) PublishScheduledPost(ctx context.Context, postID int64) error {
+	return f.record("publish")
+}
+
+func (f *fakeServices) SendEmail(ctx context.Context, to, subject, body string) error {
+	return f.record("email " + to)
+}
+
+func TestRegisterTasks(t *testing.T) {
+	services := &fakeServices{}
+	registry := NewRegistry()
+	require.NoError(t, RegisterTasks(registry, Services{
+		Search:        services,
+		Notifications: services,
+		Feed:          services,
+		Hotness:       services,
+		Recompute:     services,
+		Counts:        services,
+		Email:         services,
+		Posts:         services,
+	}))
+	assert.Len(t, registry.Names(), 8)
+
+	config := DefaultConfig()
+	config.Registry = registry
+	config.Retry = RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	pool, err := NewPool(config)
+	require.NoError(t, err)
+	defer pool.Release()
+
+	t.Run("builds tasks from their arguments", func(t *testing.T) {
+		task, err := registry.New(TaskUpdateCount, UpdateCountTask{EntityType: "post", EntityID: 1, CountType: CountTypeFavorite, Delta: 1})
+		require.NoError(t, err)
+		assert.Equal(t, TaskUpdateCount, taskName(task))
+
+		require.NoError(t, pool.Submit(task))
+		require.NoError(t, pool.SubmitTask(TaskSendNotification, SendNotificationTask{UserID: 2, Notification: Notification{Type: "system"}}))
+		require.NoError(t, pool.SubmitTask(TaskSendEmail, map[string]string{"to": "a@example.com"}))
+		pool.Wait()
+
+		assert.ElementsMatch(t, []string{"favorite", "notify system", "email a@example.com"}, services.Calls())
+	})
+
+	t.Run("count updates are not retried", func(t *testing.T) {
+		services.reset(1)
+
+		require.NoError(t, pool.SubmitTask(TaskUpdateCount, UpdateCountTask{CountType: CountTypeUpvote, Delta: 1}))
+		pool.Wait()
+		assert.Equal(t, []string{"upvote"}, services.Calls())
+	})
+
+	t.Run("other tasks are retried with the pool's policy", func(t *testing.T) {
+		services.reset(1)
+
+		require.NoError(t, pool.SubmitTask(TaskUpdateHotnessScore, UpdateHotnessScoreTask{PostID: 1}))
+		pool.Wait()
+		assert.Equal(t, []string{"hotness", "hotness"}, services.Calls())
+	})
+
+	t.Run("unknown tasks are rejected", func(t *testing.T) {
+		assert.ErrorIs(t, pool.SubmitTask("missing.task", nil), ErrUnknownTask)
+	})
+}
+
+func TestRegisterTasks_SkipsMissingServices(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, RegisterTasks(registry, Services{Email: &fakeServices{}}))
+	assert.Equal(t, []string{TaskSendEmail}, registry.Names())
+
+	assert.Error(t, RegisterTasks(registry, Services{Email: &fakeServices{}}), "registering a task twice fails")
+}
+
+func TestUpdateCountTask_UnknownCountType(t *testing.T) {
+	task := &UpdateCountTask{CountType: "shares", Counts: &fakeServices{}}
+	assert.True(t, IsPermanent(task.Execute(context.Background())))
+}
@@ -0,0 +1,2 @@
+-- Drop follows table
+DROP TABLE IF EXISTS `follows`;
@@ -0,0 +1,11 @@
+-- Create follows table
+CREATE TABLE IF NOT EXISTS `follows` (
+    `id` BIGINT PRIMARY KEY AUTO_INCREMENT,
+    `follower_id` BIGINT NOT NULL,
+    `following_id` BIGINT NOT NULL,
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY `idx_follower_following` (`follower_id`, `following_id`),
+    INDEX `idx_following_id` (`following_id`),
+    FOREIGN KEY (`follower_id`) REFERENCES `users`(`id`) ON DELETE CASCADE,
+    FOREIGN KEY (`following_id`) REFERENCES `users`(`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,8 @@
+-- Remove collection assignment from favorites
+ALTER TABLE `favorites`
+    DROP FOREIGN KEY `fk_favorites_collection`,
+    DROP INDEX `idx_favorites_collection_id`,
+    DROP COLUMN `collection_id`;
+
+-- Drop favorite collections table
+DROP TABLE IF EXISTS `favorite_collections`;
@@ -0,0 +1,17 @@
+-- Create favorite collections table
+CREATE TABLE IF NOT EXISTS `favorite_collections` (
+    `id` BIGINT PRIMARY KEY AUTO_INCREMENT,
+    `user_id` BIGINT NOT NULL,
+    `name` VARCHAR(100) NOT NULL,
+    `description` VARCHAR(500),
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY `idx_user_name` (`user_id`, `name`),
+    FOREIGN KEY (`user_id`) REFERENCES `users`(`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Let favorites be filed into a collection; deleting a collection leaves its favorites uncategorized
+ALTER TABLE `favorites`
+    ADD COLUMN `collection_id` BIGINT NULL AFTER `post_id`,
+    ADD INDEX `idx_favorites_collection_id` (`collection_id`),
+    ADD CONSTRAINT `fk_favorites_collection` FOREIGN KEY (`collection_id`) REFERENCES `favorite_collections`(`id`) ON DELETE SET NULL;
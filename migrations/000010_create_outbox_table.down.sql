@@ -0,0 +1,2 @@
+-- Drop transactional outbox table
+DROP TABLE IF EXISTS `outbox`;
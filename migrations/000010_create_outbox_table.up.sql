@@ -0,0 +1,21 @@
+-- Create transactional outbox table
+-- Rows are written in the same transaction as the change that raised the event
+-- and published to RabbitMQ by the outbox relay
+CREATE TABLE IF NOT EXISTS `outbox` (
+    `id` BIGINT PRIMARY KEY AUTO_INCREMENT,
+    `event_id` VARCHAR(36) NOT NULL,
+    `aggregate_type` VARCHAR(50) NOT NULL,
+    `aggregate_id` BIGINT NOT NULL,
+    `topic` VARCHAR(100) NOT NULL,
+    `payload` JSON NOT NULL,
+    `status` VARCHAR(20) NOT NULL DEFAULT 'pending' COMMENT 'pending, published, failed',
+    `attempts` INT NOT NULL DEFAULT 0,
+    `last_error` TEXT,
+    `next_attempt_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `published_at` DATETIME NULL,
+    UNIQUE KEY `idx_outbox_event_id` (`event_id`),
+    INDEX `idx_outbox_aggregate` (`aggregate_type`, `aggregate_id`),
+    INDEX `idx_outbox_status_next` (`status`, `next_attempt_at`),
+    INDEX `idx_outbox_published_at` (`published_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
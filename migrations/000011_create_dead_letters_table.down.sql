@@ -0,0 +1,2 @@
+-- Drop dead letters table
+DROP TABLE IF EXISTS `dead_letters`;
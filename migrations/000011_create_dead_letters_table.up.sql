@@ -0,0 +1,21 @@
+-- Create dead letters table
+-- Messages a consumer kept failing to handle, recorded from the dead-letter queue
+-- so administrators can inspect, replay or purge them
+CREATE TABLE IF NOT EXISTS `dead_letters` (
+    `id` BIGINT PRIMARY KEY AUTO_INCREMENT,
+    `letter_id` VARCHAR(36) NOT NULL,
+    `queue` VARCHAR(255) NOT NULL,
+    `consumer_group` VARCHAR(100),
+    `topic` VARCHAR(100) NOT NULL,
+    `payload` JSON NOT NULL,
+    `attempts` INT NOT NULL DEFAULT 0,
+    `last_error` TEXT,
+    `status` VARCHAR(20) NOT NULL DEFAULT 'dead' COMMENT 'dead, replayed',
+    `replay_count` INT NOT NULL DEFAULT 0,
+    `failed_at` DATETIME NOT NULL,
+    `replayed_at` DATETIME NULL,
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY `idx_dead_letters_letter_id` (`letter_id`),
+    INDEX `idx_dead_letters_group_topic` (`consumer_group`, `topic`),
+    INDEX `idx_dead_letters_status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,2 @@
+-- Drop the published posts index
+ALTER TABLE `posts` DROP INDEX `idx_status_published_at`;
@@ -0,0 +1,3 @@
+-- Index published posts by publication time
+-- The hotness recompute job scans the posts published within its window
+ALTER TABLE `posts` ADD INDEX `idx_status_published_at` (`status`, `published_at`);
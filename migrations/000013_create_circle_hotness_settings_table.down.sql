@@ -0,0 +1,2 @@
+-- Drop circle hotness settings table
+DROP TABLE IF EXISTS `circle_hotness_settings`;
@@ -0,0 +1,9 @@
+-- Create circle hotness settings table
+-- Per-circle overrides of the hotness algorithm, weights and gravity
+CREATE TABLE IF NOT EXISTS `circle_hotness_settings` (
+    `circle_id` BIGINT PRIMARY KEY,
+    `settings` JSON NOT NULL,
+    `updated_by` BIGINT NOT NULL,
+    `updated_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (`circle_id`) REFERENCES `circles`(`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
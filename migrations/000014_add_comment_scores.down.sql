@@ -0,0 +1,8 @@
+-- Drop the comment score indexes and columns
+DROP INDEX `idx_comments_post_parent_created` ON `comments`;
+DROP INDEX `idx_comments_post_parent_best` ON `comments`;
+DROP INDEX `idx_comments_post_parent_score` ON `comments`;
+
+ALTER TABLE `comments`
+    DROP COLUMN `best_score`,
+    DROP COLUMN `score`;
@@ -0,0 +1,27 @@
+-- Add vote scores to comments
+-- Comment threads sort by net votes ("top") and by the Wilson lower bound of the share of upvotes ("best")
+ALTER TABLE `comments`
+    ADD COLUMN `score` INT NOT NULL DEFAULT 0 AFTER `path`,
+    ADD COLUMN `best_score` DOUBLE NOT NULL DEFAULT 0 AFTER `score`;
+
+-- Backfill the scores from the vote counts, at 95% confidence (z = 1.96)
+UPDATE `comments` c
+JOIN `entity_counts` ec ON ec.`entity_type` = 'comment' AND ec.`entity_id` = c.`id`
+SET c.`score` = ec.`upvote_count` - ec.`downvote_count`,
+    c.`best_score` = CASE
+        WHEN ec.`upvote_count` + ec.`downvote_count` > 0 THEN (
+            ec.`upvote_count` / (ec.`upvote_count` + ec.`downvote_count`)
+            + 1.96 * 1.96 / (2 * (ec.`upvote_count` + ec.`downvote_count`))
+            - 1.96 * SQRT(
+                (ec.`upvote_count` * ec.`downvote_count` / POW(ec.`upvote_count` + ec.`downvote_count`, 2)
+                 + 1.96 * 1.96 / (4 * (ec.`upvote_count` + ec.`downvote_count`)))
+                / (ec.`upvote_count` + ec.`downvote_count`)
+            )
+        ) / (1 + 1.96 * 1.96 / (ec.`upvote_count` + ec.`downvote_count`))
+        ELSE 0
+    END;
+
+-- Index the top-level comments of a post in each sort order
+CREATE INDEX `idx_comments_post_parent_score` ON `comments` (`post_id`, `parent_id`, `status`, `score` DESC);
+CREATE INDEX `idx_comments_post_parent_best` ON `comments` (`post_id`, `parent_id`, `status`, `best_score` DESC);
+CREATE INDEX `idx_comments_post_parent_created` ON `comments` (`post_id`, `parent_id`, `status`, `created_at`);
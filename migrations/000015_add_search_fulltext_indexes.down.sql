@@ -0,0 +1,3 @@
+-- Drop the search FULLTEXT indexes
+ALTER TABLE `users` DROP INDEX `idx_users_fulltext`;
+ALTER TABLE `posts` DROP INDEX `idx_posts_fulltext`;
@@ -0,0 +1,4 @@
+-- Index the searchable text of posts and users
+-- The MySQL search backend matches keywords with these when Elasticsearch is unavailable
+ALTER TABLE `posts` ADD FULLTEXT INDEX `idx_posts_fulltext` (`title`, `summary`, `content_markdown`);
+ALTER TABLE `users` ADD FULLTEXT INDEX `idx_users_fulltext` (`username`, `bio`);